@@ -0,0 +1,86 @@
+// Package api exposes a node's DDS content and services over HTTP. The
+// gateway handlers serve stored manifests and chunks in the format expected
+// by retriever.HTTPRetriever, so any node can act as a seed server for
+// others.
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// GatewayHandler serves a node's stored content over HTTP:
+//
+//	GET /manifests/{cid} — manifest JSON
+//	GET /chunks/{cid}    — raw chunk bytes
+type GatewayHandler struct {
+	storage storage.Storage
+	mux     *http.ServeMux
+}
+
+// NewGatewayHandler returns a handler serving content from store.
+func NewGatewayHandler(store storage.Storage) *GatewayHandler {
+	g := &GatewayHandler{storage: store, mux: http.NewServeMux()}
+	g.mux.HandleFunc("/manifests/", g.handleManifest)
+	g.mux.HandleFunc("/chunks/", g.handleChunk)
+	return g
+}
+
+// ServeHTTP implements http.Handler.
+func (g *GatewayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+func (g *GatewayHandler) handleManifest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cid := strings.TrimPrefix(r.URL.Path, "/manifests/")
+	if cid == "" || strings.Contains(cid, "/") {
+		http.Error(w, "invalid manifest CID", http.StatusBadRequest)
+		return
+	}
+	manifest, err := g.storage.GetManifest(cid)
+	if err != nil {
+		if errors.Is(err, storage.ErrManifestNotFound) {
+			http.Error(w, "manifest not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to load manifest", http.StatusInternalServerError)
+		return
+	}
+	body, err := manifest.ToJSON()
+	if err != nil {
+		http.Error(w, "failed to serialize manifest", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (g *GatewayHandler) handleChunk(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cid := strings.TrimPrefix(r.URL.Path, "/chunks/")
+	if cid == "" || strings.Contains(cid, "/") {
+		http.Error(w, "invalid chunk CID", http.StatusBadRequest)
+		return
+	}
+	chunk, err := g.storage.GetChunk(cid)
+	if err != nil {
+		if errors.Is(err, storage.ErrChunkNotFound) {
+			http.Error(w, "chunk not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "failed to load chunk", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(chunk.Data)
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// NodeStatus is the /v1/status response body.
+type NodeStatus struct {
+	Node          NodeSummary     `json:"node"`
+	UptimeSeconds int64           `json:"uptimeSeconds"`
+	Chain         *ChainSummary   `json:"chain,omitempty"`
+	PeerCount     int             `json:"peerCount"`
+	Storage       StorageSummary  `json:"storage"`
+	Counters      CountersSummary `json:"counters"`
+}
+
+// NodeSummary identifies the node serving the status.
+type NodeSummary struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// ChainSummary describes the node's view of the ledger. It is omitted from
+// NodeStatus entirely when the node runs without a ledger.
+type ChainSummary struct {
+	Height          int64  `json:"height"`
+	LatestBlockHash string `json:"latestBlockHash"`
+}
+
+// StorageSummary reports local storage usage plus the pin count.
+type StorageSummary struct {
+	ChunkCount    int   `json:"chunkCount"`
+	ManifestCount int   `json:"manifestCount"`
+	TotalBytes    int64 `json:"totalBytes"`
+	PinnedCIDs    int   `json:"pinnedCids"`
+}
+
+// CountersSummary reports operation counts since the handler was created.
+type CountersSummary struct {
+	Publishes int64 `json:"publishes"`
+	Retrieves int64 `json:"retrieves"`
+}
+
+// PeerStatus is one entry of the /v1/peers response.
+type PeerStatus struct {
+	ID              string  `json:"id"`
+	Address         string  `json:"address"`
+	ReputationScore float64 `json:"reputationScore"`
+	// LastSeen is RFC 3339; omitted when the discovery layer has no
+	// liveness information for the peer.
+	LastSeen string `json:"lastSeen,omitempty"`
+}
+
+// StatusHandler serves the operator dashboard endpoints:
+//
+//	GET /v1/status — node, chain, peer, storage and counter summary
+//	GET /v1/peers  — per-peer reputation and last-seen detail
+//	GET /v1/pins   — the CIDs pinned on this node
+//
+// It aggregates the collaborators it was constructed with; the chain may
+// be nil for nodes running without a ledger.
+type StatusHandler struct {
+	node      *p2p.Node
+	storage   storage.Storage
+	discovery p2p.PeerDiscovery
+	chain     *ledger.Blockchain
+	startedAt time.Time
+
+	publishes atomic.Int64
+	retrieves atomic.Int64
+
+	pinMu sync.RWMutex
+	pins  map[string]bool
+
+	mux *http.ServeMux
+}
+
+// NewStatusHandler wires the dashboard endpoints from their collaborators.
+// chain may be nil.
+func NewStatusHandler(node *p2p.Node, store storage.Storage, discovery p2p.PeerDiscovery, chain *ledger.Blockchain) *StatusHandler {
+	h := &StatusHandler{
+		node:      node,
+		storage:   store,
+		discovery: discovery,
+		chain:     chain,
+		startedAt: clock.NowTime(),
+		pins:      make(map[string]bool),
+		mux:       http.NewServeMux(),
+	}
+	h.mux.HandleFunc("/v1/status", h.handleStatus)
+	h.mux.HandleFunc("/v1/peers", h.handlePeers)
+	h.mux.HandleFunc("/v1/pins", h.handlePins)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// RecordPublish increments the publish counter reported by /v1/status.
+func (h *StatusHandler) RecordPublish() { h.publishes.Add(1) }
+
+// RecordRetrieve increments the retrieve counter reported by /v1/status.
+func (h *StatusHandler) RecordRetrieve() { h.retrieves.Add(1) }
+
+// Pin marks a CID as pinned on this node.
+func (h *StatusHandler) Pin(cid string) {
+	h.pinMu.Lock()
+	defer h.pinMu.Unlock()
+	h.pins[cid] = true
+}
+
+// Unpin removes a CID from the pin set.
+func (h *StatusHandler) Unpin(cid string) {
+	h.pinMu.Lock()
+	defer h.pinMu.Unlock()
+	delete(h.pins, cid)
+}
+
+func (h *StatusHandler) pinList() []string {
+	h.pinMu.RLock()
+	defer h.pinMu.RUnlock()
+	pins := make([]string, 0, len(h.pins))
+	for cid := range h.pins {
+		pins = append(pins, cid)
+	}
+	sort.Strings(pins)
+	return pins
+}
+
+func (h *StatusHandler) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	usage, err := h.storage.Usage()
+	if err != nil {
+		http.Error(w, "failed to read storage usage", http.StatusInternalServerError)
+		return
+	}
+	status := NodeStatus{
+		Node:          NodeSummary{ID: h.node.ID, Address: h.node.Address},
+		UptimeSeconds: int64(time.Since(h.startedAt).Seconds()),
+		Storage: StorageSummary{
+			ChunkCount:    usage.ChunkCount,
+			ManifestCount: usage.ManifestCount,
+			TotalBytes:    usage.TotalBytes,
+			PinnedCIDs:    len(h.pinList()),
+		},
+		Counters: CountersSummary{
+			Publishes: h.publishes.Load(),
+			Retrieves: h.retrieves.Load(),
+		},
+	}
+	if h.chain != nil {
+		summary := &ChainSummary{Height: h.chain.Height()}
+		if latest := h.chain.LatestBlock(); latest != nil {
+			summary.LatestBlockHash = latest.Hash
+		}
+		status.Chain = summary
+	}
+	if h.discovery != nil {
+		if peers, err := h.discovery.DiscoverPeers(); err == nil {
+			status.PeerCount = len(peers)
+		}
+	}
+	writeJSON(w, status)
+}
+
+func (h *StatusHandler) handlePeers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var peers []p2p.Node
+	if h.discovery != nil {
+		discovered, err := h.discovery.DiscoverPeers()
+		if err != nil {
+			http.Error(w, "failed to discover peers", http.StatusInternalServerError)
+			return
+		}
+		peers = discovered
+	}
+	statuses := make([]PeerStatus, 0, len(peers))
+	for _, peer := range peers {
+		status := PeerStatus{
+			ID:              peer.ID,
+			Address:         peer.Address,
+			ReputationScore: peer.ReputationScore,
+		}
+		if !peer.LastSeen.IsZero() {
+			status.LastSeen = peer.LastSeen.UTC().Format(time.RFC3339)
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	writeJSON(w, statuses)
+}
+
+func (h *StatusHandler) handlePins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, map[string][]string{"pins": h.pinList()})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
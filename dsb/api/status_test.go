@@ -0,0 +1,149 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/api"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+func seededStatusHandler(t *testing.T) *api.StatusHandler {
+	t.Helper()
+	store := storage.NewInMemoryStorage()
+	data := []byte("status endpoint fixture content")
+	chunk := &chunking.Chunk{ID: chunking.HashContent(data), Data: data}
+	if err := store.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+
+	discovery := p2p.NewMockPeerDiscovery()
+	peer := p2p.NewNode("peer-1", "127.0.0.1:9501")
+	peer.ReputationScore = 72
+	discovery.RegisterNode(peer)
+	discovery.RegisterNode(p2p.NewNode("peer-2", "127.0.0.1:9502"))
+
+	chain := ledger.NewBlockchain()
+	if _, err := chain.AddBlock(nil); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	h := api.NewStatusHandler(p2p.NewNode("node-status", "127.0.0.1:9500"), store, discovery, chain)
+	h.Pin("cid-pinned")
+	h.RecordPublish()
+	h.RecordRetrieve()
+	h.RecordRetrieve()
+	return h
+}
+
+func TestStatusEndpoint_Schema(t *testing.T) {
+	server := httptest.NewServer(seededStatusHandler(t))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/status")
+	if err != nil {
+		t.Fatalf("GET /v1/status: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var status api.NodeStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	if status.Node.ID != "node-status" || status.Node.Address != "127.0.0.1:9500" {
+		t.Errorf("node summary = %+v", status.Node)
+	}
+	if status.Chain == nil {
+		t.Fatal("chain summary missing")
+	}
+	if status.Chain.Height != 2 || status.Chain.LatestBlockHash == "" {
+		t.Errorf("chain summary = %+v, want height 2 with a block hash", status.Chain)
+	}
+	if status.PeerCount != 2 {
+		t.Errorf("peerCount = %d, want 2", status.PeerCount)
+	}
+	if status.Storage.ChunkCount != 1 || status.Storage.TotalBytes == 0 {
+		t.Errorf("storage summary = %+v", status.Storage)
+	}
+	if status.Storage.PinnedCIDs != 1 {
+		t.Errorf("pinnedCids = %d, want 1", status.Storage.PinnedCIDs)
+	}
+	if status.Counters.Publishes != 1 || status.Counters.Retrieves != 2 {
+		t.Errorf("counters = %+v", status.Counters)
+	}
+}
+
+func TestStatusEndpoint_OmitsChainWhenAbsent(t *testing.T) {
+	h := api.NewStatusHandler(p2p.NewNode("node-nochain", "127.0.0.1:9510"),
+		storage.NewInMemoryStorage(), p2p.NewMockPeerDiscovery(), nil)
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/status")
+	if err != nil {
+		t.Fatalf("GET /v1/status: %v", err)
+	}
+	defer resp.Body.Close()
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, present := raw["chain"]; present {
+		t.Error("chain key present for a node without a ledger")
+	}
+}
+
+func TestPeersEndpoint_SortedDetail(t *testing.T) {
+	server := httptest.NewServer(seededStatusHandler(t))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/peers")
+	if err != nil {
+		t.Fatalf("GET /v1/peers: %v", err)
+	}
+	defer resp.Body.Close()
+	var peers []api.PeerStatus
+	if err := json.NewDecoder(resp.Body).Decode(&peers); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("got %d peers, want 2", len(peers))
+	}
+	if peers[0].ID != "peer-1" || peers[1].ID != "peer-2" {
+		t.Errorf("peer order = [%s %s], want sorted by ID", peers[0].ID, peers[1].ID)
+	}
+	if peers[0].ReputationScore != 72 {
+		t.Errorf("peer-1 reputation = %v, want 72", peers[0].ReputationScore)
+	}
+	if peers[0].LastSeen == "" {
+		t.Error("peer-1 lastSeen missing")
+	}
+}
+
+func TestPinsEndpoint(t *testing.T) {
+	h := seededStatusHandler(t)
+	h.Pin("cid-another")
+	h.Unpin("cid-pinned")
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/v1/pins")
+	if err != nil {
+		t.Fatalf("GET /v1/pins: %v", err)
+	}
+	defer resp.Body.Close()
+	var body map[string][]string
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body["pins"]) != 1 || body["pins"][0] != "cid-another" {
+		t.Errorf("pins = %v, want [cid-another]", body["pins"])
+	}
+}
@@ -0,0 +1,416 @@
+package api
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+// Defaults for the WebSocket push endpoint.
+const (
+	// DefaultWSSendBuffer is how many events may queue for one connection
+	// before the client is considered stalled and disconnected.
+	DefaultWSSendBuffer = 64
+	// DefaultWSPingInterval is how often the server pings each
+	// connection; a client silent for three intervals is disconnected.
+	DefaultWSPingInterval = 30 * time.Second
+	// wsWriteTimeout bounds any single frame write.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// wsAcceptGUID is the fixed GUID of RFC 6455 §4.2.2 used to derive the
+// Sec-WebSocket-Accept response key.
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes (RFC 6455 §5.2).
+const (
+	wsOpText  = 0x1
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xa
+)
+
+// WSEvent is one JSON message pushed to a WebSocket client. Type selects
+// which of the remaining fields are set: "block" carries Index, Hash, and
+// TxCount; "post" carries Author and CID; "profile" carries Owner and CID.
+type WSEvent struct {
+	Type    string `json:"type"`
+	Index   int64  `json:"index,omitempty"`
+	Hash    string `json:"hash,omitempty"`
+	TxCount int    `json:"txCount,omitempty"`
+	Author  string `json:"author,omitempty"`
+	Owner   string `json:"owner,omitempty"`
+	CID     string `json:"cid,omitempty"`
+}
+
+// WSHandler serves the push API:
+//
+//	GET /v1/ws — upgrade to WebSocket, stream chain events as JSON
+//
+// Streamed events are every appended block, posts by addresses the viewer
+// follows (all posts when no viewer is given), and profile updates. The
+// viewer is named by the "viewer" query parameter; the follow set is read
+// from the chain at connect time and tracked live from streamed follow and
+// unfollow transactions.
+//
+// Each connection has a bounded send buffer — a client that stops reading
+// is disconnected rather than allowed to pile up unread events — and a
+// ping/pong keepalive that drops clients silent for three ping intervals.
+type WSHandler struct {
+	chain        *ledger.Blockchain
+	mux          *http.ServeMux
+	sendBuffer   int
+	pingInterval time.Duration
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// NewWSHandler returns a handler streaming events from chain.
+func NewWSHandler(chain *ledger.Blockchain) *WSHandler {
+	h := &WSHandler{
+		chain:        chain,
+		mux:          http.NewServeMux(),
+		sendBuffer:   DefaultWSSendBuffer,
+		pingInterval: DefaultWSPingInterval,
+		conns:        make(map[net.Conn]struct{}),
+	}
+	h.mux.HandleFunc("/v1/ws", h.handleWS)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *WSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// SetSendBuffer replaces the per-connection event buffer for connections
+// accepted afterwards; values below 1 restore the default.
+func (h *WSHandler) SetSendBuffer(n int) {
+	if n < 1 {
+		n = DefaultWSSendBuffer
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sendBuffer = n
+}
+
+// SetPingInterval replaces the keepalive interval for connections accepted
+// afterwards; values of zero or less restore the default.
+func (h *WSHandler) SetPingInterval(d time.Duration) {
+	if d <= 0 {
+		d = DefaultWSPingInterval
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pingInterval = d
+}
+
+// Close disconnects every client and refuses further upgrades, for server
+// shutdown.
+func (h *WSHandler) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for conn := range h.conns {
+		conn.Close()
+	}
+	h.conns = make(map[net.Conn]struct{})
+	return nil
+}
+
+// handleWS performs the RFC 6455 handshake and streams events until the
+// client disconnects, stalls, or the handler is closed.
+func (h *WSHandler) handleWS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade") {
+		http.Error(w, "websocket upgrade required", http.StatusBadRequest)
+		return
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "missing Sec-WebSocket-Key", http.StatusBadRequest)
+		return
+	}
+	viewer := r.URL.Query().Get("viewer")
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		http.Error(w, "server shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	sendBuffer, pingInterval := h.sendBuffer, h.pingInterval
+	h.mu.Unlock()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "connection cannot be hijacked", http.StatusInternalServerError)
+		return
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, "hijack failed", http.StatusInternalServerError)
+		return
+	}
+	// Subscribe before the 101 goes out, so a block appended the moment
+	// the client sees the handshake cannot fall between response and
+	// subscription.
+	blocks, cancel := h.chain.Subscribe(sendBuffer)
+	defer cancel()
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		conn.Close()
+		return
+	}
+	h.conns[conn] = struct{}{}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.conns, conn)
+		h.mu.Unlock()
+		conn.Close()
+	}()
+
+	h.stream(conn, rw.Reader, blocks, viewer, sendBuffer, pingInterval)
+}
+
+// stream is the per-connection loop: a pump goroutine turns appended
+// blocks into queued events, the main loop writes them out interleaved
+// with keepalive pings, and a reader goroutine consumes client frames to
+// track liveness.
+func (h *WSHandler) stream(conn net.Conn, r *bufio.Reader, blocks <-chan *ledger.Block, viewer string, sendBuffer int, pingInterval time.Duration) {
+	events := make(chan WSEvent, sendBuffer)
+	done := make(chan struct{})
+	var closeOnce sync.Once
+	shutdown := func() {
+		closeOnce.Do(func() {
+			close(done)
+			conn.Close()
+		})
+	}
+	defer shutdown()
+
+	// Liveness: any frame from the client counts. The reader goroutine
+	// updates it; the ping loop checks it.
+	var liveMu sync.Mutex
+	lastSeen := time.Now()
+	go func() {
+		for {
+			opcode, _, err := wsReadFrame(r)
+			if err != nil {
+				shutdown()
+				return
+			}
+			liveMu.Lock()
+			lastSeen = time.Now()
+			liveMu.Unlock()
+			if opcode == wsOpClose {
+				shutdown()
+				return
+			}
+		}
+	}()
+
+	// Pump: translate blocks into events without ever blocking on the
+	// client. A full event buffer means the client is not keeping up.
+	go func() {
+		following := h.followSet(viewer)
+		for {
+			select {
+			case <-done:
+				return
+			case block, ok := <-blocks:
+				if !ok {
+					return
+				}
+				for _, event := range blockEvents(block, viewer, following) {
+					select {
+					case events <- event:
+					default:
+						shutdown()
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case event := <-events:
+			payload, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if h.writeFrame(conn, wsOpText, payload) != nil {
+				return
+			}
+		case <-ping.C:
+			liveMu.Lock()
+			silent := time.Since(lastSeen)
+			liveMu.Unlock()
+			if silent > 3*pingInterval {
+				return
+			}
+			if h.writeFrame(conn, wsOpPing, nil) != nil {
+				return
+			}
+		}
+	}
+}
+
+// followSet reads the viewer's current follow set off the chain. An empty
+// viewer follows nobody — and is filtered on by blockEvents instead.
+func (h *WSHandler) followSet(viewer string) map[string]bool {
+	following := make(map[string]bool)
+	if viewer == "" {
+		return following
+	}
+	for _, block := range h.chain.Blocks() {
+		applyFollows(block, viewer, following)
+	}
+	return following
+}
+
+// applyFollows folds the viewer's follow and unfollow transactions in the
+// block into the set.
+func applyFollows(block *ledger.Block, viewer string, following map[string]bool) {
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.SenderAddress != viewer {
+			continue
+		}
+		switch tx.Type {
+		case ledger.TxTypeFollowed:
+			following[string(tx.Payload)] = true
+		case ledger.TxTypeUnfollowed:
+			delete(following, string(tx.Payload))
+		}
+	}
+}
+
+// blockEvents renders one appended block as the events the viewer should
+// see, updating the follow set from the block's own transactions first so
+// a follow and a post in the same block are delivered.
+func blockEvents(block *ledger.Block, viewer string, following map[string]bool) []WSEvent {
+	if viewer != "" {
+		applyFollows(block, viewer, following)
+	}
+	events := []WSEvent{{
+		Type:    "block",
+		Index:   block.Index,
+		Hash:    block.Hash,
+		TxCount: len(block.Transactions),
+	}}
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		switch tx.Type {
+		case ledger.TxTypePostCreated:
+			if viewer == "" || following[tx.SenderAddress] {
+				events = append(events, WSEvent{Type: "post", Author: tx.SenderAddress, CID: string(tx.Payload)})
+			}
+		case ledger.TxTypeProfileUpdated:
+			events = append(events, WSEvent{Type: "profile", Owner: tx.SenderAddress, CID: string(tx.Payload)})
+		}
+	}
+	return events
+}
+
+// writeFrame writes one unmasked server frame with a bounded deadline.
+func (h *WSHandler) writeFrame(conn net.Conn, opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode // FIN, no fragmentation
+	switch {
+	case len(payload) < 126:
+		header[1] = byte(len(payload))
+	case len(payload) < 1<<16:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(len(payload)))
+	}
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	if _, err := conn.Write(header); err != nil {
+		return err
+	}
+	_, err := conn.Write(payload)
+	return err
+}
+
+// wsReadFrame reads one client frame, unmasking the payload. Client frames
+// must be masked per RFC 6455 §5.1.
+func wsReadFrame(r *bufio.Reader) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > 1<<20 {
+		return 0, nil, fmt.Errorf("api: websocket frame of %d bytes exceeds the limit", length)
+	}
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return opcode, payload, nil
+}
@@ -0,0 +1,244 @@
+package api_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/api"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// wsClient is a minimal WebSocket test client over a raw TCP connection.
+type wsClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialWS connects to the test server and completes the upgrade handshake.
+func dialWS(t *testing.T, ts *httptest.Server, query string) *wsClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	fmt.Fprintf(conn, "GET /v1/ws%s HTTP/1.1\r\n"+
+		"Host: %s\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n", query, ts.Listener.Addr())
+	r := bufio.NewReader(conn)
+	status, err := r.ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading handshake response: %v", err)
+	}
+	if !strings.Contains(status, "101") {
+		t.Fatalf("handshake status %q, want 101", strings.TrimSpace(status))
+	}
+	for { // drain the remaining response headers
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading handshake headers: %v", err)
+		}
+		if line == "\r\n" {
+			break
+		}
+	}
+	return &wsClient{conn: conn, r: r}
+}
+
+// readEvent reads frames until a text frame arrives, answering pings with
+// pongs, and decodes it as a WSEvent.
+func (c *wsClient) readEvent(t *testing.T, timeout time.Duration) api.WSEvent {
+	t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			t.Fatalf("reading frame: %v", err)
+		}
+		switch opcode {
+		case 0x9: // ping
+			c.writeFrame(t, 0xa, payload)
+		case 0x1: // text
+			var event api.WSEvent
+			if err := json.Unmarshal(payload, &event); err != nil {
+				t.Fatalf("decoding event %q: %v", payload, err)
+			}
+			return event
+		}
+	}
+}
+
+func (c *wsClient) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0] & 0x0f, payload, nil
+}
+
+// writeFrame sends one masked client frame, as RFC 6455 requires of
+// clients.
+func (c *wsClient) writeFrame(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+	header := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	masked := make([]byte, len(payload))
+	for i, b := range payload {
+		masked[i] = b ^ mask[i%4]
+	}
+	frame := append(append(header, mask...), masked...)
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatalf("writing frame: %v", err)
+	}
+}
+
+func wsChainFixture(t *testing.T) (*ledger.Blockchain, *identity.Wallet, *identity.Wallet) {
+	t.Helper()
+	chain := ledger.NewBlockchain()
+	viewer, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	author, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	addTx(t, chain, viewer, ledger.TxTypeFollowed, []byte(author.Address))
+	return chain, viewer, author
+}
+
+func addTx(t *testing.T, chain *ledger.Blockchain, wallet *identity.Wallet, txType ledger.TransactionType, payload []byte) {
+	t.Helper()
+	tx, err := ledger.NewSignedTransaction(wallet, txType, payload)
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if _, err := chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+}
+
+func TestWS_StreamsBlockPostAndProfileEvents(t *testing.T) {
+	chain, viewer, author := wsChainFixture(t)
+	handler := api.NewWSHandler(chain)
+	defer handler.Close()
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	client := dialWS(t, ts, "?viewer="+viewer.Address)
+
+	// A post by the followed author: one block event, one post event.
+	addTx(t, chain, author, ledger.TxTypePostCreated, []byte("cid-followed-post"))
+	if event := client.readEvent(t, 2*time.Second); event.Type != "block" {
+		t.Fatalf("first event %+v, want the block", event)
+	}
+	event := client.readEvent(t, 2*time.Second)
+	if event.Type != "post" || event.Author != author.Address || event.CID != "cid-followed-post" {
+		t.Fatalf("second event %+v, want the followed author's post", event)
+	}
+
+	// A post by a stranger is filtered; its block event still arrives.
+	stranger, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	addTx(t, chain, stranger, ledger.TxTypePostCreated, []byte("cid-stranger-post"))
+	if event := client.readEvent(t, 2*time.Second); event.Type != "block" {
+		t.Fatalf("stranger's block produced %+v, want only the block event", event)
+	}
+
+	// A profile update is pushed regardless of the follow set.
+	addTx(t, chain, stranger, ledger.TxTypeProfileUpdated, []byte("cid-new-profile"))
+	if event := client.readEvent(t, 2*time.Second); event.Type != "block" {
+		t.Fatalf("profile block produced %+v, want the block event first", event)
+	}
+	event = client.readEvent(t, 2*time.Second)
+	if event.Type != "profile" || event.Owner != stranger.Address || event.CID != "cid-new-profile" {
+		t.Fatalf("profile event %+v, want the stranger's update", event)
+	}
+}
+
+func TestWS_DisconnectsSilentClient(t *testing.T) {
+	chain, _, _ := wsChainFixture(t)
+	handler := api.NewWSHandler(chain)
+	defer handler.Close()
+	handler.SetPingInterval(20 * time.Millisecond)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	client := dialWS(t, ts, "")
+
+	// Never answer pings: the server must hang up after three silent
+	// intervals. Read until the connection dies.
+	client.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := client.readFrame(); err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				t.Fatal("server never disconnected the silent client")
+			}
+			return // connection closed by the server, as required
+		}
+	}
+}
+
+func TestWS_CloseDisconnectsClientsAndRefusesUpgrades(t *testing.T) {
+	chain, _, _ := wsChainFixture(t)
+	handler := api.NewWSHandler(chain)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	client := dialWS(t, ts, "")
+	if err := handler.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	client.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := client.readFrame(); err == nil {
+		t.Fatal("client connection survived handler Close")
+	}
+
+	conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /v1/ws HTTP/1.1\r\nHost: x\r\nUpgrade: websocket\r\n"+
+		"Connection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	status, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading refusal: %v", err)
+	}
+	if !strings.Contains(status, "503") {
+		t.Errorf("post-Close upgrade got %q, want 503", strings.TrimSpace(status))
+	}
+}
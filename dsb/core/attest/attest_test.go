@@ -0,0 +1,124 @@
+package attest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/attest"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func newWallet(t *testing.T) *identity.Wallet {
+	t.Helper()
+	w, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	return w
+}
+
+func TestAttestationsFor_IgnoresUntrustedAttesters(t *testing.T) {
+	trusted := newWallet(t)
+	untrusted := newWallet(t)
+	subject := newWallet(t).Address
+
+	ix := attest.NewAttestationIndex(trusted.Address)
+	for _, attester := range []*identity.Wallet{trusted, untrusted} {
+		a, err := attest.NewAttestation(attester, subject, attest.ClaimVerifiedHuman, 0)
+		if err != nil {
+			t.Fatalf("NewAttestation: %v", err)
+		}
+		ix.Apply(a)
+	}
+
+	active := ix.AttestationsFor(subject)
+	if len(active) != 1 {
+		t.Fatalf("got %d active attestations, want 1", len(active))
+	}
+	if active[0].AttesterAddress != trusted.Address {
+		t.Errorf("active attestation from %s, want trusted attester", active[0].AttesterAddress)
+	}
+}
+
+func TestAttestationsFor_HonorsExpiry(t *testing.T) {
+	attester := newWallet(t)
+	subject := newWallet(t).Address
+	ix := attest.NewAttestationIndex(attester.Address)
+
+	expired, err := attest.NewAttestation(attester, subject, attest.ClaimVerifiedHuman,
+		time.Now().Add(-time.Hour).UnixNano())
+	if err != nil {
+		t.Fatalf("NewAttestation: %v", err)
+	}
+	ix.Apply(expired)
+	if active := ix.AttestationsFor(subject); len(active) != 0 {
+		t.Errorf("expired attestation surfaced: %v", active)
+	}
+
+	fresh, err := attest.NewAttestation(attester, subject, attest.ClaimModerator,
+		time.Now().Add(time.Hour).UnixNano())
+	if err != nil {
+		t.Fatalf("NewAttestation: %v", err)
+	}
+	ix.Apply(fresh)
+	if active := ix.AttestationsFor(subject); len(active) != 1 || active[0].Claim != attest.ClaimModerator {
+		t.Errorf("active = %v, want only the unexpired moderator claim", active)
+	}
+}
+
+func TestAttestationsFor_NegativeAttestationSupersedes(t *testing.T) {
+	attester := newWallet(t)
+	subject := newWallet(t).Address
+	ix := attest.NewAttestationIndex(attester.Address)
+
+	positive, err := attest.NewAttestation(attester, subject, attest.ClaimVerifiedHuman, 0)
+	if err != nil {
+		t.Fatalf("NewAttestation: %v", err)
+	}
+	ix.Apply(positive)
+	if active := ix.AttestationsFor(subject); len(active) != 1 {
+		t.Fatalf("got %d active attestations before revocation, want 1", len(active))
+	}
+
+	revocation, err := attest.NewRevocation(attester, subject, attest.ClaimVerifiedHuman)
+	if err != nil {
+		t.Fatalf("NewRevocation: %v", err)
+	}
+	ix.Apply(revocation)
+	if active := ix.AttestationsFor(subject); len(active) != 0 {
+		t.Errorf("revoked attestation still active: %v", active)
+	}
+}
+
+func TestAttestationIndex_FollowsChain(t *testing.T) {
+	attester := newWallet(t)
+	subject := newWallet(t).Address
+
+	chain := ledger.NewBlockchain()
+	ix := attest.NewAttestationIndex(attester.Address)
+	ix.Follow(chain)
+
+	a, err := attest.NewAttestation(attester, subject, attest.ClaimVerifiedHuman, 0)
+	if err != nil {
+		t.Fatalf("NewAttestation: %v", err)
+	}
+	tx, err := a.Transaction(attester)
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if _, err := chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	if active := ix.AttestationsFor(subject); len(active) != 1 {
+		t.Errorf("index following chain has %d active attestations, want 1", len(active))
+	}
+
+	// A rebuilt index over the same chain converges to the same view.
+	rebuilt := attest.NewAttestationIndex(attester.Address)
+	rebuilt.BuildFromChain(chain)
+	if active := rebuilt.AttestationsFor(subject); len(active) != 1 {
+		t.Errorf("rebuilt index has %d active attestations, want 1", len(active))
+	}
+}
@@ -0,0 +1,143 @@
+// Package attest implements profile verification badges. A trusted
+// attester signs an Attestation vouching for a subject address with a
+// claim such as "verified-human"; a TxTypeAttestation transaction carries
+// the attestation on the ledger, and AttestationIndex builds the
+// per-subject view clients consult. Which attesters to trust is a local
+// decision — the index only surfaces attestations from addresses the node
+// was configured to trust.
+package attest
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// Claim names what an attestation vouches for.
+type Claim string
+
+// The claims attesters currently issue.
+const (
+	ClaimVerifiedHuman Claim = "verified-human"
+	ClaimModerator     Claim = "moderator"
+)
+
+// Attestation is one attester's signed statement about a subject address.
+// A Negative attestation with a later timestamp supersedes (revokes) the
+// attester's earlier positive attestation for the same subject and claim.
+type Attestation struct {
+	SubjectAddress string `json:"subjectAddress"`
+	Claim          Claim  `json:"claim"`
+	// ExpiresAt is Unix nanoseconds; zero means the attestation does not
+	// expire on its own.
+	ExpiresAt         int64  `json:"expiresAt,omitempty"`
+	Negative          bool   `json:"negative,omitempty"`
+	AttesterAddress   string `json:"attesterAddress"`
+	AttesterPublicKey []byte `json:"attesterPublicKey"`
+	Timestamp         int64  `json:"timestamp"`
+	Signature         []byte `json:"signature,omitempty"`
+}
+
+func init() {
+	ledger.RegisterPayloadCodec(ledger.TxTypeAttestation,
+		ledger.JSONPayloadCodec(func() interface{} { return &Attestation{} }))
+}
+
+// NewAttestation signs an attestation about subject with the attester's
+// wallet. A zero expiresAt means no expiry.
+func NewAttestation(attester *identity.Wallet, subject string, claim Claim, expiresAt int64) (*Attestation, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("attest: attestation requires a subject address")
+	}
+	if claim == "" {
+		return nil, fmt.Errorf("attest: attestation requires a claim")
+	}
+	a := &Attestation{
+		SubjectAddress:    subject,
+		Claim:             claim,
+		ExpiresAt:         expiresAt,
+		AttesterAddress:   attester.Address,
+		AttesterPublicKey: attester.PublicKey,
+		Timestamp:         clock.Now(),
+	}
+	signing, err := a.signingBytes()
+	if err != nil {
+		return nil, err
+	}
+	if a.Signature, err = attester.Sign(signing); err != nil {
+		return nil, fmt.Errorf("attest: failed to sign attestation: %w", err)
+	}
+	return a, nil
+}
+
+// NewRevocation signs a negative attestation superseding the attester's
+// earlier attestation for the same subject and claim.
+func NewRevocation(attester *identity.Wallet, subject string, claim Claim) (*Attestation, error) {
+	a, err := NewAttestation(attester, subject, claim, 0)
+	if err != nil {
+		return nil, err
+	}
+	a.Negative = true
+	signing, err := a.signingBytes()
+	if err != nil {
+		return nil, err
+	}
+	if a.Signature, err = attester.Sign(signing); err != nil {
+		return nil, fmt.Errorf("attest: failed to sign revocation: %w", err)
+	}
+	return a, nil
+}
+
+// signingBytes returns the canonical bytes covered by the attester
+// signature.
+func (a *Attestation) signingBytes() ([]byte, error) {
+	unsigned := *a
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("attest: failed to serialize attestation for signing: %w", err)
+	}
+	return data, nil
+}
+
+// VerifySignature reports whether the attestation was signed by the
+// claimed attester.
+func (a *Attestation) VerifySignature() bool {
+	if len(a.Signature) == 0 || len(a.AttesterPublicKey) == 0 {
+		return false
+	}
+	if !identity.AddressesEqual(identity.PublicKeyToAddress(a.AttesterPublicKey), a.AttesterAddress) {
+		return false
+	}
+	data, err := a.signingBytes()
+	if err != nil {
+		return false
+	}
+	return identity.VerifySignature(a.AttesterPublicKey, data, a.Signature)
+}
+
+// Expired reports whether the attestation has lapsed at the given time.
+func (a *Attestation) Expired(now time.Time) bool {
+	return a.ExpiresAt != 0 && a.ExpiresAt < now.UnixNano()
+}
+
+// Transaction wraps the attestation in a signed TxTypeAttestation
+// transaction from the attester.
+func (a *Attestation) Transaction(attester *identity.Wallet) (*ledger.Transaction, error) {
+	payload, err := json.Marshal(a)
+	if err != nil {
+		return nil, fmt.Errorf("attest: failed to serialize attestation: %w", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypeAttestation, attester.Address, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Sign(attester); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,119 @@
+package attest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// AttestationIndex maintains the latest attestation per (attester,
+// subject, claim) from a configured set of trusted attesters. It is safe
+// for concurrent use, so it can follow a live chain via
+// Blockchain.OnBlockAdded while clients query it.
+type AttestationIndex struct {
+	mu      sync.RWMutex
+	trusted map[string]bool
+	// bySubject maps subject address → attester|claim → latest attestation.
+	bySubject map[string]map[string]*Attestation
+}
+
+// NewAttestationIndex returns an index trusting the given attester
+// addresses. Attestations from any other address are ignored.
+func NewAttestationIndex(trustedAttesters ...string) *AttestationIndex {
+	trusted := make(map[string]bool, len(trustedAttesters))
+	for _, addr := range trustedAttesters {
+		trusted[addr] = true
+	}
+	return &AttestationIndex{
+		trusted:   trusted,
+		bySubject: make(map[string]map[string]*Attestation),
+	}
+}
+
+// ApplyTransaction folds one transaction into the index. Non-attestation
+// transactions, attestations from untrusted attesters, and attestations
+// failing signature verification are silently skipped — the chain may
+// legitimately carry them, this node just doesn't surface them.
+func (ix *AttestationIndex) ApplyTransaction(tx *ledger.Transaction) {
+	if tx.Type != ledger.TxTypeAttestation {
+		return
+	}
+	decoded, err := tx.DecodePayload()
+	if err != nil {
+		return
+	}
+	a, ok := decoded.(*Attestation)
+	if !ok {
+		return
+	}
+	ix.Apply(a)
+}
+
+// Apply folds one attestation into the index, keeping only the latest
+// statement per (attester, subject, claim) so a newer negative
+// attestation supersedes an older positive one.
+func (ix *AttestationIndex) Apply(a *Attestation) {
+	if !a.VerifySignature() {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.trusted[a.AttesterAddress] {
+		return
+	}
+	key := a.AttesterAddress + "|" + string(a.Claim)
+	latest := ix.bySubject[a.SubjectAddress]
+	if latest == nil {
+		latest = make(map[string]*Attestation)
+		ix.bySubject[a.SubjectAddress] = latest
+	}
+	if prev, ok := latest[key]; ok && prev.Timestamp >= a.Timestamp {
+		return
+	}
+	latest[key] = a
+}
+
+// BuildFromChain replays every available block's transactions into the
+// index, e.g. after loading a chain from disk.
+func (ix *AttestationIndex) BuildFromChain(chain *ledger.Blockchain) {
+	for _, block := range chain.Blocks() {
+		for i := range block.Transactions {
+			ix.ApplyTransaction(&block.Transactions[i])
+		}
+	}
+}
+
+// Follow registers the index on the chain's block-added hook so later
+// blocks are folded in as they are appended.
+func (ix *AttestationIndex) Follow(chain *ledger.Blockchain) {
+	chain.OnBlockAdded(func(block *ledger.Block) {
+		for i := range block.Transactions {
+			ix.ApplyTransaction(&block.Transactions[i])
+		}
+	})
+}
+
+// AttestationsFor returns the subject's active attestations: from trusted
+// attesters, not superseded by a negative attestation, and not expired.
+// Results are ordered by claim then attester for stable output.
+func (ix *AttestationIndex) AttestationsFor(subject string) []Attestation {
+	now := clock.NowTime()
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	var active []Attestation
+	for _, a := range ix.bySubject[subject] {
+		if a.Negative || a.Expired(now) {
+			continue
+		}
+		active = append(active, *a)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].Claim != active[j].Claim {
+			return active[i].Claim < active[j].Claim
+		}
+		return active[i].AttesterAddress < active[j].AttesterAddress
+	})
+	return active
+}
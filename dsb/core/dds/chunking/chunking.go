@@ -0,0 +1,96 @@
+// Package chunking splits raw content into fixed-size, content-addressed
+// chunks and describes the result with a Manifest. Chunk and manifest
+// identifiers are hex-encoded SHA-256 digests, so identical content always
+// produces identical identifiers regardless of which node performs the split.
+package chunking
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// DefaultChunkSize is the chunk size used when callers have no preference.
+const DefaultChunkSize = 256 * 1024
+
+// Chunk is a single content-addressed piece of a larger piece of content.
+// ID is the hex-encoded SHA-256 digest of Data.
+type Chunk struct {
+	ID   string `json:"id"`
+	Data []byte `json:"data"`
+}
+
+// BasicChunker splits content into fixed-size chunks.
+type BasicChunker struct {
+	ChunkSize int
+
+	// swarmKey, when set, derives chunk, content, and manifest IDs as
+	// HMAC-SHA256 under the key instead of plain SHA-256; see
+	// NewKeyedChunker.
+	swarmKey []byte
+}
+
+// NewBasicChunker returns a chunker producing chunks of at most chunkSize
+// bytes. A non-positive chunkSize is rejected.
+func NewBasicChunker(chunkSize int) (*BasicChunker, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("chunking: chunk size must be positive, got %d", chunkSize)
+	}
+	return &BasicChunker{ChunkSize: chunkSize}, nil
+}
+
+// NewKeyedChunker returns a chunker whose identifiers are HMAC-SHA256
+// under swarmKey instead of plain SHA-256, for private swarms where
+// outsiders must not be able to probe for content existence by digest.
+// Manifests it generates record KeyFingerprint(swarmKey) so retrievers can
+// pick the matching key from their KeyProvider; the wire format is
+// otherwise unchanged. Peers without the key cannot verify or deduplicate
+// the chunks.
+func NewKeyedChunker(chunkSize int, swarmKey []byte) (*BasicChunker, error) {
+	c, err := NewBasicChunker(chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	if len(swarmKey) == 0 {
+		return nil, fmt.Errorf("chunking: keyed chunker requires a non-empty swarm key")
+	}
+	c.swarmKey = swarmKey
+	return c, nil
+}
+
+// HashContent returns the hex-encoded SHA-256 digest of data. It is the
+// canonical identifier function for both chunks and whole content.
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashContent returns data's identifier under this chunker's ID scheme:
+// the keyed HMAC for a keyed chunker, the plain SHA-256 digest otherwise.
+// Publishers derive content IDs through this method so keyed and plain
+// content address consistently.
+func (c *BasicChunker) HashContent(data []byte) string {
+	if len(c.swarmKey) > 0 {
+		return KeyedHash(c.swarmKey, data)
+	}
+	return HashContent(data)
+}
+
+// ChunkContent splits content into chunks of at most ChunkSize bytes, in
+// order. Empty content is rejected: there is nothing to address.
+func (c *BasicChunker) ChunkContent(content []byte) ([]Chunk, error) {
+	if len(content) == 0 {
+		return nil, fmt.Errorf("chunking: cannot chunk empty content")
+	}
+	chunks := make([]Chunk, 0, (len(content)+c.ChunkSize-1)/c.ChunkSize)
+	for start := 0; start < len(content); start += c.ChunkSize {
+		end := start + c.ChunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+		data := make([]byte, end-start)
+		copy(data, content[start:end])
+		chunks = append(chunks, Chunk{ID: c.HashContent(data), Data: data})
+	}
+	return chunks, nil
+}
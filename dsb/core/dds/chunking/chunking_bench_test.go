@@ -0,0 +1,99 @@
+package chunking_test
+
+// Run the full suite with:
+//
+//	go test -bench=. -benchmem ./...
+//
+// from the module root. Allocation budgets for the 1MB case are enforced
+// by TestChunkContent_AllocationBudget below so regressions fail CI rather
+// than only showing up in benchmark output.
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+func randomContent(tb testing.TB, size int) []byte {
+	tb.Helper()
+	content := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(content)
+	return content
+}
+
+func BenchmarkChunkContent(b *testing.B) {
+	sizes := []struct {
+		name string
+		size int
+	}{
+		{"4KB", 4 << 10},
+		{"1MB", 1 << 20},
+		{"64MB", 64 << 20},
+	}
+	chunkSizes := []int{4 << 10, 64 << 10, chunking.DefaultChunkSize}
+	for _, sz := range sizes {
+		content := randomContent(b, sz.size)
+		for _, cs := range chunkSizes {
+			if cs > sz.size {
+				continue
+			}
+			b.Run(fmt.Sprintf("%s/%dKBChunks", sz.name, cs>>10), func(b *testing.B) {
+				chunker, err := chunking.NewBasicChunker(cs)
+				if err != nil {
+					b.Fatalf("NewBasicChunker: %v", err)
+				}
+				b.ReportAllocs()
+				b.SetBytes(int64(sz.size))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := chunker.ChunkContent(content); err != nil {
+						b.Fatalf("ChunkContent: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGenerateManifest(b *testing.B) {
+	content := randomContent(b, 1<<20)
+	chunker, err := chunking.NewBasicChunker(64 << 10)
+	if err != nil {
+		b.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		b.Fatalf("ChunkContent: %v", err)
+	}
+	contentID := chunking.HashContent(content)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := chunker.GenerateManifest(contentID, chunks, "application/octet-stream"); err != nil {
+			b.Fatalf("GenerateManifest: %v", err)
+		}
+	}
+}
+
+// TestChunkContent_AllocationBudget fails if chunking 1MB into 64KB chunks
+// allocates more than the budget; the dominant cost should be the 16 chunk
+// copies plus bookkeeping.
+func TestChunkContent_AllocationBudget(t *testing.T) {
+	const budget = 64
+	content := randomContent(t, 1<<20)
+	chunker, err := chunking.NewBasicChunker(64 << 10)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		if _, err := chunker.ChunkContent(content); err != nil {
+			t.Fatalf("ChunkContent: %v", err)
+		}
+	})
+	if allocs > budget {
+		t.Errorf("ChunkContent(1MB, 64KB chunks) allocates %.0f times, budget is %d", allocs, budget)
+	}
+}
@@ -0,0 +1,72 @@
+package chunking
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+)
+
+// Multicodec bytes used in the self-describing CID form: CIDv1, raw
+// content, SHA2-256 with a 32-byte digest. These match the IPFS multiformat
+// registries so third-party tooling can parse the encoded identifiers.
+const (
+	cidVersion      = 0x01
+	codecRaw        = 0x55
+	hashSHA256      = 0x12
+	sha256DigestLen = 0x20
+)
+
+// multibasePrefix is the multibase code for lowercase base32 without
+// padding, the canonical CIDv1 text encoding.
+const multibasePrefix = 'b'
+
+var base32Lower = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// EncodeCID converts a hex digest (as produced by HashContent) into a
+// self-describing CIDv1-style identifier: multibase base32-lower over
+// version, raw codec, and a SHA2-256 multihash. Hex remains the default
+// identifier form everywhere; this encoding is for interoperability with
+// IPFS-adjacent tooling.
+func EncodeCID(hexDigest string) (string, error) {
+	digest, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return "", fmt.Errorf("chunking: digest %q is not valid hex: %w", hexDigest, err)
+	}
+	if len(digest) != sha256DigestLen {
+		return "", fmt.Errorf("chunking: digest has %d bytes, want %d", len(digest), sha256DigestLen)
+	}
+	payload := make([]byte, 0, 4+len(digest))
+	payload = append(payload, cidVersion, codecRaw, hashSHA256, sha256DigestLen)
+	payload = append(payload, digest...)
+	return string(multibasePrefix) + base32Lower.EncodeToString(payload), nil
+}
+
+// DecodeCID recovers the hex digest from an identifier in either form:
+// the self-describing encoding produced by EncodeCID, or the plain hex
+// default, which is returned unchanged after validation.
+func DecodeCID(cid string) (string, error) {
+	if len(cid) == 0 {
+		return "", fmt.Errorf("chunking: cannot decode an empty CID")
+	}
+	if cid[0] != multibasePrefix {
+		digest, err := hex.DecodeString(cid)
+		if err != nil {
+			return "", fmt.Errorf("chunking: CID %q is neither multibase nor hex: %w", cid, err)
+		}
+		if len(digest) != sha256DigestLen {
+			return "", fmt.Errorf("chunking: hex CID has %d bytes, want %d", len(digest), sha256DigestLen)
+		}
+		return cid, nil
+	}
+	payload, err := base32Lower.DecodeString(cid[1:])
+	if err != nil {
+		return "", fmt.Errorf("chunking: CID %q has invalid base32: %w", cid, err)
+	}
+	if len(payload) != 4+sha256DigestLen {
+		return "", fmt.Errorf("chunking: CID payload has %d bytes, want %d", len(payload), 4+sha256DigestLen)
+	}
+	if payload[0] != cidVersion || payload[1] != codecRaw || payload[2] != hashSHA256 || payload[3] != sha256DigestLen {
+		return "", fmt.Errorf("chunking: CID %q has unsupported version or codec", cid)
+	}
+	return hex.EncodeToString(payload[4:]), nil
+}
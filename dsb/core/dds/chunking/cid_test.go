@@ -0,0 +1,66 @@
+package chunking_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+func TestEncodeCID_StableGoldenForm(t *testing.T) {
+	digest := chunking.HashContent([]byte("hello world"))
+	encoded, err := chunking.EncodeCID(digest)
+	if err != nil {
+		t.Fatalf("EncodeCID: %v", err)
+	}
+	// Golden value: CIDv1, raw codec, SHA2-256, base32-lower multibase —
+	// the same identifier IPFS tooling derives for these bytes.
+	const want = "bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e"
+	if encoded != want {
+		t.Errorf("EncodeCID = %s, want %s", encoded, want)
+	}
+}
+
+func TestCID_RoundTrip(t *testing.T) {
+	digest := chunking.HashContent([]byte("round trip content"))
+	encoded, err := chunking.EncodeCID(digest)
+	if err != nil {
+		t.Fatalf("EncodeCID: %v", err)
+	}
+	if !strings.HasPrefix(encoded, "b") {
+		t.Errorf("encoded CID %s lacks the base32 multibase prefix", encoded)
+	}
+	decoded, err := chunking.DecodeCID(encoded)
+	if err != nil {
+		t.Fatalf("DecodeCID: %v", err)
+	}
+	if decoded != digest {
+		t.Errorf("DecodeCID = %s, want original digest %s", decoded, digest)
+	}
+
+	// The plain hex default passes through validation unchanged.
+	passthrough, err := chunking.DecodeCID(digest)
+	if err != nil {
+		t.Fatalf("DecodeCID(hex): %v", err)
+	}
+	if passthrough != digest {
+		t.Errorf("DecodeCID(hex) = %s, want %s", passthrough, digest)
+	}
+}
+
+func TestDecodeCID_RejectsGarbage(t *testing.T) {
+	for _, cid := range []string{"", "not-hex-not-multibase", "b!!!!", "abcd", "bafkreig"} {
+		if _, err := chunking.DecodeCID(cid); err == nil {
+			t.Errorf("DecodeCID(%q) succeeded, want error", cid)
+		}
+	}
+}
+
+func TestEncodeCID_RejectsBadDigest(t *testing.T) {
+	if _, err := chunking.EncodeCID("zzzz"); err == nil {
+		t.Error("EncodeCID accepted non-hex input")
+	}
+	if _, err := chunking.EncodeCID("abcd"); err == nil {
+		t.Error("EncodeCID accepted a short digest")
+	}
+}
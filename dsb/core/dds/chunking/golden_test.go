@@ -0,0 +1,50 @@
+package chunking_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/internal/compat"
+)
+
+// goldenContent is the fixed input every chunking golden vector derives
+// from. Never change it: the vectors pin the IDs it produces.
+var goldenContent = []byte("The quick brown fox jumps over the lazy dog")
+
+func TestGoldenChunkIDs(t *testing.T) {
+	for _, size := range []int{4, 8, 64} {
+		chunker, err := chunking.NewBasicChunker(size)
+		if err != nil {
+			t.Fatalf("NewBasicChunker(%d): %v", size, err)
+		}
+		chunks, err := chunker.ChunkContent(goldenContent)
+		if err != nil {
+			t.Fatalf("ChunkContent(size %d): %v", size, err)
+		}
+		ids := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			ids[i] = chunk.ID
+		}
+		compat.AssertStableCID(t, fmt.Sprintf("chunk-ids-size%d", size), strings.Join(ids, "\n"))
+	}
+}
+
+func TestGoldenManifestID(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(goldenContent)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(goldenContent), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	compat.AssertStableCID(t, "content-id", manifest.ContentID)
+	compat.AssertStableCID(t, "manifest-id", manifest.ID)
+	compat.AssertStableCID(t, "manifest-id-empty", chunker.GenerateEmptyManifest("text/plain").ID)
+}
@@ -0,0 +1,50 @@
+package chunking
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"sync"
+)
+
+// hasherPool recycles SHA-256 state between verifications so hot retrieval
+// paths don't allocate a fresh hasher per content. It is shared by
+// ContentRetriever and the DDSCoreService local reassembly path.
+var hasherPool = sync.Pool{
+	New: func() any { return sha256.New() },
+}
+
+// AcquireHasher returns a reset SHA-256 hasher from the shared pool.
+func AcquireHasher() hash.Hash {
+	h := hasherPool.Get().(hash.Hash)
+	h.Reset()
+	return h
+}
+
+// ReleaseHasher returns a hasher to the shared pool.
+func ReleaseHasher(h hash.Hash) {
+	hasherPool.Put(h)
+}
+
+// VerifyData reports whether data's SHA-256 digest matches the hex-encoded
+// id, without allocating.
+func VerifyData(data []byte, id string) bool {
+	if len(id) != 2*sha256.Size {
+		return false
+	}
+	sum := sha256.Sum256(data)
+	var hexSum [2 * sha256.Size]byte
+	hex.Encode(hexSum[:], sum[:])
+	for i := range hexSum {
+		if hexSum[i] != id[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SumToHex returns the hex digest accumulated in h without resetting it.
+func SumToHex(h hash.Hash) string {
+	var sum [sha256.Size]byte
+	return hex.EncodeToString(h.Sum(sum[:0]))
+}
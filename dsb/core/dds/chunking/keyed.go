@@ -0,0 +1,140 @@
+package chunking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"sync"
+)
+
+// ErrSwarmKeyUnknown reports that a manifest's IDs are keyed to a swarm
+// secret this node does not hold, so its chunks can be neither verified
+// nor deduplicated here. Callers detect it with errors.Is.
+var ErrSwarmKeyUnknown = errors.New("swarm key is unknown")
+
+// KeyedHash returns the hex-encoded HMAC-SHA256 of data under key. It is
+// the identifier function NewKeyedChunker substitutes for HashContent:
+// without the key, an outsider cannot derive a content's IDs even from the
+// content itself, so private swarms are not probeable by guessed CIDs.
+func KeyedHash(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewKeyedHasher returns incremental hash state for KeyedHash, the keyed
+// counterpart of AcquireHasher. HMAC state is cheap and key-specific, so
+// it is not pooled; finish it with SumToHex like the pooled hashers.
+func NewKeyedHasher(key []byte) hash.Hash {
+	return hmac.New(sha256.New, key)
+}
+
+// VerifyDataKeyed reports whether data's keyed digest under key matches
+// the hex-encoded id.
+func VerifyDataKeyed(data []byte, id string, key []byte) bool {
+	want, err := hex.DecodeString(id)
+	if err != nil || len(want) != sha256.Size {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+// KeyFingerprint derives the non-secret identifier of a swarm key that
+// manifests record so retrievers know which key their IDs are under. It is
+// a plain SHA-256 of the key behind a domain separator: safe to publish,
+// and never valid as an HMAC under the key itself.
+func KeyFingerprint(key []byte) string {
+	return HashContent([]byte("dsb|swarm-key-fingerprint|v1|" + string(key)))
+}
+
+// KeyProvider resolves swarm keys by the fingerprint a manifest carries.
+// The retrieval layers take one by injection, so key storage and rotation
+// stay outside the DDS.
+type KeyProvider interface {
+	// SwarmKey returns the key with the given fingerprint, or an error
+	// wrapping ErrSwarmKeyUnknown when this node does not hold it.
+	SwarmKey(fingerprint string) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider over a fixed in-memory key set,
+// indexed by fingerprint. It is safe for concurrent use.
+type StaticKeyProvider struct {
+	mu   sync.RWMutex
+	keys map[string][]byte
+}
+
+// NewStaticKeyProvider returns a provider holding the given keys.
+func NewStaticKeyProvider(keys ...[]byte) *StaticKeyProvider {
+	p := &StaticKeyProvider{keys: make(map[string][]byte)}
+	for _, key := range keys {
+		p.Add(key)
+	}
+	return p
+}
+
+// Add registers another key under its fingerprint.
+func (p *StaticKeyProvider) Add(key []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[KeyFingerprint(key)] = key
+}
+
+// SwarmKey implements KeyProvider.
+func (p *StaticKeyProvider) SwarmKey(fingerprint string) ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	key, ok := p.keys[fingerprint]
+	if !ok {
+		return nil, fmt.Errorf("chunking: no key with fingerprint %s: %w", fingerprint, ErrSwarmKeyUnknown)
+	}
+	return key, nil
+}
+
+// Keyed reports whether the manifest's IDs are HMAC-derived under a swarm
+// key rather than plain SHA-256 digests.
+func (m *Manifest) Keyed() bool {
+	return m.KeyFingerprint != ""
+}
+
+// ResolveSwarmKey returns the key the manifest's IDs are under, resolved
+// through keys by fingerprint. Unkeyed manifests resolve to a nil key with
+// no error; a keyed manifest without a matching provider fails with an
+// error wrapping ErrSwarmKeyUnknown.
+func (m *Manifest) ResolveSwarmKey(keys KeyProvider) ([]byte, error) {
+	if !m.Keyed() {
+		return nil, nil
+	}
+	if keys == nil {
+		return nil, fmt.Errorf("chunking: manifest %s is keyed (fingerprint %s) but no key provider is configured: %w",
+			m.ID, m.KeyFingerprint, ErrSwarmKeyUnknown)
+	}
+	key, err := keys.SwarmKey(m.KeyFingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("chunking: manifest %s: %w", m.ID, err)
+	}
+	return key, nil
+}
+
+// VerifyChunk reports whether data matches id under the manifest's ID
+// scheme: keyed manifests check the HMAC under key (as returned by
+// ResolveSwarmKey), plain manifests the SHA-256 digest.
+func (m *Manifest) VerifyChunk(data []byte, id string, key []byte) bool {
+	if m.Keyed() {
+		return VerifyDataKeyed(data, id, key)
+	}
+	return VerifyData(data, id)
+}
+
+// ContentDigest returns reassembled content's identifier under the
+// manifest's ID scheme, for comparison against ContentID.
+func (m *Manifest) ContentDigest(content, key []byte) string {
+	if m.Keyed() {
+		return KeyedHash(key, content)
+	}
+	return HashContent(content)
+}
@@ -0,0 +1,93 @@
+package chunking_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+func keyedManifest(t *testing.T, key []byte, content []byte) (*chunking.BasicChunker, *chunking.Manifest) {
+	t.Helper()
+	chunker, err := chunking.NewKeyedChunker(4, key)
+	if err != nil {
+		t.Fatalf("NewKeyedChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunker.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	return chunker, manifest
+}
+
+func TestKeyedChunker_DifferentKeysDifferentIDs(t *testing.T) {
+	content := []byte("the same content every time")
+	_, withKeyA := keyedManifest(t, []byte("key-a"), content)
+	_, withKeyB := keyedManifest(t, []byte("key-b"), content)
+	plainChunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	plainChunks, err := plainChunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	plain, err := plainChunker.GenerateManifest(chunking.HashContent(content), plainChunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+
+	if withKeyA.ID == withKeyB.ID || withKeyA.ID == plain.ID {
+		t.Error("manifest IDs collide across keys")
+	}
+	if withKeyA.ContentID == withKeyB.ContentID || withKeyA.ContentID == plain.ContentID {
+		t.Error("content IDs collide across keys")
+	}
+	if withKeyA.ChunkIDs[0] == withKeyB.ChunkIDs[0] || withKeyA.ChunkIDs[0] == plain.ChunkIDs[0] {
+		t.Error("chunk IDs collide across keys")
+	}
+	if fp := withKeyA.KeyFingerprint; fp == "" || fp == withKeyB.KeyFingerprint {
+		t.Errorf("fingerprints %q / %q should be set and key-specific", fp, withKeyB.KeyFingerprint)
+	}
+	if plain.KeyFingerprint != "" || plain.Keyed() {
+		t.Error("plain manifest claims to be keyed")
+	}
+}
+
+func TestKeyedChunker_VerificationNeedsTheKey(t *testing.T) {
+	key := []byte("swarm-secret")
+	content := []byte("private bytes")
+	_, manifest := keyedManifest(t, key, content)
+
+	if !chunking.VerifyDataKeyed(content[:4], manifest.ChunkIDs[0], key) {
+		t.Error("chunk does not verify under its own key")
+	}
+	if chunking.VerifyDataKeyed(content[:4], manifest.ChunkIDs[0], []byte("wrong")) {
+		t.Error("chunk verifies under the wrong key")
+	}
+	if chunking.VerifyData(content[:4], manifest.ChunkIDs[0]) {
+		t.Error("keyed chunk ID verifies as a plain digest")
+	}
+
+	provider := chunking.NewStaticKeyProvider(key)
+	resolved, err := manifest.ResolveSwarmKey(provider)
+	if err != nil || string(resolved) != string(key) {
+		t.Fatalf("ResolveSwarmKey = %q, %v", resolved, err)
+	}
+	if _, err := manifest.ResolveSwarmKey(chunking.NewStaticKeyProvider([]byte("other"))); !errors.Is(err, chunking.ErrSwarmKeyUnknown) {
+		t.Errorf("resolving with the wrong key set: %v, want ErrSwarmKeyUnknown", err)
+	}
+	if _, err := manifest.ResolveSwarmKey(nil); !errors.Is(err, chunking.ErrSwarmKeyUnknown) {
+		t.Errorf("resolving without a provider: %v, want ErrSwarmKeyUnknown", err)
+	}
+}
+
+func TestNewKeyedChunker_RequiresAKey(t *testing.T) {
+	if _, err := chunking.NewKeyedChunker(4, nil); err == nil {
+		t.Error("empty swarm key was accepted")
+	}
+}
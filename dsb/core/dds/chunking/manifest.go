@@ -0,0 +1,253 @@
+package chunking
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// ErrManifestOrderInvalid reports a manifest whose chunk ordering cannot
+// be trusted: sequence numbers are missing, non-contiguous, or disagree
+// with the ChunkIDs slice. Retrieval rejects such manifests before
+// fetching any chunks, since a reordered chunk set would otherwise pass
+// every per-chunk check and only fail the final content digest.
+var ErrManifestOrderInvalid = errors.New("manifest chunk ordering is invalid")
+
+// ChunkRef names one chunk of a manifest together with its position in
+// the reassembly order. The sequence number is covered by the manifest ID,
+// so reordering ChunkIDs without re-deriving the manifest is detectable.
+type ChunkRef struct {
+	ID  string `json:"id"`
+	Seq int    `json:"seq"`
+}
+
+// Manifest describes one piece of published content: the digest of the whole
+// content, the ordered chunk identifiers needed to reassemble it, and basic
+// metadata. The manifest's own ID is derived from its contents so that the
+// manifest itself is content-addressed.
+//
+// ChunkIDs and ChunkRefs carry the same chunks; ChunkIDs remains the form
+// older readers iterate, while ChunkRefs adds the explicit sequence
+// numbers ValidateOrder checks.
+type Manifest struct {
+	ID          string     `json:"id"`
+	ContentID   string     `json:"contentId"`
+	ChunkIDs    []string   `json:"chunkIds"`
+	ChunkRefs   []ChunkRef `json:"chunkRefs,omitempty"`
+	TotalSize   int64      `json:"totalSize"`
+	ContentType string     `json:"contentType,omitempty"`
+	CreatedAt   int64      `json:"createdAt"`
+
+	// OriginalSize is the content length before any publish-side
+	// transform (compression, encryption); StoredSize is the sum of the
+	// chunks' stored bytes, which is what reassembly produces. For
+	// untransformed content both equal TotalSize, and legacy manifests
+	// carry neither — use EffectiveOriginalSize/EffectiveStoredSize,
+	// which fall back to TotalSize.
+	OriginalSize int64 `json:"originalSize,omitempty"`
+	StoredSize   int64 `json:"storedSize,omitempty"`
+
+	// StorageBackend records which local storage backend holds this
+	// manifest's chunks when a routing store dispatched them. It is local
+	// bookkeeping, not covered by the manifest ID.
+	StorageBackend string `json:"storageBackend,omitempty"`
+
+	// KeyFingerprint, when set, records the non-secret fingerprint of the
+	// swarm key this manifest's IDs are HMAC-derived under (see
+	// NewKeyedChunker), so retrievers can pick the matching key. Like
+	// StorageBackend it is not covered by the manifest ID — a tampered
+	// fingerprint only selects a key under which nothing verifies.
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+
+	// PublisherPublicKey and Signature, when set, bind the manifest to the
+	// wallet that published it: Signature covers the manifest's canonical
+	// bytes under the key (see SignPublisher and VerifyPublisher). Both
+	// are optional — unsigned manifests stay valid wherever provenance is
+	// not required — and neither is covered by the manifest ID, so signing
+	// does not change the CID.
+	PublisherPublicKey []byte `json:"publisherPublicKey,omitempty"`
+	Signature          []byte `json:"signature,omitempty"`
+}
+
+// ValidateOrder checks that the manifest's explicit chunk sequence is
+// present, contiguous from zero, and consistent with ChunkIDs, returning
+// an error wrapping ErrManifestOrderInvalid otherwise.
+func (m *Manifest) ValidateOrder() error {
+	if len(m.ChunkRefs) != len(m.ChunkIDs) {
+		return fmt.Errorf("chunking: manifest %s has %d chunk refs for %d chunk IDs: %w",
+			m.ID, len(m.ChunkRefs), len(m.ChunkIDs), ErrManifestOrderInvalid)
+	}
+	for i, ref := range m.ChunkRefs {
+		if ref.Seq != i {
+			return fmt.Errorf("chunking: manifest %s chunk %d has sequence %d: %w",
+				m.ID, i, ref.Seq, ErrManifestOrderInvalid)
+		}
+		if ref.ID != m.ChunkIDs[i] {
+			return fmt.Errorf("chunking: manifest %s chunk %d ref %s disagrees with chunk ID %s: %w",
+				m.ID, i, ref.ID, m.ChunkIDs[i], ErrManifestOrderInvalid)
+		}
+	}
+	return nil
+}
+
+// DiagnoseDigestMismatch explains a failed whole-content digest, given the
+// chunk data that was reassembled in manifest order and the swarm key for
+// a keyed manifest (nil otherwise; see ResolveSwarmKey). A chunk whose
+// data does not hash to its ID is corruption, named with its position;
+// when every chunk matches its ID the list itself cannot reproduce
+// ContentID — it was reordered or substituted — reported via
+// ErrManifestOrderInvalid.
+func (m *Manifest) DiagnoseDigestMismatch(data [][]byte, key []byte) error {
+	for i, chunkData := range data {
+		if i >= len(m.ChunkIDs) {
+			break
+		}
+		if !m.VerifyChunk(chunkData, m.ChunkIDs[i], key) {
+			return fmt.Errorf("chunking: chunk %d (%s) of manifest %s is corrupt (got digest %s)",
+				i, m.ChunkIDs[i], m.ID, m.ContentDigest(chunkData, key))
+		}
+	}
+	return fmt.Errorf("chunking: every chunk of manifest %s matches its ID, but the chunk list does not reproduce content %s; the list was reordered or substituted: %w",
+		m.ID, m.ContentID, ErrManifestOrderInvalid)
+}
+
+// GenerateEmptyManifest builds the manifest for zero-byte content: no
+// chunks, TotalSize zero, and ContentID fixed to the digest of the empty
+// string. Retrieval already reassembles such manifests to empty content;
+// this is the publishing-side counterpart.
+func (c *BasicChunker) GenerateEmptyManifest(contentType string) *Manifest {
+	contentID := c.HashContent(nil)
+	return &Manifest{
+		ID:             c.manifestID(contentID, 0, nil),
+		ContentID:      contentID,
+		ContentType:    contentType,
+		CreatedAt:      clock.Now(),
+		KeyFingerprint: c.keyFingerprint(),
+	}
+}
+
+// GenerateManifest builds the manifest for content with the given digest,
+// split into the given chunks (in reassembly order). For zero-byte content
+// use GenerateEmptyManifest.
+func (c *BasicChunker) GenerateManifest(contentID string, chunks []Chunk, contentType string) (*Manifest, error) {
+	if contentID == "" {
+		return nil, fmt.Errorf("chunking: manifest requires a content ID")
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("chunking: manifest requires at least one chunk")
+	}
+	chunkIDs := make([]string, len(chunks))
+	refs := make([]ChunkRef, len(chunks))
+	var totalSize int64
+	for i, ch := range chunks {
+		chunkIDs[i] = ch.ID
+		refs[i] = ChunkRef{ID: ch.ID, Seq: i}
+		totalSize += int64(len(ch.Data))
+	}
+	return &Manifest{
+		ID:             c.manifestID(contentID, totalSize, chunkIDs),
+		ContentID:      contentID,
+		ChunkIDs:       chunkIDs,
+		ChunkRefs:      refs,
+		TotalSize:      totalSize,
+		OriginalSize:   totalSize,
+		StoredSize:     totalSize,
+		ContentType:    contentType,
+		CreatedAt:      clock.Now(),
+		KeyFingerprint: c.keyFingerprint(),
+	}, nil
+}
+
+// keyFingerprint returns the fingerprint manifests record for a keyed
+// chunker, and the empty string for a plain one.
+func (c *BasicChunker) keyFingerprint() string {
+	if len(c.swarmKey) == 0 {
+		return ""
+	}
+	return KeyFingerprint(c.swarmKey)
+}
+
+// GenerateTransformedManifest builds the manifest for content that was
+// transformed (compressed, encrypted) before chunking: the chunks carry the
+// transformed bytes, while originalSize records the pre-transform content
+// length a consumer recovers after undoing the transform. ContentID must be
+// the digest of the transformed bytes — that is what reassembly can verify.
+func (c *BasicChunker) GenerateTransformedManifest(contentID string, originalSize int64, chunks []Chunk, contentType string) (*Manifest, error) {
+	if originalSize < 0 {
+		return nil, fmt.Errorf("chunking: manifest requires a non-negative original size")
+	}
+	manifest, err := c.GenerateManifest(contentID, chunks, contentType)
+	if err != nil {
+		return nil, err
+	}
+	manifest.OriginalSize = originalSize
+	return manifest, nil
+}
+
+// EffectiveStoredSize returns the byte count reassembling the chunks must
+// produce, falling back to TotalSize for manifests from before the
+// original/stored split.
+func (m *Manifest) EffectiveStoredSize() int64 {
+	if m.StoredSize > 0 {
+		return m.StoredSize
+	}
+	return m.TotalSize
+}
+
+// EffectiveOriginalSize returns the pre-transform content length, falling
+// back to TotalSize for manifests from before the original/stored split.
+func (m *Manifest) EffectiveOriginalSize() int64 {
+	if m.OriginalSize > 0 {
+		return m.OriginalSize
+	}
+	return m.TotalSize
+}
+
+// VerifyOriginalSize checks recovered content — after any transform has
+// been undone — against the manifest's original size. Reassembly already
+// checks the stored size; this is the post-transform counterpart for
+// consumers of transformed content.
+func (m *Manifest) VerifyOriginalSize(content []byte) error {
+	if int64(len(content)) != m.EffectiveOriginalSize() {
+		return fmt.Errorf("chunking: recovered content is %d bytes, manifest declares an original size of %d",
+			len(content), m.EffectiveOriginalSize())
+	}
+	return nil
+}
+
+// manifestID derives the deterministic, content-addressed manifest ID
+// under the chunker's ID scheme (keyed HMAC or plain SHA-256). The payload
+// uses length-prefixed fields with explicit separators — the content
+// digest, total size, chunk count, then each chunk's sequence number and
+// ID — so no two distinct manifests can serialize to the same payload
+// through concatenation ambiguity, and reordering chunks or changing the
+// total size always changes the ID.
+func (c *BasicChunker) manifestID(contentID string, totalSize int64, chunkIDs []string) string {
+	var payload strings.Builder
+	fmt.Fprintf(&payload, "manifest|v1|%d:%s|size=%d|chunks=%d", len(contentID), contentID, totalSize, len(chunkIDs))
+	for i, id := range chunkIDs {
+		fmt.Fprintf(&payload, "|%d:%d:%s", i, len(id), id)
+	}
+	return c.HashContent([]byte(payload.String()))
+}
+
+// ToJSON serializes the manifest for storage or transfer.
+func (m *Manifest) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("chunking: failed to serialize manifest %s: %w", m.ID, err)
+	}
+	return data, nil
+}
+
+// ManifestFromJSON deserializes a manifest previously produced by ToJSON.
+func ManifestFromJSON(data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("chunking: failed to deserialize manifest: %w", err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,128 @@
+package chunking_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+func generateManifest(t *testing.T, contentID string, chunks []chunking.Chunk) *chunking.Manifest {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(contentID, chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	return manifest
+}
+
+func TestGenerateManifest_IDIsDeterministic(t *testing.T) {
+	chunks := []chunking.Chunk{
+		{ID: chunking.HashContent([]byte("alpha")), Data: []byte("alpha")},
+		{ID: chunking.HashContent([]byte("beta")), Data: []byte("beta")},
+	}
+	first := generateManifest(t, "cid-content", chunks)
+	second := generateManifest(t, "cid-content", chunks)
+	if first.ID != second.ID {
+		t.Errorf("same inputs produced manifest IDs %s and %s", first.ID, second.ID)
+	}
+}
+
+func TestGenerateManifest_IDBindsChunkOrder(t *testing.T) {
+	a := chunking.Chunk{ID: chunking.HashContent([]byte("alpha")), Data: []byte("alpha")}
+	b := chunking.Chunk{ID: chunking.HashContent([]byte("beta1")), Data: []byte("beta1")}
+	forward := generateManifest(t, "cid-content", []chunking.Chunk{a, b})
+	reversed := generateManifest(t, "cid-content", []chunking.Chunk{b, a})
+	if forward.ID == reversed.ID {
+		t.Errorf("manifests with reordered chunks share ID %s", forward.ID)
+	}
+}
+
+func TestGenerateManifest_IDBindsTotalSize(t *testing.T) {
+	// Same content ID and chunk IDs, different chunk data lengths: only
+	// TotalSize differs between the two manifests.
+	id := chunking.HashContent([]byte("payload"))
+	small := generateManifest(t, "cid-content", []chunking.Chunk{{ID: id, Data: []byte("1234")}})
+	large := generateManifest(t, "cid-content", []chunking.Chunk{{ID: id, Data: []byte("12345678")}})
+	if small.TotalSize == large.TotalSize {
+		t.Fatal("fixture broken: total sizes should differ")
+	}
+	if small.ID == large.ID {
+		t.Errorf("manifests differing only in total size share ID %s", small.ID)
+	}
+}
+
+func TestGenerateManifest_IDResistsConcatenationAmbiguity(t *testing.T) {
+	// Two chunk lists whose naive concatenation is identical ("ab"+"c" vs
+	// "a"+"bc") must still yield distinct manifest IDs.
+	one := generateManifest(t, "cid-content", []chunking.Chunk{
+		{ID: "ab", Data: []byte("xx")},
+		{ID: "c", Data: []byte("yy")},
+	})
+	two := generateManifest(t, "cid-content", []chunking.Chunk{
+		{ID: "a", Data: []byte("xx")},
+		{ID: "bc", Data: []byte("yy")},
+	})
+	if one.ID == two.ID {
+		t.Errorf("manifests with ambiguous chunk ID concatenations share ID %s", one.ID)
+	}
+}
+
+func TestGenerateEmptyManifest(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	first := chunker.GenerateEmptyManifest("text/plain")
+	second := chunker.GenerateEmptyManifest("text/plain")
+
+	if first.ID != second.ID {
+		t.Errorf("empty manifests have IDs %s and %s, want identical", first.ID, second.ID)
+	}
+	if first.TotalSize != 0 || len(first.ChunkIDs) != 0 {
+		t.Errorf("empty manifest = %+v, want zero size and no chunks", first)
+	}
+	if first.ContentID != chunking.HashContent(nil) {
+		t.Errorf("empty manifest ContentID = %s, want digest of empty content", first.ContentID)
+	}
+	if err := first.ValidateOrder(); err != nil {
+		t.Errorf("ValidateOrder on empty manifest: %v", err)
+	}
+}
+
+func TestGenerateTransformedManifest_Sizes(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	stored := []byte("transformed bytes!!")
+	chunks, err := chunker.ChunkContent(stored)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateTransformedManifest(chunking.HashContent(stored), 64, chunks, "text/plain+gzip")
+	if err != nil {
+		t.Fatalf("GenerateTransformedManifest: %v", err)
+	}
+	if manifest.StoredSize != int64(len(stored)) || manifest.EffectiveStoredSize() != int64(len(stored)) {
+		t.Errorf("stored size %d, want %d", manifest.StoredSize, len(stored))
+	}
+	if manifest.OriginalSize != 64 || manifest.EffectiveOriginalSize() != 64 {
+		t.Errorf("original size %d, want 64", manifest.OriginalSize)
+	}
+	// The ID covers the stored bytes, so a transformed manifest derives
+	// the same ID as an untransformed one over the same chunks.
+	plain, err := chunker.GenerateManifest(chunking.HashContent(stored), chunks, "text/plain+gzip")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	if manifest.ID != plain.ID {
+		t.Errorf("transformed manifest ID %s differs from plain %s", manifest.ID, plain.ID)
+	}
+	if _, err := chunker.GenerateTransformedManifest(chunking.HashContent(stored), -1, chunks, ""); err == nil {
+		t.Error("negative original size was accepted")
+	}
+}
@@ -0,0 +1,102 @@
+package chunking_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func signedManifest(t *testing.T) (*chunking.Manifest, *identity.Wallet) {
+	t.Helper()
+	chunks := []chunking.Chunk{
+		{ID: chunking.HashContent([]byte("alpha")), Data: []byte("alpha")},
+		{ID: chunking.HashContent([]byte("beta")), Data: []byte("beta")},
+	}
+	manifest := generateManifest(t, "cid-signed-content", chunks)
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	if err := manifest.SignPublisher(wallet); err != nil {
+		t.Fatalf("SignPublisher: %v", err)
+	}
+	return manifest, wallet
+}
+
+func TestVerifyPublisher_SignedManifestVerifies(t *testing.T) {
+	manifest, wallet := signedManifest(t)
+	if err := manifest.VerifyPublisher(); err != nil {
+		t.Fatalf("VerifyPublisher: %v", err)
+	}
+	if got := manifest.PublisherAddress(); got != wallet.Address {
+		t.Errorf("PublisherAddress = %s, want %s", got, wallet.Address)
+	}
+
+	// The signature survives serialization: a peer receiving the manifest
+	// as JSON can verify the same provenance.
+	data, err := manifest.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	decoded, err := chunking.ManifestFromJSON(data)
+	if err != nil {
+		t.Fatalf("ManifestFromJSON: %v", err)
+	}
+	if err := decoded.VerifyPublisher(); err != nil {
+		t.Errorf("VerifyPublisher after JSON round trip: %v", err)
+	}
+}
+
+func TestVerifyPublisher_TamperedManifestFails(t *testing.T) {
+	tampers := map[string]func(m *chunking.Manifest){
+		"content type": func(m *chunking.Manifest) { m.ContentType = "application/evil" },
+		"chunk list":   func(m *chunking.Manifest) { m.ChunkIDs[0], m.ChunkIDs[1] = m.ChunkIDs[1], m.ChunkIDs[0] },
+		"total size":   func(m *chunking.Manifest) { m.TotalSize++ },
+		"publisher key": func(m *chunking.Manifest) {
+			other, err := identity.NewWallet()
+			if err != nil {
+				t.Fatalf("NewWallet: %v", err)
+			}
+			m.PublisherPublicKey = other.PublicKey
+		},
+	}
+	for name, tamper := range tampers {
+		t.Run(name, func(t *testing.T) {
+			manifest, _ := signedManifest(t)
+			tamper(manifest)
+			if err := manifest.VerifyPublisher(); !errors.Is(err, chunking.ErrManifestSignatureInvalid) {
+				t.Errorf("VerifyPublisher after tampering with the %s: %v, want ErrManifestSignatureInvalid", name, err)
+			}
+		})
+	}
+}
+
+func TestVerifyPublisher_UnsignedManifest(t *testing.T) {
+	chunks := []chunking.Chunk{
+		{ID: chunking.HashContent([]byte("plain")), Data: []byte("plain")},
+	}
+	manifest := generateManifest(t, "cid-unsigned-content", chunks)
+	if manifest.Signed() {
+		t.Fatal("freshly generated manifest reports itself signed")
+	}
+	if err := manifest.VerifyPublisher(); !errors.Is(err, chunking.ErrManifestUnsigned) {
+		t.Errorf("VerifyPublisher on an unsigned manifest: %v, want ErrManifestUnsigned", err)
+	}
+	if got := manifest.PublisherAddress(); got != "" {
+		t.Errorf("PublisherAddress on an unsigned manifest = %q", got)
+	}
+}
+
+func TestSignPublisher_DoesNotChangeManifestID(t *testing.T) {
+	manifest, _ := signedManifest(t)
+	chunks := []chunking.Chunk{
+		{ID: chunking.HashContent([]byte("alpha")), Data: []byte("alpha")},
+		{ID: chunking.HashContent([]byte("beta")), Data: []byte("beta")},
+	}
+	unsigned := generateManifest(t, "cid-signed-content", chunks)
+	if manifest.ID != unsigned.ID {
+		t.Errorf("signing changed the manifest ID: %s vs %s", manifest.ID, unsigned.ID)
+	}
+}
@@ -0,0 +1,51 @@
+package originator
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+)
+
+// GossipOriginator announces content over a real P2P service: advertising a
+// manifest CID tells the network that the local node provides it, so peers'
+// known-content views pick it up. It is the production counterpart to
+// MockOriginator and plugs into DDSCoreService the same way.
+type GossipOriginator struct {
+	service p2p.P2PService
+	node    *p2p.Node
+}
+
+// NewGossipOriginator returns an originator that advertises through the
+// given P2P service on behalf of node.
+func NewGossipOriginator(service p2p.P2PService, node *p2p.Node) *GossipOriginator {
+	return &GossipOriginator{service: service, node: node}
+}
+
+// AdvertiseContent broadcasts the manifest CID to the network with the
+// local node as its provider.
+func (o *GossipOriginator) AdvertiseContent(manifestCID string) error {
+	if manifestCID == "" {
+		return fmt.Errorf("originator: cannot advertise an empty CID")
+	}
+	if o.service == nil || o.node == nil {
+		return fmt.Errorf("originator: gossip originator is not wired to a P2P service and node")
+	}
+	if err := o.service.AdvertiseContent(manifestCID, o.node); err != nil {
+		return fmt.Errorf("originator: failed to gossip advertisement for %s: %w", manifestCID, err)
+	}
+	return nil
+}
+
+// RevokeContent withdraws the local node's advertisement for the CID.
+// There is no network-wide recall in the protocol today; peers that cached
+// the advertisement drop it when its TTL lapses.
+func (o *GossipOriginator) RevokeContent(manifestCID string) error {
+	if manifestCID == "" {
+		return fmt.Errorf("originator: cannot revoke an empty CID")
+	}
+	if o.node == nil {
+		return fmt.Errorf("originator: gossip originator is not wired to a node")
+	}
+	o.node.RemoveAdvertisedContent(manifestCID)
+	return nil
+}
@@ -0,0 +1,81 @@
+package originator_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestGossipOriginator_AdvertisesToPeersViews(t *testing.T) {
+	node := p2p.NewNode("node-gossip-test", "127.0.0.1:9050")
+	network := p2p.NewMockP2PService()
+	network.AddPeer(node)
+	discovery := p2p.NewMockPeerDiscovery()
+	discovery.RegisterNode(node)
+
+	gossip := originator.NewGossipOriginator(network, node)
+	if err := gossip.AdvertiseContent("cid-gossiped"); err != nil {
+		t.Fatalf("AdvertiseContent: %v", err)
+	}
+	if !node.HasContent("cid-gossiped") {
+		t.Error("advertisement did not reach the provider's known-content view")
+	}
+	providers, err := discovery.FindProviders("cid-gossiped")
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(providers) != 1 || providers[0].ID != node.ID {
+		t.Errorf("FindProviders = %v, want just %s", providers, node.ID)
+	}
+
+	if err := gossip.AdvertiseContent(""); err == nil {
+		t.Error("advertising an empty CID succeeded")
+	}
+}
+
+func TestGossipOriginator_RevokeClearsLocalAdvertisement(t *testing.T) {
+	node := p2p.NewNode("node-gossip-revoke", "127.0.0.1:9051")
+	network := p2p.NewMockP2PService()
+	network.AddPeer(node)
+
+	gossip := originator.NewGossipOriginator(network, node)
+	if err := gossip.AdvertiseContent("cid-revoked-later"); err != nil {
+		t.Fatalf("AdvertiseContent: %v", err)
+	}
+	if err := gossip.RevokeContent("cid-revoked-later"); err != nil {
+		t.Fatalf("RevokeContent: %v", err)
+	}
+	if node.HasContent("cid-revoked-later") {
+		t.Error("revoked CID still in the provider's known-content view")
+	}
+}
+
+func TestGossipOriginator_WiresIntoService(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	node := p2p.NewNode("node-gossip-service", "127.0.0.1:9052")
+	network := p2p.NewMockP2PService()
+	network.AddPeer(node)
+	discovery := p2p.NewMockPeerDiscovery()
+	discovery.RegisterNode(node)
+
+	svc := service.NewDDSCoreService(node, storage.NewInMemoryStorage(), chunker,
+		originator.NewGossipOriginator(network, node), discovery, network)
+	cid, err := svc.Publish([]byte("content gossiped on publish"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	providers, err := discovery.FindProviders(cid)
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(providers) != 1 {
+		t.Fatalf("published CID has %d providers, want 1", len(providers))
+	}
+}
@@ -0,0 +1,117 @@
+package originator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults for HTTPAnnounceOriginator; override with SetTimeout and
+// SetRetries before the first advertisement.
+const (
+	DefaultAnnounceTimeout = 10 * time.Second
+	DefaultAnnounceRetries = 2
+)
+
+// AnnounceStats counts announcement outcomes. Failed counts announcements
+// that exhausted their retries, not individual failed attempts.
+type AnnounceStats struct {
+	Succeeded int64
+	Failed    int64
+}
+
+// announcement is the JSON body POSTed to the tracker.
+type announcement struct {
+	CID     string `json:"cid"`
+	NodeID  string `json:"nodeId"`
+	Address string `json:"address"`
+}
+
+// HTTPAnnounceOriginator announces published CIDs to an HTTP tracker by
+// POSTing {cid, nodeID, address} to the tracker URL, retrying transient
+// failures. It is safe for concurrent use.
+type HTTPAnnounceOriginator struct {
+	trackerURL string
+	nodeID     string
+	address    string
+	client     *http.Client
+	retries    int
+
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+// NewHTTPAnnounceOriginator returns an originator announcing on behalf of
+// the node identified by nodeID and address, with default timeout and
+// retries.
+func NewHTTPAnnounceOriginator(trackerURL, nodeID, address string) *HTTPAnnounceOriginator {
+	return &HTTPAnnounceOriginator{
+		trackerURL: trackerURL,
+		nodeID:     nodeID,
+		address:    address,
+		client:     &http.Client{Timeout: DefaultAnnounceTimeout},
+		retries:    DefaultAnnounceRetries,
+	}
+}
+
+// SetTimeout replaces the per-attempt HTTP timeout.
+func (o *HTTPAnnounceOriginator) SetTimeout(d time.Duration) {
+	o.client.Timeout = d
+}
+
+// SetRetries sets how many additional attempts follow a failed announce.
+// Negative values are treated as zero.
+func (o *HTTPAnnounceOriginator) SetRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	o.retries = n
+}
+
+// AdvertiseContent announces the CID to the tracker, retrying on transport
+// errors and non-2xx responses.
+func (o *HTTPAnnounceOriginator) AdvertiseContent(manifestCID string) error {
+	if manifestCID == "" {
+		return fmt.Errorf("originator: cannot advertise an empty CID")
+	}
+	body, err := json.Marshal(announcement{CID: manifestCID, NodeID: o.nodeID, Address: o.address})
+	if err != nil {
+		return fmt.Errorf("originator: failed to encode announcement for %s: %w", manifestCID, err)
+	}
+	var lastErr error
+	for attempt := 0; attempt <= o.retries; attempt++ {
+		if lastErr = o.post(body); lastErr == nil {
+			o.succeeded.Add(1)
+			return nil
+		}
+	}
+	o.failed.Add(1)
+	return fmt.Errorf("originator: announce of %s to %s failed after %d attempts: %w",
+		manifestCID, o.trackerURL, o.retries+1, lastErr)
+}
+
+func (o *HTTPAnnounceOriginator) post(body []byte) error {
+	resp, err := o.client.Post(o.trackerURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("tracker returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Stats returns a snapshot of announcement outcomes.
+func (o *HTTPAnnounceOriginator) Stats() AnnounceStats {
+	return AnnounceStats{Succeeded: o.succeeded.Load(), Failed: o.failed.Load()}
+}
+
+// Close releases idle connections held by the underlying HTTP client.
+func (o *HTTPAnnounceOriginator) Close() error {
+	o.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,66 @@
+package originator_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+)
+
+func TestHTTPAnnounceOriginator_RetriesPastFlakyResponse(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		var ann struct {
+			CID     string `json:"cid"`
+			NodeID  string `json:"nodeId"`
+			Address string `json:"address"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&ann); err != nil {
+			t.Errorf("decoding announcement: %v", err)
+		}
+		if ann.CID != "cid-flaky" || ann.NodeID != "node-ann" || ann.Address != "127.0.0.1:9200" {
+			t.Errorf("announcement = %+v, want cid-flaky from node-ann", ann)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	orig := originator.NewHTTPAnnounceOriginator(server.URL, "node-ann", "127.0.0.1:9200")
+	defer orig.Close()
+	orig.SetRetries(2)
+
+	if err := orig.AdvertiseContent("cid-flaky"); err != nil {
+		t.Fatalf("AdvertiseContent: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("tracker saw %d requests, want 2 (one failure, one success)", got)
+	}
+	if stats := orig.Stats(); stats.Succeeded != 1 || stats.Failed != 0 {
+		t.Errorf("Stats() = %+v, want one success", stats)
+	}
+}
+
+func TestHTTPAnnounceOriginator_ExhaustedRetriesCountAsOneFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	orig := originator.NewHTTPAnnounceOriginator(server.URL, "node-ann", "127.0.0.1:9200")
+	defer orig.Close()
+	orig.SetRetries(1)
+
+	if err := orig.AdvertiseContent("cid-doomed"); err == nil {
+		t.Fatal("AdvertiseContent succeeded against an always-failing tracker")
+	}
+	if stats := orig.Stats(); stats.Failed != 1 || stats.Succeeded != 0 {
+		t.Errorf("Stats() = %+v, want one failure", stats)
+	}
+}
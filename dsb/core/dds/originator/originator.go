@@ -0,0 +1,88 @@
+// Package originator covers the publishing side of the DDS: announcing new
+// content to the wider network and the ContentPublisher pipeline that
+// chunks, stores, and advertises content in one step.
+package originator
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Originator announces newly published content to the network so that other
+// nodes can discover it.
+type Originator interface {
+	AdvertiseContent(manifestCID string) error
+}
+
+// MockOriginator records advertisements in memory for tests and demos. It
+// is safe for concurrent use.
+type MockOriginator struct {
+	mu sync.RWMutex
+	// AdvertisedManifests maps manifest CIDs to true once advertised.
+	// Access it through WasAdvertised/AdvertisedCount; it is exported only
+	// for legacy callers and must not be mutated concurrently with them.
+	AdvertisedManifests map[string]bool
+	// advertisements counts how many times each CID was advertised, for
+	// tests asserting on re-advertisement behavior.
+	advertisements map[string]int
+}
+
+// NewMockOriginator returns an originator with no recorded advertisements.
+func NewMockOriginator() *MockOriginator {
+	return &MockOriginator{
+		AdvertisedManifests: make(map[string]bool),
+		advertisements:      make(map[string]int),
+	}
+}
+
+// AdvertiseContent records the CID as advertised.
+func (o *MockOriginator) AdvertiseContent(manifestCID string) error {
+	if manifestCID == "" {
+		return fmt.Errorf("originator: cannot advertise an empty CID")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.AdvertisedManifests[manifestCID] = true
+	o.advertisements[manifestCID]++
+	return nil
+}
+
+// Advertisements returns how many times the CID has been advertised.
+func (o *MockOriginator) Advertisements(manifestCID string) int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.advertisements[manifestCID]
+}
+
+// WasAdvertised reports whether the CID has been advertised.
+func (o *MockOriginator) WasAdvertised(manifestCID string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.AdvertisedManifests[manifestCID]
+}
+
+// AdvertisedCount returns the number of distinct CIDs advertised.
+func (o *MockOriginator) AdvertisedCount() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.AdvertisedManifests)
+}
+
+// ContentRevoker is the optional take-down counterpart to Originator:
+// implementations can withdraw an advertisement they previously made, so
+// unpublished content stops being announced to the network.
+type ContentRevoker interface {
+	RevokeContent(manifestCID string) error
+}
+
+// RevokeContent forgets the CID's advertisements.
+func (o *MockOriginator) RevokeContent(manifestCID string) error {
+	if manifestCID == "" {
+		return fmt.Errorf("originator: cannot revoke an empty CID")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.AdvertisedManifests, manifestCID)
+	delete(o.advertisements, manifestCID)
+	return nil
+}
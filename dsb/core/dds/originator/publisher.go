@@ -0,0 +1,151 @@
+package originator
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// PublishOptions configures how PublishContent treats advertisement
+// failures. The zero value is the default mode: storage succeeding is what
+// counts, and a failed advertisement is reported as a non-fatal
+// PartialPublishError so callers can re-advertise later.
+type PublishOptions struct {
+	// AdvertisementRequired makes a failed advertisement fatal: the stored
+	// chunks and manifest are rolled back and PublishContent returns an
+	// error without a manifest.
+	AdvertisementRequired bool
+	// AdvertiseRetries is how many additional advertisement attempts are
+	// made after the first fails.
+	AdvertiseRetries int
+}
+
+// PartialPublishError reports that content was durably stored but could
+// not be advertised. ManifestCID identifies the stored content, so the
+// caller can serve it locally and retry the advertisement.
+type PartialPublishError struct {
+	ManifestCID string
+	Err         error
+}
+
+// Error implements error.
+func (e *PartialPublishError) Error() string {
+	return fmt.Sprintf("originator: content %s stored but not advertised: %v", e.ManifestCID, e.Err)
+}
+
+// Unwrap exposes the underlying advertisement failure.
+func (e *PartialPublishError) Unwrap() error {
+	return e.Err
+}
+
+// ContentPublisher runs the full publishing pipeline: chunk the content,
+// store chunks and manifest locally, then advertise the manifest CID.
+type ContentPublisher struct {
+	chunker    *chunking.BasicChunker
+	storage    storage.Storage
+	originator Originator
+}
+
+// NewContentPublisher wires a publisher from its three collaborators.
+func NewContentPublisher(chunker *chunking.BasicChunker, store storage.Storage, orig Originator) *ContentPublisher {
+	return &ContentPublisher{chunker: chunker, storage: store, originator: orig}
+}
+
+// PublishContent chunks, stores, and advertises content with default
+// options: a storage failure fails the publish, while an advertisement
+// failure returns the manifest together with a *PartialPublishError. This
+// matches how DDSCoreService treats advertisement failures.
+func (p *ContentPublisher) PublishContent(content []byte, contentType string) (*chunking.Manifest, error) {
+	return p.publishContent(context.Background(), content, contentType, PublishOptions{})
+}
+
+// PublishContentWithOptions is PublishContent with explicit advertisement
+// semantics; see PublishOptions.
+func (p *ContentPublisher) PublishContentWithOptions(content []byte, contentType string, opts PublishOptions) (*chunking.Manifest, error) {
+	return p.publishContent(context.Background(), content, contentType, opts)
+}
+
+// PublishContentContext is PublishContent with request tracing: errors
+// carry the request ID from ctx (generated if absent).
+func (p *ContentPublisher) PublishContentContext(ctx context.Context, content []byte, contentType string) (*chunking.Manifest, error) {
+	return p.publishContent(dds.EnsureRequestID(ctx), content, contentType, PublishOptions{})
+}
+
+func (p *ContentPublisher) publishContent(ctx context.Context, content []byte, contentType string, opts PublishOptions) (*chunking.Manifest, error) {
+	op := dds.Op(ctx, "publish")
+	var chunks []chunking.Chunk
+	if len(content) > 0 {
+		var err error
+		chunks, err = p.chunker.ChunkContent(content)
+		if err != nil {
+			return nil, fmt.Errorf(op+": chunking failed: %w", err)
+		}
+	}
+	stored := make([]string, 0, len(chunks))
+	for i := range chunks {
+		if err := p.storage.StoreChunk(&chunks[i]); err != nil {
+			p.rollback(stored, "")
+			return nil, fmt.Errorf(op+": failed to store chunk %s: %w", chunks[i].ID, err)
+		}
+		stored = append(stored, chunks[i].ID)
+	}
+	var manifest *chunking.Manifest
+	if len(chunks) == 0 {
+		manifest = p.chunker.GenerateEmptyManifest(contentType)
+	} else {
+		var err error
+		manifest, err = p.chunker.GenerateManifest(p.chunker.HashContent(content), chunks, contentType)
+		if err != nil {
+			p.rollback(stored, "")
+			return nil, fmt.Errorf(op+": failed to generate manifest: %w", err)
+		}
+	}
+	if err := p.storage.StoreManifest(manifest); err != nil {
+		p.rollback(stored, "")
+		return nil, fmt.Errorf(op+": failed to store manifest %s: %w", manifest.ID, err)
+	}
+
+	advErr := p.advertiseWithRetries(manifest.ID, opts.AdvertiseRetries)
+	if advErr == nil {
+		return manifest, nil
+	}
+	if opts.AdvertisementRequired {
+		p.rollback(stored, manifest.ID)
+		return nil, fmt.Errorf(op+": failed to advertise manifest %s: %w", manifest.ID, advErr)
+	}
+	return manifest, &PartialPublishError{ManifestCID: manifest.ID, Err: advErr}
+}
+
+// advertiseWithRetries attempts the advertisement up to 1+retries times,
+// returning the last error if every attempt fails.
+func (p *ContentPublisher) advertiseWithRetries(manifestCID string, retries int) error {
+	if retries < 0 {
+		retries = 0
+	}
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if lastErr = p.originator.AdvertiseContent(manifestCID); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// rollback removes chunks (and optionally the manifest) stored by a
+// failed publish, logging rather than failing on cleanup errors.
+func (p *ContentPublisher) rollback(chunkIDs []string, manifestCID string) {
+	for _, id := range chunkIDs {
+		if err := p.storage.DeleteChunk(id); err != nil {
+			log.Printf("originator: warning: failed to roll back chunk %s: %v", id, err)
+		}
+	}
+	if manifestCID != "" {
+		if err := p.storage.DeleteManifest(manifestCID); err != nil {
+			log.Printf("originator: warning: failed to roll back manifest %s: %v", manifestCID, err)
+		}
+	}
+}
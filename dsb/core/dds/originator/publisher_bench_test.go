@@ -0,0 +1,31 @@
+package originator_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func BenchmarkPublishContent1MB(b *testing.B) {
+	content := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(content)
+	chunker, err := chunking.NewBasicChunker(64 << 10)
+	if err != nil {
+		b.Fatalf("NewBasicChunker: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// A fresh store per iteration so every publish does real work
+		// rather than overwriting existing chunks.
+		publisher := originator.NewContentPublisher(chunker, storage.NewInMemoryStorage(), originator.NewMockOriginator())
+		if _, err := publisher.PublishContent(content, "application/octet-stream"); err != nil {
+			b.Fatalf("PublishContent: %v", err)
+		}
+	}
+}
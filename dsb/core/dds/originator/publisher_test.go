@@ -0,0 +1,109 @@
+package originator_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// flakyOriginator fails its first failures advertisement attempts, then
+// succeeds, counting every attempt.
+type flakyOriginator struct {
+	failures int
+	attempts int
+}
+
+func (o *flakyOriginator) AdvertiseContent(manifestCID string) error {
+	o.attempts++
+	if o.attempts <= o.failures {
+		return fmt.Errorf("announce rejected (attempt %d)", o.attempts)
+	}
+	return nil
+}
+
+func newTestPublisher(t *testing.T, orig originator.Originator) (*originator.ContentPublisher, storage.Storage) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	return originator.NewContentPublisher(chunker, store, orig), store
+}
+
+func TestPublishContent_AdvertisementFailureIsPartialByDefault(t *testing.T) {
+	pub, store := newTestPublisher(t, &flakyOriginator{failures: 100})
+	content := []byte("content that outlives a failed advertisement")
+
+	manifest, err := pub.PublishContent(content, "text/plain")
+	if manifest == nil {
+		t.Fatal("PublishContent returned no manifest despite successful storage")
+	}
+	var partial *originator.PartialPublishError
+	if !errors.As(err, &partial) {
+		t.Fatalf("PublishContent error = %v, want *PartialPublishError", err)
+	}
+	if partial.ManifestCID != manifest.ID {
+		t.Errorf("PartialPublishError.ManifestCID = %s, want %s", partial.ManifestCID, manifest.ID)
+	}
+	if ok, _ := store.HasManifest(manifest.ID); !ok {
+		t.Error("manifest was not kept in storage after a partial publish")
+	}
+	for _, chunkID := range manifest.ChunkIDs {
+		if ok, _ := store.HasChunk(chunkID); !ok {
+			t.Errorf("chunk %s was not kept in storage after a partial publish", chunkID)
+		}
+	}
+}
+
+func TestPublishContent_AdvertisementRequiredRollsBack(t *testing.T) {
+	pub, store := newTestPublisher(t, &flakyOriginator{failures: 100})
+
+	manifest, err := pub.PublishContentWithOptions(
+		[]byte("content that must be advertised or not exist at all"),
+		"text/plain",
+		originator.PublishOptions{AdvertisementRequired: true},
+	)
+	if err == nil {
+		t.Fatal("PublishContentWithOptions succeeded despite a required advertisement failing")
+	}
+	if manifest != nil {
+		t.Errorf("PublishContentWithOptions returned manifest %s alongside a fatal error", manifest.ID)
+	}
+	var partial *originator.PartialPublishError
+	if errors.As(err, &partial) {
+		t.Errorf("required-mode error is a PartialPublishError: %v", err)
+	}
+	usage, uerr := store.Usage()
+	if uerr != nil {
+		t.Fatalf("Usage: %v", uerr)
+	}
+	if usage.ChunkCount != 0 || usage.ManifestCount != 0 {
+		t.Errorf("storage after rollback holds %d chunks and %d manifests, want none",
+			usage.ChunkCount, usage.ManifestCount)
+	}
+}
+
+func TestPublishContent_AdvertiseRetriesRecover(t *testing.T) {
+	orig := &flakyOriginator{failures: 2}
+	pub, _ := newTestPublisher(t, orig)
+
+	manifest, err := pub.PublishContentWithOptions(
+		[]byte("content advertised on the third attempt"),
+		"text/plain",
+		originator.PublishOptions{AdvertiseRetries: 2},
+	)
+	if err != nil {
+		t.Fatalf("PublishContentWithOptions with retries: %v", err)
+	}
+	if manifest == nil {
+		t.Fatal("PublishContentWithOptions returned no manifest")
+	}
+	if orig.attempts != 3 {
+		t.Errorf("advertisement attempts = %d, want 3", orig.attempts)
+	}
+}
@@ -0,0 +1,109 @@
+package originator
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// PublishSession is an in-progress resumable publish of one piece of
+// content, identified by its content digest. Chunks are fed in reassembly
+// order through PutChunk, which skips chunks the storage backend already
+// holds — the chunks themselves are the persisted session state, so a
+// session that dies halfway leaves its progress behind for a later resume.
+// Complete generates and stores the manifest only once every chunk is
+// present, then advertises it.
+type PublishSession struct {
+	publisher *ContentPublisher
+	contentID string
+	chunks    []chunking.Chunk
+	seen      map[string]bool
+	completed bool
+}
+
+// BeginPublish starts a resumable publish for content whose whole-content
+// digest is contentID (chunking.HashContent of the full bytes).
+func (p *ContentPublisher) BeginPublish(contentID string) (*PublishSession, error) {
+	if contentID == "" {
+		return nil, fmt.Errorf("originator: resumable publish requires a content ID")
+	}
+	return &PublishSession{
+		publisher: p,
+		contentID: contentID,
+		seen:      make(map[string]bool),
+	}, nil
+}
+
+// ResumePublish continues a publish that died before Complete. There is no
+// separate session record to load — chunk IDs are content-addressed, so
+// re-chunking the same content yields the same IDs and PutChunk finds the
+// previously stored chunks via HasChunk and skips rewriting them.
+func (p *ContentPublisher) ResumePublish(contentID string) (*PublishSession, error) {
+	return p.BeginPublish(contentID)
+}
+
+// PutChunk records the next chunk of the content, storing it unless the
+// backend already holds it. It reports whether the chunk was actually
+// written, so callers can observe how much work a resume skipped.
+func (s *PublishSession) PutChunk(chunk *chunking.Chunk) (stored bool, err error) {
+	if s.completed {
+		return false, fmt.Errorf("originator: session for %s is already complete", s.contentID)
+	}
+	if chunk == nil || chunk.ID == "" {
+		return false, fmt.Errorf("originator: cannot put a chunk without an ID")
+	}
+	if s.seen[chunk.ID] {
+		// The same chunk can repeat within one content; it only needs
+		// recording once per occurrence in order.
+		s.chunks = append(s.chunks, *chunk)
+		return false, nil
+	}
+	exists, err := s.publisher.storage.HasChunk(chunk.ID)
+	if err != nil {
+		return false, fmt.Errorf("originator: failed to check chunk %s: %w", chunk.ID, err)
+	}
+	if !exists {
+		if err := s.publisher.storage.StoreChunk(chunk); err != nil {
+			// The chunk stays un-recorded; stored progress survives for a
+			// later resume.
+			return false, fmt.Errorf("originator: failed to store chunk %s: %w", chunk.ID, err)
+		}
+	}
+	s.seen[chunk.ID] = true
+	s.chunks = append(s.chunks, *chunk)
+	return !exists, nil
+}
+
+// Complete verifies every recorded chunk is present, then generates,
+// stores, and advertises the manifest. Advertisement failures are
+// non-fatal and reported as *PartialPublishError alongside the manifest,
+// matching PublishContent.
+func (s *PublishSession) Complete(contentType string) (*chunking.Manifest, error) {
+	if s.completed {
+		return nil, fmt.Errorf("originator: session for %s is already complete", s.contentID)
+	}
+	if len(s.chunks) == 0 {
+		return nil, fmt.Errorf("originator: session for %s has no chunks", s.contentID)
+	}
+	for i := range s.chunks {
+		exists, err := s.publisher.storage.HasChunk(s.chunks[i].ID)
+		if err != nil {
+			return nil, fmt.Errorf("originator: failed to check chunk %s: %w", s.chunks[i].ID, err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("originator: chunk %s missing from storage; publish cannot complete", s.chunks[i].ID)
+		}
+	}
+	manifest, err := s.publisher.chunker.GenerateManifest(s.contentID, s.chunks, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("originator: failed to generate manifest: %w", err)
+	}
+	if err := s.publisher.storage.StoreManifest(manifest); err != nil {
+		return nil, fmt.Errorf("originator: failed to store manifest %s: %w", manifest.ID, err)
+	}
+	s.completed = true
+	if advErr := s.publisher.advertiseWithRetries(manifest.ID, 0); advErr != nil {
+		return manifest, &PartialPublishError{ManifestCID: manifest.ID, Err: advErr}
+	}
+	return manifest, nil
+}
@@ -0,0 +1,136 @@
+package originator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// dyingStorage lets a configured number of chunk writes through, then
+// fails every one after, simulating a publish killed partway.
+type dyingStorage struct {
+	storage.Storage
+	writesLeft int
+}
+
+func (d *dyingStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if d.writesLeft <= 0 {
+		return errors.New("storage died mid-publish")
+	}
+	d.writesLeft--
+	return d.Storage.StoreChunk(chunk)
+}
+
+func TestPublishSession_ResumeAfterDeathMatchesUninterrupted(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("resumable content, six chunks between attempts")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	contentID := chunking.HashContent(content)
+
+	// First attempt dies after three chunk writes.
+	inner := storage.NewInMemoryStorage()
+	dying := &dyingStorage{Storage: inner, writesLeft: 3}
+	firstOrig := originator.NewMockOriginator()
+	session, err := originator.NewContentPublisher(chunker, dying, firstOrig).BeginPublish(contentID)
+	if err != nil {
+		t.Fatalf("BeginPublish: %v", err)
+	}
+	var died bool
+	for i := range chunks {
+		if _, err := session.PutChunk(&chunks[i]); err != nil {
+			died = true
+			break
+		}
+	}
+	if !died {
+		t.Fatal("first attempt was expected to die")
+	}
+
+	// The resume over the surviving storage skips the stored chunks and
+	// only writes the rest.
+	orig := originator.NewMockOriginator()
+	resumed, err := originator.NewContentPublisher(chunker, inner, orig).ResumePublish(contentID)
+	if err != nil {
+		t.Fatalf("ResumePublish: %v", err)
+	}
+	written := 0
+	for i := range chunks {
+		stored, err := resumed.PutChunk(&chunks[i])
+		if err != nil {
+			t.Fatalf("PutChunk on resume: %v", err)
+		}
+		if stored {
+			written++
+		}
+	}
+	if written != len(chunks)-3 {
+		t.Errorf("resume wrote %d chunks, want %d (the rest were already stored)", written, len(chunks)-3)
+	}
+	manifest, err := resumed.Complete("text/plain")
+	if err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if !orig.WasAdvertised(manifest.ID) {
+		t.Error("completed manifest was not advertised")
+	}
+
+	// The resumed manifest is identical to an uninterrupted publish.
+	direct, err := originator.NewContentPublisher(chunker, storage.NewInMemoryStorage(), originator.NewMockOriginator()).
+		PublishContent(content, "text/plain")
+	if err != nil {
+		t.Fatalf("uninterrupted PublishContent: %v", err)
+	}
+	if manifest.ID != direct.ID || manifest.ContentID != direct.ContentID {
+		t.Errorf("resumed manifest %s differs from uninterrupted %s", manifest.ID, direct.ID)
+	}
+}
+
+func TestPublishSession_CompleteRequiresEveryChunk(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	pub := originator.NewContentPublisher(chunker, store, originator.NewMockOriginator())
+	content := []byte("chunked body")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	session, err := pub.BeginPublish(chunking.HashContent(content))
+	if err != nil {
+		t.Fatalf("BeginPublish: %v", err)
+	}
+	for i := range chunks {
+		if _, err := session.PutChunk(&chunks[i]); err != nil {
+			t.Fatalf("PutChunk: %v", err)
+		}
+	}
+	// A chunk vanishing between Put and Complete (e.g. eviction) must
+	// block completion rather than publish a manifest with holes.
+	if err := store.DeleteChunk(chunks[0].ID); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+	if _, err := session.Complete("text/plain"); err == nil {
+		t.Error("Complete succeeded with a missing chunk")
+	}
+
+	if err := store.StoreChunk(&chunks[0]); err != nil {
+		t.Fatalf("restoring the missing chunk: %v", err)
+	}
+	if _, err := session.Complete("text/plain"); err != nil {
+		t.Errorf("Complete after restoring the chunk: %v", err)
+	}
+	if _, err := session.Complete("text/plain"); err == nil {
+		t.Error("double Complete succeeded")
+	}
+}
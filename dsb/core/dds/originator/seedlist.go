@@ -0,0 +1,101 @@
+package originator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SeedListOriginator persists advertised CIDs to a plain text file, one
+// CID per line in first-advertised order. The file doubles as a bootstrap
+// content catalog: a freshly joined node can fetch it and seed retrievals
+// before any peer discovery works. It is safe for concurrent use.
+type SeedListOriginator struct {
+	mu    sync.Mutex
+	path  string
+	seen  map[string]bool
+	order []string
+}
+
+// NewSeedListOriginator opens (or creates) the seed list at path, loading
+// any CIDs a previous run recorded.
+func NewSeedListOriginator(path string) (*SeedListOriginator, error) {
+	o := &SeedListOriginator{path: path, seen: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return o, nil
+		}
+		return nil, fmt.Errorf("originator: failed to read seed list %s: %w", path, err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		cid := strings.TrimSpace(line)
+		if cid == "" || o.seen[cid] {
+			continue
+		}
+		o.seen[cid] = true
+		o.order = append(o.order, cid)
+	}
+	return o, nil
+}
+
+// AdvertiseContent records the CID and persists the updated list.
+// Re-advertising a known CID is a no-op.
+func (o *SeedListOriginator) AdvertiseContent(manifestCID string) error {
+	if manifestCID == "" {
+		return fmt.Errorf("originator: cannot advertise an empty CID")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.seen[manifestCID] {
+		return nil
+	}
+	o.seen[manifestCID] = true
+	o.order = append(o.order, manifestCID)
+	return o.flushLocked()
+}
+
+// CIDs returns the recorded CIDs in first-advertised order.
+func (o *SeedListOriginator) CIDs() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]string(nil), o.order...)
+}
+
+// Close flushes the list to disk. The originator stays usable afterwards;
+// Close exists so shutdown paths can guarantee durability.
+func (o *SeedListOriginator) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.flushLocked()
+}
+
+// flushLocked writes the list atomically: to a temp file in the same
+// directory, then renamed over the target. Callers hold the lock.
+func (o *SeedListOriginator) flushLocked() error {
+	var b strings.Builder
+	for _, cid := range o.order {
+		b.WriteString(cid)
+		b.WriteByte('\n')
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), ".seedlist-*")
+	if err != nil {
+		return fmt.Errorf("originator: failed to stage seed list %s: %w", o.path, err)
+	}
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("originator: failed to write seed list %s: %w", o.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("originator: failed to close seed list %s: %w", o.path, err)
+	}
+	if err := os.Rename(tmp.Name(), o.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("originator: failed to replace seed list %s: %w", o.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package originator_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+)
+
+func TestSeedListOriginator_PersistsAndDeduplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seeds.txt")
+	orig, err := originator.NewSeedListOriginator(path)
+	if err != nil {
+		t.Fatalf("NewSeedListOriginator: %v", err)
+	}
+	for _, cid := range []string{"cid-a", "cid-b", "cid-a", "cid-c"} {
+		if err := orig.AdvertiseContent(cid); err != nil {
+			t.Fatalf("AdvertiseContent(%s): %v", cid, err)
+		}
+	}
+	if err := orig.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if got, want := string(data), "cid-a\ncid-b\ncid-c\n"; got != want {
+		t.Errorf("seed list file = %q, want %q", got, want)
+	}
+
+	// A new originator on the same path inherits the recorded catalog.
+	reopened, err := originator.NewSeedListOriginator(path)
+	if err != nil {
+		t.Fatalf("NewSeedListOriginator(reopen): %v", err)
+	}
+	if got := reopened.CIDs(); len(got) != 3 || got[0] != "cid-a" {
+		t.Errorf("reloaded CIDs = %v, want [cid-a cid-b cid-c]", got)
+	}
+}
+
+func TestSeedListOriginator_ConcurrentAdvertises(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seeds.txt")
+	orig, err := originator.NewSeedListOriginator(path)
+	if err != nil {
+		t.Fatalf("NewSeedListOriginator: %v", err)
+	}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				// Half the CIDs collide across workers to exercise dedup.
+				cid := "cid-shared"
+				if i%2 == 0 {
+					cid = "cid-" + strings.Repeat("x", w+1)
+				}
+				if err := orig.AdvertiseContent(cid); err != nil {
+					t.Errorf("AdvertiseContent: %v", err)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := orig.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := len(orig.CIDs()); got != 9 {
+		t.Errorf("recorded %d distinct CIDs, want 9", got)
+	}
+}
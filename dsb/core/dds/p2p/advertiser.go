@@ -0,0 +1,226 @@
+package p2p
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// BatchAdvertiser is implemented by P2P services whose protocol can announce
+// several CIDs in a single message. The Advertiser uses it to collapse a
+// flush into one round trip; services without it receive one announcement
+// per CID.
+type BatchAdvertiser interface {
+	AdvertiseContentBatch(manifestCIDs []string, provider *Node) error
+}
+
+// AdvertiserConfig tunes the advertisement scheduler.
+type AdvertiserConfig struct {
+	// Window is both the coalescing horizon — re-announcements of a CID
+	// within it are suppressed — and the flush cadence for queued
+	// announcements. Zero or negative disables scheduling entirely and
+	// every advertisement passes straight through.
+	Window time.Duration
+	// PerPeerPerMinute caps how many announce messages are sent on behalf
+	// of any one provider per minute. Announcements over the cap stay
+	// queued until the minute rolls over. Zero means no cap.
+	PerPeerPerMinute int
+}
+
+// Advertiser wraps a P2PService and schedules its content announcements:
+// duplicate announcements of a CID within the window are suppressed, queued
+// CIDs are flushed together (one batch message when the inner service
+// supports it), and a per-provider rate cap keeps re-publish storms from
+// spamming peers. It implements P2PService, so it drops in wherever the
+// inner service is used.
+//
+// Like the rest of the package it is timer-free: flushing happens on the
+// advertisement that finds the window elapsed, or when Flush is called.
+type Advertiser struct {
+	inner P2PService
+	cfg   AdvertiserConfig
+
+	mu              sync.Mutex
+	pending         []string
+	pendingSet      map[string]struct{}
+	pendingProvider map[string]*Node
+	lastAnnounced   map[string]int64 // CID -> clock.Now() of its last announcement
+	sentAt          map[string][]int64
+	lastFlush       int64
+	suppressed      uint64
+}
+
+// NewAdvertiser wraps inner with the given scheduling config.
+func NewAdvertiser(inner P2PService, cfg AdvertiserConfig) *Advertiser {
+	return &Advertiser{
+		inner:           inner,
+		cfg:             cfg,
+		pendingSet:      make(map[string]struct{}),
+		pendingProvider: make(map[string]*Node),
+		lastAnnounced:   make(map[string]int64),
+		sentAt:          make(map[string][]int64),
+	}
+}
+
+// Suppressed returns how many duplicate advertisements were coalesced away
+// since the Advertiser was created.
+func (a *Advertiser) Suppressed() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.suppressed
+}
+
+// Pending returns how many CIDs are queued awaiting the next flush.
+func (a *Advertiser) Pending() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.pending)
+}
+
+// AdvertiseContent queues the announcement, suppressing it when the same
+// CID was announced within the window or is already queued. The queue is
+// flushed when the window has elapsed since the previous flush, so a lone
+// first announcement still goes out immediately.
+func (a *Advertiser) AdvertiseContent(manifestCID string, provider *Node) error {
+	if a.cfg.Window <= 0 {
+		return a.inner.AdvertiseContent(manifestCID, provider)
+	}
+	if provider == nil {
+		return fmt.Errorf("p2p: advertisement requires a provider node")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := clock.Now()
+	if _, queued := a.pendingSet[manifestCID]; queued {
+		a.suppressed++
+		return nil
+	}
+	if last, ok := a.lastAnnounced[manifestCID]; ok && now-last < a.cfg.Window.Nanoseconds() {
+		a.suppressed++
+		return nil
+	}
+	a.pending = append(a.pending, manifestCID)
+	a.pendingSet[manifestCID] = struct{}{}
+	a.pendingProvider[manifestCID] = provider
+	if now-a.lastFlush >= a.cfg.Window.Nanoseconds() {
+		return a.flushLocked(now)
+	}
+	return nil
+}
+
+// Flush sends every queued announcement now, without waiting for the
+// window to elapse. CIDs held back by the per-provider rate cap remain
+// queued for a later flush.
+func (a *Advertiser) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.flushLocked(clock.Now())
+}
+
+// flushLocked drains the queue grouped by provider. Callers must hold a.mu.
+func (a *Advertiser) flushLocked(now int64) error {
+	a.lastFlush = now
+	if len(a.pending) == 0 {
+		return nil
+	}
+	// Group queued CIDs by provider, preserving queue order within each
+	// group. In practice there is one provider — the local node — and a
+	// single group.
+	order := make([]*Node, 0, 1)
+	groups := make(map[string][]string)
+	for _, cid := range a.pending {
+		provider := a.pendingProvider[cid]
+		if _, seen := groups[provider.ID]; !seen {
+			order = append(order, provider)
+		}
+		groups[provider.ID] = append(groups[provider.ID], cid)
+	}
+
+	var held []string
+	var firstErr error
+	for _, provider := range order {
+		cids := groups[provider.ID]
+		if firstErr != nil || !a.underCapLocked(provider.ID, now) {
+			held = append(held, cids...)
+			continue
+		}
+		if err := a.announceLocked(cids, provider, now); err != nil {
+			// Keep the group queued so a transient send failure is
+			// retried at the next flush.
+			held = append(held, cids...)
+			firstErr = err
+		}
+	}
+
+	a.pending = a.pending[:0]
+	for cid := range a.pendingSet {
+		delete(a.pendingSet, cid)
+	}
+	for _, cid := range held {
+		a.pending = append(a.pending, cid)
+		a.pendingSet[cid] = struct{}{}
+	}
+	for cid := range a.pendingProvider {
+		if _, stillQueued := a.pendingSet[cid]; !stillQueued {
+			delete(a.pendingProvider, cid)
+		}
+	}
+	return firstErr
+}
+
+// announceLocked sends one provider's CIDs: a single batch message when the
+// inner service supports batching, one message per CID otherwise.
+func (a *Advertiser) announceLocked(cids []string, provider *Node, now int64) error {
+	if batcher, ok := a.inner.(BatchAdvertiser); ok && len(cids) > 1 {
+		if err := batcher.AdvertiseContentBatch(cids, provider); err != nil {
+			return err
+		}
+		a.sentAt[provider.ID] = append(a.sentAt[provider.ID], now)
+		for _, cid := range cids {
+			a.lastAnnounced[cid] = now
+		}
+		return nil
+	}
+	for _, cid := range cids {
+		if err := a.inner.AdvertiseContent(cid, provider); err != nil {
+			return err
+		}
+		a.sentAt[provider.ID] = append(a.sentAt[provider.ID], now)
+		a.lastAnnounced[cid] = now
+	}
+	return nil
+}
+
+// underCapLocked reports whether the provider may send another announce
+// message, pruning send records older than a minute as a side effect.
+func (a *Advertiser) underCapLocked(providerID string, now int64) bool {
+	if a.cfg.PerPeerPerMinute <= 0 {
+		return true
+	}
+	recent := a.sentAt[providerID][:0]
+	for _, sent := range a.sentAt[providerID] {
+		if now-sent < time.Minute.Nanoseconds() {
+			recent = append(recent, sent)
+		}
+	}
+	a.sentAt[providerID] = recent
+	return len(recent) < a.cfg.PerPeerPerMinute
+}
+
+// FetchManifest delegates to the wrapped service.
+func (a *Advertiser) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	return a.inner.FetchManifest(manifestCID)
+}
+
+// FetchChunkFromPeer delegates to the wrapped service.
+func (a *Advertiser) FetchChunkFromPeer(peerID, chunkID string) (*chunking.Chunk, error) {
+	return a.inner.FetchChunkFromPeer(peerID, chunkID)
+}
+
+// PeerCapabilities delegates to the wrapped service.
+func (a *Advertiser) PeerCapabilities(peerID string) (Capabilities, bool) {
+	return a.inner.PeerCapabilities(peerID)
+}
@@ -0,0 +1,101 @@
+package p2p_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// advertiserFixture wraps a mock network in an Advertiser under a fake
+// clock, so tests control exactly when the coalescing window elapses.
+func advertiserFixture(t *testing.T, cfg p2p.AdvertiserConfig) (*p2p.Advertiser, *p2p.MockP2PService, *clock.Fake, *p2p.Node) {
+	t.Helper()
+	fake := clock.NewFake(time.Now().UnixNano())
+	prev := clock.Set(fake)
+	t.Cleanup(func() { clock.Set(prev) })
+	mock := p2p.NewMockP2PService()
+	node := p2p.NewNode("node-advertiser-test", "127.0.0.1:9050")
+	mock.AddPeer(node)
+	return p2p.NewAdvertiser(mock, cfg), mock, fake, node
+}
+
+func TestAdvertiser_RapidDuplicatesCoalesce(t *testing.T) {
+	adv, mock, _, node := advertiserFixture(t, p2p.AdvertiserConfig{Window: 30 * time.Second})
+
+	for i := 0; i < 100; i++ {
+		if err := adv.AdvertiseContent("cid-hot", node); err != nil {
+			t.Fatalf("AdvertiseContent %d: %v", i, err)
+		}
+	}
+	if got := mock.AdvertisedToPeers("cid-hot"); got > 2 {
+		t.Errorf("100 rapid advertises produced %d announcements, want at most 2", got)
+	}
+	if got := mock.AdvertisedToPeers("cid-hot"); got == 0 {
+		t.Error("the first advertisement was never announced")
+	}
+	if adv.Suppressed() != 99 {
+		t.Errorf("Suppressed() = %d, want 99", adv.Suppressed())
+	}
+	// The peer discovery view still sees the content.
+	if !node.HasContent("cid-hot") {
+		t.Error("provider node does not advertise the content")
+	}
+}
+
+func TestAdvertiser_DistinctCIDsBatchIntoOneMessage(t *testing.T) {
+	adv, mock, _, node := advertiserFixture(t, p2p.AdvertiserConfig{Window: 30 * time.Second})
+
+	// The first advertisement finds the window elapsed and goes straight
+	// out; the next three land inside the window and queue up.
+	for _, cid := range []string{"cid-a", "cid-b", "cid-c", "cid-d"} {
+		if err := adv.AdvertiseContent(cid, node); err != nil {
+			t.Fatalf("AdvertiseContent %s: %v", cid, err)
+		}
+	}
+	if adv.Pending() != 3 {
+		t.Fatalf("Pending() = %d, want 3 queued behind the first flush", adv.Pending())
+	}
+	if err := adv.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	msgs := mock.Announcements()
+	if len(msgs) != 2 {
+		t.Fatalf("network saw %d announce messages %v, want 2", len(msgs), msgs)
+	}
+	if len(msgs[1]) != 3 {
+		t.Errorf("second message carried %v, want the three queued CIDs batched", msgs[1])
+	}
+	for _, cid := range []string{"cid-a", "cid-b", "cid-c", "cid-d"} {
+		if got := mock.AdvertisedToPeers(cid); got != 1 {
+			t.Errorf("%s announced %d times, want 1", cid, got)
+		}
+	}
+}
+
+func TestAdvertiser_PerPeerCapHoldsExcessUntilMinuteRolls(t *testing.T) {
+	adv, mock, fake, node := advertiserFixture(t, p2p.AdvertiserConfig{
+		Window:           time.Second,
+		PerPeerPerMinute: 2,
+	})
+
+	for i := 0; i < 3; i++ {
+		cid := fmt.Sprintf("cid-%d", i)
+		if err := adv.AdvertiseContent(cid, node); err != nil {
+			t.Fatalf("AdvertiseContent %s: %v", cid, err)
+		}
+		fake.Advance(2 * time.Second)
+	}
+	if got := mock.AdvertisedToPeers("cid-2"); got != 0 {
+		t.Fatalf("third announcement went out despite the cap (%d messages)", got)
+	}
+	fake.Advance(time.Minute)
+	if err := adv.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := mock.AdvertisedToPeers("cid-2"); got != 1 {
+		t.Errorf("held announcement not sent after the minute rolled (%d messages)", got)
+	}
+}
@@ -0,0 +1,73 @@
+package p2p
+
+import "errors"
+
+// ProtocolVersion is the wire-protocol version this node speaks. Peers on
+// older versions interoperate through the feature list exchanged in the
+// handshake: a request may only use features the peer advertised.
+const ProtocolVersion = 2
+
+// Feature names a peer can advertise during the handshake.
+const (
+	// FeatureBatchChunks means the peer accepts one request carrying many
+	// chunk IDs instead of a round-trip per chunk.
+	FeatureBatchChunks = "batch-chunks"
+	// FeatureCompression means the peer accepts compressed payloads.
+	FeatureCompression = "compression"
+	// FeatureAuth means the peer requires signed requests.
+	FeatureAuth = "auth"
+)
+
+// ErrUnsupportedFeature reports a request that needs a feature the peer did
+// not advertise. Callers should downgrade to the non-feature request shape
+// where one exists.
+var ErrUnsupportedFeature = errors.New("p2p: peer does not support requested feature")
+
+// Handshake is the first message exchanged on a new connection, telling
+// the peer who we are and what we can do.
+type Handshake struct {
+	ProtocolVersion int      `json:"protocolVersion"`
+	NodeID          string   `json:"nodeId"`
+	Features        []string `json:"features,omitempty"`
+}
+
+// NewHandshake builds the handshake the given node sends on connection,
+// advertising the current protocol version and the listed features.
+func NewHandshake(nodeID string, features ...string) Handshake {
+	return Handshake{ProtocolVersion: ProtocolVersion, NodeID: nodeID, Features: features}
+}
+
+// Capabilities converts a received handshake into the capability record
+// kept per peer.
+func (h Handshake) Capabilities() Capabilities {
+	caps := Capabilities{ProtocolVersion: h.ProtocolVersion, NodeID: h.NodeID}
+	if len(h.Features) > 0 {
+		caps.features = make(map[string]bool, len(h.Features))
+		for _, f := range h.Features {
+			caps.features[f] = true
+		}
+	}
+	return caps
+}
+
+// Capabilities records what a peer advertised in its handshake.
+type Capabilities struct {
+	ProtocolVersion int
+	NodeID          string
+
+	features map[string]bool
+}
+
+// Supports reports whether the peer advertised the feature.
+func (c Capabilities) Supports(feature string) bool {
+	return c.features[feature]
+}
+
+// Features returns the advertised feature names in unspecified order.
+func (c Capabilities) Features() []string {
+	out := make([]string, 0, len(c.features))
+	for f := range c.features {
+		out = append(out, f)
+	}
+	return out
+}
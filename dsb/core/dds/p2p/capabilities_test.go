@@ -0,0 +1,56 @@
+package p2p_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+)
+
+func TestHandshake_CapabilitiesRoundTrip(t *testing.T) {
+	h := p2p.NewHandshake("node-hs", p2p.FeatureBatchChunks, p2p.FeatureCompression)
+	caps := h.Capabilities()
+
+	if caps.ProtocolVersion != p2p.ProtocolVersion {
+		t.Errorf("ProtocolVersion = %d, want %d", caps.ProtocolVersion, p2p.ProtocolVersion)
+	}
+	if caps.NodeID != "node-hs" {
+		t.Errorf("NodeID = %q, want %q", caps.NodeID, "node-hs")
+	}
+	if !caps.Supports(p2p.FeatureBatchChunks) || !caps.Supports(p2p.FeatureCompression) {
+		t.Error("advertised features not reported as supported")
+	}
+	if caps.Supports(p2p.FeatureAuth) {
+		t.Error("unadvertised feature reported as supported")
+	}
+	if got := len(caps.Features()); got != 2 {
+		t.Errorf("Features() has %d entries, want 2", got)
+	}
+}
+
+func TestMockPeerCapabilities_DefaultsToFullFeatureSet(t *testing.T) {
+	network := p2p.NewMockP2PService()
+	network.AddPeer(p2p.NewNode("node-modern", "127.0.0.1:0"))
+
+	caps, ok := network.PeerCapabilities("node-modern")
+	if !ok {
+		t.Fatal("registered peer has no capabilities")
+	}
+	if !caps.Supports(p2p.FeatureBatchChunks) {
+		t.Error("default capabilities lack batch support")
+	}
+	if _, ok := network.PeerCapabilities("node-unknown"); ok {
+		t.Error("unknown peer reported capabilities")
+	}
+}
+
+func TestFetchChunksFromPeer_RejectsV1Peer(t *testing.T) {
+	network := p2p.NewMockP2PService()
+	network.AddPeer(p2p.NewNode("node-v1", "127.0.0.1:0"))
+	network.SetPeerHandshake("node-v1", p2p.Handshake{ProtocolVersion: 1, NodeID: "node-v1"})
+
+	_, err := network.FetchChunksFromPeer("node-v1", []string{"chunk-1"})
+	if !errors.Is(err, p2p.ErrUnsupportedFeature) {
+		t.Errorf("batch request to v1 peer: got %v, want ErrUnsupportedFeature", err)
+	}
+}
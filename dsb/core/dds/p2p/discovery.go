@@ -0,0 +1,125 @@
+package p2p
+
+import (
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// PeerDiscovery finds other nodes on the network, either in bulk or
+// filtered to the providers of a specific manifest CID.
+type PeerDiscovery interface {
+	DiscoverPeers() ([]Node, error)
+	// DiscoverPeersN returns at most limit peers, best-reputation first.
+	// A limit of zero or less means no limit.
+	DiscoverPeersN(limit int) ([]Node, error)
+	FindProviders(manifestCID string) ([]Node, error)
+}
+
+// MockPeerDiscovery is an in-process PeerDiscovery backed by a registry of
+// known nodes. Tests register nodes directly; FindProviders consults each
+// node's advertised content.
+type MockPeerDiscovery struct {
+	mu    sync.RWMutex
+	peers map[string]*Node
+
+	// blacklist and allowlist hold node IDs or addresses. A non-empty
+	// allowlist restricts discovery to listed peers and takes precedence
+	// over the blacklist; otherwise blacklisted peers are excluded.
+	blacklist map[string]bool
+	allowlist map[string]bool
+}
+
+// NewMockPeerDiscovery returns an empty discovery registry.
+func NewMockPeerDiscovery() *MockPeerDiscovery {
+	return &MockPeerDiscovery{peers: make(map[string]*Node)}
+}
+
+// SetBlacklist replaces the set of peers (by node ID or address) excluded
+// from discovery results. Passing an empty list clears it.
+func (d *MockPeerDiscovery) SetBlacklist(entries []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.blacklist = toSet(entries)
+}
+
+// SetAllowlist replaces the set of peers (by node ID or address) discovery
+// is restricted to. A non-empty allowlist takes precedence over the
+// blacklist; an empty one means all peers are allowed.
+func (d *MockPeerDiscovery) SetAllowlist(entries []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.allowlist = toSet(entries)
+}
+
+func toSet(entries []string) map[string]bool {
+	if len(entries) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		set[e] = true
+	}
+	return set
+}
+
+// admitted reports whether discovery may return the node. Callers hold at
+// least a read lock.
+func (d *MockPeerDiscovery) admitted(node *Node) bool {
+	if len(d.allowlist) > 0 {
+		return d.allowlist[node.ID] || d.allowlist[node.Address]
+	}
+	return !d.blacklist[node.ID] && !d.blacklist[node.Address]
+}
+
+// RegisterNode adds (or replaces) a node in the registry.
+func (d *MockPeerDiscovery) RegisterNode(node *Node) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.peers[node.ID] = node
+}
+
+// DiscoverPeers returns every registered node admitted by the configured
+// allowlist and blacklist.
+func (d *MockPeerDiscovery) DiscoverPeers() ([]Node, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	peers := make([]Node, 0, len(d.peers))
+	for _, node := range d.peers {
+		if d.admitted(node) {
+			peers = append(peers, *node)
+		}
+	}
+	return peers, nil
+}
+
+// DiscoverPeersN returns at most limit registered nodes, ordered by
+// descending reputation (ties broken by node ID). On a large network this
+// keeps callers from pulling the whole peer table at once.
+func (d *MockPeerDiscovery) DiscoverPeersN(limit int) ([]Node, error) {
+	peers, err := d.DiscoverPeers()
+	if err != nil {
+		return nil, err
+	}
+	peers = ByReputation{}.Select(peers, "")
+	if limit > 0 && len(peers) > limit {
+		peers = peers[:limit]
+	}
+	return peers, nil
+}
+
+// FindProviders returns the registered nodes with an unexpired
+// advertisement for the given manifest CID, subject to the same allowlist
+// and blacklist filtering as DiscoverPeers.
+func (d *MockPeerDiscovery) FindProviders(manifestCID string) ([]Node, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	now := clock.NowTime()
+	var providers []Node
+	for _, node := range d.peers {
+		if d.admitted(node) && node.HasContentAt(manifestCID, now) {
+			providers = append(providers, *node)
+		}
+	}
+	return providers, nil
+}
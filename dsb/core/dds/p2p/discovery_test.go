@@ -0,0 +1,131 @@
+package p2p_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+)
+
+func nodeWithScore(id string, score float64) *p2p.Node {
+	node := p2p.NewNode(id, "addr-"+id)
+	node.ReputationScore = score
+	return node
+}
+
+func TestDiscoverPeersN_RespectsLimit(t *testing.T) {
+	disc := p2p.NewMockPeerDiscovery()
+	for i := 0; i < 10; i++ {
+		disc.RegisterNode(nodeWithScore(fmt.Sprintf("node-%02d", i), float64(i)))
+	}
+
+	peers, err := disc.DiscoverPeersN(3)
+	if err != nil {
+		t.Fatalf("DiscoverPeersN: %v", err)
+	}
+	if len(peers) != 3 {
+		t.Fatalf("got %d peers, want 3", len(peers))
+	}
+	// Highest reputation first.
+	for i, want := range []string{"node-09", "node-08", "node-07"} {
+		if peers[i].ID != want {
+			t.Errorf("peers[%d].ID = %s, want %s", i, peers[i].ID, want)
+		}
+	}
+}
+
+func TestDiscoverPeersN_FewerAvailableThanLimit(t *testing.T) {
+	disc := p2p.NewMockPeerDiscovery()
+	disc.RegisterNode(nodeWithScore("node-a", 1))
+	disc.RegisterNode(nodeWithScore("node-b", 2))
+
+	peers, err := disc.DiscoverPeersN(5)
+	if err != nil {
+		t.Fatalf("DiscoverPeersN: %v", err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("got %d peers, want all 2", len(peers))
+	}
+
+	// A non-positive limit means unbounded.
+	peers, err = disc.DiscoverPeersN(0)
+	if err != nil {
+		t.Fatalf("DiscoverPeersN: %v", err)
+	}
+	if len(peers) != 2 {
+		t.Fatalf("limit 0 returned %d peers, want 2", len(peers))
+	}
+}
+
+func discoveredIDs(t *testing.T, disc *p2p.MockPeerDiscovery) map[string]bool {
+	t.Helper()
+	peers, err := disc.DiscoverPeers()
+	if err != nil {
+		t.Fatalf("DiscoverPeers: %v", err)
+	}
+	ids := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		ids[peer.ID] = true
+	}
+	return ids
+}
+
+func TestDiscoverPeers_ExcludesBlacklisted(t *testing.T) {
+	disc := p2p.NewMockPeerDiscovery()
+	disc.RegisterNode(nodeWithScore("node-good", 1))
+	disc.RegisterNode(nodeWithScore("node-bad", 2))
+	disc.RegisterNode(nodeWithScore("node-bad-addr", 3))
+	// One entry by ID, one by address.
+	disc.SetBlacklist([]string{"node-bad", "addr-node-bad-addr"})
+
+	ids := discoveredIDs(t, disc)
+	if !ids["node-good"] || len(ids) != 1 {
+		t.Errorf("discovered peers = %v, want only node-good", ids)
+	}
+}
+
+func TestDiscoverPeers_AllowlistRestrictsAndOverridesBlacklist(t *testing.T) {
+	disc := p2p.NewMockPeerDiscovery()
+	disc.RegisterNode(nodeWithScore("node-a", 1))
+	disc.RegisterNode(nodeWithScore("node-b", 2))
+	disc.RegisterNode(nodeWithScore("node-c", 3))
+	// node-a is both allowlisted and blacklisted; the allowlist wins.
+	disc.SetBlacklist([]string{"node-a"})
+	disc.SetAllowlist([]string{"node-a", "addr-node-b"})
+
+	ids := discoveredIDs(t, disc)
+	if !ids["node-a"] || !ids["node-b"] || len(ids) != 2 {
+		t.Errorf("discovered peers = %v, want node-a and node-b", ids)
+	}
+}
+
+func TestDiscoverPeers_EmptyAllowlistAllowsAll(t *testing.T) {
+	disc := p2p.NewMockPeerDiscovery()
+	disc.RegisterNode(nodeWithScore("node-a", 1))
+	disc.RegisterNode(nodeWithScore("node-b", 2))
+	disc.SetAllowlist([]string{"node-a"})
+	disc.SetAllowlist(nil)
+
+	if ids := discoveredIDs(t, disc); len(ids) != 2 {
+		t.Errorf("discovered peers = %v, want both after clearing the allowlist", ids)
+	}
+}
+
+func TestFindProviders_RespectsBlacklist(t *testing.T) {
+	disc := p2p.NewMockPeerDiscovery()
+	good := nodeWithScore("node-good", 1)
+	bad := nodeWithScore("node-bad", 2)
+	good.AddAdvertisedContent("cid-filtered")
+	bad.AddAdvertisedContent("cid-filtered")
+	disc.RegisterNode(good)
+	disc.RegisterNode(bad)
+	disc.SetBlacklist([]string{"node-bad"})
+
+	providers, err := disc.FindProviders("cid-filtered")
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(providers) != 1 || providers[0].ID != "node-good" {
+		t.Errorf("providers = %v, want only node-good", providers)
+	}
+}
@@ -0,0 +1,228 @@
+// Package p2p models the peer-to-peer layer of the DDS: the nodes that make
+// up the network, peer discovery, and the service used to exchange manifests
+// and chunks with other nodes. The real wire protocol is still to come; the
+// mock implementations here simulate a network inside a single process and
+// are what the tests and demo programs run against.
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// ContentAdvertisement is one entry in a node's advertised content: a
+// manifest CID and when the claim stops being trustworthy. A zero ExpiresAt
+// means the advertisement never expires.
+type ContentAdvertisement struct {
+	CID       string    `json:"cid"`
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+}
+
+// Expired reports whether the advertisement has lapsed at the given time.
+func (a ContentAdvertisement) Expired(now time.Time) bool {
+	return !a.ExpiresAt.IsZero() && a.ExpiresAt.Before(now)
+}
+
+// DefaultMaxKnownContent bounds how many advertisements a node tracks
+// unless SetMaxKnownContent overrides it. When the bound is hit, the
+// oldest advertisement is evicted; re-advertising a CID refreshes its
+// position, so actively advertised content survives.
+const DefaultMaxKnownContent = 4096
+
+// Node identifies a participant in the DDS network.
+//
+// The advertised-content set is guarded internally and shared by value
+// copies of the Node, so advertisers and readers on different goroutines
+// (e.g. the mock network updating a provider while a retrieval scans it)
+// see one consistent set.
+type Node struct {
+	ID              string    `json:"id"`
+	Address         string    `json:"address"`
+	ReputationScore float64   `json:"reputationScore"`
+	LastSeen        time.Time `json:"lastSeen"`
+
+	content *contentSet
+}
+
+// contentSet is a mutex-guarded, bounded set of advertisements. order
+// holds CIDs oldest-first; adding or refreshing a CID moves it to the
+// back, and eviction removes from the front.
+type contentSet struct {
+	mu      sync.Mutex
+	max     int
+	order   []string
+	entries map[string]ContentAdvertisement
+}
+
+func newContentSet() *contentSet {
+	return &contentSet{
+		max:     DefaultMaxKnownContent,
+		entries: make(map[string]ContentAdvertisement),
+	}
+}
+
+// NewNode returns a node with the given identity and a neutral reputation.
+func NewNode(id, address string) *Node {
+	return &Node{
+		ID:              id,
+		Address:         address,
+		ReputationScore: 50,
+		LastSeen:        clock.NowTime(),
+		content:         newContentSet(),
+	}
+}
+
+// Equal reports whether two nodes are the same network participant,
+// comparing ID and Address. Volatile state — reputation, last-seen time,
+// advertised content — is ignored, so a stale copy of a peer still equals
+// a fresh one. Either side being nil makes them equal only if both are.
+func (n *Node) Equal(other *Node) bool {
+	if n == nil || other == nil {
+		return n == other
+	}
+	return n.ID == other.ID && n.Address == other.Address
+}
+
+// contentSet lazily initializes the advertisement set, so nodes built as
+// plain literals (rather than through NewNode) still work.
+func (n *Node) contentSet() *contentSet {
+	if n.content == nil {
+		n.content = newContentSet()
+	}
+	return n.content
+}
+
+// AddAdvertisedContent records that the node advertises the given manifest
+// CID without an expiry. Re-advertising an existing CID is a no-op beyond
+// refreshing its eviction position.
+func (n *Node) AddAdvertisedContent(cid string) {
+	n.AddAdvertisedContentTTL(cid, 0)
+}
+
+// AddAdvertisedContentTTL records the advertisement with a time-to-live.
+// A ttl of zero means the advertisement never expires. Re-advertising an
+// existing CID refreshes its expiry and its eviction position. When the
+// set is at its size bound, the oldest advertisement is evicted.
+func (n *Node) AddAdvertisedContentTTL(cid string, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = clock.NowTime().Add(ttl)
+	}
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[cid]; ok {
+		c.moveToBack(cid)
+		c.entries[cid] = ContentAdvertisement{CID: cid, ExpiresAt: expiresAt}
+		return
+	}
+	for c.max > 0 && len(c.order) >= c.max {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.entries, oldest)
+	}
+	c.order = append(c.order, cid)
+	c.entries[cid] = ContentAdvertisement{CID: cid, ExpiresAt: expiresAt}
+}
+
+// RemoveAdvertisedContent withdraws the advertisement for the CID.
+// Removing an unknown CID is a no-op.
+func (n *Node) RemoveAdvertisedContent(cid string) {
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[cid]; !ok {
+		return
+	}
+	delete(c.entries, cid)
+	c.remove(cid)
+}
+
+// SetMaxKnownContent changes the advertisement bound, evicting oldest
+// entries immediately if the set is already over the new bound. Zero or
+// less removes the bound.
+func (n *Node) SetMaxKnownContent(max int) {
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.max = max
+	for c.max > 0 && len(c.order) > c.max {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.entries, oldest)
+	}
+}
+
+// HasContent reports whether the node has an unexpired advertisement for
+// the CID right now.
+func (n *Node) HasContent(cid string) bool {
+	return n.HasContentAt(cid, clock.NowTime())
+}
+
+// HasContentAt reports whether the node has an advertisement for the CID
+// that is unexpired at the given time, letting callers evaluate a batch of
+// nodes against one consistent clock reading.
+func (n *Node) HasContentAt(cid string, now time.Time) bool {
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	adv, ok := c.entries[cid]
+	return ok && !adv.Expired(now)
+}
+
+// ContentCount returns how many advertisements the node currently tracks,
+// including expired ones that have not been pruned.
+func (n *Node) ContentCount() int {
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.order)
+}
+
+// KnownContentSnapshot returns the advertised CIDs oldest-first. The slice
+// is a copy; mutating it does not affect the node.
+func (n *Node) KnownContentSnapshot() []string {
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.order...)
+}
+
+// PruneExpiredContent drops advertisements that have lapsed at the given
+// time and returns how many were removed.
+func (n *Node) PruneExpiredContent(now time.Time) int {
+	c := n.contentSet()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	kept := c.order[:0]
+	for _, cid := range c.order {
+		if c.entries[cid].Expired(now) {
+			delete(c.entries, cid)
+			removed++
+			continue
+		}
+		kept = append(kept, cid)
+	}
+	c.order = kept
+	return removed
+}
+
+// moveToBack shifts an existing CID to the newest position. Callers hold
+// the lock.
+func (c *contentSet) moveToBack(cid string) {
+	c.remove(cid)
+	c.order = append(c.order, cid)
+}
+
+// remove deletes the CID from the order slice. Callers hold the lock.
+func (c *contentSet) remove(cid string) {
+	for i, id := range c.order {
+		if id == cid {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			return
+		}
+	}
+}
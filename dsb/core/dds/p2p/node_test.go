@@ -0,0 +1,177 @@
+package p2p_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// useFakeClock installs a fake package clock for the test and restores the
+// previous one afterwards, so expiry tests advance time instead of
+// sleeping.
+func useFakeClock(t *testing.T) *clock.Fake {
+	t.Helper()
+	fake := clock.NewFake(time.Now().UnixNano())
+	prev := clock.Set(fake)
+	t.Cleanup(func() { clock.Set(prev) })
+	return fake
+}
+
+func TestFindProviders_IgnoresExpiredAdvertisements(t *testing.T) {
+	fake := useFakeClock(t)
+	discovery := p2p.NewMockPeerDiscovery()
+	fresh := p2p.NewNode("node-fresh", "127.0.0.1:0")
+	stale := p2p.NewNode("node-stale", "127.0.0.1:0")
+	fresh.AddAdvertisedContentTTL("cid-ttl", time.Hour)
+	stale.AddAdvertisedContentTTL("cid-ttl", time.Nanosecond)
+	discovery.RegisterNode(fresh)
+	discovery.RegisterNode(stale)
+	fake.Advance(time.Millisecond)
+
+	providers, err := discovery.FindProviders("cid-ttl")
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(providers) != 1 || providers[0].ID != fresh.ID {
+		t.Fatalf("providers = %v, want only %s", providers, fresh.ID)
+	}
+}
+
+func TestPruneExpiredContent(t *testing.T) {
+	fake := useFakeClock(t)
+	node := p2p.NewNode("node-prune", "127.0.0.1:0")
+	node.AddAdvertisedContent("cid-forever")
+	node.AddAdvertisedContentTTL("cid-short", time.Nanosecond)
+	fake.Advance(time.Millisecond)
+
+	if removed := node.PruneExpiredContent(clock.NowTime()); removed != 1 {
+		t.Fatalf("pruned %d advertisements, want 1", removed)
+	}
+	if !node.HasContent("cid-forever") {
+		t.Error("unexpiring advertisement was pruned")
+	}
+	if node.HasContent("cid-short") {
+		t.Error("expired advertisement survived pruning")
+	}
+	if node.ContentCount() != 1 {
+		t.Errorf("ContentCount() = %d, want 1", node.ContentCount())
+	}
+}
+
+func TestAddAdvertisedContentTTL_RefreshesExpiry(t *testing.T) {
+	fake := useFakeClock(t)
+	node := p2p.NewNode("node-refresh", "127.0.0.1:0")
+	node.AddAdvertisedContentTTL("cid-refresh", time.Nanosecond)
+	node.AddAdvertisedContentTTL("cid-refresh", time.Hour)
+	fake.Advance(time.Millisecond)
+
+	if !node.HasContent("cid-refresh") {
+		t.Error("re-advertisement did not refresh the expiry")
+	}
+	if node.ContentCount() != 1 {
+		t.Errorf("ContentCount() = %d, want 1", node.ContentCount())
+	}
+}
+
+func TestNodeEqual(t *testing.T) {
+	a := p2p.NewNode("node-eq", "127.0.0.1:9400")
+	same := p2p.NewNode("node-eq", "127.0.0.1:9400")
+	// Volatile state must not affect equality.
+	same.ReputationScore = 99
+	same.LastSeen = time.Time{}
+	same.AddAdvertisedContent("cid-extra")
+
+	if !a.Equal(same) {
+		t.Error("nodes differing only in volatile state are not equal")
+	}
+	if a.Equal(p2p.NewNode("node-eq", "127.0.0.1:9401")) {
+		t.Error("nodes with different addresses compare equal")
+	}
+	if a.Equal(p2p.NewNode("node-other", "127.0.0.1:9400")) {
+		t.Error("nodes with different IDs compare equal")
+	}
+	if a.Equal(nil) {
+		t.Error("node equals nil")
+	}
+	var nilNode *p2p.Node
+	if !nilNode.Equal(nil) {
+		t.Error("nil nodes do not compare equal")
+	}
+}
+
+func TestAddAdvertisedContent_EvictsOldestAtBound(t *testing.T) {
+	node := p2p.NewNode("node-bound", "127.0.0.1:0")
+	node.SetMaxKnownContent(3)
+	for _, cid := range []string{"cid-0", "cid-1", "cid-2"} {
+		node.AddAdvertisedContent(cid)
+	}
+	// Refreshing the oldest advertisement moves it out of eviction order.
+	node.AddAdvertisedContent("cid-0")
+	node.AddAdvertisedContent("cid-3")
+
+	if node.ContentCount() != 3 {
+		t.Fatalf("ContentCount() = %d, want 3", node.ContentCount())
+	}
+	if node.HasContent("cid-1") {
+		t.Error("oldest unrefreshed advertisement was not evicted")
+	}
+	want := []string{"cid-2", "cid-0", "cid-3"}
+	got := node.KnownContentSnapshot()
+	if len(got) != len(want) {
+		t.Fatalf("KnownContentSnapshot() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("KnownContentSnapshot()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRemoveAdvertisedContent(t *testing.T) {
+	node := p2p.NewNode("node-remove", "127.0.0.1:0")
+	node.AddAdvertisedContent("cid-keep")
+	node.AddAdvertisedContent("cid-drop")
+	node.RemoveAdvertisedContent("cid-drop")
+	node.RemoveAdvertisedContent("cid-unknown")
+
+	if node.HasContent("cid-drop") {
+		t.Error("removed advertisement is still reported")
+	}
+	if !node.HasContent("cid-keep") {
+		t.Error("unrelated advertisement was removed")
+	}
+	if node.ContentCount() != 1 {
+		t.Errorf("ContentCount() = %d, want 1", node.ContentCount())
+	}
+}
+
+func TestNodeContent_ConcurrentAdvertisersAndReaders(t *testing.T) {
+	node := p2p.NewNode("node-race", "127.0.0.1:0")
+	node.SetMaxKnownContent(64)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				cid := fmt.Sprintf("cid-%d-%d", w, i)
+				node.AddAdvertisedContentTTL(cid, time.Hour)
+				node.HasContent(cid)
+				node.KnownContentSnapshot()
+				node.ContentCount()
+				if i%3 == 0 {
+					node.RemoveAdvertisedContent(cid)
+				}
+				node.PruneExpiredContent(time.Now())
+			}
+		}(w)
+	}
+	wg.Wait()
+	if node.ContentCount() > 64 {
+		t.Errorf("ContentCount() = %d, want at most the 64 bound", node.ContentCount())
+	}
+}
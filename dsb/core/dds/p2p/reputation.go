@@ -0,0 +1,65 @@
+package p2p
+
+import (
+	"math"
+	"time"
+)
+
+// Reputation bounds and adjustment sizes. Scores live on a 0-100 scale
+// with 50 as the neutral starting point (see NewNode); successes nudge a
+// peer up slowly while failures drop it fast, so one bad episode outweighs
+// a streak of routine successes.
+const (
+	MinReputation     = 0
+	NeutralReputation = 50
+	MaxReputation     = 100
+
+	ReputationSuccessReward  = 2
+	ReputationFailurePenalty = 10
+
+	// ReputationHalfLife is how long it takes half of a score's deviation
+	// from neutral to decay. Old reputations — good or bad — fade, so a
+	// once-great peer must keep earning its rank and a penalized peer
+	// eventually gets another chance.
+	ReputationHalfLife = time.Hour
+)
+
+// ReportPeerSuccess rewards the peer for a successful exchange, capped at
+// MaxReputation. Unknown node IDs are ignored.
+func (d *MockPeerDiscovery) ReportPeerSuccess(nodeID string) {
+	d.adjustReputation(nodeID, ReputationSuccessReward)
+}
+
+// ReportPeerFailure penalizes the peer for a failed or dishonest exchange,
+// floored at MinReputation. Unknown node IDs are ignored.
+func (d *MockPeerDiscovery) ReportPeerFailure(nodeID string) {
+	d.adjustReputation(nodeID, -ReputationFailurePenalty)
+}
+
+func (d *MockPeerDiscovery) adjustReputation(nodeID string, delta float64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	node, ok := d.peers[nodeID]
+	if !ok {
+		return
+	}
+	score := node.ReputationScore + delta
+	node.ReputationScore = math.Min(MaxReputation, math.Max(MinReputation, score))
+}
+
+// DecayReputation drifts every registered peer's score toward the neutral
+// baseline, halving each score's deviation once per ReputationHalfLife of
+// elapsed time. Callers tick it from whatever scheduler they run; the
+// elapsed duration need not be uniform between ticks.
+func (d *MockPeerDiscovery) DecayReputation(elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	factor := math.Pow(0.5, float64(elapsed)/float64(ReputationHalfLife))
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, node := range d.peers {
+		deviation := node.ReputationScore - NeutralReputation
+		node.ReputationScore = NeutralReputation + deviation*factor
+	}
+}
@@ -0,0 +1,90 @@
+package p2p_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+)
+
+func reputationOf(t *testing.T, d *p2p.MockPeerDiscovery, nodeID string) float64 {
+	t.Helper()
+	peers, err := d.DiscoverPeers()
+	if err != nil {
+		t.Fatalf("DiscoverPeers: %v", err)
+	}
+	for _, peer := range peers {
+		if peer.ID == nodeID {
+			return peer.ReputationScore
+		}
+	}
+	t.Fatalf("node %s not registered", nodeID)
+	return 0
+}
+
+func TestReportPeer_AdjustsAndClamps(t *testing.T) {
+	discovery := p2p.NewMockPeerDiscovery()
+	discovery.RegisterNode(p2p.NewNode("peer-1", "addr-1"))
+
+	discovery.ReportPeerSuccess("peer-1")
+	if got := reputationOf(t, discovery, "peer-1"); got != p2p.NeutralReputation+p2p.ReputationSuccessReward {
+		t.Errorf("score after success = %v", got)
+	}
+	discovery.ReportPeerFailure("peer-1")
+	if got := reputationOf(t, discovery, "peer-1"); got >= p2p.NeutralReputation {
+		t.Errorf("score after failure = %v, want below neutral", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		discovery.ReportPeerFailure("peer-1")
+	}
+	if got := reputationOf(t, discovery, "peer-1"); got != p2p.MinReputation {
+		t.Errorf("score = %v, want floored at %v", got, p2p.MinReputation)
+	}
+	for i := 0; i < 1000; i++ {
+		discovery.ReportPeerSuccess("peer-1")
+	}
+	if got := reputationOf(t, discovery, "peer-1"); got != p2p.MaxReputation {
+		t.Errorf("score = %v, want capped at %v", got, p2p.MaxReputation)
+	}
+
+	// Reports about unknown peers are dropped, not panics.
+	discovery.ReportPeerSuccess("peer-unknown")
+	discovery.ReportPeerFailure("peer-unknown")
+}
+
+func TestDecayReputation_DriftsTowardNeutral(t *testing.T) {
+	discovery := p2p.NewMockPeerDiscovery()
+	discovery.RegisterNode(p2p.NewNode("peer-good", "addr-good"))
+	discovery.RegisterNode(p2p.NewNode("peer-bad", "addr-bad"))
+	for i := 0; i < 1000; i++ {
+		discovery.ReportPeerSuccess("peer-good")
+	}
+	discovery.ReportPeerFailure("peer-bad")
+	discovery.ReportPeerFailure("peer-bad")
+
+	// One half-life halves each deviation from neutral.
+	discovery.DecayReputation(p2p.ReputationHalfLife)
+	if got := reputationOf(t, discovery, "peer-good"); got != 75 {
+		t.Errorf("inflated score after one half-life = %v, want 75", got)
+	}
+	if got := reputationOf(t, discovery, "peer-bad"); got != 40 {
+		t.Errorf("penalized score after one half-life = %v, want 40", got)
+	}
+
+	// Enough elapsed time brings both arbitrarily close to neutral: the
+	// penalized peer recovers, the inflated one loses its head start.
+	discovery.DecayReputation(100 * p2p.ReputationHalfLife)
+	for _, id := range []string{"peer-good", "peer-bad"} {
+		got := reputationOf(t, discovery, id)
+		if got < p2p.NeutralReputation-0.01 || got > p2p.NeutralReputation+0.01 {
+			t.Errorf("%s score = %v, want back at neutral", id, got)
+		}
+	}
+
+	// A zero or negative tick changes nothing.
+	before := reputationOf(t, discovery, "peer-good")
+	discovery.DecayReputation(0)
+	if got := reputationOf(t, discovery, "peer-good"); got != before {
+		t.Errorf("zero-duration decay changed the score: %v -> %v", before, got)
+	}
+}
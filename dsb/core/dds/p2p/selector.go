@@ -0,0 +1,76 @@
+package p2p
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// PeerSelector orders (and may filter) candidate peers for a retrieval of
+// the given CID. Implementations must not mutate the input slice.
+type PeerSelector interface {
+	Select(peers []Node, cid string) []Node
+}
+
+// sortedByID returns a copy of peers sorted by node ID, the stable baseline
+// ordering every strategy starts from.
+func sortedByID(peers []Node) []Node {
+	out := make([]Node, len(peers))
+	copy(out, peers)
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// ByReputation orders peers by descending reputation, breaking ties by
+// node ID. It is the default strategy.
+type ByReputation struct{}
+
+// Select implements PeerSelector.
+func (ByReputation) Select(peers []Node, cid string) []Node {
+	out := sortedByID(peers)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].ReputationScore > out[j].ReputationScore
+	})
+	return out
+}
+
+// RoundRobin rotates through peers in ID order, advancing one position per
+// selection so load spreads across providers.
+type RoundRobin struct {
+	mu   sync.Mutex
+	next int
+}
+
+// Select implements PeerSelector.
+func (r *RoundRobin) Select(peers []Node, cid string) []Node {
+	out := sortedByID(peers)
+	if len(out) == 0 {
+		return out
+	}
+	r.mu.Lock()
+	offset := r.next % len(out)
+	r.next++
+	r.mu.Unlock()
+	return append(out[offset:], out[:offset]...)
+}
+
+// Random shuffles peers with a seeded source, so tests get reproducible
+// "random" orderings.
+type Random struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewRandom returns a Random selector seeded deterministically.
+func NewRandom(seed int64) *Random {
+	return &Random{rng: rand.New(rand.NewSource(seed))}
+}
+
+// Select implements PeerSelector.
+func (r *Random) Select(peers []Node, cid string) []Node {
+	out := sortedByID(peers)
+	r.mu.Lock()
+	r.rng.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	r.mu.Unlock()
+	return out
+}
@@ -0,0 +1,69 @@
+package p2p_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+)
+
+func selectorPeers() []p2p.Node {
+	a := p2p.NewNode("node-a", "127.0.0.1:0")
+	b := p2p.NewNode("node-b", "127.0.0.1:0")
+	c := p2p.NewNode("node-c", "127.0.0.1:0")
+	b.ReputationScore = 90
+	c.ReputationScore = 90
+	return []p2p.Node{*a, *c, *b}
+}
+
+func idsOf(peers []p2p.Node) []string {
+	ids := make([]string, len(peers))
+	for i, p := range peers {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func TestByReputation_OrdersByScoreThenID(t *testing.T) {
+	got := idsOf(p2p.ByReputation{}.Select(selectorPeers(), "cid"))
+	want := []string{"node-b", "node-c", "node-a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ByReputation order = %v, want %v", got, want)
+	}
+}
+
+func TestRoundRobin_RotatesAcrossSelections(t *testing.T) {
+	rr := &p2p.RoundRobin{}
+	peers := selectorPeers()
+	first := idsOf(rr.Select(peers, "cid"))
+	second := idsOf(rr.Select(peers, "cid"))
+	if !reflect.DeepEqual(first, []string{"node-a", "node-b", "node-c"}) {
+		t.Errorf("first round-robin order = %v", first)
+	}
+	if !reflect.DeepEqual(second, []string{"node-b", "node-c", "node-a"}) {
+		t.Errorf("second round-robin order = %v", second)
+	}
+}
+
+func TestRandom_DeterministicWithFixedSeed(t *testing.T) {
+	peers := selectorPeers()
+	got := idsOf(p2p.NewRandom(42).Select(peers, "cid"))
+	again := idsOf(p2p.NewRandom(42).Select(peers, "cid"))
+	if !reflect.DeepEqual(got, again) {
+		t.Errorf("same seed gave different orders: %v vs %v", got, again)
+	}
+}
+
+func TestPeers_DeterministicOrder(t *testing.T) {
+	network := p2p.NewMockP2PService()
+	for _, id := range []string{"node-z", "node-m", "node-a"} {
+		network.AddPeer(p2p.NewNode(id, "127.0.0.1:0"))
+	}
+	for i := 0; i < 5; i++ {
+		got := idsOf(network.Peers())
+		want := []string{"node-a", "node-m", "node-z"}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Peers() order = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package p2p
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// P2PService exchanges content with other nodes: advertising what the local
+// node holds and fetching manifests and chunks from specific peers.
+type P2PService interface {
+	// AdvertiseContent tells the network that provider holds the content
+	// identified by manifestCID.
+	AdvertiseContent(manifestCID string, provider *Node) error
+	// FetchManifest retrieves a manifest from any peer that has it.
+	FetchManifest(manifestCID string) (*chunking.Manifest, error)
+	// FetchChunkFromPeer retrieves a single chunk from the given peer.
+	FetchChunkFromPeer(peerID, chunkID string) (*chunking.Chunk, error)
+	// PeerCapabilities returns what the peer advertised in its handshake,
+	// and false when no handshake with that peer is known. Callers must
+	// not issue requests using features the peer did not advertise.
+	PeerCapabilities(peerID string) (Capabilities, bool)
+}
+
+// BatchChunkFetcher is implemented by P2P services whose transport can
+// request many chunks in one round trip. Callers must check that the peer
+// advertised FeatureBatchChunks first; a conforming implementation fails
+// with ErrUnsupportedFeature otherwise.
+type BatchChunkFetcher interface {
+	FetchChunksFromPeer(peerID string, chunkIDs []string) ([]*chunking.Chunk, error)
+}
+
+// ContentPusher is implemented by P2P services that can push content to a
+// peer unasked — the reverse of fetching — so an originator can raise the
+// replication of content it holds. The receiving peer stores the manifest
+// and chunks and starts advertising the manifest.
+type ContentPusher interface {
+	PushContentToPeer(peerID string, manifest *chunking.Manifest, chunks []chunking.Chunk) error
+}
+
+// MockP2PService simulates a network of peers inside one process. Each
+// registered peer has its own manifest and chunk holdings; advertisements
+// update the provider's Node record so that MockPeerDiscovery sees them.
+type MockP2PService struct {
+	mu            sync.RWMutex
+	peers         map[string]*Node
+	peerManifests map[string]map[string]*chunking.Manifest
+	peerChunks    map[string]map[string]*chunking.Chunk
+	peerLatency   map[string]time.Duration
+	peerCaps      map[string]Capabilities
+	selector      PeerSelector
+	// announceLog records each announce message the network has seen as
+	// the list of CIDs it carried: single-CID advertisements log one
+	// entry, a batch logs one entry with several CIDs. Tests wrapping the
+	// mock in an Advertiser read it to assert coalescing.
+	announceLog [][]string
+}
+
+// NewMockP2PService returns a simulated network with no peers.
+func NewMockP2PService() *MockP2PService {
+	return &MockP2PService{
+		peers:         make(map[string]*Node),
+		peerManifests: make(map[string]map[string]*chunking.Manifest),
+		peerChunks:    make(map[string]map[string]*chunking.Chunk),
+		peerLatency:   make(map[string]time.Duration),
+		peerCaps:      make(map[string]Capabilities),
+		selector:      ByReputation{},
+	}
+}
+
+// SetSelector replaces the strategy used to order peers when serving
+// requests that could be answered by several of them.
+func (s *MockP2PService) SetSelector(selector PeerSelector) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.selector = selector
+}
+
+// Peers returns the registered peers in deterministic (ID) order.
+func (s *MockP2PService) Peers() []Node {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	peers := make([]Node, 0, len(s.peers))
+	for _, node := range s.peers {
+		peers = append(peers, *node)
+	}
+	return sortedByID(peers)
+}
+
+// SetPeerLatency makes every chunk fetch from the given peer take at least
+// d, for tests exercising slow responders.
+func (s *MockP2PService) SetPeerLatency(peerID string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peerLatency[peerID] = d
+}
+
+// AddPeer registers a peer with the simulated network.
+func (s *MockP2PService) AddPeer(node *Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peers[node.ID] = node
+	if s.peerManifests[node.ID] == nil {
+		s.peerManifests[node.ID] = make(map[string]*chunking.Manifest)
+	}
+	if s.peerChunks[node.ID] == nil {
+		s.peerChunks[node.ID] = make(map[string]*chunking.Chunk)
+	}
+}
+
+// SeedPeerContent gives a registered peer a manifest and its chunks, and
+// marks the peer as advertising the manifest.
+func (s *MockP2PService) SeedPeerContent(peerID string, manifest *chunking.Manifest, chunks []chunking.Chunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node, ok := s.peers[peerID]
+	if !ok {
+		return fmt.Errorf("p2p: unknown peer %s", peerID)
+	}
+	s.peerManifests[peerID][manifest.ID] = manifest
+	for i := range chunks {
+		s.peerChunks[peerID][chunks[i].ID] = &chunks[i]
+	}
+	node.AddAdvertisedContent(manifest.ID)
+	return nil
+}
+
+// PushContentToPeer stores the manifest and chunks at the receiving peer
+// and marks it as advertising the manifest, implementing ContentPusher.
+func (s *MockP2PService) PushContentToPeer(peerID string, manifest *chunking.Manifest, chunks []chunking.Chunk) error {
+	return s.SeedPeerContent(peerID, manifest, chunks)
+}
+
+// SetPeerHandshake replaces the handshake a peer is simulated to have
+// sent, e.g. an old protocol version without batch support. Peers without
+// an explicit handshake report the current version with every feature.
+func (s *MockP2PService) SetPeerHandshake(peerID string, h Handshake) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peerCaps[peerID] = h.Capabilities()
+}
+
+// PeerCapabilities returns the peer's simulated handshake capabilities.
+func (s *MockP2PService) PeerCapabilities(peerID string) (Capabilities, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.peers[peerID]; !ok {
+		return Capabilities{}, false
+	}
+	if caps, ok := s.peerCaps[peerID]; ok {
+		return caps, true
+	}
+	return NewHandshake(peerID, FeatureBatchChunks, FeatureCompression, FeatureAuth).Capabilities(), true
+}
+
+// Ping reports whether the peer is reachable on the simulated network.
+func (s *MockP2PService) Ping(peerID string) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.peers[peerID]; !ok {
+		return fmt.Errorf("p2p: peer %s is unreachable", peerID)
+	}
+	return nil
+}
+
+// AdvertiseContent records the advertisement on the provider's Node and
+// logs it as a one-CID announce message.
+func (s *MockP2PService) AdvertiseContent(manifestCID string, provider *Node) error {
+	if provider == nil {
+		return fmt.Errorf("p2p: advertisement requires a provider node")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	provider.AddAdvertisedContent(manifestCID)
+	s.announceLog = append(s.announceLog, []string{manifestCID})
+	return nil
+}
+
+// AdvertiseContentBatch records several advertisements delivered in one
+// announce message, implementing BatchAdvertiser.
+func (s *MockP2PService) AdvertiseContentBatch(manifestCIDs []string, provider *Node) error {
+	if provider == nil {
+		return fmt.Errorf("p2p: advertisement requires a provider node")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, cid := range manifestCIDs {
+		provider.AddAdvertisedContent(cid)
+	}
+	s.announceLog = append(s.announceLog, append([]string(nil), manifestCIDs...))
+	return nil
+}
+
+// AdvertisedToPeers returns how many announce messages carried the given
+// CID — duplicates suppressed by an Advertiser never reach this count.
+func (s *MockP2PService) AdvertisedToPeers(manifestCID string) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, msg := range s.announceLog {
+		for _, cid := range msg {
+			if cid == manifestCID {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Announcements returns a copy of every announce message seen, each as the
+// CID list it carried, in arrival order.
+func (s *MockP2PService) Announcements() [][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([][]string, len(s.announceLog))
+	for i, msg := range s.announceLog {
+		out[i] = append([]string(nil), msg...)
+	}
+	return out
+}
+
+// FetchManifest returns the manifest from the first peer holding it, in
+// the order chosen by the configured selector, so behavior is
+// deterministic across runs.
+func (s *MockP2PService) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	peers := s.Peers()
+	s.mu.RLock()
+	ordered := s.selector.Select(peers, manifestCID)
+	s.mu.RUnlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, peer := range ordered {
+		if m, ok := s.peerManifests[peer.ID][manifestCID]; ok {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("p2p: no peer holds manifest %s", manifestCID)
+}
+
+// FetchChunkFromPeer returns the chunk from the given peer's holdings.
+func (s *MockP2PService) FetchChunkFromPeer(peerID, chunkID string) (*chunking.Chunk, error) {
+	s.mu.RLock()
+	latency := s.peerLatency[peerID]
+	s.mu.RUnlock()
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	chunks, ok := s.peerChunks[peerID]
+	if !ok {
+		return nil, fmt.Errorf("p2p: unknown peer %s", peerID)
+	}
+	chunk, ok := chunks[chunkID]
+	if !ok {
+		return nil, fmt.Errorf("p2p: peer %s does not hold chunk %s", peerID, chunkID)
+	}
+	return chunk, nil
+}
+
+// FetchChunksFromPeer returns many chunks in one simulated round trip,
+// honoring the peer's handshake: a peer that did not advertise batch
+// support fails the request with ErrUnsupportedFeature.
+func (s *MockP2PService) FetchChunksFromPeer(peerID string, chunkIDs []string) ([]*chunking.Chunk, error) {
+	caps, ok := s.PeerCapabilities(peerID)
+	if !ok {
+		return nil, fmt.Errorf("p2p: unknown peer %s", peerID)
+	}
+	if !caps.Supports(FeatureBatchChunks) {
+		return nil, fmt.Errorf("%w: peer %s did not advertise %s", ErrUnsupportedFeature, peerID, FeatureBatchChunks)
+	}
+	out := make([]*chunking.Chunk, 0, len(chunkIDs))
+	for _, chunkID := range chunkIDs {
+		chunk, err := s.FetchChunkFromPeer(peerID, chunkID)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk)
+	}
+	return out, nil
+}
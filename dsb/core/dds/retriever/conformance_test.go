@@ -0,0 +1,40 @@
+package retriever_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/api"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever/retrievertest"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestMockRetriever_Conformance(t *testing.T) {
+	retrievertest.RunRetrieverConformance(t, func(t *testing.T, manifest *chunking.Manifest, chunks []chunking.Chunk) retriever.Retriever {
+		mock := retriever.NewMockRetriever()
+		mock.AddManifest(manifest)
+		for i := range chunks {
+			mock.AddChunk(&chunks[i])
+		}
+		return mock
+	})
+}
+
+func TestHTTPRetriever_Conformance(t *testing.T) {
+	retrievertest.RunRetrieverConformance(t, func(t *testing.T, manifest *chunking.Manifest, chunks []chunking.Chunk) retriever.Retriever {
+		store := storage.NewInMemoryStorage()
+		for i := range chunks {
+			if err := store.StoreChunk(&chunks[i]); err != nil {
+				t.Fatalf("StoreChunk: %v", err)
+			}
+		}
+		if err := store.StoreManifest(manifest); err != nil {
+			t.Fatalf("StoreManifest: %v", err)
+		}
+		srv := httptest.NewServer(api.NewGatewayHandler(store))
+		t.Cleanup(srv.Close)
+		return retriever.NewHTTPRetriever(srv.URL, 1)
+	})
+}
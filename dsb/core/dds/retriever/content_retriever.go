@@ -0,0 +1,184 @@
+package retriever
+
+import (
+	"context"
+	"fmt"
+	"hash"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// contentHasher returns incremental digest state matching the manifest's
+// ID scheme — pooled SHA-256 for plain manifests, a keyed hasher for keyed
+// ones — and the release function to defer.
+func contentHasher(manifest *chunking.Manifest, key []byte) (hash.Hash, func()) {
+	if manifest.Keyed() {
+		return chunking.NewKeyedHasher(key), func() {}
+	}
+	h := chunking.AcquireHasher()
+	return h, func() { chunking.ReleaseHasher(h) }
+}
+
+// ContentRetriever reassembles whole content from a Retriever source,
+// verifying each chunk's digest and the overall content digest.
+//
+// The reassembly path is allocation-lean: a single output buffer is
+// pre-sized from the manifest's TotalSize, chunks are written directly into
+// it as they arrive, and hash state comes from the pool shared with the
+// DDSCoreService local path.
+type ContentRetriever struct {
+	source Retriever
+
+	// verifyChunks controls the per-chunk digest re-check. It defaults to
+	// true and should only be disabled for trusted sources (e.g. a local
+	// store the caller just wrote); the whole-content digest is always
+	// verified.
+	verifyChunks bool
+
+	// prefetchWindow bounds how many chunks RetrieveContentTo holds in
+	// memory at once. See SetPrefetchWindow.
+	prefetchWindow int
+
+	// hooks inspect reassembled content before it is returned. See
+	// AddVerificationHook.
+	hooks []namedHook
+
+	// keys resolves swarm keys for manifests whose IDs are HMAC-derived.
+	// See SetKeyProvider.
+	keys chunking.KeyProvider
+
+	// chunkOrder decides the order chunks are requested in. See
+	// SetChunkOrder; the default is InOrder.
+	chunkOrder ChunkOrderStrategy
+}
+
+// NewContentRetriever returns a retriever reading from source with
+// per-chunk verification enabled.
+func NewContentRetriever(source Retriever) *ContentRetriever {
+	return &ContentRetriever{source: source, verifyChunks: true, prefetchWindow: 1, chunkOrder: InOrder{}}
+}
+
+// SetChunkOrder replaces the strategy deciding which chunks are requested
+// first (e.g. RarestFirst on a thin swarm). Reassembly order is unaffected.
+// Nil restores the default InOrder. The streaming RetrieveContentTo path
+// always fetches in manifest order, since it writes as it goes.
+func (r *ContentRetriever) SetChunkOrder(strategy ChunkOrderStrategy) {
+	if strategy == nil {
+		strategy = InOrder{}
+	}
+	r.chunkOrder = strategy
+}
+
+// SetVerifyChunks toggles per-chunk digest verification. Disable it only
+// when the source is trusted; the content-level digest check still runs.
+func (r *ContentRetriever) SetVerifyChunks(v bool) {
+	r.verifyChunks = v
+}
+
+// SetKeyProvider injects the swarm keys used to verify manifests whose IDs
+// are HMAC-derived (see chunking.NewKeyedChunker). Without a provider
+// holding the matching key, retrieving keyed content fails with an error
+// wrapping chunking.ErrSwarmKeyUnknown.
+func (r *ContentRetriever) SetKeyProvider(keys chunking.KeyProvider) {
+	r.keys = keys
+}
+
+// AddVerificationHook registers a hook run against reassembled content
+// after all integrity checks pass, in registration order. The name labels
+// the hook in rejection errors. Hooks apply to RetrieveContent and
+// RetrieveContentContext; the streaming RetrieveContentTo path does not
+// run them, since hooks need the whole content in memory.
+func (r *ContentRetriever) AddVerificationHook(name string, hook VerificationHook) {
+	r.hooks = append(r.hooks, namedHook{name: name, hook: hook})
+}
+
+// RetrieveContent fetches the manifest and all its chunks, verifies them,
+// and returns the reassembled content.
+func (r *ContentRetriever) RetrieveContent(manifestCID string) ([]byte, error) {
+	return r.retrieveContent(context.Background(), manifestCID)
+}
+
+// RetrieveContentContext is RetrieveContent with request tracing: errors
+// carry the request ID from ctx (generated if absent) so failures can be
+// correlated across layers.
+func (r *ContentRetriever) RetrieveContentContext(ctx context.Context, manifestCID string) ([]byte, error) {
+	return r.retrieveContent(dds.EnsureRequestID(ctx), manifestCID)
+}
+
+func (r *ContentRetriever) retrieveContent(ctx context.Context, manifestCID string) ([]byte, error) {
+	op := dds.Op(ctx, "retrieve")
+	manifest, err := r.source.FetchManifest(manifestCID)
+	if err != nil {
+		return nil, fmt.Errorf(op+": failed to fetch manifest %s: %w", manifestCID, err)
+	}
+	if manifest.TotalSize == 0 && len(manifest.ChunkIDs) == 0 {
+		if err := runVerificationHooks(r.hooks, manifest, nil); err != nil {
+			return nil, fmt.Errorf(op+": %w", err)
+		}
+		return []byte{}, nil
+	}
+	if err := detectManifestCycle(map[string]bool{}, manifestCID, manifest); err != nil {
+		return nil, err
+	}
+	if err := manifest.ValidateOrder(); err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+	key, err := manifest.ResolveSwarmKey(r.keys)
+	if err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+
+	content := make([]byte, 0, manifest.TotalSize)
+	contentHash, release := contentHasher(manifest, key)
+	defer release()
+
+	// Chunk IDs are digests of chunk data, so content with repeating
+	// blocks legitimately lists the same ID more than once. The fetch loop
+	// requests each distinct chunk once, in the configured strategy's
+	// order; reassembly below always follows manifest order.
+	order := r.chunkOrder
+	if order == nil {
+		order = InOrder{}
+	}
+	fetched := make(map[string][]byte, len(manifest.ChunkIDs))
+	for _, chunkID := range order.OrderChunks(manifest.ChunkIDs) {
+		chunk, err := r.source.FetchChunk(chunkID)
+		if err != nil {
+			return nil, fmt.Errorf(op+": failed to fetch chunk %s: %w", chunkID, err)
+		}
+		if r.verifyChunks && !manifest.VerifyChunk(chunk.Data, chunkID, key) {
+			return nil, fmt.Errorf(op+": chunk %s failed integrity check (got digest %s)",
+				chunkID, manifest.ContentDigest(chunk.Data, key))
+		}
+		fetched[chunkID] = chunk.Data
+	}
+	for _, chunkID := range manifest.ChunkIDs {
+		data, ok := fetched[chunkID]
+		if !ok {
+			// The strategy violated its contract and skipped a chunk.
+			return nil, fmt.Errorf(op+": chunk order strategy did not request chunk %s", chunkID)
+		}
+		content = append(content, data...)
+		contentHash.Write(data)
+	}
+
+	if int64(len(content)) != manifest.EffectiveStoredSize() {
+		return nil, fmt.Errorf(op+": reassembled size %d does not match manifest stored size %d",
+			len(content), manifest.EffectiveStoredSize())
+	}
+	if got := chunking.SumToHex(contentHash); got != manifest.ContentID {
+		// Pinpoint whether a chunk is corrupt or the manifest's chunk
+		// list itself cannot produce the content.
+		ordered := make([][]byte, len(manifest.ChunkIDs))
+		for i, chunkID := range manifest.ChunkIDs {
+			ordered[i] = fetched[chunkID]
+		}
+		return nil, fmt.Errorf(op+": reassembled content failed integrity check (got digest %s): %w",
+			got, manifest.DiagnoseDigestMismatch(ordered, key))
+	}
+	if err := runVerificationHooks(r.hooks, manifest, content); err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,31 @@
+package retriever
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// ErrManifestCycle reports a manifest chain that references itself: a
+// manifest listing its own CID among its chunk IDs, or (once nested
+// manifests exist) a descendant manifest pointing back at an ancestor.
+// Without this guard such a manifest would send retrieval into a loop.
+var ErrManifestCycle = errors.New("manifest chain contains a cycle")
+
+// detectManifestCycle records manifest's CID in visited and errors if any
+// of its chunk IDs was already visited. Callers seed visited with the CID
+// the retrieval started from and call this once per manifest fetched, so
+// both direct self-reference and deeper cycles are caught before any
+// chunk fetch recurses.
+func detectManifestCycle(visited map[string]bool, manifestCID string, manifest *chunking.Manifest) error {
+	visited[manifestCID] = true
+	visited[manifest.ID] = true
+	for _, chunkID := range manifest.ChunkIDs {
+		if visited[chunkID] {
+			return fmt.Errorf("retrieve: manifest %s references visited CID %s: %w",
+				manifestCID, chunkID, ErrManifestCycle)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package retriever_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// selfReferentialManifest builds a manifest whose chunk list includes its
+// own CID, as a buggy or malicious publisher could produce.
+func selfReferentialManifest() *chunking.Manifest {
+	data := []byte("legitimate chunk data")
+	chunkID := chunking.HashContent(data)
+	return &chunking.Manifest{
+		ID:          "cid-cyclic",
+		ContentID:   chunking.HashContent(data),
+		ChunkIDs:    []string{chunkID, "cid-cyclic"},
+		TotalSize:   int64(len(data)) * 2,
+		ContentType: "application/octet-stream",
+	}
+}
+
+func TestRetrieveContent_RejectsSelfReferentialManifest(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	mock.AddManifest(selfReferentialManifest())
+
+	cr := retriever.NewContentRetriever(mock)
+	_, err := cr.RetrieveContent("cid-cyclic")
+	if err == nil {
+		t.Fatal("self-referential manifest was not rejected")
+	}
+	if !errors.Is(err, retriever.ErrManifestCycle) {
+		t.Errorf("error is not ErrManifestCycle: %v", err)
+	}
+}
+
+func TestRetrieveContentTo_RejectsSelfReferentialManifest(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	mock.AddManifest(selfReferentialManifest())
+
+	cr := retriever.NewContentRetriever(mock)
+	err := cr.RetrieveContentTo(&bytes.Buffer{}, "cid-cyclic")
+	if err == nil {
+		t.Fatal("self-referential manifest was not rejected")
+	}
+	if !errors.Is(err, retriever.ErrManifestCycle) {
+		t.Errorf("error is not ErrManifestCycle: %v", err)
+	}
+}
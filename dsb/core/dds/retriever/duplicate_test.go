@@ -0,0 +1,65 @@
+package retriever_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// Content whose blocks repeat produces a manifest listing the same chunk
+// ID several times, because chunk IDs are digests of chunk data. Such
+// manifests are legitimate: duplicates reassemble in order.
+func TestRetrieveContent_RepeatedChunkIDsReassembleInOrder(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	content := []byte(strings.Repeat("all same", 3))
+	cid := seedMockContent(t, mock, content, 8)
+
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	if len(manifest.ChunkIDs) != 3 || manifest.ChunkIDs[0] != manifest.ChunkIDs[2] {
+		t.Fatalf("fixture did not produce duplicate chunk IDs: %v", manifest.ChunkIDs)
+	}
+
+	counter := &chunkCounter{Retriever: mock}
+	got, err := retriever.NewContentRetriever(counter).RetrieveContent(cid)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("reassembled content does not match original")
+	}
+	// The duplicate is reused, not re-downloaded.
+	if n := counter.fetches.Load(); n != 1 {
+		t.Errorf("fetched %d chunks for one distinct ID, want 1", n)
+	}
+}
+
+// A manifest referencing a chunk nobody holds still fails with a fetch
+// error naming that chunk, not a count mismatch.
+func TestRetrieveContent_MissingChunkNamedInError(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("soon to lose a chunk, sadly"), 8)
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	broken := *manifest
+	broken.ChunkIDs = append([]string{}, manifest.ChunkIDs...)
+	broken.ChunkIDs[1] = "chunk-nobody-holds"
+	broken.ChunkRefs = append([]chunking.ChunkRef{}, manifest.ChunkRefs...)
+	broken.ChunkRefs[1].ID = "chunk-nobody-holds"
+	mock.AddManifest(&broken)
+
+	_, err = retriever.NewContentRetriever(mock).RetrieveContent(cid)
+	if err == nil {
+		t.Fatal("manifest with a missing chunk was accepted")
+	}
+	if !strings.Contains(err.Error(), "chunk-nobody-holds") {
+		t.Errorf("error does not name the missing chunk: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package retriever_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// recordingRetriever wraps a Retriever and records the order chunks were
+// requested in.
+type recordingRetriever struct {
+	retriever.Retriever
+	mu       sync.Mutex
+	requests []string
+}
+
+func (r *recordingRetriever) FetchChunk(chunkID string) (*chunking.Chunk, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, chunkID)
+	r.mu.Unlock()
+	return r.Retriever.FetchChunk(chunkID)
+}
+
+// fetchOrderFixture publishes "aaaabbbbcccc" as three 4-byte chunks into a
+// recording source and returns fixed provider counts making "bbbb" the
+// rarest chunk and "aaaa" the most common.
+func fetchOrderFixture(t *testing.T) (*recordingRetriever, *chunking.Manifest, retriever.ProviderCounts) {
+	t.Helper()
+	content := []byte("aaaabbbbcccc")
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	mock := retriever.NewMockRetriever()
+	mock.AddManifest(manifest)
+	for i := range chunks {
+		mock.AddChunk(&chunks[i])
+	}
+	counts := map[string]int{
+		chunking.HashContent([]byte("aaaa")): 3,
+		chunking.HashContent([]byte("bbbb")): 1,
+		chunking.HashContent([]byte("cccc")): 2,
+	}
+	counter := retriever.ProviderCountFunc(func(chunkID string) int { return counts[chunkID] })
+	return &recordingRetriever{Retriever: mock}, manifest, counter
+}
+
+func TestRarestFirst_RequestsLeastAvailableChunkFirst(t *testing.T) {
+	source, manifest, counts := fetchOrderFixture(t)
+	r := retriever.NewContentRetriever(source)
+	r.SetChunkOrder(retriever.RarestFirst{Counts: counts})
+
+	content, err := r.RetrieveContent(manifest.ID)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	if string(content) != "aaaabbbbcccc" {
+		t.Errorf("reassembled %q, want manifest order despite rarest-first fetching", content)
+	}
+	want := []string{
+		chunking.HashContent([]byte("bbbb")),
+		chunking.HashContent([]byte("cccc")),
+		chunking.HashContent([]byte("aaaa")),
+	}
+	if fmt.Sprint(source.requests) != fmt.Sprint(want) {
+		t.Errorf("fetch order %v, want rarest first %v", source.requests, want)
+	}
+}
+
+func TestInOrder_FetchesManifestOrderOnce(t *testing.T) {
+	source, manifest, _ := fetchOrderFixture(t)
+	r := retriever.NewContentRetriever(source)
+
+	content, err := r.RetrieveContent(manifest.ID)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	if string(content) != "aaaabbbbcccc" {
+		t.Errorf("reassembled %q, want original content", content)
+	}
+	if fmt.Sprint(source.requests) != fmt.Sprint(manifest.ChunkIDs) {
+		t.Errorf("fetch order %v, want manifest order %v", source.requests, manifest.ChunkIDs)
+	}
+}
+
+func TestRarestFirst_DeduplicatesRepeatedChunks(t *testing.T) {
+	ids := []string{"x", "y", "x", "z", "y"}
+	counter := retriever.ProviderCountFunc(func(chunkID string) int {
+		return map[string]int{"x": 2, "y": 2, "z": 1}[chunkID]
+	})
+	got := retriever.RarestFirst{Counts: counter}.OrderChunks(ids)
+	// z is rarest; the x/y tie keeps manifest order.
+	want := []string{"z", "x", "y"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("OrderChunks(%v) = %v, want %v", ids, got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package retriever
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// VerificationHook inspects fully reassembled content before it leaves the
+// retrieval path, e.g. a malware scan or a size policy. Hooks run after
+// every integrity check has passed; returning an error rejects the
+// content.
+type VerificationHook interface {
+	Inspect(manifest *chunking.Manifest, content []byte) error
+}
+
+// VerificationHookFunc adapts a function to the VerificationHook
+// interface.
+type VerificationHookFunc func(manifest *chunking.Manifest, content []byte) error
+
+// Inspect implements VerificationHook.
+func (f VerificationHookFunc) Inspect(manifest *chunking.Manifest, content []byte) error {
+	return f(manifest, content)
+}
+
+// ErrContentRejected reports content refused by a verification hook. Hook
+// carries the name the hook was registered under, so a rejection from a
+// chain of hooks is attributable.
+type ErrContentRejected struct {
+	Hook   string
+	Reason string
+}
+
+// Error implements error.
+func (e *ErrContentRejected) Error() string {
+	return fmt.Sprintf("retriever: content rejected by %s: %s", e.Hook, e.Reason)
+}
+
+// namedHook pairs a registered hook with its registration name.
+type namedHook struct {
+	name string
+	hook VerificationHook
+}
+
+// RunVerificationHooks runs hooks in order against the content, stopping
+// at the first rejection. A hook error that is not already an
+// *ErrContentRejected is wrapped into one carrying the hook's name.
+func runVerificationHooks(hooks []namedHook, manifest *chunking.Manifest, content []byte) error {
+	for _, h := range hooks {
+		err := h.hook.Inspect(manifest, content)
+		if err == nil {
+			continue
+		}
+		if rejected, ok := err.(*ErrContentRejected); ok {
+			return rejected
+		}
+		return &ErrContentRejected{Hook: h.name, Reason: err.Error()}
+	}
+	return nil
+}
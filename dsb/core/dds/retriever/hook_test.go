@@ -0,0 +1,101 @@
+package retriever_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// markerHook rejects any content containing marker, standing in for a
+// malware scanner.
+func markerHook(marker string) retriever.VerificationHook {
+	return retriever.VerificationHookFunc(func(_ *chunking.Manifest, content []byte) error {
+		if bytes.Contains(content, []byte(marker)) {
+			return fmt.Errorf("content contains %q", marker)
+		}
+		return nil
+	})
+}
+
+func TestVerificationHook_RejectsFlaggedContent(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("harmless prefix EVIL-MARKER harmless suffix"), 16)
+
+	cr := retriever.NewContentRetriever(mock)
+	cr.AddVerificationHook("scanner", markerHook("EVIL-MARKER"))
+
+	_, err := cr.RetrieveContent(cid)
+	if err == nil {
+		t.Fatal("flagged content was not rejected")
+	}
+	var rejected *retriever.ErrContentRejected
+	if !errors.As(err, &rejected) {
+		t.Fatalf("error is not *ErrContentRejected: %v", err)
+	}
+	if rejected.Hook != "scanner" {
+		t.Errorf("rejection attributed to %q, want %q", rejected.Hook, "scanner")
+	}
+}
+
+func TestVerificationHook_PassesCleanContent(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	content := []byte("nothing to see here, move along")
+	cid := seedMockContent(t, mock, content, 16)
+
+	cr := retriever.NewContentRetriever(mock)
+	cr.AddVerificationHook("scanner", markerHook("EVIL-MARKER"))
+
+	got, err := cr.RetrieveContent(cid)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("retrieved content does not match original")
+	}
+}
+
+// Hooks compose: the first rejection in registration order wins and is
+// attributed to the hook that raised it.
+func TestVerificationHook_FirstRejectionWins(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("both markers: ALPHA and BETA"), 16)
+
+	cr := retriever.NewContentRetriever(mock)
+	cr.AddVerificationHook("alpha-scan", markerHook("ALPHA"))
+	cr.AddVerificationHook("beta-scan", markerHook("BETA"))
+
+	_, err := cr.RetrieveContent(cid)
+	var rejected *retriever.ErrContentRejected
+	if !errors.As(err, &rejected) {
+		t.Fatalf("error is not *ErrContentRejected: %v", err)
+	}
+	if rejected.Hook != "alpha-scan" {
+		t.Errorf("rejection attributed to %q, want %q", rejected.Hook, "alpha-scan")
+	}
+}
+
+// A hook returning its own *ErrContentRejected keeps that attribution
+// rather than being re-wrapped under the registration name.
+func TestVerificationHook_PreservesExplicitRejection(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("policy-checked content"), 16)
+
+	cr := retriever.NewContentRetriever(mock)
+	cr.AddVerificationHook("outer", retriever.VerificationHookFunc(
+		func(_ *chunking.Manifest, _ []byte) error {
+			return &retriever.ErrContentRejected{Hook: "inner-policy", Reason: "blocked"}
+		}))
+
+	_, err := cr.RetrieveContent(cid)
+	var rejected *retriever.ErrContentRejected
+	if !errors.As(err, &rejected) {
+		t.Fatalf("error is not *ErrContentRejected: %v", err)
+	}
+	if rejected.Hook != "inner-policy" {
+		t.Errorf("rejection attributed to %q, want %q", rejected.Hook, "inner-policy")
+	}
+}
@@ -0,0 +1,101 @@
+package retriever
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// HTTPRetriever fetches manifests and chunks from an HTTP gateway or seed
+// server exposing `GET /manifests/{cid}` and `GET /chunks/{cid}`. Responses
+// are verified against the requested CID before being returned, so a
+// misbehaving gateway cannot hand back wrong bytes.
+type HTTPRetriever struct {
+	baseURL string
+	client  *http.Client
+	retries int
+}
+
+// DefaultHTTPTimeout bounds each individual gateway request.
+const DefaultHTTPTimeout = 10 * time.Second
+
+// NewHTTPRetriever returns a retriever reading from the gateway at baseURL
+// (no trailing slash required). retries is the number of additional attempts
+// made per request after the first fails.
+func NewHTTPRetriever(baseURL string, retries int) *HTTPRetriever {
+	if retries < 0 {
+		retries = 0
+	}
+	return &HTTPRetriever{
+		baseURL: trimTrailingSlash(baseURL),
+		client:  &http.Client{Timeout: DefaultHTTPTimeout},
+		retries: retries,
+	}
+}
+
+func trimTrailingSlash(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '/' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// get fetches a URL, retrying transient failures.
+func (r *HTTPRetriever) get(url string) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; attempt <= r.retries; attempt++ {
+		resp, err := r.client.Get(url)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("retriever: gateway has no content at %s", url)
+		}
+		if resp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("retriever: gateway returned %s for %s", resp.Status, url)
+			continue
+		}
+		return body, nil
+	}
+	return nil, fmt.Errorf("retriever: gateway request failed after %d attempts: %w", r.retries+1, lastErr)
+}
+
+// FetchManifest fetches and deserializes the manifest, verifying that its ID
+// matches the requested CID.
+func (r *HTTPRetriever) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	body, err := r.get(r.baseURL + "/manifests/" + manifestCID)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := chunking.ManifestFromJSON(body)
+	if err != nil {
+		return nil, fmt.Errorf("retriever: gateway returned invalid manifest for %s: %w", manifestCID, err)
+	}
+	if manifest.ID != manifestCID {
+		return nil, fmt.Errorf("retriever: gateway returned manifest %s when asked for %s", manifest.ID, manifestCID)
+	}
+	return manifest, nil
+}
+
+// FetchChunk fetches raw chunk bytes and verifies their digest against the
+// requested chunk ID.
+func (r *HTTPRetriever) FetchChunk(chunkID string) (*chunking.Chunk, error) {
+	body, err := r.get(r.baseURL + "/chunks/" + chunkID)
+	if err != nil {
+		return nil, err
+	}
+	if got := chunking.HashContent(body); got != chunkID {
+		return nil, fmt.Errorf("retriever: chunk from gateway failed integrity check (got digest %s, want %s)", got, chunkID)
+	}
+	return &chunking.Chunk{ID: chunkID, Data: body}, nil
+}
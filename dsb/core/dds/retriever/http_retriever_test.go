@@ -0,0 +1,125 @@
+package retriever_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/api"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// seedGateway publishes content into a fresh store and serves it over an
+// httptest server speaking the gateway protocol.
+func seedGateway(t *testing.T, content []byte) (*httptest.Server, *chunking.Manifest) {
+	t.Helper()
+	store := storage.NewInMemoryStorage()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	for i := range chunks {
+		if err := store.StoreChunk(&chunks[i]); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	if err := store.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+	srv := httptest.NewServer(api.NewGatewayHandler(store))
+	t.Cleanup(srv.Close)
+	return srv, manifest
+}
+
+func TestHTTPRetriever_RoundTrip(t *testing.T) {
+	content := []byte("seeded content served over the gateway")
+	srv, manifest := seedGateway(t, content)
+
+	r := retriever.NewHTTPRetriever(srv.URL, 1)
+	got, err := retriever.NewContentRetriever(r).RetrieveContent(manifest.ID)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+}
+
+func TestHTTPRetriever_NotFound(t *testing.T) {
+	srv, _ := seedGateway(t, []byte("something"))
+	r := retriever.NewHTTPRetriever(srv.URL, 0)
+	if _, err := r.FetchManifest("no-such-cid"); err == nil {
+		t.Fatal("want error for unknown manifest, got nil")
+	}
+	if _, err := r.FetchChunk("no-such-chunk"); err == nil {
+		t.Fatal("want error for unknown chunk, got nil")
+	}
+}
+
+func TestHTTPRetriever_RejectsCorruptChunk(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/chunks/") {
+			w.Write([]byte("not the bytes this chunk ID promises"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	r := retriever.NewHTTPRetriever(srv.URL, 0)
+	if _, err := r.FetchChunk(chunking.HashContent([]byte("expected"))); err == nil {
+		t.Fatal("want integrity error for corrupt chunk, got nil")
+	}
+}
+
+func TestHTTPRetriever_RetriesTransientFailures(t *testing.T) {
+	content := []byte("eventually served")
+	gateway, manifest := seedGateway(t, content)
+
+	var calls int
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "temporarily broken", http.StatusInternalServerError)
+			return
+		}
+		resp, err := http.Get(gateway.URL + r.URL.Path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		buf := make([]byte, 1<<16)
+		for {
+			n, err := resp.Body.Read(buf)
+			if n > 0 {
+				w.Write(buf[:n])
+			}
+			if err != nil {
+				break
+			}
+		}
+	}))
+	defer flaky.Close()
+
+	r := retriever.NewHTTPRetriever(flaky.URL, 2)
+	m, err := r.FetchManifest(manifest.ID)
+	if err != nil {
+		t.Fatalf("FetchManifest after retry: %v", err)
+	}
+	if m.ID != manifest.ID {
+		t.Errorf("got manifest %s, want %s", m.ID, manifest.ID)
+	}
+}
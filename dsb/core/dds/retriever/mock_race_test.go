@@ -0,0 +1,59 @@
+package retriever_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// TestMocks_ConcurrentAdvertiseAndFetch exercises MockOriginator and
+// MockRetriever from many goroutines at once; run with -race.
+func TestMocks_ConcurrentAdvertiseAndFetch(t *testing.T) {
+	orig := originator.NewMockOriginator()
+	mock := retriever.NewMockRetriever()
+
+	const workers = 8
+	const perWorker = 50
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				data := []byte(fmt.Sprintf("worker-%d-item-%d", w, i))
+				chunk := &chunking.Chunk{ID: chunking.HashContent(data), Data: data}
+				mock.AddChunk(chunk)
+				manifest := &chunking.Manifest{
+					ID:        chunking.HashContent([]byte(chunk.ID)),
+					ContentID: chunk.ID,
+					ChunkIDs:  []string{chunk.ID},
+					TotalSize: int64(len(data)),
+				}
+				mock.AddManifest(manifest)
+				if err := orig.AdvertiseContent(manifest.ID); err != nil {
+					t.Errorf("AdvertiseContent: %v", err)
+				}
+				// Interleave reads with the writes above.
+				if _, err := mock.FetchChunk(chunk.ID); err != nil {
+					t.Errorf("FetchChunk: %v", err)
+				}
+				if _, err := mock.FetchManifest(manifest.ID); err != nil {
+					t.Errorf("FetchManifest: %v", err)
+				}
+				if !orig.WasAdvertised(manifest.ID) {
+					t.Errorf("manifest %s not recorded as advertised", manifest.ID)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if got := orig.AdvertisedCount(); got != workers*perWorker {
+		t.Errorf("advertised count = %d, want %d", got, workers*perWorker)
+	}
+}
@@ -0,0 +1,74 @@
+package retriever
+
+import "sort"
+
+// ChunkOrderStrategy decides the order in which a manifest's distinct
+// chunks are requested from the source. It only shapes the network access
+// pattern — reassembly always follows manifest order regardless of what
+// the strategy returns.
+type ChunkOrderStrategy interface {
+	// OrderChunks returns the distinct IDs from chunkIDs (which may repeat)
+	// in the order they should be fetched, each exactly once.
+	OrderChunks(chunkIDs []string) []string
+}
+
+// InOrder fetches chunks in manifest order, the default strategy.
+type InOrder struct{}
+
+// OrderChunks implements ChunkOrderStrategy.
+func (InOrder) OrderChunks(chunkIDs []string) []string {
+	return distinctChunkIDs(chunkIDs)
+}
+
+// ProviderCounts reports how many providers claim to hold a chunk;
+// RarestFirst consumes it. The service layer adapts its peer discovery to
+// this shape, and tests supply counts directly.
+type ProviderCounts interface {
+	ProviderCount(chunkID string) int
+}
+
+// ProviderCountFunc adapts a plain function to ProviderCounts.
+type ProviderCountFunc func(chunkID string) int
+
+// ProviderCount implements ProviderCounts.
+func (f ProviderCountFunc) ProviderCount(chunkID string) int {
+	return f(chunkID)
+}
+
+// RarestFirst fetches the least-replicated chunks first, so a retrieval
+// swarm spends its early requests on the chunks most at risk of
+// disappearing. Ties keep manifest order.
+type RarestFirst struct {
+	Counts ProviderCounts
+}
+
+// OrderChunks implements ChunkOrderStrategy.
+func (r RarestFirst) OrderChunks(chunkIDs []string) []string {
+	ids := distinctChunkIDs(chunkIDs)
+	if r.Counts == nil {
+		return ids
+	}
+	counts := make(map[string]int, len(ids))
+	for _, id := range ids {
+		counts[id] = r.Counts.ProviderCount(id)
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return counts[ids[i]] < counts[ids[j]]
+	})
+	return ids
+}
+
+// distinctChunkIDs returns chunkIDs with repeats removed, first occurrence
+// winning, so strategies never request the same chunk twice.
+func distinctChunkIDs(chunkIDs []string) []string {
+	seen := make(map[string]bool, len(chunkIDs))
+	ids := make([]string, 0, len(chunkIDs))
+	for _, id := range chunkIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
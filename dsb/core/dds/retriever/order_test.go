@@ -0,0 +1,91 @@
+package retriever_test
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// chunkCounter counts chunk fetches so tests can assert a rejection
+// happened before any chunk download.
+type chunkCounter struct {
+	retriever.Retriever
+	fetches atomic.Int64
+}
+
+func (c *chunkCounter) FetchChunk(cid string) (*chunking.Chunk, error) {
+	c.fetches.Add(1)
+	return c.Retriever.FetchChunk(cid)
+}
+
+// reorderedManifest seeds mock with two-chunk content and returns a
+// manifest whose chunk order was swapped without re-deriving sequence
+// numbers, as a misbehaving peer would serve it.
+func reorderedManifest(t *testing.T, mock *retriever.MockRetriever) string {
+	t.Helper()
+	content := make([]byte, 1024)
+	rand.New(rand.NewSource(5)).Read(content)
+	cid := seedMockContent(t, mock, content, len(content)/2)
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	reordered := *manifest
+	reordered.ChunkIDs = []string{manifest.ChunkIDs[1], manifest.ChunkIDs[0]}
+	mock.AddManifest(&reordered)
+	return cid
+}
+
+func TestRetrieveContent_RejectsReorderedManifestBeforeFetching(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := reorderedManifest(t, mock)
+	counter := &chunkCounter{Retriever: mock}
+
+	cr := retriever.NewContentRetriever(counter)
+	_, err := cr.RetrieveContent(cid)
+	if err == nil {
+		t.Fatal("reordered manifest was not rejected")
+	}
+	if !errors.Is(err, chunking.ErrManifestOrderInvalid) {
+		t.Errorf("error is not ErrManifestOrderInvalid: %v", err)
+	}
+	if n := counter.fetches.Load(); n != 0 {
+		t.Errorf("rejection fetched %d chunks, want 0", n)
+	}
+}
+
+func TestRetrieveContentTo_RejectsReorderedManifestBeforeFetching(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := reorderedManifest(t, mock)
+	counter := &chunkCounter{Retriever: mock}
+
+	cr := retriever.NewContentRetriever(counter)
+	err := cr.RetrieveContentTo(&bytes.Buffer{}, cid)
+	if err == nil {
+		t.Fatal("reordered manifest was not rejected")
+	}
+	if !errors.Is(err, chunking.ErrManifestOrderInvalid) {
+		t.Errorf("error is not ErrManifestOrderInvalid: %v", err)
+	}
+	if n := counter.fetches.Load(); n != 0 {
+		t.Errorf("rejection fetched %d chunks, want 0", n)
+	}
+}
+
+func TestValidateOrder_AcceptsGeneratedManifest(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	content := bytes.Repeat([]byte("well ordered "), 32)
+	cid := seedMockContent(t, mock, content, 64)
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	if err := manifest.ValidateOrder(); err != nil {
+		t.Errorf("generated manifest failed order validation: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package retriever_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// reorderManifest returns a copy of the manifest with its first two chunks
+// swapped and the sequence numbers rewritten to look legitimate, modeling
+// a tampered chunk list that no per-chunk digest check can catch.
+func reorderManifest(t *testing.T, manifest *chunking.Manifest) *chunking.Manifest {
+	t.Helper()
+	if len(manifest.ChunkIDs) < 2 {
+		t.Fatal("fixture needs at least two chunks to reorder")
+	}
+	swapped := *manifest
+	swapped.ChunkIDs = append([]string{}, manifest.ChunkIDs...)
+	swapped.ChunkIDs[0], swapped.ChunkIDs[1] = swapped.ChunkIDs[1], swapped.ChunkIDs[0]
+	swapped.ChunkRefs = make([]chunking.ChunkRef, len(swapped.ChunkIDs))
+	for i, id := range swapped.ChunkIDs {
+		swapped.ChunkRefs[i] = chunking.ChunkRef{ID: id, Seq: i}
+	}
+	return &swapped
+}
+
+func TestRetrieveContent_ReorderedChunkListPinpointed(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("abcdefghABCDEFGH01234567"), 8)
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	mock.AddManifest(reorderManifest(t, manifest))
+
+	_, err = retriever.NewContentRetriever(mock).RetrieveContent(cid)
+	if err == nil {
+		t.Fatal("reordered manifest was accepted")
+	}
+	if !errors.Is(err, chunking.ErrManifestOrderInvalid) {
+		t.Fatalf("reordering not reported as an order problem: %v", err)
+	}
+	if !strings.Contains(err.Error(), "reordered") {
+		t.Errorf("error does not mention reordering: %v", err)
+	}
+}
+
+func TestRetrieveContent_CorruptChunkPinpointed(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("abcdefghABCDEFGH01234567"), 8)
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	mock.AddChunk(&chunking.Chunk{ID: manifest.ChunkIDs[1], Data: []byte("corrupt!")})
+
+	_, err = retriever.NewContentRetriever(mock).RetrieveContent(cid)
+	if err == nil {
+		t.Fatal("corrupt chunk was accepted")
+	}
+	if errors.Is(err, chunking.ErrManifestOrderInvalid) {
+		t.Fatalf("corruption misreported as a reordering: %v", err)
+	}
+	if !strings.Contains(err.Error(), manifest.ChunkIDs[1]) {
+		t.Errorf("error does not name the corrupt chunk: %v", err)
+	}
+}
+
+// With per-chunk verification off (the trusted-local-store mode), a
+// corrupt chunk only surfaces at the whole-content digest; the diagnosis
+// must still name the chunk rather than blaming the chunk list.
+func TestRetrieveContent_CorruptChunkPinpointedWithoutPerChunkChecks(t *testing.T) {
+	mock := retriever.NewMockRetriever()
+	cid := seedMockContent(t, mock, []byte("abcdefghABCDEFGH01234567"), 8)
+	manifest, err := mock.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	mock.AddChunk(&chunking.Chunk{ID: manifest.ChunkIDs[2], Data: []byte("corrupt!")})
+
+	r := retriever.NewContentRetriever(mock)
+	r.SetVerifyChunks(false)
+	_, err = r.RetrieveContent(cid)
+	if err == nil {
+		t.Fatal("corrupt chunk was accepted")
+	}
+	if errors.Is(err, chunking.ErrManifestOrderInvalid) {
+		t.Fatalf("corruption misreported as a reordering: %v", err)
+	}
+	if !strings.Contains(err.Error(), "chunk 2") || !strings.Contains(err.Error(), "corrupt") {
+		t.Errorf("diagnosis does not pinpoint chunk 2 as corrupt: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+// Package retriever covers the consuming side of the DDS: fetching
+// manifests and chunks from a content source and reassembling them into the
+// original bytes, verifying integrity along the way.
+package retriever
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// Retriever is a source of manifests and chunks, local or remote.
+type Retriever interface {
+	FetchManifest(manifestCID string) (*chunking.Manifest, error)
+	FetchChunk(chunkID string) (*chunking.Chunk, error)
+}
+
+// MockRetriever serves manifests and chunks from in-memory maps, for tests
+// and demos. It is safe for concurrent use.
+type MockRetriever struct {
+	mu sync.RWMutex
+	// KnownManifests and KnownChunks are exported for legacy callers;
+	// prefer AddManifest/AddChunk and the fetch methods, which lock.
+	KnownManifests map[string]*chunking.Manifest
+	KnownChunks    map[string]*chunking.Chunk
+}
+
+// NewMockRetriever returns a retriever with no known content.
+func NewMockRetriever() *MockRetriever {
+	return &MockRetriever{
+		KnownManifests: make(map[string]*chunking.Manifest),
+		KnownChunks:    make(map[string]*chunking.Chunk),
+	}
+}
+
+// AddManifest makes a manifest available for fetching.
+func (r *MockRetriever) AddManifest(manifest *chunking.Manifest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.KnownManifests[manifest.ID] = manifest
+}
+
+// AddChunk makes a chunk available for fetching.
+func (r *MockRetriever) AddChunk(chunk *chunking.Chunk) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.KnownChunks[chunk.ID] = chunk
+}
+
+// FetchManifest returns the manifest if known.
+func (r *MockRetriever) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	manifest, ok := r.KnownManifests[manifestCID]
+	if !ok {
+		return nil, fmt.Errorf("retriever: manifest %s not known", manifestCID)
+	}
+	return manifest, nil
+}
+
+// FetchChunk returns the chunk if known.
+func (r *MockRetriever) FetchChunk(chunkID string) (*chunking.Chunk, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	chunk, ok := r.KnownChunks[chunkID]
+	if !ok {
+		return nil, fmt.Errorf("retriever: chunk %s not known", chunkID)
+	}
+	return chunk, nil
+}
@@ -0,0 +1,82 @@
+// Package retrievertest provides a conformance suite every
+// retriever.Retriever implementation must pass, mirroring storagetest for
+// the storage side. The factory seeds an implementation with prepared
+// content and returns it ready to serve.
+package retrievertest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// Factory returns a retriever serving exactly the given manifest and its
+// chunks. A fresh instance is requested for each subtest.
+type Factory func(t *testing.T, manifest *chunking.Manifest, chunks []chunking.Chunk) retriever.Retriever
+
+// RunRetrieverConformance exercises the Retriever contract.
+func RunRetrieverConformance(t *testing.T, factory Factory) {
+	content := []byte("retriever conformance content, long enough for several chunks")
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+
+	t.Run("FetchManifest", func(t *testing.T) {
+		r := factory(t, manifest, chunks)
+		got, err := r.FetchManifest(manifest.ID)
+		if err != nil {
+			t.Fatalf("FetchManifest: %v", err)
+		}
+		if got.ID != manifest.ID || got.ContentID != manifest.ContentID {
+			t.Errorf("fetched manifest %+v differs from seeded %+v", got, manifest)
+		}
+		if len(got.ChunkIDs) != len(manifest.ChunkIDs) {
+			t.Errorf("fetched manifest lists %d chunks, want %d", len(got.ChunkIDs), len(manifest.ChunkIDs))
+		}
+	})
+
+	t.Run("FetchChunk", func(t *testing.T) {
+		r := factory(t, manifest, chunks)
+		for i := range chunks {
+			got, err := r.FetchChunk(chunks[i].ID)
+			if err != nil {
+				t.Fatalf("FetchChunk(%s): %v", chunks[i].ID, err)
+			}
+			if !bytes.Equal(got.Data, chunks[i].Data) {
+				t.Errorf("chunk %s data differs from seeded data", chunks[i].ID)
+			}
+		}
+	})
+
+	t.Run("MissingContentErrors", func(t *testing.T) {
+		r := factory(t, manifest, chunks)
+		if _, err := r.FetchManifest("cid-no-such-manifest"); err == nil {
+			t.Error("FetchManifest(missing) returned nil error")
+		}
+		if _, err := r.FetchChunk("cid-no-such-chunk"); err == nil {
+			t.Error("FetchChunk(missing) returned nil error")
+		}
+	})
+
+	t.Run("FullReassembly", func(t *testing.T) {
+		r := factory(t, manifest, chunks)
+		got, err := retriever.NewContentRetriever(r).RetrieveContent(manifest.ID)
+		if err != nil {
+			t.Fatalf("RetrieveContent: %v", err)
+		}
+		if !bytes.Equal(got, content) {
+			t.Error("reassembled content differs from original")
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package retriever
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// SetPrefetchWindow sets how many chunks RetrieveContentTo may hold in
+// memory at once: chunks are fetched up to window ahead of the write
+// position and written strictly in manifest order. Values below 1 are
+// treated as 1 (fully sequential).
+func (r *ContentRetriever) SetPrefetchWindow(window int) {
+	if window < 1 {
+		window = 1
+	}
+	r.prefetchWindow = window
+}
+
+// RetrieveContentTo streams the content identified by manifestCID to w in
+// manifest order. Unlike RetrieveContent it never materializes the whole
+// content: at most the configured prefetch window of chunks is buffered,
+// so memory stays bounded regardless of content size. The overall content
+// digest is verified incrementally; a mismatch is reported after the
+// bytes have been written, so callers writing to durable destinations
+// should stage the output until RetrieveContentTo returns nil.
+func (r *ContentRetriever) RetrieveContentTo(w io.Writer, manifestCID string) error {
+	manifest, err := r.source.FetchManifest(manifestCID)
+	if err != nil {
+		return fmt.Errorf("retrieve: failed to fetch manifest %s: %w", manifestCID, err)
+	}
+	if manifest.TotalSize == 0 && len(manifest.ChunkIDs) == 0 {
+		return nil
+	}
+	if err := detectManifestCycle(map[string]bool{}, manifestCID, manifest); err != nil {
+		return err
+	}
+	if err := manifest.ValidateOrder(); err != nil {
+		return fmt.Errorf("retrieve: %w", err)
+	}
+	key, err := manifest.ResolveSwarmKey(r.keys)
+	if err != nil {
+		return fmt.Errorf("retrieve: %w", err)
+	}
+
+	type fetchResult struct {
+		chunk *chunking.Chunk
+		err   error
+	}
+	// Each chunk's fetch is handed a single-use channel; reading the
+	// channels in manifest order restores ordering while the semaphore
+	// bounds how many fetched chunks exist at once.
+	window := r.prefetchWindow
+	if window < 1 {
+		window = 1
+	}
+	sem := make(chan struct{}, window)
+	quit := make(chan struct{})
+	var quitOnce sync.Once
+	cancel := func() { quitOnce.Do(func() { close(quit) }) }
+	futures := make([]chan fetchResult, len(manifest.ChunkIDs))
+	for i := range futures {
+		futures[i] = make(chan fetchResult, 1)
+	}
+	// The dispatcher claims window slots in manifest order before spawning
+	// each fetch, so the chunks in flight are always the next ones the
+	// writer needs — out-of-order slot grabs would deadlock the in-order
+	// writer.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i, chunkID := range manifest.ChunkIDs {
+			select {
+			case sem <- struct{}{}:
+			case <-quit:
+				return
+			}
+			wg.Add(1)
+			go func(i int, chunkID string) {
+				defer wg.Done()
+				chunk, err := r.source.FetchChunk(chunkID)
+				if err != nil {
+					err = fmt.Errorf("retrieve: failed to fetch chunk %s: %w", chunkID, err)
+				} else if r.verifyChunks && !manifest.VerifyChunk(chunk.Data, chunkID, key) {
+					err = fmt.Errorf("retrieve: chunk %s failed integrity check (got digest %s)",
+						chunkID, manifest.ContentDigest(chunk.Data, key))
+				}
+				futures[i] <- fetchResult{chunk: chunk, err: err}
+			}(i, chunkID)
+		}
+	}()
+	// On any exit, stop goroutines still waiting for a window slot and let
+	// in-flight fetches finish before returning.
+	defer wg.Wait()
+	defer cancel()
+
+	contentHash, release := contentHasher(manifest, key)
+	defer release()
+	var written int64
+	for i := range futures {
+		res := <-futures[i]
+		if res.err != nil {
+			return res.err
+		}
+		if _, err := w.Write(res.chunk.Data); err != nil {
+			return fmt.Errorf("retrieve: failed to write chunk %s: %w", res.chunk.ID, err)
+		}
+		contentHash.Write(res.chunk.Data)
+		written += int64(len(res.chunk.Data))
+		// The chunk's bytes are out of our hands; release its window slot.
+		<-sem
+	}
+
+	if written != manifest.EffectiveStoredSize() {
+		return fmt.Errorf("retrieve: reassembled size %d does not match manifest stored size %d",
+			written, manifest.EffectiveStoredSize())
+	}
+	if got := chunking.SumToHex(contentHash); got != manifest.ContentID {
+		return fmt.Errorf("retrieve: reassembled content failed integrity check (got digest %s, want %s)",
+			got, manifest.ContentID)
+	}
+	return nil
+}
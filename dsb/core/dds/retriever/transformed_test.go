@@ -0,0 +1,125 @@
+package retriever_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// publishCompressed simulates a compressing publisher: the content is
+// gzipped, the compressed bytes are chunked and served by the retriever,
+// and the manifest records both the original and the stored size.
+func publishCompressed(t *testing.T, content []byte) (*retriever.MockRetriever, *chunking.Manifest, int64) {
+	t.Helper()
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(content); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(compressed.Bytes())
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateTransformedManifest(
+		chunking.HashContent(compressed.Bytes()), int64(len(content)), chunks, "text/plain+gzip")
+	if err != nil {
+		t.Fatalf("GenerateTransformedManifest: %v", err)
+	}
+
+	source := retriever.NewMockRetriever()
+	source.AddManifest(manifest)
+	for i := range chunks {
+		source.AddChunk(&chunks[i])
+	}
+	return source, manifest, int64(compressed.Len())
+}
+
+func TestRetrieveContent_CompressedPublishRecordsBothSizes(t *testing.T) {
+	content := []byte(strings.Repeat("compressible content! ", 40))
+	source, manifest, compressedLen := publishCompressed(t, content)
+
+	if manifest.StoredSize != compressedLen {
+		t.Errorf("stored size %d, want compressed length %d", manifest.StoredSize, compressedLen)
+	}
+	if manifest.OriginalSize != int64(len(content)) {
+		t.Errorf("original size %d, want content length %d", manifest.OriginalSize, len(content))
+	}
+	if manifest.TotalSize != compressedLen {
+		t.Errorf("total size %d, want stored bytes %d for legacy readers", manifest.TotalSize, compressedLen)
+	}
+
+	// Reassembly validates against the stored (compressed) size...
+	r := retriever.NewContentRetriever(source)
+	reassembled, err := r.RetrieveContent(manifest.ID)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	// ...and the recovered content, after undoing the transform,
+	// validates against the original size.
+	zr, err := gzip.NewReader(bytes.NewReader(reassembled))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	recovered, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("decompress: %v", err)
+	}
+	if err := manifest.VerifyOriginalSize(recovered); err != nil {
+		t.Errorf("VerifyOriginalSize on recovered content: %v", err)
+	}
+	if !bytes.Equal(recovered, content) {
+		t.Error("recovered content differs from the original")
+	}
+	if err := manifest.VerifyOriginalSize(reassembled); err == nil {
+		t.Error("compressed bytes passed the original-size check")
+	}
+}
+
+func TestRetrieveContent_LegacyManifestFallsBackToTotalSize(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("content from an older node")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	// A manifest written before the original/stored split carries only
+	// TotalSize.
+	manifest.OriginalSize = 0
+	manifest.StoredSize = 0
+
+	source := retriever.NewMockRetriever()
+	source.AddManifest(manifest)
+	for i := range chunks {
+		source.AddChunk(&chunks[i])
+	}
+	got, err := retriever.NewContentRetriever(source).RetrieveContent(manifest.ID)
+	if err != nil {
+		t.Fatalf("RetrieveContent: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("legacy manifest did not reassemble")
+	}
+	if err := manifest.VerifyOriginalSize(got); err != nil {
+		t.Errorf("VerifyOriginalSize fallback: %v", err)
+	}
+}
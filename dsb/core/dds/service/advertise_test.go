@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// failingOriginator rejects every advertisement.
+type failingOriginator struct{}
+
+var errAnnounce = errors.New("tracker unreachable")
+
+func (failingOriginator) AdvertiseContent(manifestCID string) error {
+	return errAnnounce
+}
+
+func TestPublish_AdvertisementFailureIsWarningByDefault(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-adv", "127.0.0.1:9070"),
+		store, chunker, failingOriginator{},
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+
+	cid, err := svc.Publish([]byte("content kept despite advertisement failure"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish with default semantics: %v", err)
+	}
+	if ok, _ := store.HasManifest(cid); !ok {
+		t.Error("published manifest missing from storage")
+	}
+}
+
+func TestPublish_AdvertisementRequiredRollsBack(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-adv-req", "127.0.0.1:9071"),
+		store, chunker, failingOriginator{},
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+		service.WithAdvertisementRequired(true),
+	)
+
+	_, err = svc.Publish([]byte("content that must be advertised"), "text/plain")
+	if !errors.Is(err, errAnnounce) {
+		t.Fatalf("Publish = %v, want the advertisement error", err)
+	}
+	if !strings.Contains(err.Error(), "failed to advertise") {
+		t.Errorf("error does not mention advertisement: %v", err)
+	}
+	usage, uerr := store.Usage()
+	if uerr != nil {
+		t.Fatalf("Usage: %v", uerr)
+	}
+	if usage.ChunkCount != 0 || usage.ManifestCount != 0 {
+		t.Errorf("storage after required-advertisement failure = %+v, want empty", usage)
+	}
+}
@@ -0,0 +1,201 @@
+package service_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// storageBackedNetwork serves manifests and chunks straight out of each
+// peer's own storage, so content one node caches becomes fetchable by the
+// others without reseeding. Peers can be taken offline.
+type storageBackedNetwork struct {
+	mu      sync.Mutex
+	stores  map[string]storage.Storage
+	offline map[string]bool
+}
+
+func newStorageBackedNetwork() *storageBackedNetwork {
+	return &storageBackedNetwork{
+		stores:  make(map[string]storage.Storage),
+		offline: make(map[string]bool),
+	}
+}
+
+func (n *storageBackedNetwork) attach(peerID string, store storage.Storage) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.stores[peerID] = store
+}
+
+func (n *storageBackedNetwork) setOffline(peerID string, down bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.offline[peerID] = down
+}
+
+func (n *storageBackedNetwork) AdvertiseContent(manifestCID string, provider *p2p.Node) error {
+	return nil
+}
+
+func (n *storageBackedNetwork) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for peerID, store := range n.stores {
+		if n.offline[peerID] {
+			continue
+		}
+		if manifest, err := store.GetManifest(manifestCID); err == nil {
+			return manifest, nil
+		}
+	}
+	return nil, fmt.Errorf("manifest %s not held by any online peer", manifestCID)
+}
+
+func (n *storageBackedNetwork) FetchChunkFromPeer(peerID, chunkID string) (*chunking.Chunk, error) {
+	n.mu.Lock()
+	store, ok := n.stores[peerID]
+	down := n.offline[peerID]
+	n.mu.Unlock()
+	if !ok || down {
+		return nil, fmt.Errorf("peer %s is unreachable", peerID)
+	}
+	return store.GetChunk(chunkID)
+}
+
+func (n *storageBackedNetwork) PeerCapabilities(peerID string) (p2p.Capabilities, bool) {
+	return p2p.Capabilities{}, false
+}
+
+// announceFixture wires three nodes onto a shared discovery and
+// storage-backed network. Only node B announces retrieved content.
+type announceFixture struct {
+	network   *storageBackedNetwork
+	discovery *p2p.MockPeerDiscovery
+	svcA      *service.DDSCoreService
+	svcB      *service.DDSCoreService
+	svcC      *service.DDSCoreService
+	nodeB     *p2p.Node
+}
+
+func newAnnounceFixture(t *testing.T, optsB ...service.Option) *announceFixture {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	f := &announceFixture{
+		network:   newStorageBackedNetwork(),
+		discovery: p2p.NewMockPeerDiscovery(),
+	}
+	newNode := func(id string, opts ...service.Option) (*service.DDSCoreService, *p2p.Node) {
+		node := p2p.NewNode(id, "127.0.0.1:0")
+		store := storage.NewInMemoryStorage()
+		f.network.attach(id, store)
+		f.discovery.RegisterNode(node)
+		svc := service.NewDDSCoreService(
+			node, store, chunker, originator.NewMockOriginator(),
+			f.discovery, f.network, opts...,
+		)
+		return svc, node
+	}
+	f.svcA, _ = newNode("node-a")
+	f.svcB, f.nodeB = newNode("node-b", optsB...)
+	f.svcC, _ = newNode("node-c")
+	return f
+}
+
+func TestRetrieve_AnnouncedCacheServesOtherNodes(t *testing.T) {
+	f := newAnnounceFixture(t, service.WithAnnounceRetrieved(true))
+	content := manyChunkContent(16)
+	cid, err := f.svcA.Publish(content, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish on node A: %v", err)
+	}
+
+	got, err := f.svcB.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve on node B: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("node B retrieved wrong content")
+	}
+	if !f.nodeB.HasContent(cid) {
+		t.Fatal("node B did not advertise the content it cached")
+	}
+
+	// With node A gone, node C can only be served by node B's cache.
+	f.network.setOffline("node-a", true)
+	got, err = f.svcC.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve on node C with node A offline: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("node C retrieved wrong content")
+	}
+
+	// Eviction withdraws the advertisement so discovery stops listing B.
+	f.svcB.Unadvertise(cid)
+	providers, err := f.discovery.FindProviders(cid)
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	for _, p := range providers {
+		if p.ID == "node-b" {
+			t.Error("node B still listed as a provider after Unadvertise")
+		}
+	}
+}
+
+func TestRetrieve_PartiallyCachedContentNotAnnounced(t *testing.T) {
+	f := newAnnounceFixture(t, service.WithAnnounceRetrieved(true))
+	content := manyChunkContent(16)
+	cid, err := f.svcA.Publish(content, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish on node A: %v", err)
+	}
+	// Shrink node B's quota to nothing so every cache write is refused.
+	quotaed := storage.NewQuotaStorage(storage.NewInMemoryStorage(), 1, nil)
+	f.network.attach("node-b", quotaed)
+	nodeB := p2p.NewNode("node-b-quota", "127.0.0.1:0")
+	f.discovery.RegisterNode(nodeB)
+	chunker, _ := chunking.NewBasicChunker(8)
+	svcB := service.NewDDSCoreService(
+		nodeB, quotaed, chunker, originator.NewMockOriginator(),
+		f.discovery, f.network,
+		service.WithAnnounceRetrieved(true),
+	)
+
+	got, err := svcB.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve with full quota: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("retrieved wrong content")
+	}
+	if nodeB.HasContent(cid) {
+		t.Error("partially cached content was announced")
+	}
+}
+
+func TestRetrieve_AnnounceDisabledByDefault(t *testing.T) {
+	f := newAnnounceFixture(t)
+	content := manyChunkContent(4)
+	cid, err := f.svcA.Publish(content, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish on node A: %v", err)
+	}
+	if _, err := f.svcB.Retrieve(cid); err != nil {
+		t.Fatalf("Retrieve on node B: %v", err)
+	}
+	if f.nodeB.HasContent(cid) {
+		t.Error("node advertised retrieved content without the option")
+	}
+}
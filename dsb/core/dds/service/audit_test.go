@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// newAuditService publishes n pieces of content and returns the service,
+// its storage, and the published CIDs.
+func newAuditService(t *testing.T, n int) (*service.DDSCoreService, storage.Storage, []string) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-audit", "127.0.0.1:9080"),
+		store, chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	cids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		// Repeat a per-content token so no 16-byte chunk is shared between
+		// contents; a deduplicated chunk would make damage non-local.
+		content := []byte(strings.Repeat(fmt.Sprintf("audited-%d ", i), 8))
+		cid, err := svc.Publish(content, "text/plain")
+		if err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+		cids = append(cids, cid)
+	}
+	return svc, store, cids
+}
+
+func TestAuditLocalContent_HealthyStore(t *testing.T) {
+	svc, _, cids := newAuditService(t, 3)
+
+	report, err := svc.AuditLocalContent()
+	if err != nil {
+		t.Fatalf("AuditLocalContent: %v", err)
+	}
+	if len(report.Unhealthy) != 0 {
+		t.Errorf("healthy store reported unhealthy manifests: %v", report.Unhealthy)
+	}
+	if len(report.Healthy) != len(cids) {
+		t.Errorf("report lists %d healthy manifests, want %d", len(report.Healthy), len(cids))
+	}
+}
+
+func TestAuditLocalContent_MissingChunkSurfacesOnlyAffectedManifest(t *testing.T) {
+	svc, store, cids := newAuditService(t, 3)
+	victim, err := store.GetManifest(cids[1])
+	if err != nil {
+		t.Fatalf("GetManifest: %v", err)
+	}
+	if err := store.DeleteChunk(victim.ChunkIDs[0]); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+
+	report, err := svc.AuditLocalContent()
+	if err != nil {
+		t.Fatalf("AuditLocalContent: %v", err)
+	}
+	if len(report.Unhealthy) != 1 {
+		t.Fatalf("report lists %d unhealthy manifests, want exactly 1: %v", len(report.Unhealthy), report.Unhealthy)
+	}
+	auditErr, ok := report.Unhealthy[cids[1]]
+	if !ok {
+		t.Fatalf("unhealthy set %v does not contain the damaged manifest %s", report.Unhealthy, cids[1])
+	}
+	if !strings.Contains(auditErr.Error(), "missing") {
+		t.Errorf("audit error does not mention the missing chunk: %v", auditErr)
+	}
+	if len(report.Healthy) != 2 {
+		t.Errorf("report lists %d healthy manifests, want 2", len(report.Healthy))
+	}
+}
+
+func TestAuditLocalContent_CorruptChunkDetected(t *testing.T) {
+	svc, store, cids := newAuditService(t, 1)
+	manifest, err := store.GetManifest(cids[0])
+	if err != nil {
+		t.Fatalf("GetManifest: %v", err)
+	}
+	// Overwrite a chunk with data that no longer matches its ID.
+	if err := store.StoreChunk(&chunking.Chunk{ID: manifest.ChunkIDs[0], Data: []byte("rotted bits")}); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+
+	report, err := svc.AuditLocalContent()
+	if err != nil {
+		t.Fatalf("AuditLocalContent: %v", err)
+	}
+	auditErr, ok := report.Unhealthy[cids[0]]
+	if !ok {
+		t.Fatalf("corrupt chunk not reported; report = %+v", report)
+	}
+	if !strings.Contains(auditErr.Error(), "integrity") {
+		t.Errorf("audit error does not mention integrity: %v", auditErr)
+	}
+}
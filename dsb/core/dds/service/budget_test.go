@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// countingNetwork serves manifests but fails every chunk fetch, recording
+// which peers were asked.
+type countingNetwork struct {
+	mu       sync.Mutex
+	manifest *chunking.Manifest
+	asked    map[string]bool
+}
+
+func (n *countingNetwork) AdvertiseContent(manifestCID string, provider *p2p.Node) error {
+	return nil
+}
+
+func (n *countingNetwork) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	return n.manifest, nil
+}
+
+func (n *countingNetwork) FetchChunkFromPeer(peerID, chunkID string) (*chunking.Chunk, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.asked == nil {
+		n.asked = make(map[string]bool)
+	}
+	n.asked[peerID] = true
+	return nil, fmt.Errorf("peer %s refuses chunk %s", peerID, chunkID)
+}
+
+func (n *countingNetwork) PeerCapabilities(peerID string) (p2p.Capabilities, bool) {
+	return p2p.Capabilities{}, false
+}
+
+func (n *countingNetwork) peersAsked() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.asked)
+}
+
+func (n *countingNetwork) reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.asked = nil
+}
+
+func budgetFixture(t *testing.T) (*chunking.Manifest, []chunking.Chunk, *p2p.MockPeerDiscovery) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("budgeted content spanning several chunks")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	discovery := p2p.NewMockPeerDiscovery()
+	for i := 0; i < 6; i++ {
+		node := p2p.NewNode(fmt.Sprintf("node-budget-%d", i), "127.0.0.1:0")
+		node.AddAdvertisedContent(manifest.ID)
+		discovery.RegisterNode(node)
+	}
+	return manifest, chunks, discovery
+}
+
+func TestRetrieve_PeerAttemptBudgetHonored(t *testing.T) {
+	manifest, _, discovery := budgetFixture(t)
+	network := &countingNetwork{manifest: manifest}
+	chunker, _ := chunking.NewBasicChunker(8)
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-local", "127.0.0.1:9100"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithMaxPeerAttempts(2),
+	)
+
+	_, err := svc.Retrieve(manifest.ID)
+	if !errors.Is(err, service.ErrRetrievalBudgetExhausted) {
+		t.Fatalf("Retrieve = %v, want ErrRetrievalBudgetExhausted", err)
+	}
+	if asked := network.peersAsked(); asked != 2 {
+		t.Errorf("retrieval asked %d peers, want exactly the budget of 2", asked)
+	}
+}
+
+func TestRetrieve_OverallDeadlineAbortsMidRetrieval(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("slow content whose chunks trickle in one by one!")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	network := p2p.NewMockP2PService()
+	provider := p2p.NewNode("node-slow", "127.0.0.1:0")
+	network.AddPeer(provider)
+	if err := network.SeedPeerContent(provider.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+	network.SetPeerLatency(provider.ID, 50*time.Millisecond)
+	// A batch-capable peer would deliver all chunks in one call; this
+	// scenario needs the chunk-by-chunk path so the deadline can interrupt
+	// between fetches.
+	network.SetPeerHandshake(provider.ID, p2p.Handshake{ProtocolVersion: 1, NodeID: provider.ID})
+	discovery := p2p.NewMockPeerDiscovery()
+	discovery.RegisterNode(provider)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-local", "127.0.0.1:9101"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithRetrievalTimeout(80*time.Millisecond),
+	)
+
+	start := time.Now()
+	_, err = svc.Retrieve(manifest.ID)
+	if !errors.Is(err, service.ErrRetrievalBudgetExhausted) {
+		t.Fatalf("Retrieve = %v, want ErrRetrievalBudgetExhausted", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("budget error does not wrap the deadline: %v", err)
+	}
+	// Six 8-byte chunks at 50ms each would take ~300ms without the
+	// deadline; the abort must come much sooner.
+	if elapsed := time.Since(start); elapsed > 200*time.Millisecond {
+		t.Errorf("retrieval ran %v before aborting, want well under the full fetch time", elapsed)
+	}
+}
@@ -0,0 +1,156 @@
+package service_test
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// slowStorage delays every chunk write, imitating file-backed storage, and
+// records which chunk IDs were stored.
+type slowStorage struct {
+	storage.Storage
+	delay  time.Duration
+	mu     sync.Mutex
+	stored map[string]bool
+}
+
+func (s *slowStorage) StoreChunk(chunk *chunking.Chunk) error {
+	time.Sleep(s.delay)
+	if err := s.Storage.StoreChunk(chunk); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.stored == nil {
+		s.stored = make(map[string]bool)
+	}
+	s.stored[chunk.ID] = true
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *slowStorage) storedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.stored)
+}
+
+// manyChunkContent builds content whose 8-byte blocks are all distinct, so
+// an 8-byte chunker yields n distinct chunks.
+func manyChunkContent(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "%08d", i)
+	}
+	return buf.Bytes()
+}
+
+// cacheFixture seeds one provider with the given content and returns a
+// service that caches retrieved chunks into store.
+func cacheFixture(tb testing.TB, store storage.Storage, content []byte) (*service.DDSCoreService, string) {
+	tb.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		tb.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		tb.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "application/octet-stream")
+	if err != nil {
+		tb.Fatalf("GenerateManifest: %v", err)
+	}
+	network := p2p.NewMockP2PService()
+	provider := p2p.NewNode("node-cache-provider", "127.0.0.1:0")
+	network.AddPeer(provider)
+	if err := network.SeedPeerContent(provider.ID, manifest, chunks); err != nil {
+		tb.Fatalf("SeedPeerContent: %v", err)
+	}
+	discovery := p2p.NewMockPeerDiscovery()
+	discovery.RegisterNode(provider)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-cache-local", "127.0.0.1:9150"),
+		store, chunker, originator.NewMockOriginator(),
+		discovery, network,
+	)
+	return svc, manifest.ID
+}
+
+func TestRetrieve_AllFetchedChunksCached(t *testing.T) {
+	content := manyChunkContent(32)
+	store := &slowStorage{Storage: storage.NewInMemoryStorage(), delay: 200 * time.Microsecond}
+	svc, cid := cacheFixture(t, store, content)
+
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("retrieved content does not match original")
+	}
+	manifest, err := store.GetManifest(cid)
+	if err != nil {
+		t.Fatalf("manifest was not cached: %v", err)
+	}
+	// The writes race each other; regardless of completion order, every
+	// chunk must land in storage.
+	for _, chunkID := range manifest.ChunkIDs {
+		ok, err := store.HasChunk(chunkID)
+		if err != nil {
+			t.Fatalf("HasChunk(%s): %v", chunkID, err)
+		}
+		if !ok {
+			t.Errorf("chunk %s missing from the cache", chunkID)
+		}
+	}
+	if n := store.storedCount(); n != len(manifest.ChunkIDs) {
+		t.Errorf("stored %d chunks, want %d", n, len(manifest.ChunkIDs))
+	}
+}
+
+// BenchmarkPeerChunkCaching compares the old serial write-back loop against
+// the bounded-parallel caching a network retrieval now performs, with the
+// same simulated per-write latency.
+func BenchmarkPeerChunkCaching(b *testing.B) {
+	const writeDelay = 200 * time.Microsecond
+	content := manyChunkContent(64)
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		b.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		b.Fatalf("ChunkContent: %v", err)
+	}
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			store := &slowStorage{Storage: storage.NewInMemoryStorage(), delay: writeDelay}
+			for j := range chunks {
+				if err := store.StoreChunk(&chunks[j]); err != nil {
+					b.Fatalf("StoreChunk: %v", err)
+				}
+			}
+		}
+	})
+
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			store := &slowStorage{Storage: storage.NewInMemoryStorage(), delay: writeDelay}
+			svc, cid := cacheFixture(b, store, content)
+			if _, err := svc.Retrieve(cid); err != nil {
+				b.Fatalf("Retrieve: %v", err)
+			}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package service_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// batchCountingNetwork wraps the mock network, counting batch requests so
+// tests can tell which request shape the retrieval path chose.
+type batchCountingNetwork struct {
+	*p2p.MockP2PService
+	batchCalls atomic.Int64
+	failBatch  bool
+}
+
+func (n *batchCountingNetwork) FetchChunksFromPeer(peerID string, chunkIDs []string) ([]*chunking.Chunk, error) {
+	n.batchCalls.Add(1)
+	if n.failBatch {
+		return nil, fmt.Errorf("%w: batch refused despite handshake", p2p.ErrUnsupportedFeature)
+	}
+	return n.MockP2PService.FetchChunksFromPeer(peerID, chunkIDs)
+}
+
+// capsFixture seeds one provider with multi-chunk content and returns a
+// service retrieving through the counting network.
+func capsFixture(t *testing.T, network *batchCountingNetwork, peerID string) (*service.DDSCoreService, []byte, string) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("capability negotiated content spanning chunks")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	discovery := p2p.NewMockPeerDiscovery()
+	provider := p2p.NewNode(peerID, "127.0.0.1:0")
+	network.AddPeer(provider)
+	if err := network.SeedPeerContent(provider.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+	discovery.RegisterNode(provider)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-negotiator", "127.0.0.1:9120"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+	)
+	return svc, content, manifest.ID
+}
+
+func TestRetrieve_UsesBatchRequestForModernPeer(t *testing.T) {
+	network := &batchCountingNetwork{MockP2PService: p2p.NewMockP2PService()}
+	svc, content, cid := capsFixture(t, network, "node-modern")
+
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("retrieved content does not match original")
+	}
+	if n := network.batchCalls.Load(); n != 1 {
+		t.Errorf("batch requests = %d, want 1", n)
+	}
+}
+
+func TestRetrieve_FallsBackToSingleChunksForV1Peer(t *testing.T) {
+	network := &batchCountingNetwork{MockP2PService: p2p.NewMockP2PService()}
+	svc, content, cid := capsFixture(t, network, "node-v1")
+	network.SetPeerHandshake("node-v1", p2p.Handshake{ProtocolVersion: 1, NodeID: "node-v1"})
+
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve from v1 peer: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("retrieved content does not match original")
+	}
+	if n := network.batchCalls.Load(); n != 0 {
+		t.Errorf("batch requests to a v1 peer = %d, want 0", n)
+	}
+}
+
+// A peer whose handshake overstates its abilities still gets served: the
+// batch failure downgrades to single-chunk requests instead of failing the
+// retrieval.
+func TestRetrieve_DowngradesWhenBatchRefused(t *testing.T) {
+	network := &batchCountingNetwork{MockP2PService: p2p.NewMockP2PService(), failBatch: true}
+	svc, content, cid := capsFixture(t, network, "node-liar")
+
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve with refused batch: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("retrieved content does not match original")
+	}
+	if n := network.batchCalls.Load(); n != 1 {
+		t.Errorf("batch requests = %d, want 1", n)
+	}
+}
+
+func TestRetrieve_BatchErrorOtherThanUnsupportedFails(t *testing.T) {
+	// Direct check that only ErrUnsupportedFeature triggers the downgrade:
+	// the sentinel must be preserved through the mock's wrapping.
+	network := p2p.NewMockP2PService()
+	network.AddPeer(p2p.NewNode("node-v1", "127.0.0.1:0"))
+	network.SetPeerHandshake("node-v1", p2p.Handshake{ProtocolVersion: 1, NodeID: "node-v1"})
+	_, err := network.FetchChunksFromPeer("node-v1", []string{"chunk"})
+	if !errors.Is(err, p2p.ErrUnsupportedFeature) {
+		t.Errorf("got %v, want ErrUnsupportedFeature", err)
+	}
+}
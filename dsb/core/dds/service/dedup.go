@@ -0,0 +1,72 @@
+package service
+
+import (
+	"fmt"
+)
+
+// DedupReport summarizes how much content-addressed chunk sharing saves on
+// this node: manifests referencing the same chunk store it once.
+type DedupReport struct {
+	// ManifestCount is how many manifests were walked; TotalReferences is
+	// every chunk reference across them, counting repeats; UniqueChunks is
+	// the distinct chunk IDs referenced; SharedChunks is how many of those
+	// are referenced by more than one manifest.
+	ManifestCount   int
+	TotalReferences int
+	UniqueChunks    int
+	SharedChunks    int
+	// StoredBytes is what the referenced chunks actually occupy;
+	// LogicalBytes is what naive per-manifest storage would occupy (every
+	// reference stored separately); SavedBytes is the difference.
+	StoredBytes  int64
+	LogicalBytes int64
+	SavedBytes   int64
+}
+
+// DedupReport walks the locally stored manifests and reports how many
+// chunks they share and the bytes deduplication saves versus storing each
+// manifest's chunks separately. Chunks a manifest references but storage
+// no longer holds contribute nothing to the byte totals; AuditStorage is
+// the tool that surfaces those.
+func (s *DDSCoreService) DedupReport() (DedupReport, error) {
+	report := DedupReport{}
+	manifestCIDs, err := s.storage.ListManifests()
+	if err != nil {
+		return report, fmt.Errorf("dedup: failed to list local manifests: %w", err)
+	}
+	report.ManifestCount = len(manifestCIDs)
+
+	references := make(map[string]int)   // chunk ID -> total references
+	manifestsFor := make(map[string]int) // chunk ID -> distinct referencing manifests
+	for _, cid := range manifestCIDs {
+		manifest, err := s.storage.GetManifest(cid)
+		if err != nil {
+			return report, fmt.Errorf("dedup: failed to read manifest %s: %w", cid, err)
+		}
+		seen := make(map[string]bool, len(manifest.ChunkIDs))
+		for _, chunkID := range manifest.ChunkIDs {
+			report.TotalReferences++
+			references[chunkID]++
+			if !seen[chunkID] {
+				seen[chunkID] = true
+				manifestsFor[chunkID]++
+			}
+		}
+	}
+
+	report.UniqueChunks = len(references)
+	for chunkID, refs := range references {
+		if manifestsFor[chunkID] > 1 {
+			report.SharedChunks++
+		}
+		chunk, err := s.storage.GetChunk(chunkID)
+		if err != nil {
+			continue
+		}
+		size := int64(len(chunk.Data))
+		report.StoredBytes += size
+		report.LogicalBytes += size * int64(refs)
+	}
+	report.SavedBytes = report.LogicalBytes - report.StoredBytes
+	return report, nil
+}
@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func newDedupService(t *testing.T) *service.DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	return service.NewDDSCoreService(
+		p2p.NewNode("node-dedup-test", "127.0.0.1:9043"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+}
+
+func TestDedupReport_SharedChunksAndSavings(t *testing.T) {
+	svc := newDedupService(t)
+	// With 4-byte chunks the two contents share exactly their first chunk.
+	if _, err := svc.Publish([]byte("aaaabbbb"), "text/plain"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, err := svc.Publish([]byte("aaaacccc"), "text/plain"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	report, err := svc.DedupReport()
+	if err != nil {
+		t.Fatalf("DedupReport: %v", err)
+	}
+	if report.ManifestCount != 2 {
+		t.Errorf("manifest count %d, want 2", report.ManifestCount)
+	}
+	if report.TotalReferences != 4 || report.UniqueChunks != 3 {
+		t.Errorf("references %d over %d unique chunks, want 4 over 3",
+			report.TotalReferences, report.UniqueChunks)
+	}
+	if report.SharedChunks != 1 {
+		t.Errorf("shared chunks %d, want 1", report.SharedChunks)
+	}
+	if report.StoredBytes != 12 || report.LogicalBytes != 16 {
+		t.Errorf("stored %d / logical %d bytes, want 12 / 16", report.StoredBytes, report.LogicalBytes)
+	}
+	if report.SavedBytes != 4 {
+		t.Errorf("saved %d bytes, want 4", report.SavedBytes)
+	}
+}
+
+func TestDedupReport_RepeatsWithinOneManifestSave(t *testing.T) {
+	svc := newDedupService(t)
+	// One content repeating the same 4-byte block: three references, one
+	// stored chunk, but nothing shared across manifests.
+	if _, err := svc.Publish([]byte("aaaaaaaaaaaa"), "text/plain"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	report, err := svc.DedupReport()
+	if err != nil {
+		t.Fatalf("DedupReport: %v", err)
+	}
+	if report.TotalReferences != 3 || report.UniqueChunks != 1 {
+		t.Errorf("references %d over %d unique chunks, want 3 over 1",
+			report.TotalReferences, report.UniqueChunks)
+	}
+	if report.SharedChunks != 0 {
+		t.Errorf("shared chunks %d, want 0 for a single manifest", report.SharedChunks)
+	}
+	if report.SavedBytes != 8 {
+		t.Errorf("saved %d bytes, want 8", report.SavedBytes)
+	}
+}
+
+func TestDedupReport_EmptyStorage(t *testing.T) {
+	svc := newDedupService(t)
+	report, err := svc.DedupReport()
+	if err != nil {
+		t.Fatalf("DedupReport: %v", err)
+	}
+	if report != (service.DedupReport{}) {
+		t.Errorf("empty storage report %+v, want zero value", report)
+	}
+}
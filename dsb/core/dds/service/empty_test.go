@@ -0,0 +1,85 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func newEmptyContentService(t *testing.T, id string) *service.DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	return service.NewDDSCoreService(
+		p2p.NewNode(id, "127.0.0.1:9090"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+}
+
+func TestPublish_EmptyContentRoundTrip(t *testing.T) {
+	svc := newEmptyContentService(t, "node-empty")
+
+	cid, err := svc.Publish(nil, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish(empty): %v", err)
+	}
+	content, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve(empty): %v", err)
+	}
+	if len(content) != 0 {
+		t.Errorf("retrieved %d bytes for empty content, want 0", len(content))
+	}
+}
+
+func TestPublish_EmptyContentCIDIsStable(t *testing.T) {
+	first := newEmptyContentService(t, "node-empty-a")
+	second := newEmptyContentService(t, "node-empty-b")
+
+	cidA, err := first.Publish([]byte{}, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	cidB, err := second.Publish([]byte{}, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if cidA != cidB {
+		t.Errorf("empty content CIDs differ across nodes: %s vs %s", cidA, cidB)
+	}
+	// Republishing on the same node short-circuits to the same CID.
+	again, err := first.Publish(nil, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("Publish(again): %v", err)
+	}
+	if again != cidA {
+		t.Errorf("republish CID = %s, want %s", again, cidA)
+	}
+}
+
+func TestContentPublisher_EmptyContent(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := storage.NewInMemoryStorage()
+	pub := originator.NewContentPublisher(chunker, store, originator.NewMockOriginator())
+
+	manifest, err := pub.PublishContent(nil, "application/octet-stream")
+	if err != nil {
+		t.Fatalf("PublishContent(empty): %v", err)
+	}
+	if manifest.TotalSize != 0 || len(manifest.ChunkIDs) != 0 {
+		t.Errorf("manifest = %+v, want zero-chunk manifest", manifest)
+	}
+	if ok, _ := store.HasManifest(manifest.ID); !ok {
+		t.Error("zero-chunk manifest was not stored")
+	}
+}
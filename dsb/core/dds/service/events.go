@@ -0,0 +1,85 @@
+package service
+
+// Event is a notification about content activity on this node. Applications
+// subscribe via DDSCoreService.Subscribe and switch on the concrete type.
+type Event interface {
+	// EventCID returns the manifest CID the event concerns.
+	EventCID() string
+}
+
+// ContentPublished is emitted after content has been chunked and stored
+// locally by Publish.
+type ContentPublished struct {
+	CID string
+}
+
+// EventCID implements Event.
+func (e ContentPublished) EventCID() string { return e.CID }
+
+// ContentRetrieved is emitted after content has been successfully retrieved
+// and verified. Source is SourceLocal or the serving peer's ID.
+type ContentRetrieved struct {
+	CID    string
+	Source string
+}
+
+// EventCID implements Event.
+func (e ContentRetrieved) EventCID() string { return e.CID }
+
+// ContentAdvertised is emitted after a manifest CID has been announced to
+// the network.
+type ContentAdvertised struct {
+	CID string
+}
+
+// EventCID implements Event.
+func (e ContentAdvertised) EventCID() string { return e.CID }
+
+// subscriberBuffer is the per-subscriber channel capacity. A subscriber that
+// falls further behind than this starts losing events rather than blocking
+// the publishing path.
+const subscriberBuffer = 64
+
+// Subscribe registers an event subscriber. The returned cancel function
+// unregisters it and closes the channel. Delivery is non-blocking: events
+// that a full subscriber cannot accept are dropped for that subscriber.
+func (s *DDSCoreService) Subscribe() (<-chan Event, func()) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	id := s.nextID
+	s.nextID++
+	ch := make(chan Event, subscriberBuffer)
+	s.subs[id] = ch
+	cancel := func() {
+		s.subMu.Lock()
+		defer s.subMu.Unlock()
+		if sub, ok := s.subs[id]; ok {
+			delete(s.subs, id)
+			close(sub)
+		}
+	}
+	return ch, cancel
+}
+
+// emit delivers an event to every subscriber without blocking.
+func (s *DDSCoreService) emit(event Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for _, ch := range s.subs {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is full; drop rather than stall publishing.
+		}
+	}
+}
+
+// ContentUnpublished is emitted after locally published content has been
+// taken down: manifest deleted, unshared chunks removed, advertisements
+// withdrawn.
+type ContentUnpublished struct {
+	CID string
+}
+
+// EventCID implements Event.
+func (e ContentUnpublished) EventCID() string { return e.CID }
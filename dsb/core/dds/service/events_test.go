@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// newTestService wires a DDSCoreService against in-memory collaborators.
+func newTestService(t *testing.T) *DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	return NewDDSCoreService(
+		p2p.NewNode("node-test", "127.0.0.1:9000"),
+		storage.NewInMemoryStorage(),
+		chunker,
+		originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(),
+		p2p.NewMockP2PService(),
+	)
+}
+
+func TestSubscribe_PublishEmitsSingleContentPublished(t *testing.T) {
+	svc := newTestService(t)
+	events, cancel := svc.Subscribe()
+	defer cancel()
+
+	cid, err := svc.Publish([]byte("hello, decentralized world"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var published []ContentPublished
+drain:
+	for {
+		select {
+		case ev := <-events:
+			if p, ok := ev.(ContentPublished); ok {
+				published = append(published, p)
+			}
+		default:
+			break drain
+		}
+	}
+	if len(published) != 1 {
+		t.Fatalf("want exactly 1 ContentPublished event, got %d", len(published))
+	}
+	if published[0].CID != cid {
+		t.Errorf("ContentPublished CID = %s, want %s", published[0].CID, cid)
+	}
+}
+
+func TestSubscribe_RetrieveEmitsContentRetrieved(t *testing.T) {
+	svc := newTestService(t)
+	cid, err := svc.Publish([]byte("retrieve me"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	events, cancel := svc.Subscribe()
+	defer cancel()
+	if _, err := svc.Retrieve(cid); err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+
+	select {
+	case ev := <-events:
+		retrieved, ok := ev.(ContentRetrieved)
+		if !ok {
+			t.Fatalf("want ContentRetrieved, got %T", ev)
+		}
+		if retrieved.CID != cid || retrieved.Source != SourceLocal {
+			t.Errorf("got %+v, want CID %s from %s", retrieved, cid, SourceLocal)
+		}
+	default:
+		t.Fatal("no event delivered for local retrieval")
+	}
+}
+
+func TestSubscribe_SlowSubscriberDoesNotBlockPublish(t *testing.T) {
+	svc := newTestService(t)
+	// Never read from this subscription; publishing must still complete.
+	_, cancel := svc.Subscribe()
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		if _, err := svc.Publish([]byte{byte(i), byte(i >> 8), 1}, "application/octet-stream"); err != nil {
+			t.Fatalf("Publish %d: %v", i, err)
+		}
+	}
+}
+
+func TestSubscribe_CancelClosesChannel(t *testing.T) {
+	svc := newTestService(t)
+	events, cancel := svc.Subscribe()
+	cancel()
+	if _, open := <-events; open {
+		t.Fatal("channel still open after cancel")
+	}
+	// A second cancel must be harmless.
+	cancel()
+}
@@ -0,0 +1,31 @@
+package service_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+)
+
+func TestDiscoveryProviderCounts(t *testing.T) {
+	discovery := p2p.NewMockPeerDiscovery()
+	for i := 0; i < 3; i++ {
+		node := p2p.NewNode(fmt.Sprintf("node-%d", i), fmt.Sprintf("127.0.0.1:91%02d", i))
+		node.AddAdvertisedContent("chunk-common")
+		if i == 0 {
+			node.AddAdvertisedContent("chunk-rare")
+		}
+		discovery.RegisterNode(node)
+	}
+	counts := service.DiscoveryProviderCounts{Discovery: discovery}
+	if got := counts.ProviderCount("chunk-common"); got != 3 {
+		t.Errorf("common chunk count %d, want 3", got)
+	}
+	if got := counts.ProviderCount("chunk-rare"); got != 1 {
+		t.Errorf("rare chunk count %d, want 1", got)
+	}
+	if got := counts.ProviderCount("chunk-unknown"); got != 0 {
+		t.Errorf("unknown chunk count %d, want 0", got)
+	}
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// rejectMarker stands in for a malware scanner: it rejects content
+// containing the marker string.
+func rejectMarker(marker string) retriever.VerificationHook {
+	return retriever.VerificationHookFunc(func(_ *chunking.Manifest, content []byte) error {
+		if bytes.Contains(content, []byte(marker)) {
+			return fmt.Errorf("content contains %q", marker)
+		}
+		return nil
+	})
+}
+
+// Content a peer serves that a hook rejects must fail the retrieval and
+// must not be cached into local storage.
+func TestRetrieve_HookRejectionPreventsCaching(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("perfectly well-formed content carrying EVIL-MARKER inside")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	provider := p2p.NewNode("node-flagged-provider", "127.0.0.1:0")
+	network.AddPeer(provider)
+	if err := network.SeedPeerContent(provider.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+	discovery.RegisterNode(provider)
+
+	store := storage.NewInMemoryStorage()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-scanned", "127.0.0.1:9110"),
+		store, chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithVerificationHook("scanner", rejectMarker("EVIL-MARKER")),
+	)
+
+	_, err = svc.Retrieve(manifest.ID)
+	if err == nil {
+		t.Fatal("Retrieve accepted hook-rejected content")
+	}
+	var rejected *retriever.ErrContentRejected
+	if !errors.As(err, &rejected) {
+		t.Fatalf("error is not *retriever.ErrContentRejected: %v", err)
+	}
+	if rejected.Hook != "scanner" {
+		t.Errorf("rejection attributed to %q, want %q", rejected.Hook, "scanner")
+	}
+
+	// Nothing from the rejected retrieval may have been cached.
+	if ok, err := store.HasManifest(manifest.ID); err != nil || ok {
+		t.Errorf("HasManifest(%s) = %v, %v; want false, nil", manifest.ID, ok, err)
+	}
+	for _, chunk := range chunks {
+		if ok, err := store.HasChunk(chunk.ID); err != nil || ok {
+			t.Errorf("HasChunk(%s) = %v, %v; want false, nil", chunk.ID, ok, err)
+		}
+	}
+}
+
+// Hooks also gate the local path, so already-cached content that a newly
+// installed hook flags stops being served.
+func TestRetrieve_HookRejectsLocalContent(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-local-scan", "127.0.0.1:9111"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+		service.WithVerificationHook("scanner", rejectMarker("EVIL-MARKER")),
+	)
+
+	cid, err := svc.Publish([]byte("previously published EVIL-MARKER payload"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	_, err = svc.Retrieve(cid)
+	var rejected *retriever.ErrContentRejected
+	if !errors.As(err, &rejected) {
+		t.Fatalf("local retrieval error is not *retriever.ErrContentRejected: %v", err)
+	}
+
+	clean, err := svc.Publish([]byte("nothing objectionable here"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, err := svc.Retrieve(clean); err != nil {
+		t.Errorf("Retrieve of clean content failed: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// countingStorage counts store operations passing through to the inner
+// storage.
+type countingStorage struct {
+	storage.Storage
+	chunkStores    atomic.Int64
+	manifestStores atomic.Int64
+}
+
+func (c *countingStorage) StoreChunk(chunk *chunking.Chunk) error {
+	c.chunkStores.Add(1)
+	return c.Storage.StoreChunk(chunk)
+}
+
+func (c *countingStorage) StoreManifest(manifest *chunking.Manifest) error {
+	c.manifestStores.Add(1)
+	return c.Storage.StoreManifest(manifest)
+}
+
+func TestPublish_SecondPublishOfIdenticalContentStoresNothing(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := &countingStorage{Storage: storage.NewInMemoryStorage()}
+	orig := originator.NewMockOriginator()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-idem", "127.0.0.1:9100"),
+		store, chunker, orig,
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+
+	content := []byte("publish me twice, store me once")
+	first, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("first Publish: %v", err)
+	}
+	chunkStores := store.chunkStores.Load()
+	manifestStores := store.manifestStores.Load()
+	advertised := orig.Advertisements(first)
+
+	second, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if second != first {
+		t.Fatalf("republish returned CID %s, want %s", second, first)
+	}
+	if got := store.chunkStores.Load(); got != chunkStores {
+		t.Errorf("republish stored %d more chunks", got-chunkStores)
+	}
+	if got := store.manifestStores.Load(); got != manifestStores {
+		t.Errorf("republish stored %d more manifests", got-manifestStores)
+	}
+	if got := orig.Advertisements(first); got != advertised {
+		t.Errorf("republish advertised again without WithReadvertiseOnRepublish: %d -> %d", advertised, got)
+	}
+}
+
+func TestPublish_RepublishReadvertisesWhenEnabled(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	orig := originator.NewMockOriginator()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-idem2", "127.0.0.1:9101"),
+		storage.NewInMemoryStorage(), chunker, orig,
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+		service.WithReadvertiseOnRepublish(true),
+	)
+	content := []byte("announce me again")
+	cid, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("first Publish: %v", err)
+	}
+	if _, err := svc.Publish(content, "text/plain"); err != nil {
+		t.Fatalf("second Publish: %v", err)
+	}
+	if got := orig.Advertisements(cid); got != 2 {
+		t.Errorf("advertised %d times, want 2", got)
+	}
+}
@@ -0,0 +1,77 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func newKeyedService(t *testing.T, swarmKey []byte, store storage.Storage, opts ...service.Option) *service.DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewKeyedChunker(4, swarmKey)
+	if err != nil {
+		t.Fatalf("NewKeyedChunker: %v", err)
+	}
+	return service.NewDDSCoreService(
+		p2p.NewNode("node-keyed-test", "127.0.0.1:9044"),
+		store, chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(), opts...,
+	)
+}
+
+func TestKeyedService_RoundTripWithTheRightKey(t *testing.T) {
+	key := []byte("swarm-secret")
+	svc := newKeyedService(t, key, storage.NewInMemoryStorage(),
+		service.WithSwarmKeys(chunking.NewStaticKeyProvider(key)))
+
+	content := []byte("members only")
+	cid, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+
+	manifest, err := svc.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	if !manifest.Keyed() || manifest.KeyFingerprint != chunking.KeyFingerprint(key) {
+		t.Errorf("manifest fingerprint %q, want the swarm key's", manifest.KeyFingerprint)
+	}
+}
+
+func TestKeyedService_RetrieveFailsTypedWithoutTheKey(t *testing.T) {
+	key := []byte("swarm-secret")
+	store := storage.NewInMemoryStorage()
+	publisher := newKeyedService(t, key, store,
+		service.WithSwarmKeys(chunking.NewStaticKeyProvider(key)))
+	cid, err := publisher.Publish([]byte("members only"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// A node over the same storage but without the key: the manifest names a
+	// fingerprint its provider cannot resolve.
+	outsider := newKeyedService(t, []byte("some-other-key"), store,
+		service.WithSwarmKeys(chunking.NewStaticKeyProvider([]byte("some-other-key"))))
+	if _, err := outsider.Retrieve(cid); !errors.Is(err, chunking.ErrSwarmKeyUnknown) {
+		t.Errorf("retrieval without the key: %v, want ErrSwarmKeyUnknown", err)
+	}
+
+	// No provider at all fails the same way.
+	keyless := newKeyedService(t, key, store)
+	if _, err := keyless.Retrieve(cid); !errors.Is(err, chunking.ErrSwarmKeyUnknown) {
+		t.Errorf("retrieval without a provider: %v, want ErrSwarmKeyUnknown", err)
+	}
+}
@@ -0,0 +1,101 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// negCacheFixture builds a service whose only provider refuses every chunk
+// fetch, so retrievals always fail while the peer-request count is visible.
+func negCacheFixture(t *testing.T, ttl time.Duration, opts ...service.Option) (*service.DDSCoreService, *countingNetwork, string) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("content that is nowhere to be found")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	network := &countingNetwork{manifest: manifest}
+	discovery := p2p.NewMockPeerDiscovery()
+	provider := p2p.NewNode("node-refusing", "127.0.0.1:0")
+	provider.AddAdvertisedContent(manifest.ID)
+	discovery.RegisterNode(provider)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-neg-cache", "127.0.0.1:9140"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		append([]service.Option{service.WithNegativeCacheTTL(ttl)}, opts...)...,
+	)
+	return svc, network, manifest.ID
+}
+
+func TestRetrieve_NegativeCacheShortCircuitsWithinTTL(t *testing.T) {
+	svc, network, cid := negCacheFixture(t, time.Minute)
+
+	if _, err := svc.Retrieve(cid); err == nil {
+		t.Fatal("first retrieval unexpectedly succeeded")
+	}
+	asked := network.peersAsked()
+	if asked == 0 {
+		t.Fatal("first retrieval asked no peers")
+	}
+
+	_, err := svc.Retrieve(cid)
+	if !errors.Is(err, service.ErrContentUnavailable) {
+		t.Fatalf("second retrieval = %v, want ErrContentUnavailable", err)
+	}
+	if network.peersAsked() != asked {
+		t.Error("cached failure still issued peer requests")
+	}
+}
+
+func TestRetrieve_NegativeCacheEntryExpires(t *testing.T) {
+	fake := clock.NewFake(time.Now().UnixNano())
+	svc, network, cid := negCacheFixture(t, 30*time.Millisecond, service.WithClock(fake))
+
+	if _, err := svc.Retrieve(cid); err == nil {
+		t.Fatal("first retrieval unexpectedly succeeded")
+	}
+	fake.Advance(50 * time.Millisecond)
+
+	_, err := svc.Retrieve(cid)
+	if errors.Is(err, service.ErrContentUnavailable) {
+		t.Fatal("expired entry still short-circuited the retrieval")
+	}
+	if network.peersAsked() == 0 {
+		t.Error("fresh attempt after expiry asked no peers")
+	}
+}
+
+func TestRetrieveFresh_BypassesNegativeCache(t *testing.T) {
+	svc, network, cid := negCacheFixture(t, time.Minute)
+
+	if _, err := svc.Retrieve(cid); err == nil {
+		t.Fatal("first retrieval unexpectedly succeeded")
+	}
+	network.reset()
+
+	_, err := svc.RetrieveFresh(cid)
+	if errors.Is(err, service.ErrContentUnavailable) {
+		t.Fatal("RetrieveFresh was short-circuited by the cache")
+	}
+	if network.peersAsked() == 0 {
+		t.Error("RetrieveFresh asked no peers")
+	}
+}
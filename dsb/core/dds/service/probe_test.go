@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestProbeAvailability_ReturnsAllAdvertisingPeers(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("probed but never downloaded")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	for _, id := range []string{"node-p1", "node-p2"} {
+		node := p2p.NewNode(id, "127.0.0.1:0")
+		network.AddPeer(node)
+		if err := network.SeedPeerContent(node.ID, manifest, chunks); err != nil {
+			t.Fatalf("SeedPeerContent: %v", err)
+		}
+		discovery.RegisterNode(node)
+	}
+	// A bystander without the content must not appear.
+	bystander := p2p.NewNode("node-bystander", "127.0.0.1:0")
+	network.AddPeer(bystander)
+	discovery.RegisterNode(bystander)
+
+	localStore := storage.NewInMemoryStorage()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-prober", "127.0.0.1:9060"),
+		localStore, chunker, originator.NewMockOriginator(),
+		discovery, network,
+	)
+	providers, err := svc.ProbeAvailability(manifest.ID)
+	if err != nil {
+		t.Fatalf("ProbeAvailability: %v", err)
+	}
+	if len(providers) != 2 {
+		t.Fatalf("got %d providers, want 2", len(providers))
+	}
+	for _, p := range providers {
+		if p.ID == bystander.ID {
+			t.Errorf("bystander %s reported as provider", p.ID)
+		}
+	}
+	// Probing must not have downloaded anything.
+	usage, err := localStore.Usage()
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.ChunkCount != 0 || usage.ManifestCount != 0 {
+		t.Errorf("probe fetched content into local storage: %+v", usage)
+	}
+}
+
+func TestProbeAvailability_EmptyWhenNoProviders(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-prober2", "127.0.0.1:9061"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	providers, err := svc.ProbeAvailability("cid-nobody-has")
+	if err != nil {
+		t.Fatalf("ProbeAvailability: %v", err)
+	}
+	if len(providers) != 0 {
+		t.Errorf("got %d providers, want 0", len(providers))
+	}
+}
@@ -0,0 +1,41 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestPublish_QuotaHitMidPublishRollsBack(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	inner := storage.NewInMemoryStorage()
+	// Budget fits two 8-byte chunks but not the four the content needs.
+	quota := storage.NewQuotaStorage(inner, 20, nil)
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-quota", "127.0.0.1:9050"),
+		quota, chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+
+	_, err = svc.Publish([]byte("abcdefghijklmnopqrstuvwxyz012345"), "text/plain")
+	if !errors.Is(err, storage.ErrQuotaExceeded) {
+		t.Fatalf("want ErrQuotaExceeded from publish, got %v", err)
+	}
+
+	// Every chunk the failed publish stored must have been rolled back.
+	usage, err := inner.Usage()
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.ChunkCount != 0 || usage.ManifestCount != 0 {
+		t.Errorf("storage after failed publish = %+v, want empty", usage)
+	}
+}
@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// seedContent prepares a manifest and its chunks for seeding into peers.
+func seedContent(t *testing.T, chunker *chunking.BasicChunker, content []byte) (*chunking.Manifest, []chunking.Chunk) {
+	t.Helper()
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	return manifest, chunks
+}
+
+func TestRaceProviders_FastestPeerWins(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("raced across two providers")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	fast := p2p.NewNode("node-fast", "127.0.0.1:9040")
+	slow := p2p.NewNode("node-slow", "127.0.0.1:9041")
+	for _, node := range []*p2p.Node{fast, slow} {
+		network.AddPeer(node)
+		if err := network.SeedPeerContent(node.ID, manifest, chunks); err != nil {
+			t.Fatalf("SeedPeerContent: %v", err)
+		}
+		discovery.RegisterNode(node)
+	}
+	network.SetPeerLatency(slow.ID, 300*time.Millisecond)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-racer", "127.0.0.1:9042"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithParallelPeers(2),
+	)
+	events, cancel := svc.Subscribe()
+	defer cancel()
+
+	start := time.Now()
+	got, err := svc.Retrieve(manifest.ID)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+	if elapsed := time.Since(start); elapsed >= 300*time.Millisecond {
+		t.Errorf("retrieval waited for the slow peer (%v elapsed)", elapsed)
+	}
+	select {
+	case ev := <-events:
+		if retrieved, ok := ev.(service.ContentRetrieved); ok && retrieved.Source != fast.ID {
+			t.Errorf("retrieval source = %s, want the fast peer %s", retrieved.Source, fast.ID)
+		}
+	default:
+		t.Fatal("no retrieval event delivered")
+	}
+}
+
+func TestRaceProviders_CorruptFastPeerSkipped(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("integrity beats speed")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	// The fast peer serves corrupted chunk bytes under the right IDs.
+	corrupt := make([]chunking.Chunk, len(chunks))
+	copy(corrupt, chunks)
+	for i := range corrupt {
+		bad := make([]byte, len(corrupt[i].Data))
+		copy(bad, corrupt[i].Data)
+		bad[0] ^= 0xFF
+		corrupt[i].Data = bad
+	}
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	fastBad := p2p.NewNode("node-fast-corrupt", "127.0.0.1:9043")
+	slowGood := p2p.NewNode("node-slow-honest", "127.0.0.1:9044")
+	network.AddPeer(fastBad)
+	network.AddPeer(slowGood)
+	if err := network.SeedPeerContent(fastBad.ID, manifest, corrupt); err != nil {
+		t.Fatalf("SeedPeerContent(corrupt): %v", err)
+	}
+	if err := network.SeedPeerContent(slowGood.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent(honest): %v", err)
+	}
+	discovery.RegisterNode(fastBad)
+	discovery.RegisterNode(slowGood)
+	network.SetPeerLatency(slowGood.ID, 50*time.Millisecond)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-racer2", "127.0.0.1:9045"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithParallelPeers(2),
+	)
+	got, err := svc.Retrieve(manifest.ID)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+)
+
+// ErrUnderReplicated is returned by EnsureReplication when the requested
+// factor could not be reached: every candidate peer was tried and the
+// provider count is still short.
+var ErrUnderReplicated = errors.New("replicate: replication factor not met")
+
+// EnsureReplication checks how many providers advertise manifestCID and,
+// when fewer than factor, pushes the manifest and chunks to additional
+// peers until the factor is met. The content must be held locally — a node
+// cannot raise the replication of content it does not have — and the local
+// node counts as a provider like any other. Content already at or above
+// the factor is left untouched.
+//
+// Push failures are tolerated as long as enough other peers accept;
+// running out of candidates yields ErrUnderReplicated wrapping the last
+// push error, if any.
+func (s *DDSCoreService) EnsureReplication(ctx context.Context, manifestCID string, factor int) error {
+	if factor < 1 {
+		return fmt.Errorf("replicate: factor must be at least 1, got %d", factor)
+	}
+	providers, err := s.discovery.FindProviders(manifestCID)
+	if err != nil {
+		return fmt.Errorf("replicate: failed to find providers for %s: %w", manifestCID, err)
+	}
+	if len(providers) >= factor {
+		return nil
+	}
+
+	manifest, err := s.storage.GetManifest(manifestCID)
+	if err != nil {
+		return fmt.Errorf("replicate: %s is not held locally: %w", manifestCID, err)
+	}
+	chunks := make([]chunking.Chunk, 0, len(manifest.ChunkIDs))
+	for _, chunkID := range manifest.ChunkIDs {
+		chunk, err := s.storage.GetChunk(chunkID)
+		if err != nil {
+			return fmt.Errorf("replicate: chunk %s of %s missing locally: %w", chunkID, manifestCID, err)
+		}
+		chunks = append(chunks, *chunk)
+	}
+	pusher, ok := s.network.(p2p.ContentPusher)
+	if !ok {
+		return fmt.Errorf("replicate: the P2P service cannot push content")
+	}
+
+	holding := make(map[string]bool, len(providers)+1)
+	holding[s.localNode.ID] = true
+	for _, provider := range providers {
+		holding[provider.ID] = true
+	}
+	candidates, err := s.discovery.DiscoverPeers()
+	if err != nil {
+		return fmt.Errorf("replicate: failed to discover peers: %w", err)
+	}
+
+	have := len(providers)
+	var lastPushErr error
+	for _, peer := range candidates {
+		if have >= factor {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if holding[peer.ID] {
+			continue
+		}
+		if err := pusher.PushContentToPeer(peer.ID, manifest, chunks); err != nil {
+			lastPushErr = err
+			continue
+		}
+		have++
+	}
+	if have >= factor {
+		return nil
+	}
+	if lastPushErr != nil {
+		return fmt.Errorf("%w: %d of %d providers for %s (last push: %v)",
+			ErrUnderReplicated, have, factor, manifestCID, lastPushErr)
+	}
+	return fmt.Errorf("%w: %d of %d providers for %s", ErrUnderReplicated, have, factor, manifestCID)
+}
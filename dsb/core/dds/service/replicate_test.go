@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// replicationFixture is a publisher with three idle peers on the network.
+func replicationFixture(t *testing.T) (*service.DDSCoreService, *p2p.MockPeerDiscovery, *p2p.MockP2PService, string) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	local := p2p.NewNode("node-replicator", "127.0.0.1:9090")
+	discovery.RegisterNode(local)
+	for _, id := range []string{"node-r1", "node-r2", "node-r3"} {
+		peer := p2p.NewNode(id, "127.0.0.1:0")
+		network.AddPeer(peer)
+		discovery.RegisterNode(peer)
+	}
+	svc := service.NewDDSCoreService(
+		local, storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+	)
+	cid, err := svc.Publish([]byte("content that must survive this node"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	return svc, discovery, network, cid
+}
+
+func TestEnsureReplication_PushesUntilFactorMet(t *testing.T) {
+	svc, discovery, network, cid := replicationFixture(t)
+
+	if err := svc.EnsureReplication(context.Background(), cid, 3); err != nil {
+		t.Fatalf("EnsureReplication: %v", err)
+	}
+	providers, err := discovery.FindProviders(cid)
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(providers) != 3 {
+		t.Fatalf("%d providers after replication, want exactly 3", len(providers))
+	}
+	// The pushed peers really hold the content, not just the advertisement.
+	manifest, err := svc.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	for _, provider := range providers {
+		if provider.ID == svc.LocalNode().ID {
+			continue
+		}
+		for _, chunkID := range manifest.ChunkIDs {
+			if _, err := network.FetchChunkFromPeer(provider.ID, chunkID); err != nil {
+				t.Errorf("provider %s cannot serve chunk %s: %v", provider.ID, chunkID, err)
+			}
+		}
+	}
+
+	// Re-running against the met factor pushes nothing further.
+	if err := svc.EnsureReplication(context.Background(), cid, 3); err != nil {
+		t.Fatalf("EnsureReplication re-run: %v", err)
+	}
+	providers, err = discovery.FindProviders(cid)
+	if err != nil {
+		t.Fatalf("FindProviders: %v", err)
+	}
+	if len(providers) != 3 {
+		t.Errorf("%d providers after re-run, want still 3", len(providers))
+	}
+}
+
+func TestEnsureReplication_SufficientContentUntouched(t *testing.T) {
+	svc, _, network, cid := replicationFixture(t)
+
+	// The local node already provides the content; factor 1 is satisfied.
+	if err := svc.EnsureReplication(context.Background(), cid, 1); err != nil {
+		t.Fatalf("EnsureReplication: %v", err)
+	}
+	for _, peer := range network.Peers() {
+		if peer.HasContent(cid) {
+			t.Errorf("peer %s received a push despite the factor being met", peer.ID)
+		}
+	}
+}
+
+func TestEnsureReplication_ReportsUnmetFactor(t *testing.T) {
+	svc, _, _, cid := replicationFixture(t)
+
+	// Only four nodes exist in total.
+	err := svc.EnsureReplication(context.Background(), cid, 10)
+	if !errors.Is(err, service.ErrUnderReplicated) {
+		t.Fatalf("EnsureReplication: %v, want ErrUnderReplicated", err)
+	}
+
+	if err := svc.EnsureReplication(context.Background(), cid, 0); err == nil {
+		t.Error("factor 0 was accepted")
+	}
+	if err := svc.EnsureReplication(context.Background(), "cid-not-held", 2); err == nil {
+		t.Error("replication of content not held locally was accepted")
+	}
+}
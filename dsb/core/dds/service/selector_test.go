@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// recordingSelector reverses the default order and records that it ran.
+type recordingSelector struct {
+	mu     sync.Mutex
+	called bool
+}
+
+func (r *recordingSelector) Select(peers []p2p.Node, cid string) []p2p.Node {
+	r.mu.Lock()
+	r.called = true
+	r.mu.Unlock()
+	out := p2p.ByReputation{}.Select(peers, cid)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+func TestRetrieve_HonorsCustomSelector(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("selector-ordered retrieval")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	for _, id := range []string{"node-s1", "node-s2"} {
+		node := p2p.NewNode(id, "127.0.0.1:0")
+		network.AddPeer(node)
+		if err := network.SeedPeerContent(node.ID, manifest, chunks); err != nil {
+			t.Fatalf("SeedPeerContent: %v", err)
+		}
+		discovery.RegisterNode(node)
+	}
+
+	sel := &recordingSelector{}
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-sel", "127.0.0.1:9070"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithPeerSelector(sel),
+	)
+	got, err := svc.Retrieve(manifest.ID)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("retrieved content does not match original")
+	}
+	sel.mu.Lock()
+	defer sel.mu.Unlock()
+	if !sel.called {
+		t.Errorf("custom selector was never consulted")
+	}
+}
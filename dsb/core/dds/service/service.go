@@ -0,0 +1,1203 @@
+// Package service ties the DDS layers together. DDSCoreService is the
+// facade applications use: it publishes content through local storage and
+// the originator, and retrieves content from local storage first, falling
+// back to peers found through discovery.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// SourceLocal is the retrieval source reported when content was served from
+// the node's own storage.
+const SourceLocal = "local"
+
+// DefaultMaxProviders is how many discovered providers a retrieval
+// considers unless WithMaxProviders overrides it.
+const DefaultMaxProviders = 8
+
+// DDSCoreService is the application-facing entry point to the DDS.
+type DDSCoreService struct {
+	localNode  *p2p.Node
+	storage    storage.Storage
+	chunker    *chunking.BasicChunker
+	originator originator.Originator
+	discovery  p2p.PeerDiscovery
+	network    p2p.P2PService
+
+	// fallbacks are tried in order after both local storage and P2P
+	// retrieval fail, e.g. an HTTP gateway holding seeded content.
+	fallbacks []retriever.Retriever
+
+	// parallelPeers is how many providers are raced concurrently during
+	// network retrieval. At most 1 means sequential peer-by-peer attempts.
+	parallelPeers int
+
+	// selector orders discovered providers before retrieval attempts.
+	selector p2p.PeerSelector
+
+	// maxProviders caps how many discovered providers a single retrieval
+	// will consider. Zero or less means no cap.
+	maxProviders int
+
+	// maxPeerAttempts bounds how many peers one retrieval actually tries
+	// before giving up with ErrRetrievalBudgetExhausted. Zero or less
+	// means no bound beyond maxProviders.
+	maxPeerAttempts int
+
+	// retrievalTimeout is the overall deadline for one retrieval across
+	// all peer attempts. Zero means no deadline.
+	retrievalTimeout time.Duration
+
+	// negTTL enables the negative cache: CIDs whose retrieval failed
+	// everywhere are remembered for this long and fail fast with
+	// ErrContentUnavailable. Zero disables the cache.
+	negTTL   time.Duration
+	negMu    sync.Mutex
+	negCache map[string]time.Time
+
+	// timeSource overrides the package-wide default clock for this
+	// service's time reads. Nil means the default.
+	timeSource clock.Clock
+
+	// hook, when set, observes the start and end of publish and retrieve
+	// operations. See dds.SpanHook.
+	hook dds.SpanHook
+
+	// verificationHooks inspect fully reassembled content after integrity
+	// checks pass and before it is returned or cached. See
+	// WithVerificationHook.
+	verificationHooks []serviceHook
+
+	// verifyLocalChunks controls per-chunk digest checks when serving from
+	// the node's own storage. Network chunks are always verified.
+	verifyLocalChunks bool
+
+	// swarmKeys resolves the keys needed to verify manifests whose IDs are
+	// HMAC-derived for a private swarm. See WithSwarmKeys.
+	swarmKeys chunking.KeyProvider
+
+	// publisherWallet, when set, signs every published manifest so peers
+	// can verify provenance. See WithPublisherWallet.
+	publisherWallet *identity.Wallet
+
+	// requirePublisherSig makes retrieval reject manifests without a valid
+	// publisher signature. See WithRequirePublisherSignature.
+	requirePublisherSig bool
+
+	// chunkOrder decides which chunks are requested first during network
+	// retrieval. See WithChunkOrder; nil means manifest order.
+	chunkOrder retriever.ChunkOrderStrategy
+
+	// readvertiseOnRepublish makes a republish of already-stored content
+	// re-announce the CID instead of returning silently.
+	readvertiseOnRepublish bool
+
+	// announceRetrieved makes the node advertise content it fully cached
+	// after a network retrieval, so cached copies improve availability.
+	announceRetrieved bool
+
+	// advertisementRequired makes a failed originator advertisement fail
+	// the publish and roll back what it stored, instead of the default
+	// warn-and-continue behavior.
+	advertisementRequired bool
+
+	subMu  sync.Mutex
+	subs   map[int]chan Event
+	nextID int
+}
+
+// Option configures optional behavior on a DDSCoreService.
+type Option func(*DDSCoreService)
+
+// WithParallelPeers makes network retrieval race up to k providers
+// concurrently and take the fastest valid response, abandoning slower
+// peers.
+func WithParallelPeers(k int) Option {
+	return func(s *DDSCoreService) {
+		s.parallelPeers = k
+	}
+}
+
+// WithReadvertiseOnRepublish makes Publish re-announce content that is
+// already stored locally. By default a republish returns the existing CID
+// without another advertisement round.
+func WithReadvertiseOnRepublish(v bool) Option {
+	return func(s *DDSCoreService) {
+		s.readvertiseOnRepublish = v
+	}
+}
+
+// WithAdvertisementRequired makes Publish treat an originator advertisement
+// failure as fatal: the chunks and manifest stored by that publish are rolled
+// back and the error is returned. By default advertisement failures are
+// logged as warnings because the content is already durably stored; see
+// originator.PublishOptions for the same choice at the publisher level.
+func WithAdvertisementRequired(v bool) Option {
+	return func(s *DDSCoreService) {
+		s.advertisementRequired = v
+	}
+}
+
+// WithVerifyLocalChunks toggles per-chunk digest verification on the local
+// retrieval path. The default is true; disabling it is safe only when local
+// storage is trusted (e.g. reading back freshly-written content). Chunks
+// fetched from the network are always verified regardless of this flag.
+func WithVerifyLocalChunks(v bool) Option {
+	return func(s *DDSCoreService) {
+		s.verifyLocalChunks = v
+	}
+}
+
+// WithSwarmKeys injects the swarm keys used to verify content whose IDs
+// are HMAC-derived under a shared secret (see chunking.NewKeyedChunker).
+// Publishing keyed content needs only a keyed chunker; retrieval needs the
+// provider to hold the key the manifest's fingerprint names, and fails
+// with an error wrapping chunking.ErrSwarmKeyUnknown otherwise.
+func WithSwarmKeys(keys chunking.KeyProvider) Option {
+	return func(s *DDSCoreService) {
+		s.swarmKeys = keys
+	}
+}
+
+// WithPublisherWallet makes every publish sign its manifest with the
+// wallet (see chunking.Manifest.SignPublisher), so peers can verify who
+// published the content. Signing does not change the CID — the manifest ID
+// covers only the content — so signed and unsigned publishes of the same
+// bytes deduplicate as before.
+func WithPublisherWallet(w *identity.Wallet) Option {
+	return func(s *DDSCoreService) {
+		s.publisherWallet = w
+	}
+}
+
+// WithRequirePublisherSignature makes every retrieval demand a valid
+// publisher signature on the manifest before any chunk is fetched, failing
+// with an error wrapping chunking.ErrManifestUnsigned or
+// chunking.ErrManifestSignatureInvalid otherwise. The default accepts
+// unsigned manifests.
+func WithRequirePublisherSignature(v bool) Option {
+	return func(s *DDSCoreService) {
+		s.requirePublisherSig = v
+	}
+}
+
+// WithChunkOrder sets the strategy deciding which of a manifest's chunks
+// are requested first during network retrieval — e.g.
+// retriever.RarestFirst backed by DiscoveryProviderCounts, so the
+// least-replicated chunks are secured before common ones. Reassembly
+// always follows manifest order; the default fetches in manifest order
+// too. Batched peer fetches are unaffected, since they request every
+// chunk at once.
+func WithChunkOrder(strategy retriever.ChunkOrderStrategy) Option {
+	return func(s *DDSCoreService) {
+		s.chunkOrder = strategy
+	}
+}
+
+// WithPeerSelector replaces the strategy that orders providers before the
+// retrieval loop tries them. The default is p2p.ByReputation.
+func WithPeerSelector(sel p2p.PeerSelector) Option {
+	return func(s *DDSCoreService) {
+		s.selector = sel
+	}
+}
+
+// WithMaxProviders caps how many providers a retrieval considers after
+// selection, so discovery on a large network cannot flood the retrieval
+// loop. The default is DefaultMaxProviders; zero or less removes the cap.
+func WithMaxProviders(n int) Option {
+	return func(s *DDSCoreService) {
+		s.maxProviders = n
+	}
+}
+
+// WithMaxPeerAttempts bounds how many peers a single retrieval tries
+// before failing with ErrRetrievalBudgetExhausted. Zero or less removes
+// the bound (the default).
+func WithMaxPeerAttempts(n int) Option {
+	return func(s *DDSCoreService) {
+		s.maxPeerAttempts = n
+	}
+}
+
+// WithNegativeCacheTTL remembers CIDs whose retrieval failed on every
+// source for the given duration, so repeated lookups of missing content
+// fail fast with ErrContentUnavailable instead of redoing discovery and
+// per-peer requests each time. RetrieveFresh bypasses the cache. Zero
+// disables it (the default).
+func WithNegativeCacheTTL(d time.Duration) Option {
+	return func(s *DDSCoreService) {
+		s.negTTL = d
+	}
+}
+
+// WithAnnounceRetrieved makes a successful network retrieval advertise the
+// CID through the originator and P2P layer once the content is fully
+// cached, turning this node into a provider. Partially cached content —
+// e.g. a chunk write refused by a storage quota — is never announced, and
+// content rejected by verification hooks never reaches the cache at all.
+// Announcement failures are logged, not returned: the caller already has
+// the content. Use Unadvertise when cached content is later evicted.
+func WithAnnounceRetrieved(v bool) Option {
+	return func(s *DDSCoreService) {
+		s.announceRetrieved = v
+	}
+}
+
+// WithClock substitutes the service's time source, letting tests advance
+// time deterministically instead of sleeping. Nil keeps the package-wide
+// default clock.
+func WithClock(c clock.Clock) Option {
+	return func(s *DDSCoreService) {
+		s.timeSource = c
+	}
+}
+
+// WithRetrievalTimeout sets an overall deadline for each retrieval across
+// every peer attempt, so a retrieval cannot hang on slow peers
+// indefinitely. Zero means no deadline (the default).
+func WithRetrievalTimeout(d time.Duration) Option {
+	return func(s *DDSCoreService) {
+		s.retrievalTimeout = d
+	}
+}
+
+// serviceHook pairs a registered verification hook with its name, matching
+// the attribution the retriever layer gives rejections.
+type serviceHook struct {
+	name string
+	hook retriever.VerificationHook
+}
+
+// WithVerificationHook registers a content verification hook (e.g. a
+// malware scan or policy check) run against reassembled content on every
+// retrieval path, after integrity checks pass. A rejection fails the
+// retrieval with a *retriever.ErrContentRejected naming the hook, and
+// content fetched from the network is not cached locally. Hooks run in
+// registration order; the first rejection wins.
+func WithVerificationHook(name string, hook retriever.VerificationHook) Option {
+	return func(s *DDSCoreService) {
+		s.verificationHooks = append(s.verificationHooks, serviceHook{name: name, hook: hook})
+	}
+}
+
+// WithSpanHook installs a hook observing publish and retrieve spans, so
+// an external tracer can follow operations without the DDS depending on
+// it. The context passed to the hook carries the request ID.
+func WithSpanHook(h dds.SpanHook) Option {
+	return func(s *DDSCoreService) {
+		s.hook = h
+	}
+}
+
+// WithFallbackRetriever appends a retriever tried after P2P retrieval
+// fails. Fallbacks are consulted in the order they were added.
+func WithFallbackRetriever(r retriever.Retriever) Option {
+	return func(s *DDSCoreService) {
+		s.fallbacks = append(s.fallbacks, r)
+	}
+}
+
+// NewDDSCoreService wires a service from its collaborators.
+func NewDDSCoreService(node *p2p.Node, store storage.Storage, chunker *chunking.BasicChunker,
+	orig originator.Originator, discovery p2p.PeerDiscovery, network p2p.P2PService, opts ...Option) *DDSCoreService {
+	s := &DDSCoreService{
+		localNode:  node,
+		storage:    store,
+		chunker:    chunker,
+		originator: orig,
+		discovery:  discovery,
+		network:    network,
+		selector:   p2p.ByReputation{},
+		subs:       make(map[int]chan Event),
+
+		maxProviders: DefaultMaxProviders,
+
+		verifyLocalChunks: true,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// LocalNode returns the node this service runs on.
+func (s *DDSCoreService) LocalNode() *p2p.Node {
+	return s.localNode
+}
+
+// Publish chunks and stores content locally, then advertises the manifest
+// CID. Advertisement failures are logged as warnings rather than failing the
+// publish: the content is already durably stored and can be re-advertised.
+// WithAdvertisementRequired inverts that, rolling back the stored content
+// and returning the advertisement error instead.
+//
+// Publish is idempotent: republishing identical content (e.g. a retried
+// request) short-circuits once the manifest is found in storage, returning
+// the existing CID without re-storing anything. Whether a republish
+// re-advertises is controlled by WithReadvertiseOnRepublish.
+func (s *DDSCoreService) Publish(content []byte, contentType string) (string, error) {
+	return s.publish(context.Background(), content, contentType)
+}
+
+// PublishContext is Publish with request tracing: a request ID is taken
+// from ctx (or generated), span hooks fire around the operation, and any
+// error is prefixed with the ID for cross-layer correlation.
+func (s *DDSCoreService) PublishContext(ctx context.Context, content []byte, contentType string) (string, error) {
+	ctx = dds.EnsureRequestID(ctx)
+	done := s.span(ctx, "publish")
+	cid, err := s.publish(ctx, content, contentType)
+	done(err)
+	return cid, err
+}
+
+// PublishResumable is Publish for large uploads whose partial progress
+// should survive a failure: chunks already in storage are skipped, and a
+// mid-publish storage failure leaves the chunks stored so far in place
+// instead of rolling them back. Re-running the same call after a crash
+// therefore resumes where the dead attempt stopped — chunk IDs are
+// content-addressed, so the retry re-derives the same IDs and finds them.
+func (s *DDSCoreService) PublishResumable(content []byte, contentType string) (string, error) {
+	op := dds.Op(context.Background(), "publish")
+	if len(content) == 0 {
+		return s.Publish(content, contentType)
+	}
+	chunks, err := s.chunker.ChunkContent(content)
+	if err != nil {
+		return "", fmt.Errorf(op+": chunking failed: %w", err)
+	}
+	for i := range chunks {
+		exists, err := s.storage.HasChunk(chunks[i].ID)
+		if err != nil {
+			return "", fmt.Errorf(op+": failed to check chunk %s: %w", chunks[i].ID, err)
+		}
+		if exists {
+			continue
+		}
+		if err := s.storage.StoreChunk(&chunks[i]); err != nil {
+			return "", fmt.Errorf(op+": failed to store chunk %s: %w", chunks[i].ID, err)
+		}
+	}
+	manifest, err := s.chunker.GenerateManifest(s.chunker.HashContent(content), chunks, contentType)
+	if err != nil {
+		return "", fmt.Errorf(op+": failed to generate manifest: %w", err)
+	}
+	if err := s.signManifest(manifest); err != nil {
+		return "", fmt.Errorf(op+": %w", err)
+	}
+	if err := s.storage.StoreManifest(manifest); err != nil {
+		return "", fmt.Errorf(op+": failed to store manifest %s: %w", manifest.ID, err)
+	}
+	if err := s.advertise(manifest.ID); err != nil {
+		if s.advertisementRequired {
+			// The manifest is withdrawn but the chunks stay: the next
+			// attempt reuses them and only retries the advertisement.
+			if derr := s.storage.DeleteManifest(manifest.ID); derr != nil {
+				log.Printf("dds: warning: failed to roll back manifest %s: %v", manifest.ID, derr)
+			}
+			return "", fmt.Errorf(op+": %w", err)
+		}
+		log.Printf("dds: warning: %v", err)
+	}
+	s.emit(ContentPublished{CID: manifest.ID})
+	return manifest.ID, nil
+}
+
+func (s *DDSCoreService) publish(ctx context.Context, content []byte, contentType string) (string, error) {
+	op := dds.Op(ctx, "publish")
+	var chunks []chunking.Chunk
+	var manifest *chunking.Manifest
+	if len(content) == 0 {
+		// Zero-byte content publishes as a zero-chunk manifest; the chunk
+		// loop below has nothing to do.
+		manifest = s.chunker.GenerateEmptyManifest(contentType)
+	} else {
+		var err error
+		chunks, err = s.chunker.ChunkContent(content)
+		if err != nil {
+			return "", fmt.Errorf(op+": chunking failed: %w", err)
+		}
+		manifest, err = s.chunker.GenerateManifest(s.chunker.HashContent(content), chunks, contentType)
+		if err != nil {
+			return "", fmt.Errorf(op+": failed to generate manifest: %w", err)
+		}
+	}
+	if err := s.signManifest(manifest); err != nil {
+		return "", fmt.Errorf(op+": %w", err)
+	}
+	exists, err := s.storage.HasManifest(manifest.ID)
+	if err != nil {
+		return "", fmt.Errorf(op+": failed to check manifest %s: %w", manifest.ID, err)
+	}
+	if exists {
+		if s.readvertiseOnRepublish {
+			// Nothing new was stored, so a failed re-advertisement is only
+			// ever a warning regardless of WithAdvertisementRequired.
+			if err := s.advertise(manifest.ID); err != nil {
+				log.Printf("dds: warning: %v", err)
+			}
+		}
+		return manifest.ID, nil
+	}
+	// Track which chunks this publish added so a mid-publish failure
+	// (e.g. a quota hit) can roll back without touching shared chunks
+	// that were already stored.
+	var added []string
+	for i := range chunks {
+		exists, err := s.storage.HasChunk(chunks[i].ID)
+		if err != nil {
+			s.rollbackChunks(added)
+			return "", fmt.Errorf(op+": failed to check chunk %s: %w", chunks[i].ID, err)
+		}
+		if exists {
+			continue
+		}
+		if err := s.storage.StoreChunk(&chunks[i]); err != nil {
+			s.rollbackChunks(added)
+			return "", fmt.Errorf(op+": failed to store chunk %s: %w", chunks[i].ID, err)
+		}
+		added = append(added, chunks[i].ID)
+	}
+	if err := s.storage.StoreManifest(manifest); err != nil {
+		s.rollbackChunks(added)
+		return "", fmt.Errorf(op+": failed to store manifest %s: %w", manifest.ID, err)
+	}
+	if err := s.advertise(manifest.ID); err != nil {
+		if s.advertisementRequired {
+			s.rollbackChunks(added)
+			if derr := s.storage.DeleteManifest(manifest.ID); derr != nil {
+				log.Printf("dds: warning: failed to roll back manifest %s: %v", manifest.ID, derr)
+			}
+			return "", fmt.Errorf(op+": %w", err)
+		}
+		log.Printf("dds: warning: %v", err)
+	}
+	s.emit(ContentPublished{CID: manifest.ID})
+	return manifest.ID, nil
+}
+
+// signManifest signs a freshly generated manifest with the configured
+// publisher wallet, and is a no-op without one.
+func (s *DDSCoreService) signManifest(manifest *chunking.Manifest) error {
+	if s.publisherWallet == nil {
+		return nil
+	}
+	return manifest.SignPublisher(s.publisherWallet)
+}
+
+// checkProvenance enforces WithRequirePublisherSignature on a manifest
+// about to drive a retrieval, and is a no-op when provenance is not
+// required.
+func (s *DDSCoreService) checkProvenance(op string, manifest *chunking.Manifest) error {
+	if !s.requirePublisherSig {
+		return nil
+	}
+	if err := manifest.VerifyPublisher(); err != nil {
+		return fmt.Errorf(op+": %w", err)
+	}
+	return nil
+}
+
+// span fires the configured hook around an operation and returns the
+// function ending the span. With no hook it is a no-op.
+func (s *DDSCoreService) span(ctx context.Context, op string) func(error) {
+	if s.hook == nil {
+		return func(error) {}
+	}
+	s.hook.SpanStart(ctx, op)
+	return func(err error) { s.hook.SpanEnd(ctx, op, err) }
+}
+
+// rollbackChunks deletes chunks a failed publish had stored, so a partial
+// publish does not leak storage.
+func (s *DDSCoreService) rollbackChunks(chunkIDs []string) {
+	for _, id := range chunkIDs {
+		if err := s.storage.DeleteChunk(id); err != nil {
+			log.Printf("dds: warning: failed to roll back chunk %s: %v", id, err)
+		}
+	}
+}
+
+// advertise announces the CID through the originator and the P2P layer.
+// It returns the first failure; the caller decides whether that is fatal
+// (WithAdvertisementRequired) or only worth a warning.
+func (s *DDSCoreService) advertise(manifestCID string) error {
+	if s.originator != nil {
+		if err := s.originator.AdvertiseContent(manifestCID); err != nil {
+			return fmt.Errorf("failed to advertise %s via originator: %w", manifestCID, err)
+		}
+	}
+	if s.localNode != nil {
+		s.localNode.AddAdvertisedContent(manifestCID)
+	}
+	if s.network != nil && s.localNode != nil {
+		if err := s.network.AdvertiseContent(manifestCID, s.localNode); err != nil {
+			return fmt.Errorf("failed to advertise %s to peers: %w", manifestCID, err)
+		}
+	}
+	s.emit(ContentAdvertised{CID: manifestCID})
+	return nil
+}
+
+// Unadvertise withdraws the local node's advertisement for a CID. Eviction
+// callbacks must call it when previously announced content is dropped from
+// storage, so the node stops being discovered as a provider of content it
+// no longer holds.
+func (s *DDSCoreService) Unadvertise(manifestCID string) {
+	if s.localNode != nil {
+		s.localNode.RemoveAdvertisedContent(manifestCID)
+	}
+}
+
+// Unpublish takes locally published content down: the manifest and every
+// chunk not shared with another stored manifest are deleted, the local
+// advertisement is withdrawn, and an originator supporting revocation is
+// told to stop announcing the CID. Chunks referenced by other manifests
+// survive, since deduplicated storage shares them.
+func (s *DDSCoreService) Unpublish(manifestCID string) error {
+	op := dds.Op(context.Background(), "unpublish")
+	manifest, err := s.storage.GetManifest(manifestCID)
+	if err != nil {
+		return fmt.Errorf(op+": cannot unpublish %s: %w", manifestCID, err)
+	}
+	shared, err := s.chunksSharedWithOthers(manifestCID)
+	if err != nil {
+		return fmt.Errorf(op+": %w", err)
+	}
+	for _, chunkID := range manifest.ChunkIDs {
+		if shared[chunkID] {
+			continue
+		}
+		if err := s.storage.DeleteChunk(chunkID); err != nil {
+			return fmt.Errorf(op+": failed to delete chunk %s: %w", chunkID, err)
+		}
+	}
+	if err := s.storage.DeleteManifest(manifestCID); err != nil {
+		return fmt.Errorf(op+": failed to delete manifest %s: %w", manifestCID, err)
+	}
+	s.Unadvertise(manifestCID)
+	if revoker, ok := s.originator.(originator.ContentRevoker); ok {
+		if err := revoker.RevokeContent(manifestCID); err != nil {
+			log.Printf("dds: warning: failed to revoke advertisement for %s: %v", manifestCID, err)
+		}
+	}
+	s.emit(ContentUnpublished{CID: manifestCID})
+	return nil
+}
+
+// chunksSharedWithOthers returns the chunk IDs referenced by any stored
+// manifest other than the one being removed.
+func (s *DDSCoreService) chunksSharedWithOthers(manifestCID string) (map[string]bool, error) {
+	manifestIDs, err := s.storage.ListManifests()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manifests: %w", err)
+	}
+	shared := make(map[string]bool)
+	for _, id := range manifestIDs {
+		if id == manifestCID {
+			continue
+		}
+		other, err := s.storage.GetManifest(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load manifest %s: %w", id, err)
+		}
+		for _, chunkID := range other.ChunkIDs {
+			shared[chunkID] = true
+		}
+	}
+	return shared, nil
+}
+
+// Retrieve returns the content identified by manifestCID, serving from
+// local storage when possible and otherwise fetching from peers advertising
+// the content. Content fetched from the network is cached locally.
+func (s *DDSCoreService) Retrieve(manifestCID string) ([]byte, error) {
+	return s.retrieve(context.Background(), manifestCID)
+}
+
+// RetrieveContext is Retrieve with request tracing; see PublishContext.
+func (s *DDSCoreService) RetrieveContext(ctx context.Context, manifestCID string) ([]byte, error) {
+	ctx = dds.EnsureRequestID(ctx)
+	done := s.span(ctx, "retrieve")
+	content, err := s.retrieve(ctx, manifestCID)
+	done(err)
+	return content, err
+}
+
+// ErrRetrievalBudgetExhausted reports a retrieval abandoned because it hit
+// the configured peer-attempt bound or overall deadline before any peer
+// delivered verified content.
+var ErrRetrievalBudgetExhausted = errors.New("retrieval budget exhausted")
+
+// ErrContentUnavailable reports a CID the negative cache remembers as
+// recently unretrievable; no discovery or peer requests were made. Use
+// RetrieveFresh to force a new attempt within the TTL window.
+var ErrContentUnavailable = errors.New("content recently unavailable")
+
+// RetrieveFresh is Retrieve with the negative cache bypassed: the CID's
+// failure entry (if any) is dropped and a full retrieval attempt is made.
+func (s *DDSCoreService) RetrieveFresh(manifestCID string) ([]byte, error) {
+	s.forgetUnavailable(manifestCID)
+	return s.retrieve(context.Background(), manifestCID)
+}
+
+// recentlyUnavailable reports whether the CID has an unexpired negative
+// cache entry.
+func (s *DDSCoreService) recentlyUnavailable(manifestCID string) bool {
+	if s.negTTL <= 0 {
+		return false
+	}
+	s.negMu.Lock()
+	defer s.negMu.Unlock()
+	expiry, ok := s.negCache[manifestCID]
+	if !ok {
+		return false
+	}
+	if s.now().After(expiry) {
+		delete(s.negCache, manifestCID)
+		return false
+	}
+	return true
+}
+
+// rememberUnavailable records a CID whose retrieval failed on every
+// source.
+func (s *DDSCoreService) rememberUnavailable(manifestCID string) {
+	if s.negTTL <= 0 {
+		return
+	}
+	s.negMu.Lock()
+	defer s.negMu.Unlock()
+	if s.negCache == nil {
+		s.negCache = make(map[string]time.Time)
+	}
+	s.negCache[manifestCID] = s.now().Add(s.negTTL)
+}
+
+// now returns the current time from the service's clock, falling back to
+// the package-wide default.
+func (s *DDSCoreService) now() time.Time {
+	if s.timeSource != nil {
+		return time.Unix(0, s.timeSource.Now())
+	}
+	return clock.NowTime()
+}
+
+// forgetUnavailable drops a CID's negative cache entry.
+func (s *DDSCoreService) forgetUnavailable(manifestCID string) {
+	s.negMu.Lock()
+	defer s.negMu.Unlock()
+	delete(s.negCache, manifestCID)
+}
+
+func (s *DDSCoreService) retrieve(ctx context.Context, manifestCID string) ([]byte, error) {
+	if s.retrievalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.retrievalTimeout)
+		defer cancel()
+	}
+	content, err := s.retrieveLocal(ctx, manifestCID)
+	if err == nil {
+		s.emit(ContentRetrieved{CID: manifestCID, Source: SourceLocal})
+		return content, nil
+	}
+	// A hook rejection is terminal: peers would serve the same bytes, so
+	// falling through to the network could only reject them again.
+	var rejected *retriever.ErrContentRejected
+	if errors.As(err, &rejected) {
+		return nil, err
+	}
+	// So is a missing swarm key: without it no source's chunks can be
+	// verified, however many peers hold them.
+	if errors.Is(err, chunking.ErrSwarmKeyUnknown) {
+		return nil, err
+	}
+	// And so is a provenance failure on the local copy: the manifest is
+	// already at hand and does not carry the required signature.
+	if errors.Is(err, chunking.ErrManifestUnsigned) || errors.Is(err, chunking.ErrManifestSignatureInvalid) {
+		return nil, err
+	}
+	if s.recentlyUnavailable(manifestCID) {
+		return nil, fmt.Errorf(dds.Op(ctx, "retrieve")+": %s: %w", manifestCID, ErrContentUnavailable)
+	}
+	content, source, err := s.retrieveFromNetwork(ctx, manifestCID)
+	if err != nil {
+		content, source, err = s.retrieveFromFallbacks(ctx, manifestCID, err)
+		if err != nil {
+			s.rememberUnavailable(manifestCID)
+			return nil, err
+		}
+	}
+	s.forgetUnavailable(manifestCID)
+	s.emit(ContentRetrieved{CID: manifestCID, Source: source})
+	return content, nil
+}
+
+// SourceFallback is the retrieval source reported when content was served
+// by a configured fallback retriever rather than local storage or a peer.
+const SourceFallback = "fallback"
+
+// retrieveFromFallbacks tries each configured fallback retriever in order,
+// caching the content locally on success. networkErr is the P2P failure
+// that triggered the fallback, preserved if every fallback also fails.
+func (s *DDSCoreService) retrieveFromFallbacks(ctx context.Context, manifestCID string, networkErr error) ([]byte, string, error) {
+	if len(s.fallbacks) == 0 {
+		return nil, "", networkErr
+	}
+	var lastErr error = networkErr
+	for _, fb := range s.fallbacks {
+		r := retriever.NewContentRetriever(fb)
+		for _, h := range s.verificationHooks {
+			r.AddVerificationHook(h.name, h.hook)
+		}
+		content, err := r.RetrieveContent(manifestCID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		s.announceCached(manifestCID, s.cacheRetrieved(manifestCID, fb, content))
+		return content, SourceFallback, nil
+	}
+	return nil, "", fmt.Errorf(dds.Op(ctx, "retrieve")+": all fallback retrievers failed for %s: %w", manifestCID, lastErr)
+}
+
+// cacheRetrieved stores content fetched from a fallback retriever locally,
+// logging (not failing) on storage errors as the caller already has the
+// verified bytes. It reports whether the manifest and all its chunks were
+// cached, which gates announce-on-retrieve.
+func (s *DDSCoreService) cacheRetrieved(manifestCID string, source retriever.Retriever, content []byte) bool {
+	manifest, err := source.FetchManifest(manifestCID)
+	if err != nil {
+		log.Printf("dds: warning: failed to re-fetch manifest %s for caching: %v", manifestCID, err)
+		return false
+	}
+	for _, chunkID := range manifest.ChunkIDs {
+		chunk, err := source.FetchChunk(chunkID)
+		if err != nil {
+			log.Printf("dds: warning: failed to cache chunk %s locally: %v", chunkID, err)
+			return false
+		}
+		if err := s.storage.StoreChunk(chunk); err != nil {
+			log.Printf("dds: warning: failed to cache chunk %s locally: %v", chunkID, err)
+			return false
+		}
+	}
+	if err := s.storage.StoreManifest(manifest); err != nil {
+		log.Printf("dds: warning: failed to cache manifest %s locally: %v", manifestCID, err)
+		return false
+	}
+	return true
+}
+
+// FetchManifest returns the manifest for manifestCID without fetching any
+// chunks, so callers can inspect size and content type before deciding to
+// download. Local storage is consulted first, then the P2P network, then
+// any configured fallback retrievers.
+func (s *DDSCoreService) FetchManifest(manifestCID string) (*chunking.Manifest, error) {
+	if manifest, err := s.storage.GetManifest(manifestCID); err == nil {
+		return manifest, nil
+	}
+	var lastErr error
+	if s.network != nil {
+		manifest, err := s.network.FetchManifest(manifestCID)
+		if err == nil {
+			return manifest, nil
+		}
+		lastErr = err
+	}
+	for _, fb := range s.fallbacks {
+		manifest, err := fb.FetchManifest(manifestCID)
+		if err == nil {
+			return manifest, nil
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("fetch manifest: %s not found locally or remotely: %w", manifestCID, lastErr)
+	}
+	return nil, fmt.Errorf("fetch manifest: %s not found locally and no network configured", manifestCID)
+}
+
+// Pinger is implemented by P2P services that can cheaply check peer
+// liveness.
+type Pinger interface {
+	Ping(peerID string) error
+}
+
+// ProbeAvailability reports which peers claim to hold manifestCID without
+// downloading anything. When the P2P layer supports pinging, unreachable
+// claimants are filtered out. An empty slice means no provider was found.
+func (s *DDSCoreService) ProbeAvailability(manifestCID string) ([]p2p.Node, error) {
+	if s.discovery == nil {
+		return nil, fmt.Errorf("probe: no discovery configured")
+	}
+	candidates, err := s.discovery.FindProviders(manifestCID)
+	if err != nil {
+		return nil, fmt.Errorf("probe: provider discovery for %s failed: %w", manifestCID, err)
+	}
+	pinger, ok := s.network.(Pinger)
+	if !ok {
+		return candidates, nil
+	}
+	providers := make([]p2p.Node, 0, len(candidates))
+	for _, peer := range candidates {
+		if err := pinger.Ping(peer.ID); err != nil {
+			continue
+		}
+		providers = append(providers, peer)
+	}
+	return providers, nil
+}
+
+// retrieveLocal reassembles content entirely from local storage.
+func (s *DDSCoreService) retrieveLocal(ctx context.Context, manifestCID string) ([]byte, error) {
+	manifest, err := s.storage.GetManifest(manifestCID)
+	if err != nil {
+		return nil, err
+	}
+	op := dds.Op(ctx, "retrieve")
+	if err := s.checkProvenance(op, manifest); err != nil {
+		return nil, err
+	}
+	key, err := manifest.ResolveSwarmKey(s.swarmKeys)
+	if err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+	chunks := make([]*chunking.Chunk, len(manifest.ChunkIDs))
+	for i, chunkID := range manifest.ChunkIDs {
+		chunk, err := s.storage.GetChunk(chunkID)
+		if err != nil {
+			return nil, fmt.Errorf(op+": local chunk %s missing: %w", chunkID, err)
+		}
+		if s.verifyLocalChunks && !manifest.VerifyChunk(chunk.Data, chunkID, key) {
+			return nil, fmt.Errorf(op+": local chunk %s failed integrity check", chunkID)
+		}
+		chunks[i] = chunk
+	}
+	content, err := reassemble(op, manifest, chunks, key)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.runVerificationHooks(manifest, content); err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+	return content, nil
+}
+
+// runVerificationHooks applies the registered hooks to reassembled content,
+// stopping at the first rejection and wrapping hook errors the same way the
+// retriever layer does.
+func (s *DDSCoreService) runVerificationHooks(manifest *chunking.Manifest, content []byte) error {
+	for _, h := range s.verificationHooks {
+		err := h.hook.Inspect(manifest, content)
+		if err == nil {
+			continue
+		}
+		var rejected *retriever.ErrContentRejected
+		if errors.As(err, &rejected) {
+			return rejected
+		}
+		return &retriever.ErrContentRejected{Hook: h.name, Reason: err.Error()}
+	}
+	return nil
+}
+
+// retrieveFromNetwork discovers providers, fetches manifest and chunks from
+// one of them, verifies, and caches the content locally. It returns the ID
+// of the peer that served the content.
+func (s *DDSCoreService) retrieveFromNetwork(ctx context.Context, manifestCID string) ([]byte, string, error) {
+	op := dds.Op(ctx, "retrieve")
+	if s.discovery == nil || s.network == nil {
+		return nil, "", fmt.Errorf(op+": content %s not found locally and no network configured", manifestCID)
+	}
+	providers, err := s.discovery.FindProviders(manifestCID)
+	if err != nil {
+		return nil, "", fmt.Errorf(op+": provider discovery for %s failed: %w", manifestCID, err)
+	}
+	if len(providers) == 0 {
+		return nil, "", fmt.Errorf(op+": no providers found for %s", manifestCID)
+	}
+	if s.selector != nil {
+		providers = s.selector.Select(providers, manifestCID)
+	}
+	if s.maxProviders > 0 && len(providers) > s.maxProviders {
+		providers = providers[:s.maxProviders]
+	}
+	if s.maxPeerAttempts > 0 && len(providers) > s.maxPeerAttempts {
+		providers = providers[:s.maxPeerAttempts]
+	}
+	if s.parallelPeers > 1 {
+		return s.raceProviders(ctx, manifestCID, providers)
+	}
+	var lastErr error
+	for attempts, peer := range providers {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, "", fmt.Errorf(op+": %w after %d peer attempts for %s: %w",
+				ErrRetrievalBudgetExhausted, attempts, manifestCID, ctxErr)
+		}
+		content, err := s.retrieveFromPeer(ctx, manifestCID, peer)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return content, peer.ID, nil
+	}
+	if s.maxPeerAttempts > 0 && len(providers) == s.maxPeerAttempts {
+		return nil, "", fmt.Errorf(op+": %w after %d peer attempts for %s: %w",
+			ErrRetrievalBudgetExhausted, len(providers), manifestCID, lastErr)
+	}
+	return nil, "", fmt.Errorf(op+": all %d providers failed for %s: %w", len(providers), manifestCID, lastErr)
+}
+
+// raceProviders fetches from up to parallelPeers providers concurrently
+// and returns the first response that passes verification. A responder
+// whose data fails integrity checks is skipped, so a fast-but-corrupt peer
+// cannot beat a slower honest one.
+func (s *DDSCoreService) raceProviders(ctx context.Context, manifestCID string, providers []p2p.Node) ([]byte, string, error) {
+	k := s.parallelPeers
+	if k > len(providers) {
+		k = len(providers)
+	}
+	type peerResult struct {
+		content []byte
+		peerID  string
+		err     error
+	}
+	results := make(chan peerResult, k)
+	for _, peer := range providers[:k] {
+		go func(peer p2p.Node) {
+			content, err := s.retrieveFromPeer(ctx, manifestCID, peer)
+			results <- peerResult{content: content, peerID: peer.ID, err: err}
+		}(peer)
+	}
+	var lastErr error
+	for i := 0; i < k; i++ {
+		r := <-results
+		if r.err != nil {
+			lastErr = r.err
+			continue
+		}
+		return r.content, r.peerID, nil
+	}
+	return nil, "", fmt.Errorf(dds.Op(ctx, "retrieve")+": all %d raced providers failed for %s: %w", k, manifestCID, lastErr)
+}
+
+// retrieveFromPeer fetches and verifies the content from a single peer and
+// caches it locally on success.
+func (s *DDSCoreService) retrieveFromPeer(ctx context.Context, manifestCID string, peer p2p.Node) ([]byte, error) {
+	op := dds.Op(ctx, "retrieve")
+	manifest, err := s.network.FetchManifest(manifestCID)
+	if err != nil {
+		return nil, fmt.Errorf(op+": failed to fetch manifest %s from network: %w", manifestCID, err)
+	}
+	// Provenance is checked before any chunk is fetched, so an unsigned or
+	// tampered manifest costs no chunk transfers.
+	if err := s.checkProvenance(op, manifest); err != nil {
+		return nil, err
+	}
+	key, err := manifest.ResolveSwarmKey(s.swarmKeys)
+	if err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+	chunks, err := s.fetchPeerChunks(ctx, op, manifest, peer, key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := reassemble(op, manifest, chunks, key)
+	if err != nil {
+		return nil, err
+	}
+	// Hooks run before the caching loop so rejected content never lands in
+	// local storage.
+	if err := s.runVerificationHooks(manifest, content); err != nil {
+		return nil, fmt.Errorf(op+": %w", err)
+	}
+	cached := s.cacheChunks(chunks)
+	if err := s.storage.StoreManifest(manifest); err != nil {
+		log.Printf("dds: warning: failed to cache manifest %s locally: %v", manifest.ID, err)
+		cached = false
+	}
+	s.announceCached(manifest.ID, cached)
+	return content, nil
+}
+
+// announceCached advertises a CID the node just finished caching, when
+// announce-on-retrieve is enabled and the whole manifest landed in local
+// storage. Failures are only warnings: the retrieval itself succeeded.
+func (s *DDSCoreService) announceCached(manifestCID string, fullyCached bool) {
+	if !s.announceRetrieved || !fullyCached {
+		return
+	}
+	if err := s.advertise(manifestCID); err != nil {
+		log.Printf("dds: warning: %v", err)
+	}
+}
+
+// cacheWriteConcurrency bounds how many chunk writes cacheChunks issues at
+// once when caching content fetched from the network.
+const cacheWriteConcurrency = 8
+
+// cacheChunks stores fetched chunks through a bounded pool of workers, so
+// caching does not serialize on storage I/O for file-backed storage. A
+// manifest may reference the same chunk more than once; each distinct
+// chunk is written once. Failures are logged rather than returned — the
+// caller already holds the verified content — but the return value reports
+// whether every chunk was stored, which gates announce-on-retrieve.
+func (s *DDSCoreService) cacheChunks(chunks []*chunking.Chunk) bool {
+	sem := make(chan struct{}, cacheWriteConcurrency)
+	var wg sync.WaitGroup
+	var failed atomic.Bool
+	seen := make(map[string]bool, len(chunks))
+	for _, chunk := range chunks {
+		if seen[chunk.ID] {
+			continue
+		}
+		seen[chunk.ID] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(chunk *chunking.Chunk) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := s.storage.StoreChunk(chunk); err != nil {
+				failed.Store(true)
+				log.Printf("dds: warning: failed to cache chunk %s locally: %v", chunk.ID, err)
+			}
+		}(chunk)
+	}
+	wg.Wait()
+	return !failed.Load()
+}
+
+// fetchPeerChunks downloads a manifest's chunks from one peer, using a
+// single batched request when both the transport and the peer's handshake
+// support it and falling back to chunk-by-chunk requests otherwise. Every
+// chunk is verified against its ID regardless of path, under the
+// manifest's ID scheme (key is the resolved swarm key, nil when unkeyed).
+func (s *DDSCoreService) fetchPeerChunks(ctx context.Context, op string, manifest *chunking.Manifest, peer p2p.Node, key []byte) ([]*chunking.Chunk, error) {
+	if batcher, ok := s.network.(p2p.BatchChunkFetcher); ok {
+		if caps, known := s.network.PeerCapabilities(peer.ID); known && caps.Supports(p2p.FeatureBatchChunks) {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf(op+": %w fetching chunks from peer %s: %w",
+					ErrRetrievalBudgetExhausted, peer.ID, ctxErr)
+			}
+			chunks, err := batcher.FetchChunksFromPeer(peer.ID, manifest.ChunkIDs)
+			switch {
+			case err == nil:
+				if len(chunks) != len(manifest.ChunkIDs) {
+					return nil, fmt.Errorf(op+": peer %s returned %d chunks, want %d",
+						peer.ID, len(chunks), len(manifest.ChunkIDs))
+				}
+				for i, chunk := range chunks {
+					if chunk.ID != manifest.ChunkIDs[i] || !manifest.VerifyChunk(chunk.Data, manifest.ChunkIDs[i], key) {
+						return nil, fmt.Errorf(op+": chunk %s from peer %s failed integrity check",
+							manifest.ChunkIDs[i], peer.ID)
+					}
+				}
+				return chunks, nil
+			case errors.Is(err, p2p.ErrUnsupportedFeature):
+				// The peer's handshake overstated its abilities; downgrade to
+				// single-chunk requests below.
+			default:
+				return nil, fmt.Errorf(op+": failed to fetch chunks from peer %s: %w", peer.ID, err)
+			}
+		}
+	}
+	order := s.chunkOrder
+	if order == nil {
+		order = retriever.InOrder{}
+	}
+	fetchIDs := order.OrderChunks(manifest.ChunkIDs)
+	fetched := make(map[string]*chunking.Chunk, len(fetchIDs))
+	for i, chunkID := range fetchIDs {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf(op+": %w fetching chunk %d/%d from peer %s: %w",
+				ErrRetrievalBudgetExhausted, i, len(fetchIDs), peer.ID, ctxErr)
+		}
+		chunk, err := s.network.FetchChunkFromPeer(peer.ID, chunkID)
+		if err != nil {
+			return nil, fmt.Errorf(op+": failed to fetch chunk %s from peer %s: %w", chunkID, peer.ID, err)
+		}
+		if !manifest.VerifyChunk(chunk.Data, chunkID, key) {
+			return nil, fmt.Errorf(op+": chunk %s from peer %s failed integrity check", chunkID, peer.ID)
+		}
+		fetched[chunkID] = chunk
+	}
+	chunks := make([]*chunking.Chunk, len(manifest.ChunkIDs))
+	for i, chunkID := range manifest.ChunkIDs {
+		chunk, ok := fetched[chunkID]
+		if !ok {
+			return nil, fmt.Errorf(op+": chunk order strategy did not request chunk %s", chunkID)
+		}
+		chunks[i] = chunk
+	}
+	return chunks, nil
+}
+
+// DiscoveryProviderCounts adapts a PeerDiscovery to
+// retriever.ProviderCounts, counting a chunk's providers via
+// FindProviders. Lookup failures count as zero providers, which is the
+// safe direction for rarest-first ordering.
+type DiscoveryProviderCounts struct {
+	Discovery p2p.PeerDiscovery
+}
+
+// ProviderCount implements retriever.ProviderCounts.
+func (d DiscoveryProviderCounts) ProviderCount(chunkID string) int {
+	providers, err := d.Discovery.FindProviders(chunkID)
+	if err != nil {
+		return 0
+	}
+	return len(providers)
+}
+
+// reassemble concatenates chunks in manifest order into a single pre-sized
+// buffer and verifies the overall size and content digest, hashing
+// incrementally with pooled state shared with ContentRetriever. For keyed
+// manifests key is the resolved swarm key and the digest is its HMAC.
+func reassemble(op string, manifest *chunking.Manifest, chunks []*chunking.Chunk, key []byte) ([]byte, error) {
+	content := make([]byte, 0, manifest.TotalSize)
+	var contentHash hash.Hash
+	if manifest.Keyed() {
+		contentHash = chunking.NewKeyedHasher(key)
+	} else {
+		pooled := chunking.AcquireHasher()
+		defer chunking.ReleaseHasher(pooled)
+		contentHash = pooled
+	}
+	for _, chunk := range chunks {
+		content = append(content, chunk.Data...)
+		contentHash.Write(chunk.Data)
+	}
+	if int64(len(content)) != manifest.EffectiveStoredSize() {
+		return nil, fmt.Errorf(op+": reassembled size %d does not match manifest stored size %d",
+			len(content), manifest.EffectiveStoredSize())
+	}
+	if got := chunking.SumToHex(contentHash); got != manifest.ContentID {
+		// Pinpoint whether a chunk is corrupt or the manifest's chunk
+		// list itself cannot produce the content.
+		data := make([][]byte, len(chunks))
+		for i, chunk := range chunks {
+			data[i] = chunk.Data
+		}
+		return nil, fmt.Errorf(op+": reassembled content failed integrity check (got digest %s): %w",
+			got, manifest.DiagnoseDigestMismatch(data, key))
+	}
+	return content, nil
+}
@@ -0,0 +1,159 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/api"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+
+	"net/http/httptest"
+)
+
+func TestRetrieve_FallsBackToHTTPGatewayWhenNoPeers(t *testing.T) {
+	// A seed node publishes content and exposes it through the gateway.
+	seedStore := storage.NewInMemoryStorage()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	seed := service.NewDDSCoreService(
+		p2p.NewNode("node-seed", "127.0.0.1:9001"),
+		seedStore, chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	content := []byte("content only the gateway has")
+	cid, err := seed.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	srv := httptest.NewServer(api.NewGatewayHandler(seedStore))
+	defer srv.Close()
+
+	// A fresh node with zero peers retrieves via the HTTP fallback.
+	client := service.NewDDSCoreService(
+		p2p.NewNode("node-client", "127.0.0.1:9002"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+		service.WithFallbackRetriever(retriever.NewHTTPRetriever(srv.URL, 1)),
+	)
+	got, err := client.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve via fallback: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+
+	// The fallback path must have cached the content locally: a second
+	// retrieval is served from local storage.
+	events, cancel := client.Subscribe()
+	defer cancel()
+	if _, err := client.Retrieve(cid); err != nil {
+		t.Fatalf("second Retrieve: %v", err)
+	}
+	select {
+	case ev := <-events:
+		retrieved, ok := ev.(service.ContentRetrieved)
+		if !ok {
+			t.Fatalf("want ContentRetrieved, got %T", ev)
+		}
+		if retrieved.Source != service.SourceLocal {
+			t.Errorf("second retrieval source = %s, want %s", retrieved.Source, service.SourceLocal)
+		}
+	default:
+		t.Fatal("no event delivered for second retrieval")
+	}
+}
+
+func TestFetchManifest_LocalStorage(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-a", "127.0.0.1:9010"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	content := []byte("manifest-only inspection")
+	cid, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	manifest, err := svc.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	if manifest.ID != cid {
+		t.Errorf("manifest ID = %s, want %s", manifest.ID, cid)
+	}
+	if manifest.TotalSize != int64(len(content)) {
+		t.Errorf("manifest TotalSize = %d, want %d", manifest.TotalSize, len(content))
+	}
+	if manifest.ContentType != "text/plain" {
+		t.Errorf("manifest ContentType = %s, want text/plain", manifest.ContentType)
+	}
+}
+
+func TestFetchManifest_FallsBackToPeer(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	network := p2p.NewMockP2PService()
+	peer := p2p.NewNode("node-peer", "127.0.0.1:9011")
+	network.AddPeer(peer)
+
+	content := []byte("held only by the peer")
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	if err := network.SeedPeerContent(peer.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+
+	localStore := storage.NewInMemoryStorage()
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-b", "127.0.0.1:9012"),
+		localStore, chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), network,
+	)
+	got, err := svc.FetchManifest(manifest.ID)
+	if err != nil {
+		t.Fatalf("FetchManifest via peer: %v", err)
+	}
+	if got.ID != manifest.ID {
+		t.Errorf("manifest ID = %s, want %s", got.ID, manifest.ID)
+	}
+	// Manifest-only fetch must not have pulled any chunks locally.
+	for _, chunkID := range manifest.ChunkIDs {
+		if ok, _ := localStore.HasChunk(chunkID); ok {
+			t.Errorf("chunk %s was fetched by a manifest-only call", chunkID)
+		}
+	}
+}
+
+func TestRetrieve_NoFallbackStillFailsWithoutPeers(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	client := service.NewDDSCoreService(
+		p2p.NewNode("node-lonely", "127.0.0.1:9003"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	if _, err := client.Retrieve("cid-that-does-not-exist"); err == nil {
+		t.Fatal("want error retrieving unknown content with no peers, got nil")
+	}
+}
@@ -0,0 +1,132 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func signedFixture(t *testing.T, opts ...service.Option) *service.DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	return service.NewDDSCoreService(
+		p2p.NewNode("node-signer", "127.0.0.1:9140"),
+		storage.NewInMemoryStorage(),
+		chunker,
+		originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(),
+		p2p.NewMockP2PService(),
+		opts...,
+	)
+}
+
+func TestPublish_WalletSignsManifest(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	svc := signedFixture(t,
+		service.WithPublisherWallet(wallet),
+		service.WithRequirePublisherSignature(true),
+	)
+	content := []byte("provenance travels with the manifest")
+	cid, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	manifest, err := svc.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	if err := manifest.VerifyPublisher(); err != nil {
+		t.Fatalf("VerifyPublisher: %v", err)
+	}
+	if got := manifest.PublisherAddress(); got != wallet.Address {
+		t.Errorf("manifest publisher is %s, want %s", got, wallet.Address)
+	}
+
+	// Retrieval under required provenance accepts the signed manifest.
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+}
+
+func TestRetrieve_RequiredProvenanceRejectsUnsigned(t *testing.T) {
+	svc := signedFixture(t, service.WithRequirePublisherSignature(true))
+	cid, err := svc.Publish([]byte("published without a wallet"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if _, err := svc.Retrieve(cid); !errors.Is(err, chunking.ErrManifestUnsigned) {
+		t.Fatalf("Retrieve of unsigned content: %v, want ErrManifestUnsigned", err)
+	}
+}
+
+func TestRetrieve_UnsignedAcceptedWhenProvenanceNotRequired(t *testing.T) {
+	svc := signedFixture(t)
+	content := []byte("unsigned content is still fine by default")
+	cid, err := svc.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	got, err := svc.Retrieve(cid)
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("retrieved %q, want %q", got, content)
+	}
+}
+
+func TestRetrieve_RequiredProvenanceRejectsTamperedNetworkManifest(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	content := []byte("the network copy was altered after signing")
+	manifest, chunks := seedContent(t, chunker, content)
+	if err := manifest.SignPublisher(wallet); err != nil {
+		t.Fatalf("SignPublisher: %v", err)
+	}
+	manifest.ContentType = "application/retagged"
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	provider := p2p.NewNode("node-tamperer", "127.0.0.1:0")
+	network.AddPeer(provider)
+	if err := network.SeedPeerContent(provider.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+	discovery.RegisterNode(provider)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-wary", "127.0.0.1:9141"),
+		storage.NewInMemoryStorage(),
+		chunker,
+		originator.NewMockOriginator(),
+		discovery,
+		network,
+		service.WithRequirePublisherSignature(true),
+	)
+	if _, err := svc.Retrieve(manifest.ID); !errors.Is(err, chunking.ErrManifestSignatureInvalid) {
+		t.Fatalf("Retrieve of tampered manifest: %v, want ErrManifestSignatureInvalid", err)
+	}
+}
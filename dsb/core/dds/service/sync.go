@@ -0,0 +1,23 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// SyncPlan compares the local manifest holdings against what the peer
+// advertises and returns the reconciliation steps: toFetch lists manifest
+// CIDs the peer holds and this node does not; toOffer lists CIDs this node
+// holds and the peer does not. Both are sorted. The plan is a snapshot —
+// the peer's advertisements may change before the caller acts on it.
+func (s *DDSCoreService) SyncPlan(peer p2p.Node) (toFetch, toOffer []string, err error) {
+	local, err := s.storage.ListManifests()
+	if err != nil {
+		return nil, nil, fmt.Errorf("sync: failed to list local manifests: %w", err)
+	}
+	remote := peer.KnownContentSnapshot()
+	onlyLocal, onlyRemote, _ := storage.DiffManifests(local, remote)
+	return onlyRemote, onlyLocal, nil
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestSyncPlan_SplitsHoldingsAgainstPeer(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-sync-local", "127.0.0.1:9070"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	sharedCID, err := svc.Publish([]byte("content both nodes hold"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	localOnlyCID, err := svc.Publish([]byte("content only published here"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	peer := p2p.NewNode("node-sync-peer", "127.0.0.1:9071")
+	peer.AddAdvertisedContent(sharedCID)
+	peer.AddAdvertisedContent("cid-peer-only")
+
+	toFetch, toOffer, err := svc.SyncPlan(*peer)
+	if err != nil {
+		t.Fatalf("SyncPlan: %v", err)
+	}
+	if !reflect.DeepEqual(toFetch, []string{"cid-peer-only"}) {
+		t.Errorf("toFetch = %v, want only the peer-exclusive CID", toFetch)
+	}
+	if !reflect.DeepEqual(toOffer, []string{localOnlyCID}) {
+		t.Errorf("toOffer = %v, want only the locally exclusive CID", toOffer)
+	}
+}
+
+func TestSyncPlan_IdenticalHoldingsNeedNothing(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-sync-same", "127.0.0.1:9072"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	cid, err := svc.Publish([]byte("already everywhere"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	peer := p2p.NewNode("node-sync-twin", "127.0.0.1:9073")
+	peer.AddAdvertisedContent(cid)
+
+	toFetch, toOffer, err := svc.SyncPlan(*peer)
+	if err != nil {
+		t.Fatalf("SyncPlan: %v", err)
+	}
+	if len(toFetch) != 0 || len(toOffer) != 0 {
+		t.Errorf("identical holdings produced a plan: fetch %v, offer %v", toFetch, toOffer)
+	}
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func newTracedService(t *testing.T, hook dds.SpanHook) *service.DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	return service.NewDDSCoreService(
+		p2p.NewNode("node-trace", "127.0.0.1:9300"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+		service.WithSpanHook(hook),
+	)
+}
+
+func TestRetrieveContext_ErrorsCarryRequestID(t *testing.T) {
+	svc := newTracedService(t, nil)
+	ctx := dds.WithRequestID(context.Background(), "abc123")
+
+	_, err := svc.RetrieveContext(ctx, "cid-missing")
+	if err == nil {
+		t.Fatal("retrieving missing content did not error")
+	}
+	if !strings.Contains(err.Error(), "retrieve[req=abc123]:") {
+		t.Errorf("error lacks request ID prefix: %v", err)
+	}
+
+	// Without an ID on the context one is generated, so the error still
+	// carries a correlation handle.
+	_, err = svc.RetrieveContext(context.Background(), "cid-missing")
+	if err == nil {
+		t.Fatal("retrieving missing content did not error")
+	}
+	if !strings.Contains(err.Error(), "[req=") {
+		t.Errorf("error lacks generated request ID: %v", err)
+	}
+}
+
+func TestSpanHook_ObservesPublishAndRetrieveEndToEnd(t *testing.T) {
+	var buf bytes.Buffer
+	svc := newTracedService(t, dds.LogSpanHook(log.New(&buf, "", 0)))
+	ctx := dds.WithRequestID(context.Background(), "abc123")
+
+	content := []byte("trace this content across publish and retrieve")
+	cid, err := svc.PublishContext(ctx, content, "text/plain")
+	if err != nil {
+		t.Fatalf("PublishContext: %v", err)
+	}
+	got, err := svc.RetrieveContext(ctx, cid)
+	if err != nil {
+		t.Fatalf("RetrieveContext: %v", err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("retrieved content differs from published content")
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"span=publish req=abc123 event=start",
+		"span=publish req=abc123 event=end",
+		"span=retrieve req=abc123 event=start",
+		"span=retrieve req=abc123 event=end",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("span log missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+type unpublishFixture struct {
+	svc       *service.DDSCoreService
+	store     *storage.InMemoryStorage
+	orig      *originator.MockOriginator
+	discovery *p2p.MockPeerDiscovery
+	node      *p2p.Node
+}
+
+func newUnpublishFixture(t *testing.T) *unpublishFixture {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	f := &unpublishFixture{
+		store:     storage.NewInMemoryStorage(),
+		orig:      originator.NewMockOriginator(),
+		discovery: p2p.NewMockPeerDiscovery(),
+		node:      p2p.NewNode("node-unpublish-test", "127.0.0.1:9040"),
+	}
+	f.discovery.RegisterNode(f.node)
+	f.svc = service.NewDDSCoreService(f.node, f.store, chunker, f.orig, f.discovery, p2p.NewMockP2PService())
+	return f
+}
+
+func TestUnpublish_RemovesContentAndAdvertisement(t *testing.T) {
+	f := newUnpublishFixture(t)
+	cid, err := f.svc.Publish([]byte("content to take down"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	manifest, err := f.svc.FetchManifest(cid)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+	if providers, _ := f.discovery.FindProviders(cid); len(providers) != 1 {
+		t.Fatalf("expected this node to provide %s before unpublish", cid)
+	}
+
+	if err := f.svc.Unpublish(cid); err != nil {
+		t.Fatalf("Unpublish: %v", err)
+	}
+	if has, _ := f.store.HasManifest(cid); has {
+		t.Error("manifest survived unpublish")
+	}
+	for _, chunkID := range manifest.ChunkIDs {
+		if has, _ := f.store.HasChunk(chunkID); has {
+			t.Errorf("chunk %s survived unpublish", chunkID)
+		}
+	}
+	if f.orig.WasAdvertised(cid) {
+		t.Error("originator still advertises the unpublished CID")
+	}
+	if providers, _ := f.discovery.FindProviders(cid); len(providers) != 0 {
+		t.Errorf("FindProviders still lists %d providers after unpublish", len(providers))
+	}
+}
+
+func TestUnpublish_SparesChunksSharedWithOtherManifests(t *testing.T) {
+	f := newUnpublishFixture(t)
+	// With 4-byte chunks, both contents share their first chunk.
+	cidA, err := f.svc.Publish([]byte("aaaabbbb"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish A: %v", err)
+	}
+	cidB, err := f.svc.Publish([]byte("aaaacccc"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish B: %v", err)
+	}
+
+	if err := f.svc.Unpublish(cidA); err != nil {
+		t.Fatalf("Unpublish: %v", err)
+	}
+	got, err := f.svc.Retrieve(cidB)
+	if err != nil {
+		t.Fatalf("shared content broken by unpublish: %v", err)
+	}
+	if string(got) != "aaaacccc" {
+		t.Errorf("retrieved %q after neighbor unpublish", got)
+	}
+	sharedChunk := chunking.HashContent([]byte("aaaa"))
+	if has, _ := f.store.HasChunk(sharedChunk); !has {
+		t.Error("shared chunk was deleted with the first manifest")
+	}
+	unsharedChunk := chunking.HashContent([]byte("bbbb"))
+	if has, _ := f.store.HasChunk(unsharedChunk); has {
+		t.Error("unshared chunk survived unpublish")
+	}
+}
+
+func TestUnpublish_UnknownCIDFails(t *testing.T) {
+	f := newUnpublishFixture(t)
+	if err := f.svc.Unpublish("cid-never-published"); err == nil {
+		t.Error("unpublishing unknown content succeeded")
+	}
+}
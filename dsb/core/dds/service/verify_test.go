@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// Even with local verification disabled, chunks arriving from the network
+// must still be digest-checked.
+func TestRetrieve_NetworkChunksVerifiedDespiteLocalToggle(t *testing.T) {
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	content := []byte("network chunks are never trusted")
+	manifest, chunks := seedContent(t, chunker, content)
+
+	// The only provider serves a corrupted chunk.
+	corrupt := make([]chunking.Chunk, len(chunks))
+	copy(corrupt, chunks)
+	corrupt[0].Data = append([]byte{}, corrupt[0].Data...)
+	corrupt[0].Data[0] ^= 0xFF
+
+	network := p2p.NewMockP2PService()
+	discovery := p2p.NewMockPeerDiscovery()
+	liar := p2p.NewNode("node-liar", "127.0.0.1:0")
+	network.AddPeer(liar)
+	if err := network.SeedPeerContent(liar.ID, manifest, corrupt); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+	discovery.RegisterNode(liar)
+
+	svc := service.NewDDSCoreService(
+		p2p.NewNode("node-trusting", "127.0.0.1:9080"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		discovery, network,
+		service.WithVerifyLocalChunks(false),
+	)
+	_, err = svc.Retrieve(manifest.ID)
+	if err == nil {
+		t.Fatal("Retrieve accepted a corrupt network chunk")
+	}
+	if !strings.Contains(err.Error(), "integrity") {
+		t.Errorf("error does not mention integrity failure: %v", err)
+	}
+}
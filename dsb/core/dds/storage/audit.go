@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+)
+
+// BrokenManifest names a stored manifest that cannot be reassembled
+// locally, together with the chunk IDs it is missing.
+type BrokenManifest struct {
+	ManifestID    string
+	MissingChunks []string
+}
+
+// AuditReport is the result of cross-checking stored manifests against
+// stored chunks: manifests with holes, chunks nothing references, and how
+// many bytes sit in each category.
+type AuditReport struct {
+	// BrokenManifests lists manifests missing at least one chunk, sorted
+	// by manifest ID; each carries its missing chunk IDs in manifest order.
+	BrokenManifests []BrokenManifest
+	// OrphanChunks lists stored chunks no manifest references, sorted.
+	OrphanChunks []string
+	// ReferencedBytes counts stored chunk bytes reachable from at least
+	// one manifest; OrphanBytes counts the rest. MissingBytes is how much
+	// of broken manifests' declared content is not stored locally.
+	ReferencedBytes int64
+	OrphanBytes     int64
+	MissingBytes    int64
+}
+
+// Healthy reports whether the audit found nothing to repair.
+func (r *AuditReport) Healthy() bool {
+	return len(r.BrokenManifests) == 0 && len(r.OrphanChunks) == 0
+}
+
+// Audit cross-checks every stored manifest against the stored chunks,
+// reporting broken manifests and orphan chunks. Partial publishes and
+// interrupted caching both leave such debris behind; the report feeds
+// Repair. The error return covers only failures to read storage.
+func Audit(s Storage) (*AuditReport, error) {
+	chunkIDs, err := s.ListChunks()
+	if err != nil {
+		return nil, fmt.Errorf("storage: audit failed to list chunks: %w", err)
+	}
+	chunkSize := make(map[string]int64, len(chunkIDs))
+	for _, id := range chunkIDs {
+		chunk, err := s.GetChunk(id)
+		if err != nil {
+			return nil, fmt.Errorf("storage: audit failed to read chunk %s: %w", id, err)
+		}
+		chunkSize[id] = int64(len(chunk.Data))
+	}
+
+	manifestIDs, err := s.ListManifests()
+	if err != nil {
+		return nil, fmt.Errorf("storage: audit failed to list manifests: %w", err)
+	}
+	report := &AuditReport{}
+	referenced := make(map[string]bool)
+	for _, manifestID := range manifestIDs {
+		manifest, err := s.GetManifest(manifestID)
+		if err != nil {
+			return nil, fmt.Errorf("storage: audit failed to read manifest %s: %w", manifestID, err)
+		}
+		var missing []string
+		var present int64
+		for _, chunkID := range manifest.ChunkIDs {
+			size, ok := chunkSize[chunkID]
+			if !ok {
+				missing = append(missing, chunkID)
+				continue
+			}
+			referenced[chunkID] = true
+			present += size
+		}
+		if len(missing) > 0 {
+			// A missing chunk's size is unknown, so the shortfall is
+			// measured against the manifest's declared total.
+			if stored := manifest.EffectiveStoredSize(); stored > present {
+				report.MissingBytes += stored - present
+			}
+			report.BrokenManifests = append(report.BrokenManifests,
+				BrokenManifest{ManifestID: manifestID, MissingChunks: missing})
+		}
+	}
+	sort.Slice(report.BrokenManifests, func(i, j int) bool {
+		return report.BrokenManifests[i].ManifestID < report.BrokenManifests[j].ManifestID
+	})
+
+	for _, chunkID := range chunkIDs {
+		if referenced[chunkID] {
+			report.ReferencedBytes += chunkSize[chunkID]
+			continue
+		}
+		report.OrphanChunks = append(report.OrphanChunks, chunkID)
+		report.OrphanBytes += chunkSize[chunkID]
+	}
+	sort.Strings(report.OrphanChunks)
+	return report, nil
+}
+
+// RepairOptions selects which repairs to apply. The zero value repairs
+// nothing (a dry run equivalent to Audit).
+type RepairOptions struct {
+	// DeleteOrphans removes chunks no manifest references.
+	DeleteOrphans bool
+	// FetchMissing, when non-nil, backfills broken manifests' missing
+	// chunks from this source, verifying each fetched chunk's digest
+	// before storing it.
+	FetchMissing retriever.Retriever
+	// Keys resolves swarm keys for keyed manifests (see
+	// chunking.NewKeyedChunker) so their backfilled chunks can be
+	// verified. Keyed manifests whose key is unavailable are left in the
+	// report rather than backfilled unverified.
+	Keys chunking.KeyProvider
+}
+
+// Repair audits the storage and applies the selected repairs, returning a
+// fresh audit of whatever damage remains — e.g. chunks the retriever could
+// not supply. Fetch failures are not fatal; they simply stay in the report.
+func Repair(s Storage, opts RepairOptions) (*AuditReport, error) {
+	report, err := Audit(s)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DeleteOrphans {
+		for _, chunkID := range report.OrphanChunks {
+			if err := s.DeleteChunk(chunkID); err != nil {
+				return nil, fmt.Errorf("storage: repair failed to delete orphan chunk %s: %w", chunkID, err)
+			}
+		}
+	}
+	if opts.FetchMissing != nil {
+		for _, broken := range report.BrokenManifests {
+			manifest, err := s.GetManifest(broken.ManifestID)
+			if err != nil {
+				continue
+			}
+			key, err := manifest.ResolveSwarmKey(opts.Keys)
+			if err != nil {
+				// No key, no verification: the manifest stays in the report.
+				continue
+			}
+			for _, chunkID := range broken.MissingChunks {
+				chunk, err := opts.FetchMissing.FetchChunk(chunkID)
+				if err != nil {
+					continue
+				}
+				if !manifest.VerifyChunk(chunk.Data, chunkID, key) {
+					continue
+				}
+				if err := s.StoreChunk(chunk); err != nil {
+					return nil, fmt.Errorf("storage: repair failed to store fetched chunk %s: %w", chunkID, err)
+				}
+			}
+		}
+	}
+	return Audit(s)
+}
@@ -0,0 +1,145 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/retriever"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// seedContent chunks the content and stores it fully: every chunk plus the
+// manifest, returning the manifest and chunks for later corruption.
+func seedContent(t *testing.T, store storage.Storage, content []byte) (*chunking.Manifest, []chunking.Chunk) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(4)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	for i := range chunks {
+		if err := store.StoreChunk(&chunks[i]); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	if err := store.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+	return manifest, chunks
+}
+
+func TestAudit_ReportsEachCorruptionClass(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	healthy, _ := seedContent(t, store, []byte("intact content !"))
+	broken, brokenChunks := seedContent(t, store, []byte("holey content .."))
+	if err := store.DeleteChunk(brokenChunks[1].ID); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+	orphan := &chunking.Chunk{ID: chunking.HashContent([]byte("stray")), Data: []byte("stray")}
+	if err := store.StoreChunk(orphan); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+
+	report, err := storage.Audit(store)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if report.Healthy() {
+		t.Fatal("audit of corrupted storage reported healthy")
+	}
+	if len(report.BrokenManifests) != 1 {
+		t.Fatalf("audit found %d broken manifests, want 1", len(report.BrokenManifests))
+	}
+	got := report.BrokenManifests[0]
+	if got.ManifestID != broken.ID {
+		t.Errorf("broken manifest %s, want %s", got.ManifestID, broken.ID)
+	}
+	if len(got.MissingChunks) != 1 || got.MissingChunks[0] != brokenChunks[1].ID {
+		t.Errorf("missing chunks %v, want [%s]", got.MissingChunks, brokenChunks[1].ID)
+	}
+	if len(report.OrphanChunks) != 1 || report.OrphanChunks[0] != orphan.ID {
+		t.Errorf("orphan chunks %v, want [%s]", report.OrphanChunks, orphan.ID)
+	}
+	if report.OrphanBytes != int64(len(orphan.Data)) {
+		t.Errorf("orphan bytes %d, want %d", report.OrphanBytes, len(orphan.Data))
+	}
+	if report.MissingBytes != int64(len(brokenChunks[1].Data)) {
+		t.Errorf("missing bytes %d, want %d", report.MissingBytes, len(brokenChunks[1].Data))
+	}
+	// Referenced bytes: the healthy manifest's full content plus what the
+	// broken one still holds.
+	want := healthy.TotalSize + broken.TotalSize - int64(len(brokenChunks[1].Data))
+	if report.ReferencedBytes != want {
+		t.Errorf("referenced bytes %d, want %d", report.ReferencedBytes, want)
+	}
+}
+
+func TestAudit_CleanStorageIsHealthy(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	seedContent(t, store, []byte("nothing wrong here"))
+	report, err := storage.Audit(store)
+	if err != nil {
+		t.Fatalf("Audit: %v", err)
+	}
+	if !report.Healthy() {
+		t.Errorf("clean storage audited unhealthy: %+v", report)
+	}
+}
+
+func TestRepair_DeletesOrphansAndBackfillsMissing(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	_, chunks := seedContent(t, store, []byte("content repaired."))
+	if err := store.DeleteChunk(chunks[2].ID); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+	orphan := &chunking.Chunk{ID: chunking.HashContent([]byte("junk")), Data: []byte("junk")}
+	if err := store.StoreChunk(orphan); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	source := retriever.NewMockRetriever()
+	source.AddChunk(&chunks[2])
+
+	report, err := storage.Repair(store, storage.RepairOptions{DeleteOrphans: true, FetchMissing: source})
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if !report.Healthy() {
+		t.Errorf("storage still unhealthy after repair: %+v", report)
+	}
+	if has, _ := store.HasChunk(orphan.ID); has {
+		t.Error("orphan chunk survived repair")
+	}
+	if has, _ := store.HasChunk(chunks[2].ID); !has {
+		t.Error("missing chunk was not backfilled")
+	}
+}
+
+func TestRepair_UnfetchableChunkStaysInReport(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	manifest, chunks := seedContent(t, store, []byte("gone for good"))
+	if err := store.DeleteChunk(chunks[0].ID); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+	// The retriever serves a chunk under the right ID but with wrong
+	// bytes; repair must refuse to store it.
+	forged := retriever.NewMockRetriever()
+	forged.AddChunk(&chunking.Chunk{ID: chunks[0].ID, Data: []byte("forged data")})
+
+	report, err := storage.Repair(store, storage.RepairOptions{FetchMissing: forged})
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if len(report.BrokenManifests) != 1 || report.BrokenManifests[0].ManifestID != manifest.ID {
+		t.Errorf("broken manifest missing from post-repair report: %+v", report.BrokenManifests)
+	}
+	if has, _ := store.HasChunk(chunks[0].ID); has {
+		t.Error("forged chunk was stored during repair")
+	}
+}
@@ -0,0 +1,371 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// Bucket names inside a BoltStorage database file.
+var (
+	boltChunksBucket    = []byte("chunks")
+	boltManifestsBucket = []byte("manifests")
+	// boltChunkRefsBucket is the reverse index from chunks to the manifests
+	// referencing them, keyed chunkID 0x00 manifestID with empty values so a
+	// prefix scan answers ManifestsContainingChunk. IDs are hex digests and
+	// never contain the separator byte.
+	boltChunkRefsBucket = []byte("chunkRefs")
+)
+
+// boltChunkRefSep separates the chunk ID from the manifest ID in a
+// chunkRefs key.
+const boltChunkRefSep = byte(0)
+
+// BoltStorage persists chunks and manifests in a single bbolt database
+// file, chunks and manifests in separate buckets keyed by ID. It trades
+// FileStorage's one-file-per-chunk sprawl for one database file, and every
+// write runs in a bbolt transaction, so a crash mid-write never leaves a
+// half-written chunk behind. bbolt serializes writers internally, making
+// the store safe for concurrent use.
+type BoltStorage struct {
+	db *bolt.DB
+}
+
+// NewBoltStorage opens (creating if needed) the database file at path.
+// The caller owns the store and must Close it to release the file lock.
+func NewBoltStorage(path string) (*BoltStorage, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to open bolt database %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltChunksBucket, boltManifestsBucket, boltChunkRefsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		// A database written before the reverse index existed has manifests
+		// but an empty chunkRefs bucket; rebuild it from the manifests.
+		refs := tx.Bucket(boltChunkRefsBucket)
+		if k, _ := refs.Cursor().First(); k != nil {
+			return nil
+		}
+		return tx.Bucket(boltManifestsBucket).ForEach(func(k, v []byte) error {
+			manifest, err := chunking.ManifestFromJSON(v)
+			if err != nil {
+				return fmt.Errorf("failed to index manifest %s: %w", k, err)
+			}
+			return putChunkRefs(refs, manifest)
+		})
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("storage: failed to prepare buckets in %s: %w", path, err)
+	}
+	return &BoltStorage{db: db}, nil
+}
+
+// Close flushes and closes the database file.
+func (s *BoltStorage) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("storage: failed to close bolt database: %w", err)
+	}
+	return nil
+}
+
+// StoreChunk writes the chunk's bytes, overwriting any existing entry.
+func (s *BoltStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if chunk == nil || chunk.ID == "" {
+		return fmt.Errorf("storage: cannot store chunk without an ID")
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltChunksBucket).Put([]byte(chunk.ID), chunk.Data)
+	})
+	if err != nil {
+		return fmt.Errorf("storage: failed to write chunk %s: %w", chunk.ID, err)
+	}
+	return nil
+}
+
+// GetChunk reads the stored chunk or returns ErrChunkNotFound.
+func (s *BoltStorage) GetChunk(chunkID string) (*chunking.Chunk, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltChunksBucket).Get([]byte(chunkID))
+		if value == nil {
+			return fmt.Errorf("%w: %s", ErrChunkNotFound, chunkID)
+		}
+		// The slice bbolt returns is only valid inside the transaction.
+		data = append([]byte{}, value...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &chunking.Chunk{ID: chunkID, Data: data}, nil
+}
+
+// HasChunk reports whether the chunk is stored.
+func (s *BoltStorage) HasChunk(chunkID string) (bool, error) {
+	return s.has(boltChunksBucket, chunkID)
+}
+
+// DeleteChunk removes the chunk. Deleting a missing chunk is not an error.
+func (s *BoltStorage) DeleteChunk(chunkID string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltChunksBucket).Delete([]byte(chunkID))
+	})
+	if err != nil {
+		return fmt.Errorf("storage: failed to delete chunk %s: %w", chunkID, err)
+	}
+	return nil
+}
+
+// StoreManifest writes the manifest's JSON, overwriting any existing
+// entry.
+func (s *BoltStorage) StoreManifest(manifest *chunking.Manifest) error {
+	if manifest == nil || manifest.ID == "" {
+		return fmt.Errorf("storage: cannot store manifest without an ID")
+	}
+	data, err := manifest.ToJSON()
+	if err != nil {
+		return err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		manifests := tx.Bucket(boltManifestsBucket)
+		refs := tx.Bucket(boltChunkRefsBucket)
+		// An overwrite may change the chunk list; drop the old entries first.
+		if old := manifests.Get([]byte(manifest.ID)); old != nil {
+			oldManifest, err := chunking.ManifestFromJSON(old)
+			if err != nil {
+				return err
+			}
+			if err := deleteChunkRefs(refs, oldManifest); err != nil {
+				return err
+			}
+		}
+		if err := manifests.Put([]byte(manifest.ID), data); err != nil {
+			return err
+		}
+		return putChunkRefs(refs, manifest)
+	})
+	if err != nil {
+		return fmt.Errorf("storage: failed to write manifest %s: %w", manifest.ID, err)
+	}
+	return nil
+}
+
+// chunkRefKey builds the chunkRefs bucket key for one reference.
+func chunkRefKey(chunkID, manifestID string) []byte {
+	key := make([]byte, 0, len(chunkID)+1+len(manifestID))
+	key = append(key, chunkID...)
+	key = append(key, boltChunkRefSep)
+	return append(key, manifestID...)
+}
+
+// putChunkRefs records every chunk reference a manifest carries.
+func putChunkRefs(refs *bolt.Bucket, manifest *chunking.Manifest) error {
+	for _, chunkID := range manifest.ChunkIDs {
+		if err := refs.Put(chunkRefKey(chunkID, manifest.ID), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteChunkRefs removes every chunk reference a manifest carries.
+func deleteChunkRefs(refs *bolt.Bucket, manifest *chunking.Manifest) error {
+	for _, chunkID := range manifest.ChunkIDs {
+		if err := refs.Delete(chunkRefKey(chunkID, manifest.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetManifest reads the stored manifest or returns ErrManifestNotFound.
+func (s *BoltStorage) GetManifest(manifestID string) (*chunking.Manifest, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		value := tx.Bucket(boltManifestsBucket).Get([]byte(manifestID))
+		if value == nil {
+			return fmt.Errorf("%w: %s", ErrManifestNotFound, manifestID)
+		}
+		data = append([]byte{}, value...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chunking.ManifestFromJSON(data)
+}
+
+// HasManifest reports whether the manifest is stored.
+func (s *BoltStorage) HasManifest(manifestID string) (bool, error) {
+	return s.has(boltManifestsBucket, manifestID)
+}
+
+// DeleteManifest removes the manifest. Deleting a missing manifest is not
+// an error.
+func (s *BoltStorage) DeleteManifest(manifestID string) error {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		manifests := tx.Bucket(boltManifestsBucket)
+		if old := manifests.Get([]byte(manifestID)); old != nil {
+			oldManifest, err := chunking.ManifestFromJSON(old)
+			if err != nil {
+				return err
+			}
+			if err := deleteChunkRefs(tx.Bucket(boltChunkRefsBucket), oldManifest); err != nil {
+				return err
+			}
+		}
+		return manifests.Delete([]byte(manifestID))
+	})
+	if err != nil {
+		return fmt.Errorf("storage: failed to delete manifest %s: %w", manifestID, err)
+	}
+	return nil
+}
+
+// ManifestsContainingChunk returns the sorted IDs of manifests referencing
+// the chunk, answered by a prefix scan over the reverse-index bucket.
+func (s *BoltStorage) ManifestsContainingChunk(chunkID string) ([]string, error) {
+	prefix := append([]byte(chunkID), boltChunkRefSep)
+	ids := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltChunkRefsBucket).Cursor()
+		for k, _ := cursor.Seek(prefix); bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+			ids = append(ids, string(k[len(prefix):]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to look up manifests for chunk %s: %w", chunkID, err)
+	}
+	return ids, nil
+}
+
+func (s *BoltStorage) has(bucket []byte, id string) (bool, error) {
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		found = tx.Bucket(bucket).Get([]byte(id)) != nil
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("storage: failed to check %s: %w", id, err)
+	}
+	return found, nil
+}
+
+// listBucket returns the keys of a bucket; bbolt iterates keys in sorted
+// order already.
+func (s *BoltStorage) listBucket(bucket []byte) ([]string, error) {
+	ids := []string{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+			ids = append(ids, string(k))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to list bucket %s: %w", bucket, err)
+	}
+	return ids, nil
+}
+
+// ListChunks returns the stored chunk IDs in sorted order.
+func (s *BoltStorage) ListChunks() ([]string, error) {
+	return s.listBucket(boltChunksBucket)
+}
+
+// ListManifests returns the stored manifest IDs in sorted order.
+func (s *BoltStorage) ListManifests() ([]string, error) {
+	return s.listBucket(boltManifestsBucket)
+}
+
+// ScanChunks pages through stored chunks in ID order, seeking straight to
+// the cursor so only the returned page is read.
+func (s *BoltStorage) ScanChunks(cursor string, limit int) ([]ChunkInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	infos := []ChunkInfo{}
+	next := ""
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltChunksBucket).Cursor()
+		for k, v := c.Seek([]byte(cursor)); k != nil; k, v = c.Next() {
+			if string(k) <= cursor {
+				// Seek lands on the cursor itself when it is still stored.
+				continue
+			}
+			if len(infos) == limit {
+				next = infos[len(infos)-1].ID
+				break
+			}
+			infos = append(infos, ChunkInfo{ID: string(k), Size: int64(len(v))})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: failed to scan chunks: %w", err)
+	}
+	return infos, next, nil
+}
+
+// ScanManifests pages through stored manifests in ID order, seeking
+// straight to the cursor so only the returned page is read.
+func (s *BoltStorage) ScanManifests(cursor string, limit int) ([]ManifestInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	infos := []ManifestInfo{}
+	next := ""
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltManifestsBucket).Cursor()
+		for k, v := c.Seek([]byte(cursor)); k != nil; k, v = c.Next() {
+			if string(k) <= cursor {
+				continue
+			}
+			if len(infos) == limit {
+				next = infos[len(infos)-1].ID
+				break
+			}
+			manifest, err := chunking.ManifestFromJSON(v)
+			if err != nil {
+				return err
+			}
+			infos = append(infos, ManifestInfo{ID: string(k), Size: int64(len(v)), CreatedAt: manifest.CreatedAt})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: failed to scan manifests: %w", err)
+	}
+	return infos, next, nil
+}
+
+// Usage reports what the store currently holds, summing value sizes.
+func (s *BoltStorage) Usage() (StorageUsage, error) {
+	var usage StorageUsage
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(boltChunksBucket).ForEach(func(k, v []byte) error {
+			usage.ChunkCount++
+			usage.TotalBytes += int64(len(v))
+			return nil
+		}); err != nil {
+			return err
+		}
+		return tx.Bucket(boltManifestsBucket).ForEach(func(k, v []byte) error {
+			usage.ManifestCount++
+			usage.TotalBytes += int64(len(v))
+			return nil
+		})
+	})
+	if err != nil {
+		return StorageUsage{}, fmt.Errorf("storage: failed to compute usage: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,90 @@
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestBoltStorage_ReopenRecoversContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dds.db")
+	store, err := storage.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("NewBoltStorage: %v", err)
+	}
+	chunk := &chunking.Chunk{ID: "chunk-persisted", Data: []byte("survives restart")}
+	if err := store.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(chunk.Data), []chunking.Chunk{*chunk}, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	if err := store.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := storage.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("reopening database: %v", err)
+	}
+	defer reopened.Close()
+	got, err := reopened.GetChunk(chunk.ID)
+	if err != nil {
+		t.Fatalf("GetChunk after reopen: %v", err)
+	}
+	if !bytes.Equal(got.Data, chunk.Data) {
+		t.Error("chunk data changed across reopen")
+	}
+	gotManifest, err := reopened.GetManifest(manifest.ID)
+	if err != nil {
+		t.Fatalf("GetManifest after reopen: %v", err)
+	}
+	if gotManifest.ContentID != manifest.ContentID {
+		t.Error("manifest changed across reopen")
+	}
+	usage, err := reopened.Usage()
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.ChunkCount != 1 || usage.ManifestCount != 1 {
+		t.Errorf("usage after reopen = %+v, want 1 chunk and 1 manifest", usage)
+	}
+}
+
+func TestBoltStorage_DeleteSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dds.db")
+	store, err := storage.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("NewBoltStorage: %v", err)
+	}
+	if err := store.StoreChunk(&chunking.Chunk{ID: "chunk-doomed", Data: []byte("x")}); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	if err := store.DeleteChunk("chunk-doomed"); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	reopened, err := storage.NewBoltStorage(path)
+	if err != nil {
+		t.Fatalf("reopening database: %v", err)
+	}
+	defer reopened.Close()
+	if _, err := reopened.GetChunk("chunk-doomed"); !errors.Is(err, storage.ErrChunkNotFound) {
+		t.Errorf("deleted chunk resurfaced after reopen: %v", err)
+	}
+}
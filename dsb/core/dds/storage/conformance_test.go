@@ -0,0 +1,73 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage/storagetest"
+)
+
+func TestInMemoryStorage_Conformance(t *testing.T) {
+	storagetest.RunStorageConformance(t, func() storage.Storage {
+		return storage.NewInMemoryStorage()
+	})
+}
+
+func TestFileStorage_Conformance(t *testing.T) {
+	storagetest.RunStorageConformance(t, func() storage.Storage {
+		store, err := storage.NewFileStorage(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewFileStorage: %v", err)
+		}
+		return store
+	})
+}
+
+func TestEncryptedStorage_Conformance(t *testing.T) {
+	storagetest.RunStorageConformance(t, func() storage.Storage {
+		store, err := storage.NewEncryptedStorage(storage.NewInMemoryStorage(), make([]byte, storage.NodeKeySize))
+		if err != nil {
+			t.Fatalf("NewEncryptedStorage: %v", err)
+		}
+		return store
+	})
+}
+
+func TestQuotaStorage_Conformance(t *testing.T) {
+	// A budget far above what the suite stores, so quota logic stays out of
+	// the way while the decorator's pass-through behavior is checked.
+	storagetest.RunStorageConformance(t, func() storage.Storage {
+		return storage.NewQuotaStorage(storage.NewInMemoryStorage(), 1<<30, nil)
+	})
+}
+
+func TestRoutingStorage_Conformance(t *testing.T) {
+	storagetest.RunStorageConformance(t, func() storage.Storage {
+		router, err := storage.NewRoutingStorage(func(m *chunking.Manifest) string {
+			if m.ContentType == "application/json" {
+				return "json"
+			}
+			return "blob"
+		}, "blob", map[string]storage.Storage{
+			"json": storage.NewInMemoryStorage(),
+			"blob": storage.NewInMemoryStorage(),
+		})
+		if err != nil {
+			t.Fatalf("NewRoutingStorage: %v", err)
+		}
+		return router
+	})
+}
+
+func TestBoltStorage_Conformance(t *testing.T) {
+	storagetest.RunStorageConformance(t, func() storage.Storage {
+		store, err := storage.NewBoltStorage(filepath.Join(t.TempDir(), "dds.db"))
+		if err != nil {
+			t.Fatalf("NewBoltStorage: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	})
+}
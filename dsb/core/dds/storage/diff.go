@@ -0,0 +1,35 @@
+package storage
+
+import "sort"
+
+// DiffManifests splits two nodes' manifest holdings into what only the
+// local side has, what only the remote side has, and what both hold. It is
+// the reconciliation primitive behind a sync exchange: the local node
+// fetches onlyRemote and offers onlyLocal. Inputs may be unsorted and may
+// contain duplicates; each result is deduplicated and sorted.
+func DiffManifests(local, remote []string) (onlyLocal, onlyRemote, common []string) {
+	localSet := make(map[string]struct{}, len(local))
+	for _, id := range local {
+		localSet[id] = struct{}{}
+	}
+	remoteSet := make(map[string]struct{}, len(remote))
+	for _, id := range remote {
+		remoteSet[id] = struct{}{}
+	}
+	for id := range localSet {
+		if _, ok := remoteSet[id]; ok {
+			common = append(common, id)
+		} else {
+			onlyLocal = append(onlyLocal, id)
+		}
+	}
+	for id := range remoteSet {
+		if _, ok := localSet[id]; !ok {
+			onlyRemote = append(onlyRemote, id)
+		}
+	}
+	sort.Strings(onlyLocal)
+	sort.Strings(onlyRemote)
+	sort.Strings(common)
+	return onlyLocal, onlyRemote, common
+}
@@ -0,0 +1,62 @@
+package storage_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func TestDiffManifests(t *testing.T) {
+	cases := []struct {
+		name                          string
+		local, remote                 []string
+		onlyLocal, onlyRemote, common []string
+	}{
+		{
+			name:       "disjoint",
+			local:      []string{"m-a", "m-b"},
+			remote:     []string{"m-c", "m-d"},
+			onlyLocal:  []string{"m-a", "m-b"},
+			onlyRemote: []string{"m-c", "m-d"},
+		},
+		{
+			name:       "overlapping",
+			local:      []string{"m-b", "m-a", "m-c"},
+			remote:     []string{"m-c", "m-d", "m-b"},
+			onlyLocal:  []string{"m-a"},
+			onlyRemote: []string{"m-d"},
+			common:     []string{"m-b", "m-c"},
+		},
+		{
+			name:   "identical",
+			local:  []string{"m-a", "m-b"},
+			remote: []string{"m-b", "m-a"},
+			common: []string{"m-a", "m-b"},
+		},
+		{
+			name:       "duplicates collapse",
+			local:      []string{"m-a", "m-a"},
+			remote:     []string{"m-b", "m-b"},
+			onlyLocal:  []string{"m-a"},
+			onlyRemote: []string{"m-b"},
+		},
+		{
+			name: "both empty",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			onlyLocal, onlyRemote, common := storage.DiffManifests(tc.local, tc.remote)
+			if !reflect.DeepEqual(onlyLocal, tc.onlyLocal) {
+				t.Errorf("onlyLocal = %v, want %v", onlyLocal, tc.onlyLocal)
+			}
+			if !reflect.DeepEqual(onlyRemote, tc.onlyRemote) {
+				t.Errorf("onlyRemote = %v, want %v", onlyRemote, tc.onlyRemote)
+			}
+			if !reflect.DeepEqual(common, tc.common) {
+				t.Errorf("common = %v, want %v", common, tc.common)
+			}
+		})
+	}
+}
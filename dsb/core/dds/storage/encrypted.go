@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// NodeKeySize is the required size of the at-rest encryption key
+// (AES-256).
+const NodeKeySize = 32
+
+// encryptedManifestType marks the carrier record EncryptedStorage writes
+// in place of a plaintext manifest.
+const encryptedManifestType = "application/x-dds-encrypted"
+
+// ErrDecryptionFailed is returned when stored ciphertext cannot be opened,
+// most commonly because the node key does not match the one the data was
+// written under.
+var ErrDecryptionFailed = errors.New("storage: decryption failed (wrong node key?)")
+
+// EncryptedStorage wraps a Storage so chunk data and manifest JSON are
+// encrypted at rest with AES-GCM under a node key. IDs stay the plaintext
+// digests, so content addressing and network serving are unaffected: a
+// chunk is decrypted on GetChunk and re-verifies against its ID as usual.
+// Each record carries its own random nonce ahead of the ciphertext, and
+// the record's ID is bound in as additional authenticated data so records
+// cannot be swapped on disk undetected.
+type EncryptedStorage struct {
+	Storage
+
+	mu   sync.RWMutex
+	aead cipher.AEAD
+}
+
+// NewEncryptedStorage wraps inner with at-rest encryption under the given
+// NodeKeySize-byte key.
+func NewEncryptedStorage(inner Storage, key []byte) (*EncryptedStorage, error) {
+	aead, err := nodeKeyAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &EncryptedStorage{Storage: inner, aead: aead}, nil
+}
+
+// LoadNodeKey reads an at-rest key from a file holding either the raw 32
+// bytes or their 64-character hex encoding.
+func LoadNodeKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to read node key file: %w", err)
+	}
+	if trimmed := strings.TrimSpace(string(data)); len(trimmed) == NodeKeySize*2 {
+		if key, err := hex.DecodeString(trimmed); err == nil {
+			return key, nil
+		}
+	}
+	if len(data) != NodeKeySize {
+		return nil, fmt.Errorf("storage: node key file %s holds %d bytes, want %d raw or %d hex",
+			path, len(data), NodeKeySize, NodeKeySize*2)
+	}
+	return data, nil
+}
+
+// nodeKeyAEAD builds the AES-GCM sealer for a node key.
+func nodeKeyAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != NodeKeySize {
+		return nil, fmt.Errorf("storage: node key must be %d bytes, got %d", NodeKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("storage: bad node key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext bound to id under the active key, returning
+// nonce||ciphertext.
+func (e *EncryptedStorage) seal(id string, plaintext []byte) ([]byte, error) {
+	e.mu.RLock()
+	aead := e.aead
+	e.mu.RUnlock()
+	return sealWith(aead, id, plaintext)
+}
+
+// open decrypts a nonce||ciphertext record bound to id under the active
+// key.
+func (e *EncryptedStorage) open(id string, record []byte) ([]byte, error) {
+	e.mu.RLock()
+	aead := e.aead
+	e.mu.RUnlock()
+	return openWith(aead, id, record)
+}
+
+// StoreChunk encrypts the chunk's data and stores it under the plaintext
+// chunk ID.
+func (e *EncryptedStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if chunk == nil {
+		return fmt.Errorf("storage: cannot store a nil chunk")
+	}
+	sealed, err := e.seal(chunk.ID, chunk.Data)
+	if err != nil {
+		return err
+	}
+	encrypted := *chunk
+	encrypted.Data = sealed
+	return e.Storage.StoreChunk(&encrypted)
+}
+
+// GetChunk retrieves and decrypts a chunk.
+func (e *EncryptedStorage) GetChunk(chunkID string) (*chunking.Chunk, error) {
+	stored, err := e.Storage.GetChunk(chunkID)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := e.open(chunkID, stored.Data)
+	if err != nil {
+		return nil, err
+	}
+	chunk := *stored
+	chunk.Data = plaintext
+	return &chunk, nil
+}
+
+// StoreManifest encrypts the manifest's JSON and stores it inside a
+// carrier manifest keeping only the ID, so listing and deletion by ID keep
+// working while chunk references and metadata stay sealed.
+func (e *EncryptedStorage) StoreManifest(manifest *chunking.Manifest) error {
+	if manifest == nil {
+		return fmt.Errorf("storage: cannot store a nil manifest")
+	}
+	data, err := manifest.ToJSON()
+	if err != nil {
+		return err
+	}
+	sealed, err := e.seal(manifest.ID, data)
+	if err != nil {
+		return err
+	}
+	return e.Storage.StoreManifest(&chunking.Manifest{
+		ID:          manifest.ID,
+		ContentID:   base64.StdEncoding.EncodeToString(sealed),
+		ContentType: encryptedManifestType,
+		CreatedAt:   manifest.CreatedAt,
+	})
+}
+
+// GetManifest retrieves and decrypts a manifest.
+func (e *EncryptedStorage) GetManifest(manifestID string) (*chunking.Manifest, error) {
+	carrier, err := e.Storage.GetManifest(manifestID)
+	if err != nil {
+		return nil, err
+	}
+	if carrier.ContentType != encryptedManifestType {
+		// Pre-encryption record written before the wrapper was introduced.
+		return carrier, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(carrier.ContentID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: manifest %s carrier is malformed", ErrDecryptionFailed, manifestID)
+	}
+	data, err := e.open(manifestID, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return chunking.ManifestFromJSON(data)
+}
+
+// ManifestsContainingChunk returns the sorted IDs of manifests referencing
+// the chunk. The inner store only sees carrier records without chunk lists,
+// so its index is blind; the wrapper decrypts each stored manifest and
+// checks its chunk list itself.
+func (e *EncryptedStorage) ManifestsContainingChunk(chunkID string) ([]string, error) {
+	manifestIDs, err := e.Storage.ListManifests()
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for _, manifestID := range manifestIDs {
+		manifest, err := e.GetManifest(manifestID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range manifest.ChunkIDs {
+			if id == chunkID {
+				ids = append(ids, manifestID)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// Rewrap eagerly re-encrypts every stored chunk and manifest under newKey
+// and switches the wrapper to it. The old key must still be the active one
+// when Rewrap is called; a record that fails to decrypt aborts the rotation
+// with the store partially rewrapped but every record still retrievable
+// under exactly one of the two keys.
+func (e *EncryptedStorage) Rewrap(newKey []byte) error {
+	newAEAD, err := nodeKeyAEAD(newKey)
+	if err != nil {
+		return err
+	}
+	chunkIDs, err := e.Storage.ListChunks()
+	if err != nil {
+		return err
+	}
+	manifestIDs, err := e.Storage.ListManifests()
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	oldAEAD := e.aead
+	for _, id := range chunkIDs {
+		stored, err := e.Storage.GetChunk(id)
+		if err != nil {
+			return err
+		}
+		plaintext, err := openWith(oldAEAD, id, stored.Data)
+		if err != nil {
+			return fmt.Errorf("storage: rewrap aborted at chunk %s: %w", id, err)
+		}
+		sealed, err := sealWith(newAEAD, id, plaintext)
+		if err != nil {
+			return err
+		}
+		rewrapped := *stored
+		rewrapped.Data = sealed
+		if err := e.Storage.StoreChunk(&rewrapped); err != nil {
+			return err
+		}
+	}
+	for _, id := range manifestIDs {
+		carrier, err := e.Storage.GetManifest(id)
+		if err != nil {
+			return err
+		}
+		if carrier.ContentType != encryptedManifestType {
+			continue
+		}
+		sealed, err := base64.StdEncoding.DecodeString(carrier.ContentID)
+		if err != nil {
+			return fmt.Errorf("%w: manifest %s carrier is malformed", ErrDecryptionFailed, id)
+		}
+		data, err := openWith(oldAEAD, id, sealed)
+		if err != nil {
+			return fmt.Errorf("storage: rewrap aborted at manifest %s: %w", id, err)
+		}
+		resealed, err := sealWith(newAEAD, id, data)
+		if err != nil {
+			return err
+		}
+		carrier.ContentID = base64.StdEncoding.EncodeToString(resealed)
+		if err := e.Storage.StoreManifest(carrier); err != nil {
+			return err
+		}
+	}
+	e.aead = newAEAD
+	return nil
+}
+
+// sealWith and openWith are seal/open against an explicit AEAD, used while
+// a rotation holds the key lock.
+func sealWith(aead cipher.AEAD, id string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("storage: failed to generate nonce: %w", err)
+	}
+	return append(nonce, aead.Seal(nil, nonce, plaintext, []byte(id))...), nil
+}
+
+func openWith(aead cipher.AEAD, id string, record []byte) ([]byte, error) {
+	if len(record) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: record for %s is shorter than a nonce", ErrDecryptionFailed, id)
+	}
+	plaintext, err := aead.Open(nil, record[:aead.NonceSize()], record[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecryptionFailed, id)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,206 @@
+package storage_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func testNodeKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, storage.NodeKeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	return key
+}
+
+func encryptedFixture(t *testing.T) (*storage.EncryptedStorage, storage.Storage, []byte) {
+	t.Helper()
+	inner := storage.NewInMemoryStorage()
+	key := testNodeKey(t)
+	store, err := storage.NewEncryptedStorage(inner, key)
+	if err != nil {
+		t.Fatalf("NewEncryptedStorage: %v", err)
+	}
+	return store, inner, key
+}
+
+func TestEncryptedStorage_DataAtRestIsNotPlaintext(t *testing.T) {
+	store, inner, _ := encryptedFixture(t)
+	plaintext := []byte("secret chunk payload that must not appear on disk")
+	chunk := &chunking.Chunk{ID: chunking.HashContent(plaintext), Data: plaintext}
+	if err := store.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+
+	raw, err := inner.GetChunk(chunk.ID)
+	if err != nil {
+		t.Fatalf("inner GetChunk: %v", err)
+	}
+	if bytes.Contains(raw.Data, plaintext) {
+		t.Error("underlying store holds the plaintext chunk data")
+	}
+
+	manifest := &chunking.Manifest{
+		ID:        "manifest-enc",
+		ContentID: chunking.HashContent(plaintext),
+		ChunkIDs:  []string{chunk.ID},
+		TotalSize: int64(len(plaintext)),
+	}
+	if err := store.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+	carrier, err := inner.GetManifest(manifest.ID)
+	if err != nil {
+		t.Fatalf("inner GetManifest: %v", err)
+	}
+	if len(carrier.ChunkIDs) != 0 || carrier.ContentID == manifest.ContentID {
+		t.Error("underlying store holds plaintext manifest fields")
+	}
+}
+
+func TestEncryptedStorage_RoundTrip(t *testing.T) {
+	store, _, _ := encryptedFixture(t)
+	plaintext := []byte("round-trippable content")
+	chunk := &chunking.Chunk{ID: chunking.HashContent(plaintext), Data: plaintext}
+	if err := store.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	got, err := store.GetChunk(chunk.ID)
+	if err != nil {
+		t.Fatalf("GetChunk: %v", err)
+	}
+	if !bytes.Equal(got.Data, plaintext) {
+		t.Error("decrypted chunk does not match plaintext")
+	}
+	if !chunking.VerifyData(got.Data, got.ID) {
+		t.Error("decrypted chunk fails its own digest check")
+	}
+
+	manifest := &chunking.Manifest{
+		ID:        "manifest-rt",
+		ContentID: chunk.ID,
+		ChunkIDs:  []string{chunk.ID},
+		TotalSize: int64(len(plaintext)),
+	}
+	if err := store.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+	gotManifest, err := store.GetManifest(manifest.ID)
+	if err != nil {
+		t.Fatalf("GetManifest: %v", err)
+	}
+	if gotManifest.ContentID != manifest.ContentID || len(gotManifest.ChunkIDs) != 1 {
+		t.Errorf("decrypted manifest lost fields: %+v", gotManifest)
+	}
+}
+
+func TestEncryptedStorage_WrongKeyFailsClearly(t *testing.T) {
+	inner := storage.NewInMemoryStorage()
+	store, err := storage.NewEncryptedStorage(inner, testNodeKey(t))
+	if err != nil {
+		t.Fatalf("NewEncryptedStorage: %v", err)
+	}
+	plaintext := []byte("written under the first key")
+	chunk := &chunking.Chunk{ID: chunking.HashContent(plaintext), Data: plaintext}
+	if err := store.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+
+	reopened, err := storage.NewEncryptedStorage(inner, testNodeKey(t))
+	if err != nil {
+		t.Fatalf("NewEncryptedStorage with second key: %v", err)
+	}
+	_, err = reopened.GetChunk(chunk.ID)
+	if !errors.Is(err, storage.ErrDecryptionFailed) {
+		t.Errorf("GetChunk under wrong key: got %v, want ErrDecryptionFailed", err)
+	}
+}
+
+func TestEncryptedStorage_RewrapKeepsContentRetrievable(t *testing.T) {
+	store, inner, _ := encryptedFixture(t)
+	plaintext := []byte("survives the key rotation")
+	chunk := &chunking.Chunk{ID: chunking.HashContent(plaintext), Data: plaintext}
+	if err := store.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	manifest := &chunking.Manifest{ID: "manifest-rot", ContentID: chunk.ID, ChunkIDs: []string{chunk.ID}}
+	if err := store.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+
+	newKey := testNodeKey(t)
+	if err := store.Rewrap(newKey); err != nil {
+		t.Fatalf("Rewrap: %v", err)
+	}
+	got, err := store.GetChunk(chunk.ID)
+	if err != nil {
+		t.Fatalf("GetChunk after rewrap: %v", err)
+	}
+	if !bytes.Equal(got.Data, plaintext) {
+		t.Error("chunk changed across rewrap")
+	}
+	if m, err := store.GetManifest(manifest.ID); err != nil || m.ContentID != chunk.ID {
+		t.Errorf("GetManifest after rewrap: %v, %v", m, err)
+	}
+
+	// A fresh wrapper on the new key reads everything; the old key cannot.
+	fresh, err := storage.NewEncryptedStorage(inner, newKey)
+	if err != nil {
+		t.Fatalf("NewEncryptedStorage: %v", err)
+	}
+	if _, err := fresh.GetChunk(chunk.ID); err != nil {
+		t.Errorf("GetChunk under rotated key: %v", err)
+	}
+}
+
+func TestLoadNodeKey_HexAndRaw(t *testing.T) {
+	dir := t.TempDir()
+	key := testNodeKey(t)
+
+	hexPath := filepath.Join(dir, "key.hex")
+	if err := os.WriteFile(hexPath, []byte(hex.EncodeToString(key)+"\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	got, err := storage.LoadNodeKey(hexPath)
+	if err != nil {
+		t.Fatalf("LoadNodeKey hex: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Error("hex-encoded key did not round-trip")
+	}
+
+	rawPath := filepath.Join(dir, "key.raw")
+	if err := os.WriteFile(rawPath, key, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	got, err = storage.LoadNodeKey(rawPath)
+	if err != nil {
+		t.Fatalf("LoadNodeKey raw: %v", err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Error("raw key did not round-trip")
+	}
+
+	badPath := filepath.Join(dir, "key.bad")
+	if err := os.WriteFile(badPath, []byte("too short"), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if _, err := storage.LoadNodeKey(badPath); err == nil {
+		t.Error("undersized key file was accepted")
+	}
+}
+
+func TestNewEncryptedStorage_RejectsBadKeySize(t *testing.T) {
+	if _, err := storage.NewEncryptedStorage(storage.NewInMemoryStorage(), []byte("short")); err == nil {
+		t.Error("undersized key was accepted")
+	}
+}
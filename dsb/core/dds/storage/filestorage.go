@@ -0,0 +1,407 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// FileStorage persists chunks and manifests as files under a root
+// directory: raw chunk bytes under root/chunks and manifest JSON under
+// root/manifests, each named by its ID. It is the backend nodes graduate
+// to when content must survive a restart.
+type FileStorage struct {
+	mu   sync.RWMutex
+	root string
+	// chunkRefs is the in-memory reverse index from chunk ID to the
+	// manifests referencing it, rebuilt from the manifest files on open
+	// and maintained on manifest store and delete.
+	chunkRefs map[string]map[string]struct{}
+}
+
+// NewFileStorage opens (creating if needed) a file store rooted at root.
+func NewFileStorage(root string) (*FileStorage, error) {
+	for _, dir := range []string{filepath.Join(root, "chunks"), filepath.Join(root, "manifests")} {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("storage: failed to create directory %s: %w", dir, err)
+		}
+	}
+	s := &FileStorage{root: root, chunkRefs: make(map[string]map[string]struct{})}
+	ids, err := s.listDir(filepath.Join(root, "manifests"))
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		data, err := os.ReadFile(s.manifestPath(id))
+		if err != nil {
+			return nil, fmt.Errorf("storage: failed to read manifest %s while indexing: %w", id, err)
+		}
+		manifest, err := chunking.ManifestFromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("storage: failed to index manifest %s: %w", id, err)
+		}
+		s.addChunkRefs(manifest)
+	}
+	return s, nil
+}
+
+// addChunkRefs records the manifest in the reverse index. Callers must
+// hold the write lock (or be the constructor).
+func (s *FileStorage) addChunkRefs(manifest *chunking.Manifest) {
+	for _, chunkID := range manifest.ChunkIDs {
+		refs, ok := s.chunkRefs[chunkID]
+		if !ok {
+			refs = make(map[string]struct{})
+			s.chunkRefs[chunkID] = refs
+		}
+		refs[manifest.ID] = struct{}{}
+	}
+}
+
+// dropChunkRefs removes the manifest from the reverse index. Callers must
+// hold the write lock.
+func (s *FileStorage) dropChunkRefs(manifest *chunking.Manifest) {
+	for _, chunkID := range manifest.ChunkIDs {
+		if refs, ok := s.chunkRefs[chunkID]; ok {
+			delete(refs, manifest.ID)
+			if len(refs) == 0 {
+				delete(s.chunkRefs, chunkID)
+			}
+		}
+	}
+}
+
+// readManifestLocked loads a stored manifest without taking the lock,
+// returning nil when the file does not exist.
+func (s *FileStorage) readManifestLocked(manifestID string) (*chunking.Manifest, error) {
+	data, err := os.ReadFile(s.manifestPath(manifestID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("storage: failed to read manifest %s: %w", manifestID, err)
+	}
+	return chunking.ManifestFromJSON(data)
+}
+
+// validID rejects IDs that could escape the storage directories. Real IDs
+// are hex digests; anything with path metacharacters is malformed.
+func validID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.Contains(id, "..") {
+		return fmt.Errorf("storage: invalid content ID %q", id)
+	}
+	return nil
+}
+
+func (s *FileStorage) chunkPath(id string) string {
+	return filepath.Join(s.root, "chunks", id)
+}
+
+func (s *FileStorage) manifestPath(id string) string {
+	return filepath.Join(s.root, "manifests", id)
+}
+
+// StoreChunk writes the chunk's bytes, overwriting any existing file.
+func (s *FileStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if chunk == nil || chunk.ID == "" {
+		return fmt.Errorf("storage: cannot store chunk without an ID")
+	}
+	if err := validID(chunk.ID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.WriteFile(s.chunkPath(chunk.ID), chunk.Data, 0o600); err != nil {
+		return fmt.Errorf("storage: failed to write chunk %s: %w", chunk.ID, err)
+	}
+	return nil
+}
+
+// GetChunk reads the stored chunk or returns ErrChunkNotFound.
+func (s *FileStorage) GetChunk(chunkID string) (*chunking.Chunk, error) {
+	if err := validID(chunkID); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, err := os.ReadFile(s.chunkPath(chunkID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrChunkNotFound, chunkID)
+		}
+		return nil, fmt.Errorf("storage: failed to read chunk %s: %w", chunkID, err)
+	}
+	return &chunking.Chunk{ID: chunkID, Data: data}, nil
+}
+
+// HasChunk reports whether the chunk file exists.
+func (s *FileStorage) HasChunk(chunkID string) (bool, error) {
+	if err := validID(chunkID); err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, err := os.Stat(s.chunkPath(chunkID))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("storage: failed to stat chunk %s: %w", chunkID, err)
+	}
+	return true, nil
+}
+
+// DeleteChunk removes the chunk file. Deleting a missing chunk is not an
+// error.
+func (s *FileStorage) DeleteChunk(chunkID string) error {
+	if err := validID(chunkID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Remove(s.chunkPath(chunkID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete chunk %s: %w", chunkID, err)
+	}
+	return nil
+}
+
+// StoreManifest writes the manifest's JSON, overwriting any existing file.
+func (s *FileStorage) StoreManifest(manifest *chunking.Manifest) error {
+	if manifest == nil || manifest.ID == "" {
+		return fmt.Errorf("storage: cannot store manifest without an ID")
+	}
+	if err := validID(manifest.ID); err != nil {
+		return err
+	}
+	data, err := manifest.ToJSON()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.readManifestLocked(manifest.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.manifestPath(manifest.ID), data, 0o600); err != nil {
+		return fmt.Errorf("storage: failed to write manifest %s: %w", manifest.ID, err)
+	}
+	if old != nil {
+		s.dropChunkRefs(old)
+	}
+	s.addChunkRefs(manifest)
+	return nil
+}
+
+// GetManifest reads the stored manifest or returns ErrManifestNotFound.
+func (s *FileStorage) GetManifest(manifestID string) (*chunking.Manifest, error) {
+	if err := validID(manifestID); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, err := os.ReadFile(s.manifestPath(manifestID))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrManifestNotFound, manifestID)
+		}
+		return nil, fmt.Errorf("storage: failed to read manifest %s: %w", manifestID, err)
+	}
+	return chunking.ManifestFromJSON(data)
+}
+
+// HasManifest reports whether the manifest file exists.
+func (s *FileStorage) HasManifest(manifestID string) (bool, error) {
+	if err := validID(manifestID); err != nil {
+		return false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, err := os.Stat(s.manifestPath(manifestID))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("storage: failed to stat manifest %s: %w", manifestID, err)
+	}
+	return true, nil
+}
+
+// DeleteManifest removes the manifest file. Deleting a missing manifest is
+// not an error.
+func (s *FileStorage) DeleteManifest(manifestID string) error {
+	if err := validID(manifestID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old, err := s.readManifestLocked(manifestID)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(s.manifestPath(manifestID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("storage: failed to delete manifest %s: %w", manifestID, err)
+	}
+	if old != nil {
+		s.dropChunkRefs(old)
+	}
+	return nil
+}
+
+// ManifestsContainingChunk returns the sorted IDs of manifests referencing
+// the chunk, answered from the in-memory reverse index.
+func (s *FileStorage) ManifestsContainingChunk(chunkID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.chunkRefs[chunkID]))
+	for id := range s.chunkRefs[chunkID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// listDir returns the sorted file names under one of the store's
+// directories.
+func (s *FileStorage) listDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("storage: failed to list %s: %w", dir, err)
+	}
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ids = append(ids, entry.Name())
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListChunks returns the stored chunk IDs in sorted order.
+func (s *FileStorage) ListChunks() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listDir(filepath.Join(s.root, "chunks"))
+}
+
+// ListManifests returns the stored manifest IDs in sorted order.
+func (s *FileStorage) ListManifests() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.listDir(filepath.Join(s.root, "manifests"))
+}
+
+// scanDir pages through a directory's sorted file names, returning the
+// page after cursor and whether more names remain. Only the page's files
+// are touched afterwards, never the whole store.
+func (s *FileStorage) scanDir(dir, cursor string, limit int) ([]string, bool, error) {
+	ids, err := s.listDir(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	start := sort.SearchStrings(ids, cursor)
+	if start < len(ids) && ids[start] == cursor {
+		start++
+	}
+	ids = ids[start:]
+	more := len(ids) > limit
+	if more {
+		ids = ids[:limit]
+	}
+	return ids, more, nil
+}
+
+// ScanChunks pages through stored chunks in ID order, stat-ing only the
+// returned page.
+func (s *FileStorage) ScanChunks(cursor string, limit int) ([]ChunkInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids, more, err := s.scanDir(filepath.Join(s.root, "chunks"), cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	infos := make([]ChunkInfo, len(ids))
+	for i, id := range ids {
+		info, err := os.Stat(s.chunkPath(id))
+		if err != nil {
+			return nil, "", fmt.Errorf("storage: failed to stat chunk %s: %w", id, err)
+		}
+		infos[i] = ChunkInfo{ID: id, Size: info.Size()}
+	}
+	next := ""
+	if more {
+		next = ids[len(ids)-1]
+	}
+	return infos, next, nil
+}
+
+// ScanManifests pages through stored manifests in ID order, reading only
+// the returned page's files.
+func (s *FileStorage) ScanManifests(cursor string, limit int) ([]ManifestInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids, more, err := s.scanDir(filepath.Join(s.root, "manifests"), cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+	infos := make([]ManifestInfo, len(ids))
+	for i, id := range ids {
+		data, err := os.ReadFile(s.manifestPath(id))
+		if err != nil {
+			return nil, "", fmt.Errorf("storage: failed to read manifest %s: %w", id, err)
+		}
+		manifest, err := chunking.ManifestFromJSON(data)
+		if err != nil {
+			return nil, "", err
+		}
+		infos[i] = ManifestInfo{ID: id, Size: int64(len(data)), CreatedAt: manifest.CreatedAt}
+	}
+	next := ""
+	if more {
+		next = ids[len(ids)-1]
+	}
+	return infos, next, nil
+}
+
+// Usage reports what the store currently holds, summing file sizes.
+func (s *FileStorage) Usage() (StorageUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var usage StorageUsage
+	for _, dir := range []string{filepath.Join(s.root, "chunks"), filepath.Join(s.root, "manifests")} {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return StorageUsage{}, fmt.Errorf("storage: failed to list %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return StorageUsage{}, fmt.Errorf("storage: failed to stat %s: %w", entry.Name(), err)
+			}
+			usage.TotalBytes += info.Size()
+			if dir == filepath.Join(s.root, "chunks") {
+				usage.ChunkCount++
+			} else {
+				usage.ManifestCount++
+			}
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,258 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// InMemoryStorage keeps chunks and manifests in process memory. It is the
+// default backend for tests and demos; content does not survive a restart.
+type InMemoryStorage struct {
+	mu        sync.RWMutex
+	chunks    map[string]*chunking.Chunk
+	manifests map[string]*chunking.Manifest
+	// chunkRefs is the reverse index from chunk ID to the manifests
+	// referencing it, maintained on manifest store and delete.
+	chunkRefs map[string]map[string]struct{}
+}
+
+// NewInMemoryStorage returns an empty in-memory store.
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{
+		chunks:    make(map[string]*chunking.Chunk),
+		manifests: make(map[string]*chunking.Manifest),
+		chunkRefs: make(map[string]map[string]struct{}),
+	}
+}
+
+// StoreChunk stores chunk, overwriting any existing chunk with the same ID.
+func (s *InMemoryStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if chunk == nil || chunk.ID == "" {
+		return fmt.Errorf("storage: cannot store chunk without an ID")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks[chunk.ID] = chunk
+	return nil
+}
+
+// GetChunk returns the stored chunk or ErrChunkNotFound.
+func (s *InMemoryStorage) GetChunk(chunkID string) (*chunking.Chunk, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	chunk, ok := s.chunks[chunkID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChunkNotFound, chunkID)
+	}
+	return chunk, nil
+}
+
+// HasChunk reports whether the chunk is stored.
+func (s *InMemoryStorage) HasChunk(chunkID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.chunks[chunkID]
+	return ok, nil
+}
+
+// DeleteChunk removes the chunk if present. Deleting a missing chunk is not
+// an error.
+func (s *InMemoryStorage) DeleteChunk(chunkID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chunks, chunkID)
+	return nil
+}
+
+// StoreManifest stores manifest, overwriting any existing manifest with the
+// same ID.
+func (s *InMemoryStorage) StoreManifest(manifest *chunking.Manifest) error {
+	if manifest == nil || manifest.ID == "" {
+		return fmt.Errorf("storage: cannot store manifest without an ID")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.manifests[manifest.ID]; ok {
+		s.dropChunkRefs(old)
+	}
+	s.manifests[manifest.ID] = manifest
+	for _, chunkID := range manifest.ChunkIDs {
+		refs, ok := s.chunkRefs[chunkID]
+		if !ok {
+			refs = make(map[string]struct{})
+			s.chunkRefs[chunkID] = refs
+		}
+		refs[manifest.ID] = struct{}{}
+	}
+	return nil
+}
+
+// dropChunkRefs removes the manifest from the reverse index. Callers must
+// hold the write lock.
+func (s *InMemoryStorage) dropChunkRefs(manifest *chunking.Manifest) {
+	for _, chunkID := range manifest.ChunkIDs {
+		if refs, ok := s.chunkRefs[chunkID]; ok {
+			delete(refs, manifest.ID)
+			if len(refs) == 0 {
+				delete(s.chunkRefs, chunkID)
+			}
+		}
+	}
+}
+
+// GetManifest returns the stored manifest or ErrManifestNotFound.
+func (s *InMemoryStorage) GetManifest(manifestID string) (*chunking.Manifest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	manifest, ok := s.manifests[manifestID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrManifestNotFound, manifestID)
+	}
+	return manifest, nil
+}
+
+// HasManifest reports whether the manifest is stored.
+func (s *InMemoryStorage) HasManifest(manifestID string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.manifests[manifestID]
+	return ok, nil
+}
+
+// DeleteManifest removes the manifest if present. Deleting a missing
+// manifest is not an error.
+func (s *InMemoryStorage) DeleteManifest(manifestID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if old, ok := s.manifests[manifestID]; ok {
+		s.dropChunkRefs(old)
+	}
+	delete(s.manifests, manifestID)
+	return nil
+}
+
+// ManifestsContainingChunk returns the sorted IDs of manifests referencing
+// the chunk, answered from the reverse index.
+func (s *InMemoryStorage) ManifestsContainingChunk(chunkID string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.chunkRefs[chunkID]))
+	for id := range s.chunkRefs[chunkID] {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListChunks returns the stored chunk IDs in sorted order.
+func (s *InMemoryStorage) ListChunks() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.chunks))
+	for id := range s.chunks {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ListManifests returns the stored manifest IDs in sorted order.
+func (s *InMemoryStorage) ListManifests() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.manifests))
+	for id := range s.manifests {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ScanChunks pages through stored chunks in ID order.
+func (s *InMemoryStorage) ScanChunks(cursor string, limit int) ([]ChunkInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.chunks))
+	for id := range s.chunks {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	more := len(ids) > limit
+	if more {
+		ids = ids[:limit]
+	}
+	infos := make([]ChunkInfo, len(ids))
+	for i, id := range ids {
+		infos[i] = ChunkInfo{ID: id, Size: int64(len(s.chunks[id].Data))}
+	}
+	next := ""
+	if more {
+		next = ids[len(ids)-1]
+	}
+	return infos, next, nil
+}
+
+// ScanManifests pages through stored manifests in ID order. Sizes are the
+// manifests' serialized JSON lengths.
+func (s *InMemoryStorage) ScanManifests(cursor string, limit int) ([]ManifestInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := make([]string, 0, len(s.manifests))
+	for id := range s.manifests {
+		if id > cursor {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	more := len(ids) > limit
+	if more {
+		ids = ids[:limit]
+	}
+	infos := make([]ManifestInfo, len(ids))
+	for i, id := range ids {
+		manifest := s.manifests[id]
+		data, err := manifest.ToJSON()
+		if err != nil {
+			return nil, "", err
+		}
+		infos[i] = ManifestInfo{ID: id, Size: int64(len(data)), CreatedAt: manifest.CreatedAt}
+	}
+	next := ""
+	if more {
+		next = ids[len(ids)-1]
+	}
+	return infos, next, nil
+}
+
+// Usage reports what the store currently holds. Manifest sizes are their
+// serialized JSON lengths.
+func (s *InMemoryStorage) Usage() (StorageUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	usage := StorageUsage{
+		ChunkCount:    len(s.chunks),
+		ManifestCount: len(s.manifests),
+	}
+	for _, chunk := range s.chunks {
+		usage.TotalBytes += int64(len(chunk.Data))
+	}
+	for _, manifest := range s.manifests {
+		data, err := manifest.ToJSON()
+		if err != nil {
+			return StorageUsage{}, err
+		}
+		usage.TotalBytes += int64(len(data))
+	}
+	return usage, nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// Migrate copies every chunk and manifest from src to dst, verifying each
+// chunk's digest during the copy. It is how a node graduates from an
+// in-memory store to a persistent one. The first corrupt chunk aborts the
+// migration with an error; dst may then hold a partial copy, but src is
+// never modified, so the migration can be retried after repairing src.
+func Migrate(src, dst Storage) error {
+	chunkIDs, err := src.ListChunks()
+	if err != nil {
+		return fmt.Errorf("storage: migration failed to list source chunks: %w", err)
+	}
+	for _, id := range chunkIDs {
+		chunk, err := src.GetChunk(id)
+		if err != nil {
+			return fmt.Errorf("storage: migration failed to read chunk %s: %w", id, err)
+		}
+		if !chunking.VerifyData(chunk.Data, id) {
+			return fmt.Errorf("storage: migration aborted: chunk %s failed integrity check (got digest %s)",
+				id, chunking.HashContent(chunk.Data))
+		}
+		if err := dst.StoreChunk(chunk); err != nil {
+			return fmt.Errorf("storage: migration failed to write chunk %s: %w", id, err)
+		}
+	}
+
+	manifestIDs, err := src.ListManifests()
+	if err != nil {
+		return fmt.Errorf("storage: migration failed to list source manifests: %w", err)
+	}
+	for _, id := range manifestIDs {
+		manifest, err := src.GetManifest(id)
+		if err != nil {
+			return fmt.Errorf("storage: migration failed to read manifest %s: %w", id, err)
+		}
+		if err := dst.StoreManifest(manifest); err != nil {
+			return fmt.Errorf("storage: migration failed to write manifest %s: %w", id, err)
+		}
+	}
+	return nil
+}
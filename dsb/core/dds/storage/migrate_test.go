@@ -0,0 +1,110 @@
+package storage_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// populate stores n pieces of chunked content in src and returns the
+// manifest IDs.
+func populate(t *testing.T, src storage.Storage, n int) []string {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(16)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	manifestIDs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		content := []byte(fmt.Sprintf("migration fixture content number %d, long enough to chunk", i))
+		chunks, err := chunker.ChunkContent(content)
+		if err != nil {
+			t.Fatalf("ChunkContent: %v", err)
+		}
+		for j := range chunks {
+			if err := src.StoreChunk(&chunks[j]); err != nil {
+				t.Fatalf("StoreChunk: %v", err)
+			}
+		}
+		manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+		if err != nil {
+			t.Fatalf("GenerateManifest: %v", err)
+		}
+		if err := src.StoreManifest(manifest); err != nil {
+			t.Fatalf("StoreManifest: %v", err)
+		}
+		manifestIDs = append(manifestIDs, manifest.ID)
+	}
+	return manifestIDs
+}
+
+func TestMigrate_InMemoryToFileReproducesEverything(t *testing.T) {
+	src := storage.NewInMemoryStorage()
+	manifestIDs := populate(t, src, 3)
+	dst, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStorage: %v", err)
+	}
+
+	if err := storage.Migrate(src, dst); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+
+	chunkIDs, err := src.ListChunks()
+	if err != nil {
+		t.Fatalf("ListChunks: %v", err)
+	}
+	for _, id := range chunkIDs {
+		want, err := src.GetChunk(id)
+		if err != nil {
+			t.Fatalf("GetChunk(src): %v", err)
+		}
+		got, err := dst.GetChunk(id)
+		if err != nil {
+			t.Fatalf("migrated chunk %s missing: %v", id, err)
+		}
+		if !bytes.Equal(got.Data, want.Data) {
+			t.Errorf("migrated chunk %s data differs", id)
+		}
+	}
+	for _, id := range manifestIDs {
+		got, err := dst.GetManifest(id)
+		if err != nil {
+			t.Fatalf("migrated manifest %s missing: %v", id, err)
+		}
+		if got.ID != id {
+			t.Errorf("migrated manifest ID = %s, want %s", got.ID, id)
+		}
+	}
+
+	srcUsage, _ := src.Usage()
+	dstUsage, _ := dst.Usage()
+	if srcUsage.ChunkCount != dstUsage.ChunkCount || srcUsage.ManifestCount != dstUsage.ManifestCount {
+		t.Errorf("usage after migration = %+v, want counts matching source %+v", dstUsage, srcUsage)
+	}
+}
+
+func TestMigrate_CorruptChunkAborts(t *testing.T) {
+	src := storage.NewInMemoryStorage()
+	populate(t, src, 1)
+	// A chunk whose ID does not match its data, as bit rot would produce.
+	if err := src.StoreChunk(&chunking.Chunk{ID: chunking.HashContent([]byte("original")), Data: []byte("tampered")}); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	dst, err := storage.NewFileStorage(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStorage: %v", err)
+	}
+
+	err = storage.Migrate(src, dst)
+	if err == nil {
+		t.Fatal("migration with a corrupt chunk did not error")
+	}
+	if !strings.Contains(err.Error(), "integrity") {
+		t.Errorf("error does not mention integrity: %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// EvictFunc is invoked by QuotaStorage when a store would exceed the
+// budget, giving the operator a chance to free space (LRU eviction,
+// unpinning) before the store is retried. needed is the number of bytes
+// the pending store requires.
+type EvictFunc func(needed int64) error
+
+// QuotaStorage wraps a Storage with a byte budget. Stores that would push
+// usage past the budget fail with ErrQuotaExceeded after the optional
+// eviction callback has had one chance to make room.
+type QuotaStorage struct {
+	Storage
+	budget int64
+	evict  EvictFunc
+}
+
+// NewQuotaStorage wraps inner with a byte budget. evict may be nil.
+func NewQuotaStorage(inner Storage, budget int64, evict EvictFunc) *QuotaStorage {
+	return &QuotaStorage{Storage: inner, budget: budget, evict: evict}
+}
+
+// ensureRoom checks the budget for a pending store of size bytes, invoking
+// the eviction callback once if needed.
+func (q *QuotaStorage) ensureRoom(size int64) error {
+	usage, err := q.Storage.Usage()
+	if err != nil {
+		return err
+	}
+	if usage.TotalBytes+size <= q.budget {
+		return nil
+	}
+	if q.evict != nil {
+		if err := q.evict(size); err != nil {
+			return fmt.Errorf("storage: eviction failed: %w", err)
+		}
+		usage, err = q.Storage.Usage()
+		if err != nil {
+			return err
+		}
+		if usage.TotalBytes+size <= q.budget {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d bytes stored, %d requested, budget %d",
+		ErrQuotaExceeded, usage.TotalBytes, size, q.budget)
+}
+
+// StoreChunk stores the chunk if the budget allows it.
+func (q *QuotaStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if chunk == nil {
+		return fmt.Errorf("storage: cannot store a nil chunk")
+	}
+	if err := q.ensureRoom(int64(len(chunk.Data))); err != nil {
+		return err
+	}
+	return q.Storage.StoreChunk(chunk)
+}
+
+// StoreManifest stores the manifest if the budget allows it.
+func (q *QuotaStorage) StoreManifest(manifest *chunking.Manifest) error {
+	if manifest == nil {
+		return fmt.Errorf("storage: cannot store a nil manifest")
+	}
+	data, err := manifest.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := q.ensureRoom(int64(len(data))); err != nil {
+		return err
+	}
+	return q.Storage.StoreManifest(manifest)
+}
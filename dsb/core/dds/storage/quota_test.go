@@ -0,0 +1,72 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+func chunkOf(data string) *chunking.Chunk {
+	return &chunking.Chunk{ID: chunking.HashContent([]byte(data)), Data: []byte(data)}
+}
+
+func TestUsage_AccurateAfterDeletes(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	a := chunkOf("aaaa")
+	b := chunkOf("bbbbbbbb")
+	for _, c := range []*chunking.Chunk{a, b} {
+		if err := store.StoreChunk(c); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+	}
+	usage, err := store.Usage()
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if usage.ChunkCount != 2 || usage.TotalBytes != 12 {
+		t.Errorf("usage = %+v, want 2 chunks / 12 bytes", usage)
+	}
+
+	if err := store.DeleteChunk(a.ID); err != nil {
+		t.Fatalf("DeleteChunk: %v", err)
+	}
+	usage, err = store.Usage()
+	if err != nil {
+		t.Fatalf("Usage after delete: %v", err)
+	}
+	if usage.ChunkCount != 1 || usage.TotalBytes != 8 {
+		t.Errorf("usage after delete = %+v, want 1 chunk / 8 bytes", usage)
+	}
+}
+
+func TestQuotaStorage_RejectsOverBudget(t *testing.T) {
+	store := storage.NewQuotaStorage(storage.NewInMemoryStorage(), 10, nil)
+	if err := store.StoreChunk(chunkOf("12345678")); err != nil {
+		t.Fatalf("StoreChunk within budget: %v", err)
+	}
+	err := store.StoreChunk(chunkOf("overflow"))
+	if !errors.Is(err, storage.ErrQuotaExceeded) {
+		t.Fatalf("want ErrQuotaExceeded, got %v", err)
+	}
+}
+
+func TestQuotaStorage_EvictionMakesRoom(t *testing.T) {
+	inner := storage.NewInMemoryStorage()
+	victim := chunkOf("12345678")
+	var evicted bool
+	store := storage.NewQuotaStorage(inner, 10, func(needed int64) error {
+		evicted = true
+		return inner.DeleteChunk(victim.ID)
+	})
+	if err := store.StoreChunk(victim); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	if err := store.StoreChunk(chunkOf("newchunk")); err != nil {
+		t.Fatalf("StoreChunk after eviction: %v", err)
+	}
+	if !evicted {
+		t.Error("eviction callback was not invoked")
+	}
+}
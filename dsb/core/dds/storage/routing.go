@@ -0,0 +1,354 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// Classifier picks the named backend that should hold a manifest's chunks,
+// based on anything the manifest carries — ContentType and TotalSize being
+// the usual inputs. Returning an empty string selects the default backend.
+type Classifier func(manifest *chunking.Manifest) string
+
+// RoutingStorage dispatches content across multiple named Storage backends
+// — e.g. images on a large-object store, small JSON profiles in memory — as
+// decided by a classifier at manifest-store time. Chunks arrive before
+// their manifest and land in the default backend; StoreManifest classifies
+// the content, migrates its chunks to the chosen backend, and records that
+// backend on the manifest so later gets route directly. Reads for content
+// the router has not seen (e.g. after a restart) fall back to probing every
+// backend and re-learn chunk locations from stored manifests.
+type RoutingStorage struct {
+	classifier  Classifier
+	defaultName string
+	backends    map[string]Storage
+
+	mu sync.RWMutex
+	// chunkHome maps chunk IDs to the name of the backend holding them,
+	// learned from manifest stores, loads, and probe hits.
+	chunkHome map[string]string
+}
+
+// NewRoutingStorage builds a router over the named backends. defaultName
+// must name one of them; classifier may be nil, in which case everything
+// stays on the default backend.
+func NewRoutingStorage(classifier Classifier, defaultName string, backends map[string]Storage) (*RoutingStorage, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("storage: routing requires at least one backend")
+	}
+	if _, ok := backends[defaultName]; !ok {
+		return nil, fmt.Errorf("storage: default backend %q is not among the configured backends", defaultName)
+	}
+	return &RoutingStorage{
+		classifier:  classifier,
+		defaultName: defaultName,
+		backends:    backends,
+		chunkHome:   make(map[string]string),
+	}, nil
+}
+
+// classify resolves the backend for a manifest, falling back to the
+// default for an empty classification and rejecting unknown names.
+func (r *RoutingStorage) classify(manifest *chunking.Manifest) (string, error) {
+	name := r.defaultName
+	if r.classifier != nil {
+		if picked := r.classifier(manifest); picked != "" {
+			name = picked
+		}
+	}
+	if _, ok := r.backends[name]; !ok {
+		return "", fmt.Errorf("storage: classifier routed manifest %s to unknown backend %q", manifest.ID, name)
+	}
+	return name, nil
+}
+
+// home returns the backend currently believed to hold the chunk, or the
+// default when the chunk is unknown.
+func (r *RoutingStorage) home(chunkID string) Storage {
+	r.mu.RLock()
+	name, ok := r.chunkHome[chunkID]
+	r.mu.RUnlock()
+	if !ok {
+		name = r.defaultName
+	}
+	return r.backends[name]
+}
+
+// StoreChunk writes the chunk to its known backend, or to the default when
+// no manifest has claimed it yet.
+func (r *RoutingStorage) StoreChunk(chunk *chunking.Chunk) error {
+	if chunk == nil {
+		return fmt.Errorf("storage: cannot store a nil chunk")
+	}
+	return r.home(chunk.ID).StoreChunk(chunk)
+}
+
+// GetChunk reads the chunk from its known backend, probing every backend
+// when the router has no record of it.
+func (r *RoutingStorage) GetChunk(chunkID string) (*chunking.Chunk, error) {
+	r.mu.RLock()
+	name, known := r.chunkHome[chunkID]
+	r.mu.RUnlock()
+	if known {
+		return r.backends[name].GetChunk(chunkID)
+	}
+	for name, backend := range r.backends {
+		chunk, err := backend.GetChunk(chunkID)
+		if err == nil {
+			r.mu.Lock()
+			r.chunkHome[chunkID] = name
+			r.mu.Unlock()
+			return chunk, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrChunkNotFound, chunkID)
+}
+
+// HasChunk reports whether any backend holds the chunk.
+func (r *RoutingStorage) HasChunk(chunkID string) (bool, error) {
+	for _, backend := range r.backends {
+		has, err := backend.HasChunk(chunkID)
+		if err != nil {
+			return false, err
+		}
+		if has {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteChunk removes the chunk from every backend holding it.
+func (r *RoutingStorage) DeleteChunk(chunkID string) error {
+	for _, backend := range r.backends {
+		if err := backend.DeleteChunk(chunkID); err != nil {
+			return err
+		}
+	}
+	r.mu.Lock()
+	delete(r.chunkHome, chunkID)
+	r.mu.Unlock()
+	return nil
+}
+
+// StoreManifest classifies the content, moves its chunks onto the chosen
+// backend, records that backend on the manifest, and stores the manifest
+// alongside its chunks.
+func (r *RoutingStorage) StoreManifest(manifest *chunking.Manifest) error {
+	if manifest == nil {
+		return fmt.Errorf("storage: cannot store a nil manifest")
+	}
+	name, err := r.classify(manifest)
+	if err != nil {
+		return err
+	}
+	target := r.backends[name]
+	for _, chunkID := range manifest.ChunkIDs {
+		if err := r.migrateChunk(chunkID, name, target); err != nil {
+			return err
+		}
+	}
+	manifest.StorageBackend = name
+	return target.StoreManifest(manifest)
+}
+
+// migrateChunk moves one chunk onto the target backend (a no-op when it is
+// already there or not stored at all) and records its new home.
+func (r *RoutingStorage) migrateChunk(chunkID, targetName string, target Storage) error {
+	for name, backend := range r.backends {
+		if name == targetName {
+			continue
+		}
+		chunk, err := backend.GetChunk(chunkID)
+		if err != nil {
+			continue
+		}
+		if err := target.StoreChunk(chunk); err != nil {
+			return fmt.Errorf("storage: failed to migrate chunk %s to backend %q: %w", chunkID, targetName, err)
+		}
+		if err := backend.DeleteChunk(chunkID); err != nil {
+			return fmt.Errorf("storage: failed to remove migrated chunk %s from backend %q: %w", chunkID, name, err)
+		}
+	}
+	r.mu.Lock()
+	r.chunkHome[chunkID] = targetName
+	r.mu.Unlock()
+	return nil
+}
+
+// GetManifest probes the backends for the manifest and re-learns its
+// chunks' home from the recorded backend name.
+func (r *RoutingStorage) GetManifest(manifestID string) (*chunking.Manifest, error) {
+	for _, backend := range r.backends {
+		manifest, err := backend.GetManifest(manifestID)
+		if err != nil {
+			continue
+		}
+		if _, ok := r.backends[manifest.StorageBackend]; ok {
+			r.mu.Lock()
+			for _, chunkID := range manifest.ChunkIDs {
+				r.chunkHome[chunkID] = manifest.StorageBackend
+			}
+			r.mu.Unlock()
+		}
+		return manifest, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrManifestNotFound, manifestID)
+}
+
+// HasManifest reports whether any backend holds the manifest.
+func (r *RoutingStorage) HasManifest(manifestID string) (bool, error) {
+	for _, backend := range r.backends {
+		has, err := backend.HasManifest(manifestID)
+		if err != nil {
+			return false, err
+		}
+		if has {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteManifest removes the manifest from every backend holding it.
+func (r *RoutingStorage) DeleteManifest(manifestID string) error {
+	for _, backend := range r.backends {
+		if err := backend.DeleteManifest(manifestID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListChunks returns the union of every backend's chunk IDs in sorted
+// order.
+func (r *RoutingStorage) ListChunks() ([]string, error) {
+	return r.listAll(Storage.ListChunks)
+}
+
+// ListManifests returns the union of every backend's manifest IDs in
+// sorted order.
+func (r *RoutingStorage) ListManifests() ([]string, error) {
+	return r.listAll(Storage.ListManifests)
+}
+
+// ManifestsContainingChunk returns the union of every backend's answers in
+// sorted order; a manifest lives on exactly one backend, but the chunk may
+// be referenced from several.
+func (r *RoutingStorage) ManifestsContainingChunk(chunkID string) ([]string, error) {
+	return r.listAll(func(backend Storage) ([]string, error) {
+		return backend.ManifestsContainingChunk(chunkID)
+	})
+}
+
+func (r *RoutingStorage) listAll(list func(Storage) ([]string, error)) ([]string, error) {
+	seen := make(map[string]bool)
+	ids := []string{}
+	for _, backend := range r.backends {
+		backendIDs, err := list(backend)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range backendIDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ScanChunks merges one page from every backend in ID order. Each call
+// asks every backend for at most limit entries, so large stores are never
+// materialized; a chunk duplicated across backends is reported once.
+func (r *RoutingStorage) ScanChunks(cursor string, limit int) ([]ChunkInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	merged := []ChunkInfo{}
+	seen := make(map[string]bool)
+	more := false
+	for _, backend := range r.backends {
+		page, next, err := backend.ScanChunks(cursor, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		if next != "" {
+			more = true
+		}
+		for _, info := range page {
+			if !seen[info.ID] {
+				seen[info.ID] = true
+				merged = append(merged, info)
+			}
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ID < merged[j].ID })
+	if len(merged) > limit {
+		// Truncated entries are past the returned cursor, so the next call
+		// re-fetches them from their backends.
+		merged = merged[:limit]
+		more = true
+	}
+	next := ""
+	if more && len(merged) > 0 {
+		next = merged[len(merged)-1].ID
+	}
+	return merged, next, nil
+}
+
+// ScanManifests merges one page from every backend in ID order, under the
+// same bounds as ScanChunks.
+func (r *RoutingStorage) ScanManifests(cursor string, limit int) ([]ManifestInfo, string, error) {
+	if err := checkScanLimit(limit); err != nil {
+		return nil, "", err
+	}
+	merged := []ManifestInfo{}
+	seen := make(map[string]bool)
+	more := false
+	for _, backend := range r.backends {
+		page, next, err := backend.ScanManifests(cursor, limit)
+		if err != nil {
+			return nil, "", err
+		}
+		if next != "" {
+			more = true
+		}
+		for _, info := range page {
+			if !seen[info.ID] {
+				seen[info.ID] = true
+				merged = append(merged, info)
+			}
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ID < merged[j].ID })
+	if len(merged) > limit {
+		merged = merged[:limit]
+		more = true
+	}
+	next := ""
+	if more && len(merged) > 0 {
+		next = merged[len(merged)-1].ID
+	}
+	return merged, next, nil
+}
+
+// Usage sums usage across every backend.
+func (r *RoutingStorage) Usage() (StorageUsage, error) {
+	var total StorageUsage
+	for _, backend := range r.backends {
+		usage, err := backend.Usage()
+		if err != nil {
+			return StorageUsage{}, err
+		}
+		total.ChunkCount += usage.ChunkCount
+		total.ManifestCount += usage.ManifestCount
+		total.TotalBytes += usage.TotalBytes
+	}
+	return total, nil
+}
@@ -0,0 +1,174 @@
+package storage_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// routingFixture routes JSON manifests to one in-memory backend and
+// everything else to another, mirroring the service's store order (chunks
+// first, then the manifest).
+type routingFixture struct {
+	router *storage.RoutingStorage
+	json   *storage.InMemoryStorage
+	blob   *storage.InMemoryStorage
+}
+
+func newRoutingFixture(t *testing.T) *routingFixture {
+	t.Helper()
+	f := &routingFixture{
+		json: storage.NewInMemoryStorage(),
+		blob: storage.NewInMemoryStorage(),
+	}
+	classifier := func(m *chunking.Manifest) string {
+		if m.ContentType == "application/json" {
+			return "json"
+		}
+		return "blob"
+	}
+	router, err := storage.NewRoutingStorage(classifier, "blob", map[string]storage.Storage{
+		"json": f.json,
+		"blob": f.blob,
+	})
+	if err != nil {
+		t.Fatalf("NewRoutingStorage: %v", err)
+	}
+	f.router = router
+	return f
+}
+
+// storeContent chunks the content and stores it through the router the way
+// the DDS service does: every chunk first, the manifest last.
+func (f *routingFixture) storeContent(t *testing.T, content []byte, contentType string) *chunking.Manifest {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	for i := range chunks {
+		if err := f.router.StoreChunk(&chunks[i]); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, contentType)
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	if err := f.router.StoreManifest(manifest); err != nil {
+		t.Fatalf("StoreManifest: %v", err)
+	}
+	return manifest
+}
+
+// chunksLiveOn fails the test unless every chunk of the manifest is held
+// by want and absent from other.
+func chunksLiveOn(t *testing.T, m *chunking.Manifest, want, other storage.Storage) {
+	t.Helper()
+	for _, chunkID := range m.ChunkIDs {
+		if has, _ := want.HasChunk(chunkID); !has {
+			t.Errorf("chunk %s missing from its classified backend", chunkID)
+		}
+		if has, _ := other.HasChunk(chunkID); has {
+			t.Errorf("chunk %s leaked onto the wrong backend", chunkID)
+		}
+	}
+}
+
+func TestRoutingStorage_DispatchesByContentType(t *testing.T) {
+	f := newRoutingFixture(t)
+	jsonManifest := f.storeContent(t, []byte(`{"displayName":"Ada"}`), "application/json")
+	blobManifest := f.storeContent(t, []byte("\x89PNG binary image bytes"), "image/png")
+
+	chunksLiveOn(t, jsonManifest, f.json, f.blob)
+	chunksLiveOn(t, blobManifest, f.blob, f.json)
+	if jsonManifest.StorageBackend != "json" || blobManifest.StorageBackend != "blob" {
+		t.Errorf("manifests record backends %q and %q, want json and blob",
+			jsonManifest.StorageBackend, blobManifest.StorageBackend)
+	}
+
+	// Gets through the router are served regardless of which backend won.
+	for _, m := range []*chunking.Manifest{jsonManifest, blobManifest} {
+		for _, chunkID := range m.ChunkIDs {
+			if _, err := f.router.GetChunk(chunkID); err != nil {
+				t.Errorf("GetChunk(%s): %v", chunkID, err)
+			}
+		}
+		if _, err := f.router.GetManifest(m.ID); err != nil {
+			t.Errorf("GetManifest(%s): %v", m.ID, err)
+		}
+	}
+}
+
+func TestRoutingStorage_RelearnsLocationsFromManifest(t *testing.T) {
+	f := newRoutingFixture(t)
+	manifest := f.storeContent(t, []byte(`{"bio":"routing survives restarts"}`), "application/json")
+
+	// A fresh router over the same backends has no in-memory locations.
+	restarted, err := storage.NewRoutingStorage(func(m *chunking.Manifest) string {
+		if m.ContentType == "application/json" {
+			return "json"
+		}
+		return "blob"
+	}, "blob", map[string]storage.Storage{"json": f.json, "blob": f.blob})
+	if err != nil {
+		t.Fatalf("NewRoutingStorage: %v", err)
+	}
+	got, err := restarted.GetManifest(manifest.ID)
+	if err != nil {
+		t.Fatalf("GetManifest after restart: %v", err)
+	}
+	if got.StorageBackend != "json" {
+		t.Errorf("recorded backend = %q, want json", got.StorageBackend)
+	}
+	for _, chunkID := range manifest.ChunkIDs {
+		chunk, err := restarted.GetChunk(chunkID)
+		if err != nil {
+			t.Fatalf("GetChunk after restart: %v", err)
+		}
+		if !chunking.VerifyData(chunk.Data, chunkID) {
+			t.Errorf("chunk %s returned wrong data", chunkID)
+		}
+	}
+}
+
+func TestRoutingStorage_NilClassifierUsesDefault(t *testing.T) {
+	blob := storage.NewInMemoryStorage()
+	router, err := storage.NewRoutingStorage(nil, "blob", map[string]storage.Storage{"blob": blob})
+	if err != nil {
+		t.Fatalf("NewRoutingStorage: %v", err)
+	}
+	chunk := &chunking.Chunk{ID: chunking.HashContent([]byte("x")), Data: []byte("x")}
+	if err := router.StoreChunk(chunk); err != nil {
+		t.Fatalf("StoreChunk: %v", err)
+	}
+	got, err := blob.GetChunk(chunk.ID)
+	if err != nil {
+		t.Fatalf("chunk not on default backend: %v", err)
+	}
+	if !bytes.Equal(got.Data, chunk.Data) {
+		t.Error("chunk data changed through routing")
+	}
+}
+
+func TestRoutingStorage_RejectsUnknownBackend(t *testing.T) {
+	if _, err := storage.NewRoutingStorage(nil, "missing", map[string]storage.Storage{
+		"blob": storage.NewInMemoryStorage(),
+	}); err == nil {
+		t.Error("unknown default backend accepted")
+	}
+	router, err := storage.NewRoutingStorage(func(*chunking.Manifest) string { return "nowhere" },
+		"blob", map[string]storage.Storage{"blob": storage.NewInMemoryStorage()})
+	if err != nil {
+		t.Fatalf("NewRoutingStorage: %v", err)
+	}
+	if err := router.StoreManifest(&chunking.Manifest{ID: "m"}); err == nil {
+		t.Error("manifest routed to a backend that does not exist")
+	}
+}
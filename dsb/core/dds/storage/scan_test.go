@@ -0,0 +1,126 @@
+package storage_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// drainChunkScan walks a chunk scan to completion, failing the test if it
+// does not terminate.
+func drainChunkScan(t *testing.T, store storage.Storage, limit int) []string {
+	t.Helper()
+	ids := []string{}
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 100_000 {
+			t.Fatal("scan never finished")
+		}
+		infos, next, err := store.ScanChunks(cursor, limit)
+		if err != nil {
+			t.Fatalf("ScanChunks(%q, %d): %v", cursor, limit, err)
+		}
+		for _, info := range infos {
+			ids = append(ids, info.ID)
+		}
+		if next == "" {
+			return ids
+		}
+		cursor = next
+	}
+}
+
+func TestScanChunks_TenThousandWithoutDuplicatesOrOmissions(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	const total = 10_000
+	want := make(map[string]bool, total)
+	for i := 0; i < total; i++ {
+		data := []byte(fmt.Sprintf("synthetic chunk %d", i))
+		chunk := &chunking.Chunk{ID: chunking.HashContent(data), Data: data}
+		if err := store.StoreChunk(chunk); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+		want[chunk.ID] = true
+	}
+
+	got := drainChunkScan(t, store, 997)
+	if len(got) != total {
+		t.Fatalf("scan visited %d chunks, want %d", len(got), total)
+	}
+	seen := make(map[string]bool, total)
+	for i, id := range got {
+		if seen[id] {
+			t.Fatalf("scan returned %s twice", id)
+		}
+		seen[id] = true
+		if !want[id] {
+			t.Fatalf("scan returned %s, which was never stored", id)
+		}
+		if i > 0 && got[i-1] >= id {
+			t.Fatalf("scan order broke at %d: %s before %s", i, got[i-1], id)
+		}
+	}
+}
+
+func TestScanChunks_CursorStableAcrossInterleavedWrites(t *testing.T) {
+	store := storage.NewInMemoryStorage()
+	for i := 0; i < 60; i++ {
+		if err := store.StoreChunk(&chunking.Chunk{
+			ID:   fmt.Sprintf("c-%03d", i),
+			Data: []byte{byte(i)},
+		}); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+	}
+
+	firstPage, cursor, err := store.ScanChunks("", 20)
+	if err != nil {
+		t.Fatalf("ScanChunks: %v", err)
+	}
+	if len(firstPage) != 20 || cursor != "c-019" {
+		t.Fatalf("first page ended at %q with %d entries", cursor, len(firstPage))
+	}
+
+	// Writes landing behind the cursor stay invisible to this scan; writes
+	// ahead of it are picked up.
+	for _, id := range []string{"c-000a", "c-010a", "c-025a", "c-059a"} {
+		if err := store.StoreChunk(&chunking.Chunk{ID: id, Data: []byte("late")}); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, info := range firstPage {
+		seen[info.ID] = true
+	}
+	rest := []string{}
+	for cursor != "" {
+		var infos []storage.ChunkInfo
+		infos, cursor, err = store.ScanChunks(cursor, 20)
+		if err != nil {
+			t.Fatalf("ScanChunks: %v", err)
+		}
+		for _, info := range infos {
+			if seen[info.ID] {
+				t.Fatalf("scan returned %s twice", info.ID)
+			}
+			seen[info.ID] = true
+			rest = append(rest, info.ID)
+		}
+	}
+	if len(rest) != 42 { // the remaining 40 plus the two late writes ahead of the cursor
+		t.Fatalf("resumed scan visited %d chunks, want 42: %v", len(rest), rest)
+	}
+	for _, id := range []string{"c-000a", "c-010a"} {
+		if seen[id] {
+			t.Errorf("scan revisited %s, stored behind the cursor", id)
+		}
+	}
+	for _, id := range []string{"c-025a", "c-059a"} {
+		if !seen[id] {
+			t.Errorf("scan missed %s, stored ahead of the cursor", id)
+		}
+	}
+}
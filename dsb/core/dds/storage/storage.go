@@ -0,0 +1,94 @@
+// Package storage defines how a node persists chunks and manifests locally,
+// together with the in-memory reference implementation used by tests and
+// single-process demos.
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+)
+
+// ErrChunkNotFound is returned by GetChunk when the chunk is not stored.
+var ErrChunkNotFound = errors.New("storage: chunk not found")
+
+// ErrQuotaExceeded is returned by quota-enforcing storage when a store
+// would push usage past the configured byte budget.
+var ErrQuotaExceeded = errors.New("storage: quota exceeded")
+
+// StorageUsage summarizes what a storage backend currently holds.
+type StorageUsage struct {
+	ChunkCount    int
+	ManifestCount int
+	TotalBytes    int64
+}
+
+// ErrManifestNotFound is returned by GetManifest when the manifest is not
+// stored.
+var ErrManifestNotFound = errors.New("storage: manifest not found")
+
+// ChunkInfo describes one stored chunk for enumeration: its ID and its
+// size in bytes as stored (so ciphertext size under an encrypting
+// wrapper).
+type ChunkInfo struct {
+	ID   string
+	Size int64
+}
+
+// ManifestInfo describes one stored manifest for enumeration. CreatedAt is
+// the manifest's own creation timestamp when the backend can read it and
+// zero otherwise.
+type ManifestInfo struct {
+	ID        string
+	Size      int64
+	CreatedAt int64
+}
+
+// checkScanLimit rejects non-positive scan page sizes.
+func checkScanLimit(limit int) error {
+	if limit < 1 {
+		return fmt.Errorf("storage: scan limit must be positive, got %d", limit)
+	}
+	return nil
+}
+
+// Storage is the persistence contract for a node's local DDS content.
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	StoreChunk(chunk *chunking.Chunk) error
+	GetChunk(chunkID string) (*chunking.Chunk, error)
+	HasChunk(chunkID string) (bool, error)
+	DeleteChunk(chunkID string) error
+
+	StoreManifest(manifest *chunking.Manifest) error
+	GetManifest(manifestID string) (*chunking.Manifest, error)
+	HasManifest(manifestID string) (bool, error)
+	DeleteManifest(manifestID string) error
+
+	// ListChunks and ListManifests return all stored IDs in sorted order,
+	// e.g. for migration or audits.
+	ListChunks() ([]string, error)
+	ListManifests() ([]string, error)
+
+	// ScanChunks and ScanManifests page through stored entries in ID
+	// order for admin tooling (audits, GC, exports) that must walk a
+	// large store without materializing it in one call. Pass an empty
+	// cursor to start and the returned cursor to resume; entries at or
+	// before the cursor are skipped, so entries stored behind a scan in
+	// flight are not revisited. An empty next cursor ends the scan. A
+	// page may hold fewer than limit entries even mid-scan; limit must be
+	// positive.
+	ScanChunks(cursor string, limit int) ([]ChunkInfo, string, error)
+	ScanManifests(cursor string, limit int) ([]ManifestInfo, string, error)
+
+	// ManifestsContainingChunk returns the IDs of stored manifests that
+	// reference the chunk, in sorted order — the reverse of a manifest's
+	// chunk list, e.g. to trace a chunk ID found in a log back to its
+	// content. A chunk no manifest references yields an empty list, not
+	// an error.
+	ManifestsContainingChunk(chunkID string) ([]string, error)
+
+	// Usage reports chunk/manifest counts and total stored bytes.
+	Usage() (StorageUsage, error)
+}
@@ -0,0 +1,335 @@
+// Package storagetest provides a conformance suite every storage.Storage
+// implementation must pass. Backends wire themselves in with a one-line
+// test calling RunStorageConformance, so behavioral drift between
+// implementations (error sentinels, overwrite semantics, concurrency
+// safety) is caught by the same assertions everywhere.
+package storagetest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// RunStorageConformance exercises the Storage contract against a fresh
+// instance from factory for each subtest.
+func RunStorageConformance(t *testing.T, factory func() storage.Storage) {
+	t.Run("ChunkRoundTrip", func(t *testing.T) {
+		store := factory()
+		chunk := testChunk([]byte("conformance chunk"))
+		if err := store.StoreChunk(chunk); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+		ok, err := store.HasChunk(chunk.ID)
+		if err != nil {
+			t.Fatalf("HasChunk: %v", err)
+		}
+		if !ok {
+			t.Fatal("HasChunk reports false for a stored chunk")
+		}
+		got, err := store.GetChunk(chunk.ID)
+		if err != nil {
+			t.Fatalf("GetChunk: %v", err)
+		}
+		if !bytes.Equal(got.Data, chunk.Data) {
+			t.Error("retrieved chunk data differs from stored data")
+		}
+	})
+
+	t.Run("ManifestRoundTrip", func(t *testing.T) {
+		store := factory()
+		manifest := testManifest(t, []byte("conformance manifest content"))
+		if err := store.StoreManifest(manifest); err != nil {
+			t.Fatalf("StoreManifest: %v", err)
+		}
+		ok, err := store.HasManifest(manifest.ID)
+		if err != nil {
+			t.Fatalf("HasManifest: %v", err)
+		}
+		if !ok {
+			t.Fatal("HasManifest reports false for a stored manifest")
+		}
+		got, err := store.GetManifest(manifest.ID)
+		if err != nil {
+			t.Fatalf("GetManifest: %v", err)
+		}
+		if got.ID != manifest.ID || got.ContentID != manifest.ContentID {
+			t.Errorf("retrieved manifest %+v differs from stored %+v", got, manifest)
+		}
+	})
+
+	t.Run("NotFoundSentinels", func(t *testing.T) {
+		store := factory()
+		if _, err := store.GetChunk("cid-missing"); !errors.Is(err, storage.ErrChunkNotFound) {
+			t.Errorf("GetChunk(missing) = %v, want ErrChunkNotFound", err)
+		}
+		if _, err := store.GetManifest("cid-missing"); !errors.Is(err, storage.ErrManifestNotFound) {
+			t.Errorf("GetManifest(missing) = %v, want ErrManifestNotFound", err)
+		}
+		if ok, err := store.HasChunk("cid-missing"); err != nil || ok {
+			t.Errorf("HasChunk(missing) = (%v, %v), want (false, nil)", ok, err)
+		}
+		if ok, err := store.HasManifest("cid-missing"); err != nil || ok {
+			t.Errorf("HasManifest(missing) = (%v, %v), want (false, nil)", ok, err)
+		}
+	})
+
+	t.Run("OverwriteIsIdempotent", func(t *testing.T) {
+		store := factory()
+		chunk := testChunk([]byte("stored twice"))
+		for i := 0; i < 2; i++ {
+			if err := store.StoreChunk(chunk); err != nil {
+				t.Fatalf("StoreChunk #%d: %v", i+1, err)
+			}
+		}
+		got, err := store.GetChunk(chunk.ID)
+		if err != nil {
+			t.Fatalf("GetChunk: %v", err)
+		}
+		if !bytes.Equal(got.Data, chunk.Data) {
+			t.Error("overwrite corrupted chunk data")
+		}
+		usage, err := store.Usage()
+		if err != nil {
+			t.Fatalf("Usage: %v", err)
+		}
+		if usage.ChunkCount != 1 {
+			t.Errorf("ChunkCount after double store = %d, want 1", usage.ChunkCount)
+		}
+	})
+
+	t.Run("DeleteMissingIsNotAnError", func(t *testing.T) {
+		store := factory()
+		if err := store.DeleteChunk("cid-missing"); err != nil {
+			t.Errorf("DeleteChunk(missing) = %v, want nil", err)
+		}
+		if err := store.DeleteManifest("cid-missing"); err != nil {
+			t.Errorf("DeleteManifest(missing) = %v, want nil", err)
+		}
+	})
+
+	t.Run("DeleteRemoves", func(t *testing.T) {
+		store := factory()
+		chunk := testChunk([]byte("deleted soon"))
+		if err := store.StoreChunk(chunk); err != nil {
+			t.Fatalf("StoreChunk: %v", err)
+		}
+		if err := store.DeleteChunk(chunk.ID); err != nil {
+			t.Fatalf("DeleteChunk: %v", err)
+		}
+		if _, err := store.GetChunk(chunk.ID); !errors.Is(err, storage.ErrChunkNotFound) {
+			t.Errorf("GetChunk(deleted) = %v, want ErrChunkNotFound", err)
+		}
+	})
+
+	t.Run("LargeChunk", func(t *testing.T) {
+		store := factory()
+		data := make([]byte, 4<<20)
+		rand.New(rand.NewSource(7)).Read(data)
+		chunk := testChunk(data)
+		if err := store.StoreChunk(chunk); err != nil {
+			t.Fatalf("StoreChunk(4MB): %v", err)
+		}
+		got, err := store.GetChunk(chunk.ID)
+		if err != nil {
+			t.Fatalf("GetChunk(4MB): %v", err)
+		}
+		if !bytes.Equal(got.Data, data) {
+			t.Error("large chunk round trip corrupted data")
+		}
+	})
+
+	t.Run("ListAll", func(t *testing.T) {
+		store := factory()
+		want := make([]string, 0, 3)
+		for i := 0; i < 3; i++ {
+			chunk := testChunk([]byte(fmt.Sprintf("listed chunk %d", i)))
+			if err := store.StoreChunk(chunk); err != nil {
+				t.Fatalf("StoreChunk: %v", err)
+			}
+			want = append(want, chunk.ID)
+		}
+		manifest := testManifest(t, []byte("listed manifest content"))
+		if err := store.StoreManifest(manifest); err != nil {
+			t.Fatalf("StoreManifest: %v", err)
+		}
+		sort.Strings(want)
+
+		chunks, err := store.ListChunks()
+		if err != nil {
+			t.Fatalf("ListChunks: %v", err)
+		}
+		if len(chunks) != len(want) {
+			t.Fatalf("ListChunks returned %d IDs, want %d", len(chunks), len(want))
+		}
+		for i := range want {
+			if chunks[i] != want[i] {
+				t.Errorf("ListChunks[%d] = %s, want %s (sorted)", i, chunks[i], want[i])
+			}
+		}
+		manifests, err := store.ListManifests()
+		if err != nil {
+			t.Fatalf("ListManifests: %v", err)
+		}
+		if len(manifests) != 1 || manifests[0] != manifest.ID {
+			t.Errorf("ListManifests = %v, want [%s]", manifests, manifest.ID)
+		}
+	})
+
+	t.Run("ScanPagination", func(t *testing.T) {
+		store := factory()
+		want := make([]string, 0, 23)
+		for i := 0; i < 23; i++ {
+			chunk := testChunk([]byte(fmt.Sprintf("scanned chunk %d", i)))
+			if err := store.StoreChunk(chunk); err != nil {
+				t.Fatalf("StoreChunk: %v", err)
+			}
+			want = append(want, chunk.ID)
+		}
+		sort.Strings(want)
+		manifest := testManifest(t, []byte("scanned manifest content"))
+		if err := store.StoreManifest(manifest); err != nil {
+			t.Fatalf("StoreManifest: %v", err)
+		}
+
+		got := []string{}
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > len(want) {
+				t.Fatal("ScanChunks never finished")
+			}
+			infos, next, err := store.ScanChunks(cursor, 5)
+			if err != nil {
+				t.Fatalf("ScanChunks: %v", err)
+			}
+			for _, info := range infos {
+				if info.Size <= 0 {
+					t.Errorf("chunk %s scanned with size %d", info.ID, info.Size)
+				}
+				got = append(got, info.ID)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+		if len(got) != len(want) {
+			t.Fatalf("scan visited %d chunks, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("scan[%d] = %s, want %s (sorted, no gaps)", i, got[i], want[i])
+			}
+		}
+
+		manifests, next, err := store.ScanManifests("", 10)
+		if err != nil {
+			t.Fatalf("ScanManifests: %v", err)
+		}
+		if next != "" || len(manifests) != 1 || manifests[0].ID != manifest.ID {
+			t.Errorf("ScanManifests = (%v, %q), want one page holding %s", manifests, next, manifest.ID)
+		}
+
+		if _, _, err := store.ScanChunks("", 0); err == nil {
+			t.Error("ScanChunks accepted a zero limit")
+		}
+	})
+
+	t.Run("ReverseChunkLookup", func(t *testing.T) {
+		store := factory()
+		// The contents share their first 8 bytes, so under the 8-byte test
+		// chunker both manifests reference the same first chunk.
+		first := testManifest(t, []byte("sharedprefix, then one tail"))
+		second := testManifest(t, []byte("sharedprefix, but another tail"))
+		if first.ChunkIDs[0] != second.ChunkIDs[0] {
+			t.Fatalf("fixture contents do not share a chunk: %s vs %s", first.ChunkIDs[0], second.ChunkIDs[0])
+		}
+		shared := first.ChunkIDs[0]
+		for _, manifest := range []*chunking.Manifest{first, second} {
+			if err := store.StoreManifest(manifest); err != nil {
+				t.Fatalf("StoreManifest: %v", err)
+			}
+		}
+
+		got, err := store.ManifestsContainingChunk(shared)
+		if err != nil {
+			t.Fatalf("ManifestsContainingChunk: %v", err)
+		}
+		want := []string{first.ID, second.ID}
+		sort.Strings(want)
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("ManifestsContainingChunk(shared) = %v, want %v", got, want)
+		}
+
+		if got, err := store.ManifestsContainingChunk("cid-unreferenced"); err != nil || len(got) != 0 {
+			t.Errorf("ManifestsContainingChunk(unreferenced) = (%v, %v), want an empty list", got, err)
+		}
+
+		// Deleting a manifest retires its references.
+		if err := store.DeleteManifest(first.ID); err != nil {
+			t.Fatalf("DeleteManifest: %v", err)
+		}
+		got, err = store.ManifestsContainingChunk(shared)
+		if err != nil {
+			t.Fatalf("ManifestsContainingChunk after delete: %v", err)
+		}
+		if len(got) != 1 || got[0] != second.ID {
+			t.Errorf("ManifestsContainingChunk after delete = %v, want [%s]", got, second.ID)
+		}
+	})
+
+	t.Run("ConcurrentAccess", func(t *testing.T) {
+		store := factory()
+		var wg sync.WaitGroup
+		for w := 0; w < 8; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < 20; i++ {
+					chunk := testChunk([]byte(fmt.Sprintf("worker %d chunk %d", w, i)))
+					if err := store.StoreChunk(chunk); err != nil {
+						t.Errorf("StoreChunk: %v", err)
+						return
+					}
+					if _, err := store.GetChunk(chunk.ID); err != nil {
+						t.Errorf("GetChunk: %v", err)
+						return
+					}
+					if _, err := store.Usage(); err != nil {
+						t.Errorf("Usage: %v", err)
+						return
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+	})
+}
+
+func testChunk(data []byte) *chunking.Chunk {
+	return &chunking.Chunk{ID: chunking.HashContent(data), Data: data}
+}
+
+func testManifest(t *testing.T, content []byte) *chunking.Manifest {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(8)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	chunks, err := chunker.ChunkContent(content)
+	if err != nil {
+		t.Fatalf("ChunkContent: %v", err)
+	}
+	manifest, err := chunker.GenerateManifest(chunking.HashContent(content), chunks, "text/plain")
+	if err != nil {
+		t.Fatalf("GenerateManifest: %v", err)
+	}
+	return manifest
+}
@@ -0,0 +1,95 @@
+// Package dds holds cross-cutting facilities shared by the DDS
+// subpackages. Currently that is request tracing: a request ID carried on
+// a context so log lines and wrapped errors produced across the service,
+// retriever, and originator layers can be correlated, plus a span hook so
+// an external tracer (e.g. OpenTelemetry) can observe operations without
+// the DDS depending on it.
+package dds
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+)
+
+type ctxKey int
+
+const requestIDKey ctxKey = iota
+
+// NewRequestID returns a fresh random request ID (12 hex characters).
+func NewRequestID() string {
+	var buf [6]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		// crypto/rand failing is unrecoverable for key material, but for a
+		// correlation ID a fixed fallback is better than aborting the call.
+		return "000000000000"
+	}
+	return hex.EncodeToString(buf[:])
+}
+
+// WithRequestID returns a context carrying the given request ID. An empty
+// id gets a freshly generated one.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = NewRequestID()
+	}
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// EnsureRequestID returns ctx unchanged if it already carries a request
+// ID, and otherwise a child context with a generated one.
+func EnsureRequestID(ctx context.Context) context.Context {
+	if RequestID(ctx) != "" {
+		return ctx
+	}
+	return WithRequestID(ctx, "")
+}
+
+// Op decorates an operation name with the context's request ID for use as
+// an error or log prefix: Op(ctx, "retrieve") is "retrieve[req=abc123]"
+// when an ID is present and just "retrieve" otherwise.
+func Op(ctx context.Context, name string) string {
+	if id := RequestID(ctx); id != "" {
+		return name + "[req=" + id + "]"
+	}
+	return name
+}
+
+// SpanHook observes the start and end of traced DDS operations. Both
+// methods receive the context carrying the request ID. Implementations
+// must be safe for concurrent use.
+type SpanHook interface {
+	SpanStart(ctx context.Context, op string)
+	SpanEnd(ctx context.Context, op string, err error)
+}
+
+// logSpanHook is a SpanHook writing one line per event to a logger.
+type logSpanHook struct {
+	l *log.Logger
+}
+
+// LogSpanHook returns a SpanHook that logs span starts and ends (with the
+// request ID and any error) to l. It doubles as the reference SpanHook
+// implementation for tests.
+func LogSpanHook(l *log.Logger) SpanHook {
+	return logSpanHook{l: l}
+}
+
+func (h logSpanHook) SpanStart(ctx context.Context, op string) {
+	h.l.Printf("span=%s req=%s event=start", op, RequestID(ctx))
+}
+
+func (h logSpanHook) SpanEnd(ctx context.Context, op string, err error) {
+	if err != nil {
+		h.l.Printf("span=%s req=%s event=end err=%q", op, RequestID(ctx), err)
+		return
+	}
+	h.l.Printf("span=%s req=%s event=end", op, RequestID(ctx))
+}
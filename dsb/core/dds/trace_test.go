@@ -0,0 +1,63 @@
+package dds_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds"
+)
+
+func TestRequestID_RoundTripAndGeneration(t *testing.T) {
+	ctx := dds.WithRequestID(context.Background(), "abc123")
+	if got := dds.RequestID(ctx); got != "abc123" {
+		t.Errorf("RequestID = %q, want abc123", got)
+	}
+
+	generated := dds.WithRequestID(context.Background(), "")
+	if got := dds.RequestID(generated); got == "" {
+		t.Error("empty ID was not generated")
+	}
+
+	// EnsureRequestID keeps an existing ID and mints one otherwise.
+	if got := dds.RequestID(dds.EnsureRequestID(ctx)); got != "abc123" {
+		t.Errorf("EnsureRequestID replaced existing ID with %q", got)
+	}
+	if got := dds.RequestID(dds.EnsureRequestID(context.Background())); got == "" {
+		t.Error("EnsureRequestID did not mint an ID")
+	}
+}
+
+func TestOp_PrefixesRequestID(t *testing.T) {
+	ctx := dds.WithRequestID(context.Background(), "abc123")
+	if got := dds.Op(ctx, "retrieve"); got != "retrieve[req=abc123]" {
+		t.Errorf("Op = %q", got)
+	}
+	if got := dds.Op(context.Background(), "retrieve"); got != "retrieve" {
+		t.Errorf("Op without ID = %q, want bare name", got)
+	}
+}
+
+func TestLogSpanHook_LogsStartEndAndError(t *testing.T) {
+	var buf bytes.Buffer
+	hook := dds.LogSpanHook(log.New(&buf, "", 0))
+	ctx := dds.WithRequestID(context.Background(), "abc123")
+
+	hook.SpanStart(ctx, "publish")
+	hook.SpanEnd(ctx, "publish", nil)
+	hook.SpanEnd(ctx, "retrieve", errors.New("boom"))
+
+	out := buf.String()
+	for _, want := range []string{
+		"span=publish req=abc123 event=start",
+		"span=publish req=abc123 event=end",
+		`span=retrieve req=abc123 event=end err="boom"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+// PostRef locates one post on the chain: the block it was recorded in, the
+// recording transaction, the content CID, and the transaction timestamp.
+type PostRef struct {
+	BlockIndex int64  `json:"blockIndex"`
+	TxID       string `json:"txId"`
+	ContentCID string `json:"contentCid"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// AuthorPostIndex maintains each address's posts in chain order, so "show
+// this profile's posts" is a map lookup instead of a chain scan per page.
+// It follows the chain incrementally via Blockchain.OnBlockAdded and can
+// be persisted across restarts; see Save and LoadAuthorPostIndex. An
+// author recording the same content CID again — an edit republished under
+// its corrected form, or a deliberate bump — surfaces once, at its latest
+// position. The chain has no post-tombstone transaction; content taken
+// down stays indexed and simply fails retrieval, which callers already
+// handle per item.
+//
+// It is safe for concurrent use.
+type AuthorPostIndex struct {
+	mu    sync.RWMutex
+	posts map[string][]PostRef
+	// headIndex/headHash identify the last applied block, so a persisted
+	// index can prove it still matches the chain it is loaded against.
+	headIndex int64
+	headHash  string
+}
+
+// NewAuthorPostIndex returns an empty index.
+func NewAuthorPostIndex() *AuthorPostIndex {
+	return &AuthorPostIndex{posts: make(map[string][]PostRef)}
+}
+
+// ApplyBlock records the block's post transactions. Blocks must be applied
+// in chain order; Follow wires this to a live chain.
+func (ix *AuthorPostIndex) ApplyBlock(block *ledger.Block) {
+	if block == nil {
+		return
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for i := range block.Transactions {
+		tx := &block.Transactions[i]
+		if tx.Type != ledger.TxTypePostCreated {
+			continue
+		}
+		ref := PostRef{
+			BlockIndex: block.Index,
+			TxID:       tx.ID,
+			ContentCID: string(tx.Payload),
+			Timestamp:  tx.Timestamp,
+		}
+		list := ix.posts[tx.SenderAddress]
+		// A re-recorded CID replaces its earlier entry so the latest
+		// version is what pagination surfaces.
+		for j := range list {
+			if list[j].ContentCID == ref.ContentCID {
+				list = append(list[:j], list[j+1:]...)
+				break
+			}
+		}
+		ix.posts[tx.SenderAddress] = append(list, ref)
+	}
+	ix.headIndex = block.Index
+	ix.headHash = block.Hash
+}
+
+// BuildFromChain resets the index and replays the whole chain.
+func (ix *AuthorPostIndex) BuildFromChain(chain *ledger.Blockchain) {
+	ix.mu.Lock()
+	ix.posts = make(map[string][]PostRef)
+	ix.headIndex = 0
+	ix.headHash = ""
+	ix.mu.Unlock()
+	for _, block := range chain.Blocks() {
+		ix.ApplyBlock(block)
+	}
+}
+
+// Follow builds the index from the chain's current state and subscribes to
+// keep it current as blocks are appended.
+func (ix *AuthorPostIndex) Follow(chain *ledger.Blockchain) {
+	ix.BuildFromChain(chain)
+	chain.OnBlockAdded(ix.ApplyBlock)
+}
+
+// PostsByAuthor returns up to limit of the address's posts, newest first.
+// The empty cursor starts from the newest post; passing the returned
+// cursor back resumes after the last item of the previous page. The
+// returned cursor is empty once the author's posts are exhausted.
+func (ix *AuthorPostIndex) PostsByAuthor(addr string, cursor string, limit int) ([]PostRef, string, error) {
+	if limit < 1 {
+		return nil, cursor, fmt.Errorf("feed: page limit must be at least 1, got %d", limit)
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	list := ix.posts[addr]
+	start := len(list) - 1
+	if cursor != "" {
+		pos := -1
+		for i := range list {
+			if list[i].TxID == cursor {
+				pos = i
+				break
+			}
+		}
+		if pos < 0 {
+			return nil, cursor, fmt.Errorf("feed: cursor %s is not in %s's posts", cursor, addr)
+		}
+		start = pos - 1
+	}
+	page := make([]PostRef, 0, limit)
+	for i := start; i >= 0 && len(page) < limit; i-- {
+		page = append(page, list[i])
+	}
+	next := ""
+	if n := len(page); n > 0 && start-n >= 0 {
+		next = page[n-1].TxID
+	}
+	return page, next, nil
+}
+
+// authorIndexFile is the persisted form of the index.
+type authorIndexFile struct {
+	HeadIndex int64                `json:"headIndex"`
+	HeadHash  string               `json:"headHash"`
+	Posts     map[string][]PostRef `json:"posts"`
+}
+
+// Save writes the index to path, so the next start can load it instead of
+// replaying the chain.
+func (ix *AuthorPostIndex) Save(path string) error {
+	ix.mu.RLock()
+	file := authorIndexFile{HeadIndex: ix.headIndex, HeadHash: ix.headHash, Posts: ix.posts}
+	data, err := json.MarshalIndent(&file, "", "  ")
+	ix.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("feed: failed to serialize author index: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("feed: failed to write author index: %w", err)
+	}
+	return nil
+}
+
+// LoadAuthorPostIndex loads a persisted index and verifies it against the
+// chain: when the persisted head block is still on the chain with the same
+// hash, the index is adopted and caught up with any newer blocks;
+// otherwise — a reorg, a different chain, or no file at all — it is
+// rebuilt from scratch. Only unreadable or unparsable files are errors.
+func LoadAuthorPostIndex(path string, chain *ledger.Blockchain) (*AuthorPostIndex, error) {
+	ix := NewAuthorPostIndex()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		ix.BuildFromChain(chain)
+		return ix, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("feed: failed to read author index: %w", err)
+	}
+	var file authorIndexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("feed: failed to parse author index: %w", err)
+	}
+	head, err := chain.GetBlock(file.HeadIndex)
+	if err != nil || head.Hash != file.HeadHash {
+		ix.BuildFromChain(chain)
+		return ix, nil
+	}
+	if file.Posts != nil {
+		ix.posts = file.Posts
+	}
+	ix.headIndex = file.HeadIndex
+	ix.headHash = file.HeadHash
+	for _, block := range chain.Blocks() {
+		if block.Index > file.HeadIndex {
+			ix.ApplyBlock(block)
+		}
+	}
+	return ix, nil
+}
@@ -0,0 +1,177 @@
+package feed_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/feed"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+type authorIndexFixture struct {
+	chain   *ledger.Blockchain
+	wallets map[string]*identity.Wallet
+}
+
+func newAuthorIndexFixture(t *testing.T, names ...string) *authorIndexFixture {
+	t.Helper()
+	f := &authorIndexFixture{chain: ledger.NewBlockchain(), wallets: make(map[string]*identity.Wallet)}
+	for _, name := range names {
+		wallet, err := identity.NewWallet()
+		if err != nil {
+			t.Fatalf("NewWallet: %v", err)
+		}
+		f.wallets[name] = wallet
+	}
+	return f
+}
+
+func (f *authorIndexFixture) post(t *testing.T, author, cid string) {
+	t.Helper()
+	tx, err := ledger.NewSignedTransaction(f.wallets[author], ledger.TxTypePostCreated, []byte(cid))
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+}
+
+// bruteForcePosts scans the whole chain for the address's posts, newest
+// first — the behavior the index must reproduce.
+func bruteForcePosts(chain *ledger.Blockchain, addr string) []string {
+	var cids []string
+	for _, block := range chain.Blocks() {
+		for i := range block.Transactions {
+			tx := &block.Transactions[i]
+			if tx.Type == ledger.TxTypePostCreated && tx.SenderAddress == addr {
+				cids = append(cids, string(tx.Payload))
+			}
+		}
+	}
+	for i, j := 0, len(cids)-1; i < j; i, j = i+1, j-1 {
+		cids[i], cids[j] = cids[j], cids[i]
+	}
+	return cids
+}
+
+func collectAllPages(t *testing.T, ix *feed.AuthorPostIndex, addr string, pageSize int) []string {
+	t.Helper()
+	var cids []string
+	cursor := ""
+	for {
+		page, next, err := ix.PostsByAuthor(addr, cursor, pageSize)
+		if err != nil {
+			t.Fatalf("PostsByAuthor: %v", err)
+		}
+		for _, ref := range page {
+			cids = append(cids, ref.ContentCID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	return cids
+}
+
+func TestAuthorPostIndex_MatchesBruteForceScan(t *testing.T) {
+	f := newAuthorIndexFixture(t, "ada", "ben")
+	for i := 0; i < 5; i++ {
+		f.post(t, "ada", fmt.Sprintf("cid-ada-%d", i))
+		if i%2 == 0 {
+			f.post(t, "ben", fmt.Sprintf("cid-ben-%d", i))
+		}
+	}
+	ix := feed.NewAuthorPostIndex()
+	ix.Follow(f.chain)
+	// A post arriving after Follow is picked up via the subscription.
+	f.post(t, "ada", "cid-ada-live")
+
+	for name, wallet := range f.wallets {
+		want := bruteForcePosts(f.chain, wallet.Address)
+		got := collectAllPages(t, ix, wallet.Address, 2)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s: index pages %v, brute force %v", name, got, want)
+		}
+	}
+	if _, _, err := ix.PostsByAuthor(f.wallets["ada"].Address, "tx-not-there", 2); err == nil {
+		t.Error("unknown cursor was accepted")
+	}
+}
+
+func TestAuthorPostIndex_RepostedCIDSurfacesLatest(t *testing.T) {
+	f := newAuthorIndexFixture(t, "ada")
+	f.post(t, "ada", "cid-edited")
+	f.post(t, "ada", "cid-other")
+	f.post(t, "ada", "cid-edited") // the edit republished
+
+	ix := feed.NewAuthorPostIndex()
+	ix.Follow(f.chain)
+	got := collectAllPages(t, ix, f.wallets["ada"].Address, 10)
+	want := []string{"cid-edited", "cid-other"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("posts %v, want %v (edit surfaced once, at its latest position)", got, want)
+	}
+}
+
+func TestLoadAuthorPostIndex_ReusesPersistedState(t *testing.T) {
+	f := newAuthorIndexFixture(t, "ada")
+	f.post(t, "ada", "cid-before-save")
+	ix := feed.NewAuthorPostIndex()
+	ix.BuildFromChain(f.chain)
+	path := filepath.Join(t.TempDir(), "authorindex.json")
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Growth after the save is caught up from the chain on load.
+	f.post(t, "ada", "cid-after-save")
+
+	loaded, err := feed.LoadAuthorPostIndex(path, f.chain)
+	if err != nil {
+		t.Fatalf("LoadAuthorPostIndex: %v", err)
+	}
+	got := collectAllPages(t, loaded, f.wallets["ada"].Address, 10)
+	want := []string{"cid-after-save", "cid-before-save"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("loaded posts %v, want %v", got, want)
+	}
+}
+
+func TestLoadAuthorPostIndex_RebuildsWhenChainDiverges(t *testing.T) {
+	f := newAuthorIndexFixture(t, "ada")
+	f.post(t, "ada", "cid-original")
+	ix := feed.NewAuthorPostIndex()
+	ix.BuildFromChain(f.chain)
+	path := filepath.Join(t.TempDir(), "authorindex.json")
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A different chain under the same file: the persisted head hash no
+	// longer matches, so the index must be rebuilt, not trusted.
+	other := newAuthorIndexFixture(t, "ada")
+	other.wallets["ada"] = f.wallets["ada"]
+	other.post(t, "ada", "cid-reorged")
+
+	loaded, err := feed.LoadAuthorPostIndex(path, other.chain)
+	if err != nil {
+		t.Fatalf("LoadAuthorPostIndex: %v", err)
+	}
+	got := collectAllPages(t, loaded, f.wallets["ada"].Address, 10)
+	want := []string{"cid-reorged"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("posts after divergent load %v, want %v", got, want)
+	}
+
+	// A missing file also builds from the chain.
+	fresh, err := feed.LoadAuthorPostIndex(filepath.Join(t.TempDir(), "missing.json"), f.chain)
+	if err != nil {
+		t.Fatalf("LoadAuthorPostIndex without a file: %v", err)
+	}
+	if got := collectAllPages(t, fresh, f.wallets["ada"].Address, 10); len(got) != 1 {
+		t.Errorf("fresh build posts %v, want one", got)
+	}
+}
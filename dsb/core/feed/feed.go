@@ -0,0 +1,179 @@
+// Package feed assembles timelines from the ledger and the DDS: post
+// transactions on chain say who published what, the DDS serves the content
+// itself, and follow/unfollow transactions decide whose posts a viewer
+// sees.
+package feed
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+// FeedItem is one post in a viewer's timeline. Content is the retrieved
+// post body; when retrieval failed, Content is nil and Err records why, but
+// the item still occupies its place so pagination stays contiguous.
+type FeedItem struct {
+	Author     string
+	ContentCID string
+	Content    []byte
+	Timestamp  int64
+	TxID       string
+	Err        error
+
+	// Muted marks a post from an author the viewer muted: it keeps its
+	// place in the timeline but clients should de-emphasize or collapse it.
+	Muted bool
+}
+
+// FeedCursor marks a position on the chain: the first transaction
+// coordinate not yet consumed. Coordinates of appended transactions never
+// change, so a cursor stays valid as the chain grows. The zero cursor
+// starts from the beginning of the available chain.
+type FeedCursor struct {
+	BlockIndex int64 `json:"blockIndex"`
+	TxIndex    int   `json:"txIndex"`
+}
+
+// BlockPolicy answers whether an address's content should be hidden or
+// de-emphasized for the local viewer; *user.BlockList implements it.
+type BlockPolicy interface {
+	IsBlocked(addr string) bool
+	IsMuted(addr string) bool
+}
+
+// FeedBuilder builds viewer timelines by walking post transactions and
+// retrieving their content through the DDS.
+type FeedBuilder struct {
+	chain *ledger.Blockchain
+	dds   *service.DDSCoreService
+
+	// blocks, when set, filters blocked authors out of pages and flags
+	// muted ones. See SetBlockPolicy.
+	blocks BlockPolicy
+}
+
+// NewFeedBuilder returns a builder reading from chain and retrieving post
+// content through dds.
+func NewFeedBuilder(chain *ledger.Blockchain, dds *service.DDSCoreService) *FeedBuilder {
+	return &FeedBuilder{chain: chain, dds: dds}
+}
+
+// SetBlockPolicy makes the builder consult the local viewer's block list:
+// posts by blocked authors are dropped from pages, posts by muted authors
+// are kept but flagged. The policy is checked at build time, so blocking
+// takes effect on the next page without touching the chain. A nil policy
+// disables filtering (the default).
+func (b *FeedBuilder) SetBlockPolicy(policy BlockPolicy) {
+	b.blocks = policy
+}
+
+// BuildFeedPage returns up to pageSize feed items for viewer starting at
+// cursor, in chain order, together with the cursor for the next page.
+// Passing the returned cursor back in resumes exactly where the page
+// ended, with no duplicates or gaps even if blocks were appended in
+// between.
+func (b *FeedBuilder) BuildFeedPage(viewer string, cursor FeedCursor, pageSize int) ([]FeedItem, FeedCursor, error) {
+	if pageSize < 1 {
+		return nil, cursor, fmt.Errorf("feed: page size must be at least 1, got %d", pageSize)
+	}
+	return b.walk(viewer, cursor, pageSize)
+}
+
+// Since returns every item newer than cursor, for pull-to-refresh flows:
+// clients keep the cursor from their last fetch and receive only what the
+// chain gained since.
+func (b *FeedBuilder) Since(viewer string, cursor FeedCursor) ([]FeedItem, FeedCursor, error) {
+	return b.walk(viewer, cursor, 0)
+}
+
+// walk scans the chain from cursor collecting up to limit items for viewer
+// (no limit when limit <= 0) and returns the cursor one past the last
+// examined transaction.
+func (b *FeedBuilder) walk(viewer string, cursor FeedCursor, limit int) ([]FeedItem, FeedCursor, error) {
+	blocks := b.chain.Blocks()
+	if len(blocks) == 0 {
+		return nil, cursor, nil
+	}
+	following := b.followSet(blocks, viewer)
+
+	var items []FeedItem
+	for _, block := range blocks {
+		if block.Index < cursor.BlockIndex {
+			continue
+		}
+		start := 0
+		if block.Index == cursor.BlockIndex {
+			start = cursor.TxIndex
+		}
+		for i := start; i < len(block.Transactions); i++ {
+			if limit > 0 && len(items) == limit {
+				return items, FeedCursor{BlockIndex: block.Index, TxIndex: i}, nil
+			}
+			tx := &block.Transactions[i]
+			if tx.Type != ledger.TxTypePostCreated || !following[tx.SenderAddress] {
+				continue
+			}
+			if b.blocks != nil && b.blocks.IsBlocked(tx.SenderAddress) {
+				continue
+			}
+			item := b.retrieveItem(tx)
+			if b.blocks != nil && b.blocks.IsMuted(tx.SenderAddress) {
+				item.Muted = true
+			}
+			items = append(items, item)
+		}
+		cursor = FeedCursor{BlockIndex: block.Index, TxIndex: len(block.Transactions)}
+	}
+	return items, cursor, nil
+}
+
+// retrieveItem fetches a post's content, recording a retrieval failure on
+// the item instead of failing the page.
+func (b *FeedBuilder) retrieveItem(tx *ledger.Transaction) FeedItem {
+	item := FeedItem{
+		Author:     tx.SenderAddress,
+		ContentCID: string(tx.Payload),
+		Timestamp:  tx.Timestamp,
+		TxID:       tx.ID,
+	}
+	content, err := b.dds.Retrieve(item.ContentCID)
+	if err != nil {
+		item.Err = fmt.Errorf("feed: failed to retrieve post %s: %w", item.ContentCID, err)
+		return item
+	}
+	// A post transaction pointing at a profile document is a client
+	// mistake; surface it as a typed error instead of rendering the raw
+	// profile JSON as post content.
+	if manifest, err := b.dds.FetchManifest(item.ContentCID); err == nil &&
+		manifest.ContentType == user.ProfileContentType && user.LooksLikeProfile(content) {
+		item.Err = &user.ErrWrongContentType{CID: item.ContentCID, Expected: "post content", Got: manifest.ContentType}
+		return item
+	}
+	item.Content = content
+	return item
+}
+
+// followSet derives who the viewer follows from the follow/unfollow
+// transactions on the given blocks, in chain order so the latest action
+// wins. Viewers always see their own posts.
+func (b *FeedBuilder) followSet(blocks []*ledger.Block, viewer string) map[string]bool {
+	following := map[string]bool{viewer: true}
+	for _, block := range blocks {
+		for i := range block.Transactions {
+			tx := &block.Transactions[i]
+			if tx.SenderAddress != viewer {
+				continue
+			}
+			switch tx.Type {
+			case ledger.TxTypeFollowed:
+				following[string(tx.Payload)] = true
+			case ledger.TxTypeUnfollowed:
+				delete(following, string(tx.Payload))
+			}
+		}
+	}
+	return following
+}
@@ -0,0 +1,277 @@
+package feed_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/feed"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+type feedFixture struct {
+	chain   *ledger.Blockchain
+	dds     *service.DDSCoreService
+	builder *feed.FeedBuilder
+	viewer  *identity.Wallet
+	author  *identity.Wallet
+}
+
+func newFeedFixture(t *testing.T) *feedFixture {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-feed-test", "127.0.0.1:9130"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	chain := ledger.NewBlockchain()
+	viewer, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	author, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	f := &feedFixture{
+		chain:   chain,
+		dds:     dds,
+		builder: feed.NewFeedBuilder(chain, dds),
+		viewer:  viewer,
+		author:  author,
+	}
+	f.addTx(t, viewer, ledger.TxTypeFollowed, []byte(author.Address))
+	return f
+}
+
+func (f *feedFixture) addTx(t *testing.T, wallet *identity.Wallet, txType ledger.TransactionType, payload []byte) {
+	t.Helper()
+	tx, err := ledger.NewSignedTransaction(wallet, txType, payload)
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+}
+
+// post publishes content to the DDS and records the post on chain,
+// returning the content CID.
+func (f *feedFixture) post(t *testing.T, wallet *identity.Wallet, content []byte) string {
+	t.Helper()
+	cid, err := f.dds.Publish(content, "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	f.addTx(t, wallet, ledger.TxTypePostCreated, []byte(cid))
+	return cid
+}
+
+func TestBuildFeedPage_PaginatesWithoutDuplicatesOrGaps(t *testing.T) {
+	f := newFeedFixture(t)
+	stranger, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	var want []string
+	for i := 0; i < 100; i++ {
+		want = append(want, f.post(t, f.author, []byte(fmt.Sprintf("post number %d", i))))
+		if i == 50 {
+			// A post from an unfollowed author must not surface.
+			f.post(t, stranger, []byte("stranger noise"))
+		}
+	}
+
+	var got []string
+	cursor := feed.FeedCursor{}
+	for page := 0; page < 10; page++ {
+		items, next, err := f.builder.BuildFeedPage(f.viewer.Address, cursor, 10)
+		if err != nil {
+			t.Fatalf("BuildFeedPage %d: %v", page, err)
+		}
+		if len(items) != 10 {
+			t.Fatalf("page %d has %d items, want 10", page, len(items))
+		}
+		for _, item := range items {
+			if item.Err != nil {
+				t.Fatalf("item %s failed retrieval: %v", item.ContentCID, item.Err)
+			}
+			got = append(got, item.ContentCID)
+		}
+		cursor = next
+	}
+	if len(got) != len(want) {
+		t.Fatalf("paginated %d items, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("item %d = %s, want %s (duplicate or gap)", i, got[i], want[i])
+		}
+	}
+
+	// The feed is exhausted: the next page is empty.
+	items, _, err := f.builder.BuildFeedPage(f.viewer.Address, cursor, 10)
+	if err != nil {
+		t.Fatalf("BuildFeedPage past end: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("page past the end has %d items, want 0", len(items))
+	}
+}
+
+func TestSince_ReturnsOnlyNewItems(t *testing.T) {
+	f := newFeedFixture(t)
+	for i := 0; i < 5; i++ {
+		f.post(t, f.author, []byte(fmt.Sprintf("old post %d", i)))
+	}
+	_, cursor, err := f.builder.Since(f.viewer.Address, feed.FeedCursor{})
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+
+	var fresh []string
+	for i := 0; i < 3; i++ {
+		fresh = append(fresh, f.post(t, f.author, []byte(fmt.Sprintf("new post %d", i))))
+	}
+	items, _, err := f.builder.Since(f.viewer.Address, cursor)
+	if err != nil {
+		t.Fatalf("Since after growth: %v", err)
+	}
+	if len(items) != len(fresh) {
+		t.Fatalf("Since returned %d items, want %d", len(items), len(fresh))
+	}
+	for i, item := range items {
+		if item.ContentCID != fresh[i] {
+			t.Errorf("item %d = %s, want %s", i, item.ContentCID, fresh[i])
+		}
+	}
+}
+
+func TestBuildFeedPage_RetrievalFailureKeepsContinuity(t *testing.T) {
+	f := newFeedFixture(t)
+	first := f.post(t, f.author, []byte("retrievable one"))
+	// A post pointing at content nobody holds.
+	f.addTx(t, f.author, ledger.TxTypePostCreated, []byte("cid-nobody-holds"))
+	third := f.post(t, f.author, []byte("retrievable two"))
+
+	items, _, err := f.builder.Since(f.viewer.Address, feed.FeedCursor{})
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("feed has %d items, want 3 including the broken one", len(items))
+	}
+	if items[0].ContentCID != first || items[2].ContentCID != third {
+		t.Error("items out of order around the broken post")
+	}
+	if items[1].Err == nil || items[1].Content != nil {
+		t.Errorf("broken post not surfaced as failed: %+v", items[1])
+	}
+	if !bytes.Equal(items[0].Content, []byte("retrievable one")) {
+		t.Error("retrievable content missing from item")
+	}
+}
+
+func TestFeed_UnfollowRemovesAuthor(t *testing.T) {
+	f := newFeedFixture(t)
+	f.post(t, f.author, []byte("seen while followed"))
+	f.addTx(t, f.viewer, ledger.TxTypeUnfollowed, []byte(f.author.Address))
+
+	items, _, err := f.builder.Since(f.viewer.Address, feed.FeedCursor{})
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("feed after unfollow has %d items, want 0", len(items))
+	}
+}
+
+func TestBuildFeedPage_BlockListFiltersWithoutChainRebuild(t *testing.T) {
+	f := newFeedFixture(t)
+	muted, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	f.addTx(t, f.viewer, ledger.TxTypeFollowed, []byte(muted.Address))
+	f.post(t, f.author, []byte("from the soon-blocked author"))
+	f.post(t, muted, []byte("from the muted author"))
+
+	list := user.NewBlockList()
+	f.builder.SetBlockPolicy(list)
+	if err := list.Block(f.author.Address); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	if err := list.Mute(muted.Address); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+
+	items, _, err := f.builder.BuildFeedPage(f.viewer.Address, feed.FeedCursor{}, 10)
+	if err != nil {
+		t.Fatalf("BuildFeedPage: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("page has %d items, want only the muted author's post", len(items))
+	}
+	if items[0].Author != muted.Address || !items[0].Muted {
+		t.Errorf("item %+v, want the muted author's post flagged as muted", items[0])
+	}
+
+	// Unblocking restores the author on the very next build.
+	if err := list.Unblock(f.author.Address); err != nil {
+		t.Fatalf("Unblock: %v", err)
+	}
+	items, _, err = f.builder.BuildFeedPage(f.viewer.Address, feed.FeedCursor{}, 10)
+	if err != nil {
+		t.Fatalf("BuildFeedPage after unblock: %v", err)
+	}
+	if len(items) != 2 {
+		t.Errorf("page has %d items after unblock, want 2", len(items))
+	}
+}
+
+func TestFeedItem_ProfileDocumentAsPostIsTypedError(t *testing.T) {
+	f := newFeedFixture(t)
+	profile, err := user.NewProfile(f.author.Address, "Ada", "not a post")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	if err := profile.Sign(f.author); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	data, err := profile.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	cid, err := f.dds.Publish(data, "application/json")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	f.addTx(t, f.author, ledger.TxTypePostCreated, []byte(cid))
+
+	items, _, err := f.builder.BuildFeedPage(f.viewer.Address, feed.FeedCursor{}, 10)
+	if err != nil {
+		t.Fatalf("BuildFeedPage: %v", err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("page has %d items, want 1", len(items))
+	}
+	var wrongType *user.ErrWrongContentType
+	if !errors.As(items[0].Err, &wrongType) {
+		t.Fatalf("item error %v is not *ErrWrongContentType", items[0].Err)
+	}
+	if items[0].Content != nil {
+		t.Error("profile JSON surfaced as post content")
+	}
+}
@@ -0,0 +1,126 @@
+package ledger
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// Block is one link in the chain: a batch of transactions bound together by
+// a Merkle root, hash-linked to its predecessor.
+type Block struct {
+	Index        int64         `json:"index"`
+	Timestamp    int64         `json:"timestamp"`
+	Transactions []Transaction `json:"transactions,omitempty"`
+	PrevHash     string        `json:"prevHash"`
+	MerkleRoot   string        `json:"merkleRoot"`
+	Hash         string        `json:"hash"`
+
+	// Proof-of-authority fields, set when the chain runs with a validator
+	// set. The producer signs the block hash, so the signature covers every
+	// header field without being part of the hash itself.
+	ProducerAddress   string `json:"producerAddress,omitempty"`
+	ProducerPublicKey []byte `json:"producerPublicKey,omitempty"`
+	ProducerSignature []byte `json:"producerSignature,omitempty"`
+
+	// Pruned marks a block whose transaction bodies were discarded by
+	// Blockchain.Prune: the header (including MerkleRoot and Hash) is
+	// intact, Transactions is empty, and body validation is skipped. Only
+	// local pruning sets it — blocks arriving from peers must carry their
+	// bodies.
+	Pruned bool `json:"pruned,omitempty"`
+}
+
+// NewBlock builds a block over the given transactions, stamped with the
+// current time and linked to prevHash.
+func NewBlock(index int64, transactions []Transaction, prevHash string) *Block {
+	b := &Block{
+		Index:        index,
+		Timestamp:    clock.Now(),
+		Transactions: transactions,
+		PrevHash:     prevHash,
+	}
+	b.MerkleRoot = CalculateMerkleRoot(transactions)
+	b.Hash = b.CalculateBlockHash()
+	return b
+}
+
+// EmptyMerkleRoot is the Merkle root of an empty transaction set: the
+// SHA-256 of empty input. It feeds the genesis block hash, so it is pinned
+// as an explicit constant — a change to empty-set handling must fail
+// loudly here rather than silently fork every node's genesis.
+const EmptyMerkleRoot = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// CalculateMerkleRoot folds the transaction IDs pairwise into a single
+// root. An empty transaction set yields EmptyMerkleRoot.
+func CalculateMerkleRoot(transactions []Transaction) string {
+	if len(transactions) == 0 {
+		return EmptyMerkleRoot
+	}
+	layer := make([]string, len(transactions))
+	for i, tx := range transactions {
+		layer[i] = tx.ID
+	}
+	for len(layer) > 1 {
+		if len(layer)%2 == 1 {
+			layer = append(layer, layer[len(layer)-1])
+		}
+		next := make([]string, 0, len(layer)/2)
+		for i := 0; i < len(layer); i += 2 {
+			next = append(next, hashToHex([]byte(layer[i]+layer[i+1])))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// CalculateBlockHash returns the hash binding the block header fields.
+func (b *Block) CalculateBlockHash() string {
+	header := strconv.FormatInt(b.Index, 10) +
+		strconv.FormatInt(b.Timestamp, 10) +
+		b.PrevHash +
+		b.MerkleRoot
+	return hashToHex([]byte(header))
+}
+
+// IsBlockValid checks that block correctly extends prev: index and hash
+// linkage, header hash, Merkle root, and every transaction signature.
+func IsBlockValid(block, prev *Block) error {
+	if block.Index != prev.Index+1 {
+		return fmt.Errorf("ledger: block index %d does not follow %d", block.Index, prev.Index)
+	}
+	if block.PrevHash != prev.Hash {
+		return fmt.Errorf("ledger: block %d prev hash does not match block %d hash", block.Index, prev.Index)
+	}
+	return isBlockSelfConsistent(block)
+}
+
+// isBlockSelfConsistent checks the block's own hashes and transaction
+// signatures, independent of its predecessor. A pruned block has no bodies
+// left to check: only its header hash is verified, which still pins the
+// Merkle root the discarded transactions committed to.
+func isBlockSelfConsistent(block *Block) error {
+	if block.Pruned {
+		if got := block.CalculateBlockHash(); got != block.Hash {
+			return fmt.Errorf("ledger: block %d hash mismatch", block.Index)
+		}
+		return nil
+	}
+	if got := CalculateMerkleRoot(block.Transactions); got != block.MerkleRoot {
+		return fmt.Errorf("ledger: block %d Merkle root mismatch", block.Index)
+	}
+	if got := block.CalculateBlockHash(); got != block.Hash {
+		return fmt.Errorf("ledger: block %d hash mismatch", block.Index)
+	}
+	if err := VerifyTransactionsParallel(block.Transactions, 0); err != nil {
+		return fmt.Errorf("ledger: block %d: %w", block.Index, err)
+	}
+	for i := range block.Transactions {
+		if block.Transactions[i].IsExpired(block.Timestamp) {
+			return fmt.Errorf("ledger: block %d transaction %s expired before the block timestamp",
+				block.Index, block.Transactions[i].ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,447 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Blockchain is the in-memory chain. It always holds a contiguous run of
+// blocks; when loaded from a checkpoint the run starts just after the
+// checkpointed block instead of at genesis.
+type Blockchain struct {
+	mu         sync.RWMutex
+	blocks     []*Block
+	checkpoint *Checkpoint
+
+	// validators, when non-nil, restricts block production to signed blocks
+	// from its members (proof-of-authority).
+	validators *ValidatorSet
+
+	// weightTable prices transactions and maxBlockWeight caps each block's
+	// total; see SetWeightTable and SetMaxBlockWeight.
+	weightTable    WeightTable
+	maxBlockWeight uint64
+
+	// verifyWorkers bounds the goroutines used for batch signature
+	// verification when blocks are appended; see SetVerifyWorkers.
+	verifyWorkers int
+
+	// txIndex maps transaction ID to its location on the chain so lookups
+	// avoid a full scan. It covers exactly the blocks held in blocks and is
+	// maintained at every append site.
+	txIndex map[string]txLocation
+
+	// subMu guards onBlockAdded separately from mu so subscribers can be
+	// notified without holding the chain lock.
+	subMu        sync.Mutex
+	onBlockAdded []func(*Block)
+	// chanSubs holds channel subscribers registered via Subscribe, keyed
+	// by a token so they can be cancelled; see subscribe.go.
+	chanSubs  map[int]chan *Block
+	nextSubID int
+
+	// waiters backs WaitForTransaction; see wait.go.
+	waiters txWaiters
+
+	// pruned, when non-nil, records which blocks keep only headers; see
+	// Prune in prune.go.
+	pruned *PruneState
+}
+
+// txLocation addresses one transaction on the chain by block index and
+// position within that block.
+type txLocation struct {
+	blockIndex int64
+	pos        int
+}
+
+// NewBlockchain returns a chain containing only the genesis block.
+func NewBlockchain() *Blockchain {
+	return &Blockchain{
+		blocks:         []*Block{NewBlock(0, nil, "")},
+		weightTable:    DefaultWeightTable(),
+		maxBlockWeight: DefaultMaxBlockWeight,
+		txIndex:        make(map[string]txLocation),
+	}
+}
+
+// indexBlock records the locations of a block's transactions. Callers must
+// hold the write lock.
+func (bc *Blockchain) indexBlock(block *Block) {
+	for i := range block.Transactions {
+		bc.txIndex[block.Transactions[i].ID] = txLocation{blockIndex: block.Index, pos: i}
+	}
+}
+
+// SetWeightTable replaces the table pricing transactions for the block
+// weight cap. Call it before blocks are produced; the table is not
+// re-applied retroactively.
+func (bc *Blockchain) SetWeightTable(table WeightTable) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.weightTable = table
+}
+
+// WeightTable returns the table currently pricing transactions.
+func (bc *Blockchain) WeightTable() WeightTable {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.weightTable
+}
+
+// SetMaxBlockWeight changes the per-block weight budget. Zero or less
+// removes the cap.
+func (bc *Blockchain) SetMaxBlockWeight(max uint64) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.maxBlockWeight = max
+}
+
+// checkWeight rejects blocks over the weight budget. Callers hold the
+// lock.
+func (bc *Blockchain) checkWeight(block *Block) error {
+	return checkBlockWeight(bc.weightTable, bc.maxBlockWeight, block)
+}
+
+// checkBlockWeight enforces a weight budget without touching chain state,
+// so it can run outside the chain lock.
+func checkBlockWeight(table WeightTable, max uint64, block *Block) error {
+	if max == 0 {
+		return nil
+	}
+	if weight := table.BlockWeight(block.Transactions); weight > max {
+		return fmt.Errorf("ledger: block %d weight %d exceeds the limit %d", block.Index, weight, max)
+	}
+	return nil
+}
+
+// SetVerifyWorkers bounds how many goroutines batch signature
+// verification uses when appending blocks. Values below 1 restore the
+// default of GOMAXPROCS.
+func (bc *Blockchain) SetVerifyWorkers(n int) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.verifyWorkers = n
+}
+
+// Height returns the number of blocks on the chain, counting any blocks
+// summarized by a checkpoint.
+func (bc *Blockchain) Height() int64 {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.headIndex() + 1
+}
+
+// headIndex returns the index of the latest block. Callers must hold the
+// lock.
+func (bc *Blockchain) headIndex() int64 {
+	if len(bc.blocks) > 0 {
+		return bc.blocks[len(bc.blocks)-1].Index
+	}
+	return bc.checkpoint.Index
+}
+
+// headHash returns the hash of the latest block (or the checkpointed block
+// when no tail blocks are held). Callers must hold the lock.
+func (bc *Blockchain) headHash() string {
+	if len(bc.blocks) > 0 {
+		return bc.blocks[len(bc.blocks)-1].Hash
+	}
+	return bc.checkpoint.BlockHash
+}
+
+// LatestBlock returns the most recent block, or nil when the chain was
+// loaded from a checkpoint with no tail.
+func (bc *Blockchain) LatestBlock() *Block {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	if len(bc.blocks) == 0 {
+		return nil
+	}
+	return bc.blocks[len(bc.blocks)-1]
+}
+
+// GetBlock returns the block at the given index. Blocks at or before a
+// loaded checkpoint are not available.
+func (bc *Blockchain) GetBlock(index int64) (*Block, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	if len(bc.blocks) == 0 || index < bc.blocks[0].Index || index > bc.headIndex() {
+		return nil, fmt.Errorf("ledger: block %d is not available on this chain", index)
+	}
+	return bc.blocks[index-bc.blocks[0].Index], nil
+}
+
+// AddBlock validates the transactions, builds the next block, and appends
+// it. Subscribers registered via OnBlockAdded are notified after the
+// append succeeds.
+func (bc *Blockchain) AddBlock(transactions []Transaction) (*Block, error) {
+	block, err := bc.appendBlock(transactions)
+	if err != nil {
+		return nil, err
+	}
+	bc.notifyBlockAdded(block)
+	return block, nil
+}
+
+func (bc *Blockchain) appendBlock(transactions []Transaction) (*Block, error) {
+	// Signature checks are expensive ECDSA operations; run them in
+	// parallel before taking any lock so readers are not blocked behind
+	// them.
+	bc.mu.RLock()
+	workers := bc.verifyWorkers
+	bc.mu.RUnlock()
+	if err := VerifyTransactionsParallel(transactions, workers); err != nil {
+		return nil, err
+	}
+	for {
+		bc.mu.RLock()
+		prevHash := bc.headHash()
+		index := bc.headIndex() + 1
+		var prev *Block
+		if len(bc.blocks) > 0 {
+			prev = bc.blocks[len(bc.blocks)-1]
+		}
+		validators := bc.validators
+		table, maxWeight := bc.weightTable, bc.maxBlockWeight
+		bc.mu.RUnlock()
+
+		// Build and validate the candidate against the snapshotted head.
+		// Appended blocks are immutable, so prev is safe to read unlocked.
+		block := NewBlock(index, transactions, prevHash)
+		if err := checkBlockWeight(table, maxWeight, block); err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			if err := IsBlockValid(block, prev); err != nil {
+				return nil, err
+			}
+		} else if err := isBlockSelfConsistent(block); err != nil {
+			return nil, err
+		}
+		if err := checkProducerAgainst(validators, block); err != nil {
+			return nil, err
+		}
+
+		bc.mu.Lock()
+		if bc.headHash() != prevHash {
+			// Another producer appended first; rebuild on the new head.
+			bc.mu.Unlock()
+			continue
+		}
+		bc.blocks = append(bc.blocks, block)
+		bc.indexBlock(block)
+		bc.mu.Unlock()
+		return block, nil
+	}
+}
+
+// ErrStaleBlock reports a peer block at or below the local head: not an
+// attack, just news that already arrived another way.
+var ErrStaleBlock = errors.New("ledger: peer block is stale")
+
+// AddBlockFromPeer validates a block produced elsewhere and appends it if
+// it directly extends the local head. A block at or below the head is
+// reported as ErrStaleBlock; a block further ahead than head+1 is an error
+// too — the caller should sync the gap first. Any other failure means the
+// block itself is invalid. Forks are not resolved here.
+func (bc *Blockchain) AddBlockFromPeer(block *Block) error {
+	if block == nil {
+		return fmt.Errorf("ledger: cannot add a nil peer block")
+	}
+	if block.Pruned {
+		// Pruned is a local storage state; a peer block claiming it would
+		// dodge every body check.
+		return fmt.Errorf("ledger: peer block %d claims to be pruned; peer blocks must carry their transactions", block.Index)
+	}
+	for {
+		bc.mu.RLock()
+		head := bc.headIndex()
+		var prev *Block
+		if len(bc.blocks) > 0 {
+			prev = bc.blocks[len(bc.blocks)-1]
+		}
+		validators := bc.validators
+		table, maxWeight := bc.weightTable, bc.maxBlockWeight
+		bc.mu.RUnlock()
+
+		if block.Index <= head {
+			return fmt.Errorf("%w: block %d at or below head %d", ErrStaleBlock, block.Index, head)
+		}
+		if block.Index > head+1 {
+			return fmt.Errorf("ledger: peer block %d leaves a gap behind head %d; sync the missing blocks first",
+				block.Index, head)
+		}
+		if err := checkBlockWeight(table, maxWeight, block); err != nil {
+			return err
+		}
+		if prev != nil {
+			if err := IsBlockValid(block, prev); err != nil {
+				return err
+			}
+		} else if err := isBlockSelfConsistent(block); err != nil {
+			return err
+		}
+		if err := checkProducerAgainst(validators, block); err != nil {
+			return err
+		}
+
+		bc.mu.Lock()
+		if bc.headIndex() != head {
+			// The head moved while validating; re-check against it.
+			bc.mu.Unlock()
+			continue
+		}
+		bc.blocks = append(bc.blocks, block)
+		bc.indexBlock(block)
+		bc.mu.Unlock()
+		bc.notifyBlockAdded(block)
+		return nil
+	}
+}
+
+// OnBlockAdded registers fn to be called after each successfully appended
+// block. Multiple subscribers are supported and called in registration
+// order. Callbacks run outside the chain lock — they may call back into
+// the chain — and after the block is already appended, so a panicking
+// subscriber cannot corrupt the chain.
+func (bc *Blockchain) OnBlockAdded(fn func(*Block)) {
+	bc.subMu.Lock()
+	defer bc.subMu.Unlock()
+	bc.onBlockAdded = append(bc.onBlockAdded, fn)
+}
+
+// notifyBlockAdded invokes the subscribers for an appended block,
+// isolating each so one panicking does not starve the rest.
+func (bc *Blockchain) notifyBlockAdded(block *Block) {
+	bc.subMu.Lock()
+	subs := make([]func(*Block), len(bc.onBlockAdded))
+	copy(subs, bc.onBlockAdded)
+	for _, ch := range bc.chanSubs {
+		select {
+		case ch <- block:
+		default:
+			// A subscriber that stopped draining its buffer loses blocks
+			// rather than stalling the chain; see Subscribe.
+		}
+	}
+	bc.subMu.Unlock()
+	for _, fn := range subs {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("ledger: warning: block-added subscriber panicked on block %d: %v", block.Index, r)
+				}
+			}()
+			fn(block)
+		}()
+	}
+}
+
+// GetTransactionByID looks a transaction up in the chain-wide index,
+// returning it together with its containing block.
+func (bc *Blockchain) GetTransactionByID(txID string) (*Transaction, *Block, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	tx, block := bc.lookupTx(txID)
+	if tx == nil {
+		if block != nil && block.Pruned {
+			return nil, nil, fmt.Errorf("%w: transaction %s was in block %d", ErrPruned, txID, block.Index)
+		}
+		return nil, nil, fmt.Errorf("ledger: transaction %s not found", txID)
+	}
+	return tx, block, nil
+}
+
+// GetTransactionsByIDs looks up many transactions in one call. Found
+// transactions are keyed by ID; IDs with no match are returned separately
+// so one unknown ID does not fail the batch.
+func (bc *Blockchain) GetTransactionsByIDs(ids []string) (map[string]*Transaction, []string) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	found := make(map[string]*Transaction, len(ids))
+	seen := make(map[string]bool, len(ids))
+	var missing []string
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		tx, _ := bc.lookupTx(id)
+		if tx == nil {
+			missing = append(missing, id)
+			continue
+		}
+		found[id] = tx
+	}
+	return found, missing
+}
+
+// lookupTx resolves a transaction ID through the index. A transaction
+// whose block was pruned resolves to a nil transaction with its (header
+// only) block, so callers can tell pruned from unknown. Callers must hold
+// the lock.
+func (bc *Blockchain) lookupTx(txID string) (*Transaction, *Block) {
+	loc, ok := bc.txIndex[txID]
+	if !ok || len(bc.blocks) == 0 {
+		return nil, nil
+	}
+	block := bc.blocks[loc.blockIndex-bc.blocks[0].Index]
+	if block.Pruned {
+		return nil, block
+	}
+	return &block.Transactions[loc.pos], block
+}
+
+// IsChainValid revalidates linkage and contents of every available block.
+// Across a pruned region only header linkage can be checked; the Pruned
+// marker makes that explicit, and a block claiming it outside the recorded
+// pruned range is rejected.
+func (bc *Blockchain) IsChainValid() error {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	for _, block := range bc.blocks {
+		if block.Pruned && (bc.pruned == nil || block.Index > bc.pruned.Through) {
+			return fmt.Errorf("ledger: block %d is marked pruned outside the recorded pruned range", block.Index)
+		}
+	}
+	for i, block := range bc.blocks {
+		if i == 0 {
+			if bc.checkpoint != nil && block.PrevHash != bc.checkpoint.BlockHash {
+				return fmt.Errorf("ledger: block %d does not link to the loaded checkpoint", block.Index)
+			}
+			if err := isBlockSelfConsistent(block); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := IsBlockValid(block, bc.blocks[i-1]); err != nil {
+			return err
+		}
+	}
+	// Genesis carries no producer signature, so the validator check only
+	// applies to later blocks.
+	for _, block := range bc.blocks {
+		if block.Index == 0 {
+			continue
+		}
+		if err := bc.checkProducer(block); err != nil {
+			return err
+		}
+		if err := bc.checkWeight(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Blocks returns a snapshot of the available blocks in order.
+func (bc *Blockchain) Blocks() []*Block {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	out := make([]*Block, len(bc.blocks))
+	copy(out, bc.blocks)
+	return out
+}
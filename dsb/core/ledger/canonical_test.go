@@ -0,0 +1,75 @@
+package ledger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// inTimeZone runs fn with time.Local swapped for the given zone, restoring
+// it afterwards. FixedZone avoids depending on the host's tzdata.
+func inTimeZone(t *testing.T, loc *time.Location, fn func()) {
+	t.Helper()
+	prev := time.Local
+	time.Local = loc
+	defer func() { time.Local = prev }()
+	fn()
+}
+
+func TestTransaction_CanonicalHashIsTimezoneIndependent(t *testing.T) {
+	prevClock := clock.Set(clock.NewFake(1700000000000000000))
+	defer clock.Set(prevClock)
+
+	zones := []*time.Location{
+		time.UTC,
+		time.FixedZone("UTC+9", 9*60*60),
+		time.FixedZone("UTC-5", -5*60*60),
+	}
+	hashes := make([]string, 0, len(zones))
+	ids := make([]string, 0, len(zones))
+	for _, zone := range zones {
+		inTimeZone(t, zone, func() {
+			tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, "address-tz", []byte("cid-tz"),
+				ledger.WithValidUntil(1700000001000000000), ledger.WithFee(3))
+			if err != nil {
+				t.Fatalf("NewTransaction: %v", err)
+			}
+			hashes = append(hashes, tx.CalculateHash())
+			ids = append(ids, tx.ID)
+		})
+	}
+	for i := 1; i < len(hashes); i++ {
+		if hashes[i] != hashes[0] {
+			t.Errorf("hash differs in zone %s: %s vs %s", zones[i], hashes[i], hashes[0])
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("ID differs in zone %s: %s vs %s", zones[i], ids[i], ids[0])
+		}
+	}
+}
+
+func TestTransaction_SignatureVerifiesAcrossTimezones(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	var tx *ledger.Transaction
+	inTimeZone(t, time.FixedZone("UTC-8", -8*60*60), func() {
+		tx, err = ledger.NewSignedTransaction(wallet, ledger.TxTypeFollowed, []byte("address-followed"))
+		if err != nil {
+			t.Fatalf("NewSignedTransaction: %v", err)
+		}
+	})
+	// A node in a different zone must reach the same signed bytes.
+	inTimeZone(t, time.FixedZone("UTC+13", 13*60*60), func() {
+		if !tx.VerifySignature() {
+			t.Error("signature signed in one zone fails to verify in another")
+		}
+		if tx.CalculateHash() != tx.ID {
+			t.Error("recomputed hash differs from the ID across zones")
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package ledger
+
+import "fmt"
+
+// Checkpoint captures a trusted point on the chain: the block hash and the
+// state root derived from every block hash up to and including that index.
+// A node restarting from a checkpoint skips revalidating everything before
+// it.
+type Checkpoint struct {
+	Index     int64  `json:"index"`
+	BlockHash string `json:"blockHash"`
+	StateRoot string `json:"stateRoot"`
+}
+
+// stateRootAfter folds a block hash into a running state root.
+func stateRootAfter(prevRoot, blockHash string) string {
+	return hashToHex([]byte(prevRoot + blockHash))
+}
+
+// Checkpoint captures the chain state at the given block index.
+func (bc *Blockchain) Checkpoint(index int64) (Checkpoint, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	if len(bc.blocks) == 0 || index < bc.blocks[0].Index || index > bc.headIndex() {
+		return Checkpoint{}, fmt.Errorf("ledger: cannot checkpoint unavailable block %d", index)
+	}
+	root := ""
+	if bc.checkpoint != nil {
+		root = bc.checkpoint.StateRoot
+	}
+	var blockHash string
+	for _, block := range bc.blocks {
+		if block.Index > index {
+			break
+		}
+		root = stateRootAfter(root, block.Hash)
+		blockHash = block.Hash
+	}
+	return Checkpoint{Index: index, BlockHash: blockHash, StateRoot: root}, nil
+}
+
+// LoadFromCheckpoint builds a chain that trusts cp and validates only the
+// tail blocks after it. The first tail block must link directly to the
+// checkpointed block; any linkage or content failure rejects the load.
+func LoadFromCheckpoint(cp Checkpoint, tail []*Block) (*Blockchain, error) {
+	if cp.BlockHash == "" {
+		return nil, fmt.Errorf("ledger: checkpoint is missing a block hash")
+	}
+	for i, block := range tail {
+		if i == 0 {
+			if block.Index != cp.Index+1 {
+				return nil, fmt.Errorf("ledger: tail block index %d does not follow checkpoint index %d",
+					block.Index, cp.Index)
+			}
+			if block.PrevHash != cp.BlockHash {
+				return nil, fmt.Errorf("ledger: tail block %d does not link to checkpoint hash", block.Index)
+			}
+			if err := isBlockSelfConsistent(block); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := IsBlockValid(block, tail[i-1]); err != nil {
+			return nil, err
+		}
+	}
+	blocks := make([]*Block, len(tail))
+	copy(blocks, tail)
+	cpCopy := cp
+	bc := &Blockchain{
+		blocks:         blocks,
+		checkpoint:     &cpCopy,
+		weightTable:    DefaultWeightTable(),
+		maxBlockWeight: DefaultMaxBlockWeight,
+		txIndex:        make(map[string]txLocation),
+	}
+	for _, block := range bc.blocks {
+		bc.indexBlock(block)
+	}
+	return bc, nil
+}
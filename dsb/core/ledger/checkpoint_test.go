@@ -0,0 +1,112 @@
+package ledger_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// addSignedBlock appends a block with one signed transaction from a fresh
+// wallet.
+func addSignedBlock(t *testing.T, bc *ledger.Blockchain, payload string) *ledger.Block {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, wallet.Address, []byte(payload))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := tx.Sign(wallet); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	block, err := bc.AddBlock([]ledger.Transaction{*tx})
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	return block
+}
+
+func TestCheckpoint_MatchesBlockHashAtIndex(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	for i := 0; i < 4; i++ {
+		addSignedBlock(t, bc, "post")
+	}
+
+	cp, err := bc.Checkpoint(2)
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	block, err := bc.GetBlock(2)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	if cp.BlockHash != block.Hash {
+		t.Errorf("checkpoint hash = %s, want block hash %s", cp.BlockHash, block.Hash)
+	}
+	if cp.StateRoot == "" {
+		t.Error("checkpoint state root is empty")
+	}
+
+	// Checkpoints at different indexes have different state roots.
+	cp3, err := bc.Checkpoint(3)
+	if err != nil {
+		t.Fatalf("Checkpoint(3): %v", err)
+	}
+	if cp3.StateRoot == cp.StateRoot {
+		t.Error("state roots for different indexes are identical")
+	}
+
+	if _, err := bc.Checkpoint(99); err == nil {
+		t.Error("want error checkpointing an unavailable index")
+	}
+}
+
+func TestLoadFromCheckpoint_ValidatesOnlyTail(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	for i := 0; i < 5; i++ {
+		addSignedBlock(t, bc, "post")
+	}
+	cp, err := bc.Checkpoint(3)
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	all := bc.Blocks()
+	tail := all[4:]
+
+	loaded, err := ledger.LoadFromCheckpoint(cp, tail)
+	if err != nil {
+		t.Fatalf("LoadFromCheckpoint: %v", err)
+	}
+	if loaded.Height() != bc.Height() {
+		t.Errorf("loaded height = %d, want %d", loaded.Height(), bc.Height())
+	}
+	if err := loaded.IsChainValid(); err != nil {
+		t.Errorf("IsChainValid after checkpoint load: %v", err)
+	}
+	// The loaded chain keeps growing normally.
+	addSignedBlock(t, loaded, "post after restore")
+	if loaded.Height() != bc.Height()+1 {
+		t.Errorf("height after append = %d, want %d", loaded.Height(), bc.Height()+1)
+	}
+}
+
+func TestLoadFromCheckpoint_RejectsMismatchedHash(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	for i := 0; i < 3; i++ {
+		addSignedBlock(t, bc, "post")
+	}
+	cp, err := bc.Checkpoint(1)
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	cp.BlockHash = strings.Repeat("0", 64)
+
+	tail := bc.Blocks()[2:]
+	if _, err := ledger.LoadFromCheckpoint(cp, tail); err == nil {
+		t.Fatal("want rejection for mismatched checkpoint hash, got nil")
+	}
+}
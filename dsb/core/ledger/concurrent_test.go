@@ -0,0 +1,55 @@
+package ledger_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func TestAddBlock_ConcurrentProducers(t *testing.T) {
+	chain := ledger.NewBlockchain()
+	const workers = 8
+	const blocksPerWorker = 5
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wallet, err := identity.NewWallet()
+		if err != nil {
+			t.Fatalf("NewWallet: %v", err)
+		}
+		wg.Add(1)
+		go func(w int, wallet *identity.Wallet) {
+			defer wg.Done()
+			for i := 0; i < blocksPerWorker; i++ {
+				tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, wallet.Address,
+					[]byte(fmt.Sprintf("cid-%d-%d", w, i)))
+				if err != nil {
+					t.Errorf("NewTransaction: %v", err)
+					return
+				}
+				if err := tx.Sign(wallet); err != nil {
+					t.Errorf("Sign: %v", err)
+					return
+				}
+				if _, err := chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+					t.Errorf("AddBlock: %v", err)
+					return
+				}
+				// Interleave reads with the appends.
+				chain.Height()
+				chain.LatestBlock()
+			}
+		}(w, wallet)
+	}
+	wg.Wait()
+
+	if got, want := chain.Height(), int64(1+workers*blocksPerWorker); got != want {
+		t.Errorf("Height = %d, want %d", got, want)
+	}
+	if err := chain.IsChainValid(); err != nil {
+		t.Errorf("IsChainValid after concurrent producers: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// signedTx builds a signed transaction with the given options.
+func signedTx(t *testing.T, opts ...ledger.TxOption) *ledger.Transaction {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, wallet.Address, []byte("cid"), opts...)
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := tx.Sign(wallet); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return tx
+}
+
+func TestMempoolSubmit_RejectsExpired(t *testing.T) {
+	pool := ledger.NewMempool()
+	expired := signedTx(t, ledger.WithValidUntil(time.Now().Add(-time.Second).UnixNano()))
+	if err := pool.Submit(expired); !errors.Is(err, ledger.ErrTxExpired) {
+		t.Fatalf("want ErrTxExpired, got %v", err)
+	}
+
+	fresh := signedTx(t, ledger.WithValidUntil(time.Now().Add(time.Hour).UnixNano()))
+	if err := pool.Submit(fresh); err != nil {
+		t.Fatalf("Submit(fresh): %v", err)
+	}
+	if pool.Len() != 1 {
+		t.Errorf("mempool len = %d, want 1", pool.Len())
+	}
+}
+
+func TestAddBlock_RejectsTxExpiredAtBlockTime(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	expired := signedTx(t, ledger.WithValidUntil(time.Now().Add(-time.Minute).UnixNano()))
+	if _, err := bc.AddBlock([]ledger.Transaction{*expired}); err == nil {
+		t.Fatal("want block rejection for expired transaction, got nil")
+	}
+}
+
+func TestAddBlock_AcceptsUnexpiredBoundary(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	// Valid until well after the block will be stamped.
+	tx := signedTx(t, ledger.WithValidUntil(time.Now().Add(time.Hour).UnixNano()))
+	if _, err := bc.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock(unexpired): %v", err)
+	}
+
+	// Zero ValidUntil means no expiry.
+	forever := signedTx(t)
+	if _, err := bc.AddBlock([]ledger.Transaction{*forever}); err != nil {
+		t.Fatalf("AddBlock(no expiry): %v", err)
+	}
+}
+
+func TestValidUntil_CoveredBySignature(t *testing.T) {
+	tx := signedTx(t, ledger.WithValidUntil(time.Now().Add(time.Hour).UnixNano()))
+	tx.ValidUntil += 1
+	if tx.VerifySignature() {
+		t.Fatal("signature still valid after mutating ValidUntil")
+	}
+}
@@ -0,0 +1,67 @@
+package ledger_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/internal/compat"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// goldenEnv freezes the clock at a fixed instant, restoring it afterwards.
+// (It no longer needs to pin the time zone: the canonical signing bytes
+// are timezone-independent.)
+func goldenEnv(t *testing.T) {
+	t.Helper()
+	prevClock := clock.Set(clock.NewFake(1700000000000000000))
+	t.Cleanup(func() {
+		clock.Set(prevClock)
+	})
+}
+
+func goldenTransactions(t *testing.T) []ledger.Transaction {
+	t.Helper()
+	first, err := ledger.NewTransaction(ledger.TxTypePostCreated, "address-golden", []byte("cid-golden"),
+		ledger.WithValidUntil(1700000001000000000), ledger.WithFee(7))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	second, err := ledger.NewTransaction(ledger.TxTypeFollowed, "address-golden", []byte("address-followed"))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	return []ledger.Transaction{*first, *second}
+}
+
+func TestGoldenTransactionID(t *testing.T) {
+	goldenEnv(t)
+	txs := goldenTransactions(t)
+	compat.AssertStableCID(t, "tx-id", txs[0].ID)
+	compat.AssertStableCID(t, "tx-id-minimal", txs[1].ID)
+}
+
+func TestGoldenBlockHash(t *testing.T) {
+	goldenEnv(t)
+	block := ledger.NewBlock(1, goldenTransactions(t), "prev-hash-golden")
+	compat.AssertStableCID(t, "merkle-root", block.MerkleRoot)
+	compat.AssertStableCID(t, "block-hash", block.Hash)
+}
+
+func TestGoldenGenesisBlock(t *testing.T) {
+	goldenEnv(t)
+	sum := sha256.Sum256(nil)
+	if ledger.EmptyMerkleRoot != hex.EncodeToString(sum[:]) {
+		t.Fatalf("EmptyMerkleRoot %s is not the SHA-256 of empty input %x", ledger.EmptyMerkleRoot, sum)
+	}
+	genesis, err := ledger.NewBlockchain().GetBlock(0)
+	if err != nil {
+		t.Fatalf("GetBlock: %v", err)
+	}
+	if genesis.MerkleRoot != ledger.EmptyMerkleRoot {
+		t.Errorf("genesis Merkle root %s, want EmptyMerkleRoot", genesis.MerkleRoot)
+	}
+	compat.AssertStableCID(t, "genesis-merkle-root", genesis.MerkleRoot)
+	compat.AssertStableCID(t, "genesis-hash", genesis.Hash)
+}
@@ -0,0 +1,138 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultGossipHops bounds how many times a block announcement is
+// re-broadcast. Each relay decrements the budget, so an announcement dies
+// out instead of circulating forever on cyclic topologies.
+const DefaultGossipHops = 8
+
+// GossipNetwork routes block announcements between gossip nodes inside one
+// process — the chain-level counterpart of the DDS mock P2P service. It
+// also keeps a reputation score per node, penalized when a node relays an
+// invalid block.
+type GossipNetwork struct {
+	mu         sync.RWMutex
+	nodes      map[string]*GossipNode
+	reputation map[string]int
+}
+
+// NewGossipNetwork returns a network with no nodes.
+func NewGossipNetwork() *GossipNetwork {
+	return &GossipNetwork{
+		nodes:      make(map[string]*GossipNode),
+		reputation: make(map[string]int),
+	}
+}
+
+// Join attaches a chain to the network under the given node ID and returns
+// its gossip endpoint.
+func (n *GossipNetwork) Join(id string, chain *Blockchain) (*GossipNode, error) {
+	if id == "" || chain == nil {
+		return nil, fmt.Errorf("ledger: gossip node requires an ID and a chain")
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.nodes[id]; ok {
+		return nil, fmt.Errorf("ledger: gossip node %s already joined", id)
+	}
+	node := &GossipNode{id: id, chain: chain, network: n, seen: make(map[string]bool)}
+	n.nodes[id] = node
+	return node, nil
+}
+
+// Reputation returns the node's current score; it starts at zero and only
+// invalid-block penalties move it.
+func (n *GossipNetwork) Reputation(id string) int {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.reputation[id]
+}
+
+func (n *GossipNetwork) penalize(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.reputation[id]--
+}
+
+// relay delivers the block to every node except the sender and the one it
+// came from. Deliveries are synchronous, so tests observe a settled
+// network when the originating broadcast returns.
+func (n *GossipNetwork) relay(fromID string, block *Block, hops int) {
+	n.mu.RLock()
+	targets := make([]*GossipNode, 0, len(n.nodes))
+	for id, node := range n.nodes {
+		if id == fromID {
+			continue
+		}
+		targets = append(targets, node)
+	}
+	n.mu.RUnlock()
+	for _, node := range targets {
+		// Per-node errors are the receiver's business (it penalized the
+		// sender already); gossip keeps flowing to the rest.
+		_ = node.ReceiveBlock(fromID, block, hops)
+	}
+}
+
+// GossipNode is one chain's endpoint on a gossip network.
+type GossipNode struct {
+	id      string
+	chain   *Blockchain
+	network *GossipNetwork
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// markSeen records the hash, reporting whether it was new.
+func (g *GossipNode) markSeen(hash string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.seen[hash] {
+		return false
+	}
+	g.seen[hash] = true
+	return true
+}
+
+// BroadcastBlock announces a locally produced block to every peer with the
+// default hop budget. The block is assumed to already be on the local
+// chain (e.g. just minted).
+func (g *GossipNode) BroadcastBlock(block *Block) error {
+	if block == nil || block.Hash == "" {
+		return fmt.Errorf("ledger: cannot broadcast a block without a hash")
+	}
+	g.markSeen(block.Hash)
+	g.network.relay(g.id, block, DefaultGossipHops)
+	return nil
+}
+
+// ReceiveBlock handles an announcement relayed by peer from. A hash seen
+// before is dropped silently; a valid new block is appended to the local
+// chain and re-gossiped with one less hop; an invalid block is dropped and
+// the sender penalized. Stale blocks are neither appended nor penalized —
+// the news just arrived late.
+func (g *GossipNode) ReceiveBlock(from string, block *Block, hops int) error {
+	if block == nil || block.Hash == "" {
+		return fmt.Errorf("ledger: received a block without a hash from %s", from)
+	}
+	if !g.markSeen(block.Hash) {
+		return nil
+	}
+	if err := g.chain.AddBlockFromPeer(block); err != nil {
+		if errors.Is(err, ErrStaleBlock) {
+			return nil
+		}
+		g.network.penalize(from)
+		return fmt.Errorf("ledger: dropped invalid block %s from %s: %w", block.Hash, from, err)
+	}
+	if hops > 1 {
+		g.network.relay(g.id, block, hops-1)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package ledger_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+type gossipFixture struct {
+	network *ledger.GossipNetwork
+	chains  map[string]*ledger.Blockchain
+	nodes   map[string]*ledger.GossipNode
+}
+
+func newGossipFixture(t *testing.T, ids ...string) *gossipFixture {
+	t.Helper()
+	// Nodes on one network share a genesis; freezing the clock gives every
+	// fixture chain the identical genesis block.
+	goldenEnv(t)
+	f := &gossipFixture{
+		network: ledger.NewGossipNetwork(),
+		chains:  make(map[string]*ledger.Blockchain),
+		nodes:   make(map[string]*ledger.GossipNode),
+	}
+	for _, id := range ids {
+		chain := ledger.NewBlockchain()
+		node, err := f.network.Join(id, chain)
+		if err != nil {
+			t.Fatalf("Join %s: %v", id, err)
+		}
+		f.chains[id] = chain
+		f.nodes[id] = node
+	}
+	return f
+}
+
+func TestGossip_BlockPropagatesToAllNodes(t *testing.T) {
+	f := newGossipFixture(t, "node-a", "node-b", "node-c")
+	block, err := f.chains["node-a"].AddBlock(signedBatch(t, 2))
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	if err := f.nodes["node-a"].BroadcastBlock(block); err != nil {
+		t.Fatalf("BroadcastBlock: %v", err)
+	}
+	for _, id := range []string{"node-b", "node-c"} {
+		chain := f.chains[id]
+		if chain.Height() != 2 {
+			t.Errorf("%s height %d, want 2", id, chain.Height())
+			continue
+		}
+		if chain.LatestBlock().Hash != block.Hash {
+			t.Errorf("%s head %s, want broadcast block %s", id, chain.LatestBlock().Hash, block.Hash)
+		}
+	}
+}
+
+func TestGossip_DuplicateAnnouncementsAreDropped(t *testing.T) {
+	f := newGossipFixture(t, "node-a", "node-b")
+	block, err := f.chains["node-a"].AddBlock(signedBatch(t, 1))
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := f.nodes["node-b"].ReceiveBlock("node-a", block, ledger.DefaultGossipHops); err != nil {
+			t.Fatalf("ReceiveBlock round %d: %v", i, err)
+		}
+	}
+	if h := f.chains["node-b"].Height(); h != 2 {
+		t.Errorf("node-b height %d after duplicate announcements, want 2", h)
+	}
+	if rep := f.network.Reputation("node-a"); rep != 0 {
+		t.Errorf("node-a penalized %d for duplicates", rep)
+	}
+}
+
+func TestGossip_InvalidBlockIsDroppedAndSenderPenalized(t *testing.T) {
+	f := newGossipFixture(t, "node-a", "node-b", "node-c")
+	block, err := f.chains["node-a"].AddBlock(signedBatch(t, 1))
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	forged := *block
+	forged.MerkleRoot = "forged-root"
+	forged.Hash = forged.CalculateBlockHash()
+
+	if err := f.nodes["node-b"].ReceiveBlock("node-a", &forged, ledger.DefaultGossipHops); err == nil {
+		t.Fatal("invalid block was accepted")
+	}
+	if h := f.chains["node-b"].Height(); h != 1 {
+		t.Errorf("node-b height %d after invalid block, want 1", h)
+	}
+	if h := f.chains["node-c"].Height(); h != 1 {
+		t.Errorf("invalid block was re-gossiped to node-c (height %d)", h)
+	}
+	if rep := f.network.Reputation("node-a"); rep >= 0 {
+		t.Errorf("sender reputation %d, want a penalty", rep)
+	}
+}
+
+func TestGossip_StaleBlockIsIgnoredWithoutPenalty(t *testing.T) {
+	f := newGossipFixture(t, "node-a", "node-b")
+	block, err := f.chains["node-a"].AddBlock(signedBatch(t, 1))
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	if err := f.nodes["node-b"].BroadcastBlock(block); err != nil {
+		t.Fatalf("BroadcastBlock: %v", err)
+	}
+	// node-b already saw the hash via its own broadcast bookkeeping; use a
+	// fresh joiner so the stale path is actually exercised.
+	late := ledger.NewBlockchain()
+	if err := late.AddBlockFromPeer(block); err != nil {
+		t.Fatalf("AddBlockFromPeer: %v", err)
+	}
+	lateNode, err := f.network.Join("node-late", late)
+	if err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := lateNode.ReceiveBlock("node-a", block, ledger.DefaultGossipHops); err != nil {
+		t.Errorf("stale block reported an error: %v", err)
+	}
+	if rep := f.network.Reputation("node-a"); rep != 0 {
+		t.Errorf("node-a penalized %d for a stale block", rep)
+	}
+}
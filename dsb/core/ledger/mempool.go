@@ -0,0 +1,94 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// ErrTxExpired is returned by Mempool.Submit for transactions whose
+// validity window has already closed.
+var ErrTxExpired = errors.New("ledger: transaction has expired")
+
+// Mempool holds signed transactions awaiting inclusion in a block.
+type Mempool struct {
+	mu  sync.Mutex
+	txs map[string]*Transaction
+	// maxDrift bounds how far in the future a submitted transaction's
+	// timestamp may lie; zero falls back to DefaultMaxTimestampDrift.
+	maxDrift time.Duration
+}
+
+// NewMempool returns an empty mempool.
+func NewMempool() *Mempool {
+	return &Mempool{txs: make(map[string]*Transaction)}
+}
+
+// SetMaxTimestampDrift replaces the future-timestamp tolerance applied on
+// submission; zero or less restores DefaultMaxTimestampDrift.
+func (m *Mempool) SetMaxTimestampDrift(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxDrift = d
+}
+
+// Submit adds a transaction after checking its signature, timestamp
+// sanity, and validity window. Resubmitting a known transaction is a
+// no-op.
+func (m *Mempool) Submit(tx *Transaction) error {
+	if !tx.VerifySignature() {
+		return fmt.Errorf("ledger: transaction %s has an invalid signature", tx.ID)
+	}
+	m.mu.Lock()
+	maxDrift := m.maxDrift
+	m.mu.Unlock()
+	if err := tx.ValidateTimestamp(clock.Now(), maxDrift); err != nil {
+		return err
+	}
+	if tx.IsExpired(clock.Now()) {
+		return fmt.Errorf("%w: %s", ErrTxExpired, tx.ID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.txs[tx.ID] = tx
+	return nil
+}
+
+// Pending returns the transactions currently awaiting inclusion, dropping
+// any that have expired while queued. The result is ordered by fee per
+// weight, highest first (ties by ID), so producers filling a weight-capped
+// block take the best-paying transactions from the front.
+func (m *Mempool) Pending() []Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := clock.Now()
+	pending := make([]Transaction, 0, len(m.txs))
+	for id, tx := range m.txs {
+		if tx.IsExpired(now) {
+			delete(m.txs, id)
+			continue
+		}
+		pending = append(pending, *tx)
+	}
+	sortByFeeDensity(pending)
+	return pending
+}
+
+// Remove drops transactions (typically after block inclusion).
+func (m *Mempool) Remove(txIDs ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range txIDs {
+		delete(m.txs, id)
+	}
+}
+
+// Len returns the number of queued transactions.
+func (m *Mempool) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.txs)
+}
@@ -0,0 +1,71 @@
+package ledger_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+func TestOnBlockAdded_SubscribersReceiveBlocksInOrder(t *testing.T) {
+	chain := ledger.NewBlockchain()
+	var first, second []int64
+	chain.OnBlockAdded(func(b *ledger.Block) { first = append(first, b.Index) })
+	chain.OnBlockAdded(func(b *ledger.Block) { second = append(second, b.Index) })
+
+	for i := 0; i < 3; i++ {
+		if _, err := chain.AddBlock(nil); err != nil {
+			t.Fatalf("AddBlock: %v", err)
+		}
+	}
+
+	want := []int64{1, 2, 3}
+	for name, got := range map[string][]int64{"first": first, "second": second} {
+		if len(got) != len(want) {
+			t.Fatalf("%s subscriber saw %d blocks, want %d", name, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s subscriber block %d has index %d, want %d", name, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestOnBlockAdded_PanickingSubscriberDoesNotCorruptChain(t *testing.T) {
+	chain := ledger.NewBlockchain()
+	var survived []int64
+	chain.OnBlockAdded(func(b *ledger.Block) { panic("subscriber bug") })
+	chain.OnBlockAdded(func(b *ledger.Block) { survived = append(survived, b.Index) })
+
+	if _, err := chain.AddBlock(nil); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	if _, err := chain.AddBlock(nil); err != nil {
+		t.Fatalf("AddBlock after panicking subscriber: %v", err)
+	}
+
+	if len(survived) != 2 {
+		t.Errorf("later subscriber saw %d blocks, want 2", len(survived))
+	}
+	if chain.Height() != 3 {
+		t.Errorf("chain height = %d, want 3", chain.Height())
+	}
+	if err := chain.IsChainValid(); err != nil {
+		t.Errorf("chain invalid after subscriber panic: %v", err)
+	}
+}
+
+// Subscribers run outside the chain lock, so reading the chain from a
+// callback must not deadlock.
+func TestOnBlockAdded_CallbackMayReadChain(t *testing.T) {
+	chain := ledger.NewBlockchain()
+	var heights []int64
+	chain.OnBlockAdded(func(b *ledger.Block) { heights = append(heights, chain.Height()) })
+
+	if _, err := chain.AddBlock(nil); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	if len(heights) != 1 || heights[0] != 2 {
+		t.Errorf("callback observed heights %v, want [2]", heights)
+	}
+}
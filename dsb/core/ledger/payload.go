@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrNoPayloadCodec is returned when a transaction's type has no registered
+// payload codec.
+var ErrNoPayloadCodec = errors.New("ledger: no payload codec registered")
+
+// PayloadCodec translates between a transaction type's typed payload struct
+// and the raw bytes stored on the transaction.
+type PayloadCodec struct {
+	Encode func(v interface{}) ([]byte, error)
+	Decode func(data []byte) (interface{}, error)
+}
+
+// Typed payloads for the built-in transaction types. Historically these
+// were raw strings in Payload; the codecs below keep that wire form.
+type (
+	// PostCreatedPayload points at the published post content.
+	PostCreatedPayload struct {
+		ContentCID string
+	}
+	// ProfileUpdatedPayload points at the new profile version.
+	ProfileUpdatedPayload struct {
+		ProfileCID string
+	}
+	// FollowPayload names the followed or unfollowed address.
+	FollowPayload struct {
+		TargetAddress string
+	}
+)
+
+var (
+	codecMu       sync.RWMutex
+	payloadCodecs = map[TransactionType]PayloadCodec{}
+)
+
+// RegisterPayloadCodec installs (or replaces) the codec for a transaction
+// type. Packages defining their own payload structs register codecs from
+// init so DecodePayload works wherever the transaction surfaces.
+func RegisterPayloadCodec(txType TransactionType, codec PayloadCodec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	payloadCodecs[txType] = codec
+}
+
+// JSONPayloadCodec returns a codec that round-trips the payload through
+// JSON, decoding into a fresh value from prototype.
+func JSONPayloadCodec(prototype func() interface{}) PayloadCodec {
+	return PayloadCodec{
+		Encode: func(v interface{}) ([]byte, error) {
+			return json.Marshal(v)
+		},
+		Decode: func(data []byte) (interface{}, error) {
+			v := prototype()
+			if err := json.Unmarshal(data, v); err != nil {
+				return nil, fmt.Errorf("ledger: failed to decode payload: %w", err)
+			}
+			return v, nil
+		},
+	}
+}
+
+// rawStringCodec encodes a single-string payload struct as the bare string,
+// preserving the historical wire form for the built-in types.
+func rawStringCodec(get func(v interface{}) (string, bool), make func(s string) interface{}) PayloadCodec {
+	return PayloadCodec{
+		Encode: func(v interface{}) ([]byte, error) {
+			s, ok := get(v)
+			if !ok {
+				return nil, fmt.Errorf("ledger: payload %T does not match the transaction type", v)
+			}
+			return []byte(s), nil
+		},
+		Decode: func(data []byte) (interface{}, error) {
+			return make(string(data)), nil
+		},
+	}
+}
+
+func init() {
+	RegisterPayloadCodec(TxTypePostCreated, rawStringCodec(
+		func(v interface{}) (string, bool) {
+			p, ok := v.(PostCreatedPayload)
+			return p.ContentCID, ok
+		},
+		func(s string) interface{} { return PostCreatedPayload{ContentCID: s} },
+	))
+	RegisterPayloadCodec(TxTypeProfileUpdated, rawStringCodec(
+		func(v interface{}) (string, bool) {
+			p, ok := v.(ProfileUpdatedPayload)
+			return p.ProfileCID, ok
+		},
+		func(s string) interface{} { return ProfileUpdatedPayload{ProfileCID: s} },
+	))
+	followCodec := rawStringCodec(
+		func(v interface{}) (string, bool) {
+			p, ok := v.(FollowPayload)
+			return p.TargetAddress, ok
+		},
+		func(s string) interface{} { return FollowPayload{TargetAddress: s} },
+	)
+	RegisterPayloadCodec(TxTypeFollowed, followCodec)
+	RegisterPayloadCodec(TxTypeUnfollowed, followCodec)
+}
+
+// EncodePayload encodes a typed payload for the given transaction type.
+func EncodePayload(txType TransactionType, v interface{}) ([]byte, error) {
+	codecMu.RLock()
+	codec, ok := payloadCodecs[txType]
+	codecMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w for type %q", ErrNoPayloadCodec, txType)
+	}
+	return codec.Encode(v)
+}
+
+// DecodePayload decodes the transaction's payload into the typed struct
+// registered for its type.
+func (tx *Transaction) DecodePayload() (interface{}, error) {
+	codecMu.RLock()
+	codec, ok := payloadCodecs[tx.Type]
+	codecMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w for type %q", ErrNoPayloadCodec, tx.Type)
+	}
+	return codec.Decode(tx.Payload)
+}
@@ -0,0 +1,70 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+func TestPayloadCodecs_BuiltinRoundTrips(t *testing.T) {
+	cases := []struct {
+		txType  ledger.TransactionType
+		payload interface{}
+	}{
+		{ledger.TxTypePostCreated, ledger.PostCreatedPayload{ContentCID: "cid-post"}},
+		{ledger.TxTypeProfileUpdated, ledger.ProfileUpdatedPayload{ProfileCID: "cid-profile"}},
+		{ledger.TxTypeFollowed, ledger.FollowPayload{TargetAddress: "addr-target"}},
+		{ledger.TxTypeUnfollowed, ledger.FollowPayload{TargetAddress: "addr-target"}},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.txType), func(t *testing.T) {
+			data, err := ledger.EncodePayload(tc.txType, tc.payload)
+			if err != nil {
+				t.Fatalf("EncodePayload: %v", err)
+			}
+			tx, err := ledger.NewTransaction(tc.txType, "addr-sender", data)
+			if err != nil {
+				t.Fatalf("NewTransaction: %v", err)
+			}
+			got, err := tx.DecodePayload()
+			if err != nil {
+				t.Fatalf("DecodePayload: %v", err)
+			}
+			if got != tc.payload {
+				t.Errorf("round trip = %#v, want %#v", got, tc.payload)
+			}
+		})
+	}
+}
+
+func TestPayloadCodecs_RawStringWireForm(t *testing.T) {
+	// Existing transactions carry the bare CID; the codec must keep that
+	// wire form.
+	data, err := ledger.EncodePayload(ledger.TxTypePostCreated, ledger.PostCreatedPayload{ContentCID: "cid-raw"})
+	if err != nil {
+		t.Fatalf("EncodePayload: %v", err)
+	}
+	if string(data) != "cid-raw" {
+		t.Errorf("encoded payload = %q, want bare CID", data)
+	}
+}
+
+func TestDecodePayload_UnregisteredType(t *testing.T) {
+	tx, err := ledger.NewTransaction(ledger.TransactionType("unknown_type"), "addr-sender", []byte("x"))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if _, err := tx.DecodePayload(); !errors.Is(err, ledger.ErrNoPayloadCodec) {
+		t.Errorf("DecodePayload = %v, want ErrNoPayloadCodec", err)
+	}
+	if _, err := ledger.EncodePayload("unknown_type", "x"); !errors.Is(err, ledger.ErrNoPayloadCodec) {
+		t.Errorf("EncodePayload = %v, want ErrNoPayloadCodec", err)
+	}
+}
+
+func TestEncodePayload_WrongStructForType(t *testing.T) {
+	if _, err := ledger.EncodePayload(ledger.TxTypePostCreated, ledger.FollowPayload{TargetAddress: "a"}); err == nil {
+		t.Error("mismatched payload struct was accepted")
+	}
+}
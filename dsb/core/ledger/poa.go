@@ -0,0 +1,134 @@
+package ledger
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// ValidatorSet is the list of addresses authorized to produce blocks on a
+// proof-of-authority chain.
+type ValidatorSet struct {
+	members map[string]bool
+}
+
+// NewValidatorSet returns a set containing the given addresses.
+func NewValidatorSet(addresses ...string) *ValidatorSet {
+	members := make(map[string]bool, len(addresses))
+	for _, addr := range addresses {
+		members[addr] = true
+	}
+	return &ValidatorSet{members: members}
+}
+
+// Contains reports whether the address is an authorized producer.
+func (vs *ValidatorSet) Contains(address string) bool {
+	return vs.members[address]
+}
+
+// SignAsProducer signs the block hash with the producer's wallet, recording
+// the producer's address and public key on the block.
+func (b *Block) SignAsProducer(wallet *identity.Wallet) error {
+	sig, err := wallet.Sign([]byte(b.Hash))
+	if err != nil {
+		return fmt.Errorf("ledger: failed to sign block %d as producer: %w", b.Index, err)
+	}
+	b.ProducerAddress = wallet.Address
+	b.ProducerPublicKey = wallet.PublicKey
+	b.ProducerSignature = sig
+	return nil
+}
+
+// VerifyProducer reports whether the block carries a valid producer
+// signature over its hash by the claimed producer address.
+func (b *Block) VerifyProducer() bool {
+	if len(b.ProducerSignature) == 0 || len(b.ProducerPublicKey) == 0 {
+		return false
+	}
+	if !identity.AddressesEqual(identity.PublicKeyToAddress(b.ProducerPublicKey), b.ProducerAddress) {
+		return false
+	}
+	return identity.VerifySignature(b.ProducerPublicKey, []byte(b.Hash), b.ProducerSignature)
+}
+
+// SetValidators configures the chain as proof-of-authority: every block
+// appended after this call must be signed by one of the given addresses.
+// Passing no addresses disables the check again.
+func (bc *Blockchain) SetValidators(addresses ...string) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if len(addresses) == 0 {
+		bc.validators = nil
+		return
+	}
+	bc.validators = NewValidatorSet(addresses...)
+}
+
+// checkProducer enforces the validator set on a candidate block. Callers
+// must hold the lock.
+func (bc *Blockchain) checkProducer(block *Block) error {
+	return checkProducerAgainst(bc.validators, block)
+}
+
+// checkProducerAgainst enforces a validator set without touching chain
+// state, so it can run outside the chain lock.
+func checkProducerAgainst(validators *ValidatorSet, block *Block) error {
+	if validators == nil {
+		return nil
+	}
+	if !validators.Contains(block.ProducerAddress) {
+		return fmt.Errorf("ledger: block %d producer %q is not an authorized validator",
+			block.Index, block.ProducerAddress)
+	}
+	if !block.VerifyProducer() {
+		return fmt.Errorf("ledger: block %d has an invalid producer signature", block.Index)
+	}
+	return nil
+}
+
+// MintBlockAs builds, signs, and appends the next block as the given
+// validator wallet. The chain must be configured with a validator set that
+// includes the wallet's address. OnBlockAdded subscribers are notified
+// after the append succeeds.
+func (bc *Blockchain) MintBlockAs(wallet *identity.Wallet, transactions []Transaction) (*Block, error) {
+	block, err := bc.mintBlockAs(wallet, transactions)
+	if err != nil {
+		return nil, err
+	}
+	bc.notifyBlockAdded(block)
+	return block, nil
+}
+
+func (bc *Blockchain) mintBlockAs(wallet *identity.Wallet, transactions []Transaction) (*Block, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.validators == nil {
+		return nil, fmt.Errorf("ledger: chain has no validator set configured")
+	}
+	if !bc.validators.Contains(wallet.Address) {
+		return nil, fmt.Errorf("ledger: wallet %s is not an authorized validator", wallet.Address)
+	}
+	if err := VerifyTransactionsParallel(transactions, bc.verifyWorkers); err != nil {
+		return nil, err
+	}
+	block := NewBlock(bc.headIndex()+1, transactions, bc.headHash())
+	if err := bc.checkWeight(block); err != nil {
+		return nil, err
+	}
+	if err := block.SignAsProducer(wallet); err != nil {
+		return nil, err
+	}
+	if len(bc.blocks) > 0 {
+		if err := IsBlockValid(block, bc.blocks[len(bc.blocks)-1]); err != nil {
+			return nil, err
+		}
+	} else if err := isBlockSelfConsistent(block); err != nil {
+		return nil, err
+	}
+	if err := bc.checkProducer(block); err != nil {
+		return nil, err
+	}
+	bc.blocks = append(bc.blocks, block)
+	bc.indexBlock(block)
+	return block, nil
+}
@@ -0,0 +1,67 @@
+package ledger_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func newValidatorChain(t *testing.T) (*ledger.Blockchain, *identity.Wallet) {
+	t.Helper()
+	validator, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	bc := ledger.NewBlockchain()
+	bc.SetValidators(validator.Address)
+	return bc, validator
+}
+
+func TestMintBlockAs_AuthorizedValidatorAccepted(t *testing.T) {
+	bc, validator := newValidatorChain(t)
+	tx := signedTx(t)
+	block, err := bc.MintBlockAs(validator, []ledger.Transaction{*tx})
+	if err != nil {
+		t.Fatalf("MintBlockAs: %v", err)
+	}
+	if block.ProducerAddress != validator.Address {
+		t.Errorf("producer address = %s, want %s", block.ProducerAddress, validator.Address)
+	}
+	if !block.VerifyProducer() {
+		t.Error("minted block's producer signature does not verify")
+	}
+	if err := bc.IsChainValid(); err != nil {
+		t.Errorf("IsChainValid after mint: %v", err)
+	}
+}
+
+func TestMintBlockAs_UnauthorizedProducerRejected(t *testing.T) {
+	bc, _ := newValidatorChain(t)
+	outsider, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	if _, err := bc.MintBlockAs(outsider, nil); err == nil {
+		t.Error("block from non-validator wallet was accepted")
+	}
+	// Plain AddBlock produces an unsigned block, which a PoA chain must
+	// also refuse.
+	if _, err := bc.AddBlock(nil); err == nil {
+		t.Error("unsigned block was accepted on a PoA chain")
+	}
+}
+
+func TestVerifyProducer_TamperedHeaderRejected(t *testing.T) {
+	bc, validator := newValidatorChain(t)
+	block, err := bc.MintBlockAs(validator, nil)
+	if err != nil {
+		t.Fatalf("MintBlockAs: %v", err)
+	}
+	tampered := *block
+	tampered.Timestamp++
+	tampered.Hash = tampered.CalculateBlockHash()
+	if tampered.VerifyProducer() {
+		t.Error("producer signature still verifies after header tampering")
+	}
+}
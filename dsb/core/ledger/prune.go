@@ -0,0 +1,91 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPruned reports a transaction whose containing block kept only its
+// header after pruning: the transaction existed — its block and Merkle
+// root still do — but its body was discarded. Callers detect it with
+// errors.Is.
+var ErrPruned = errors.New("ledger: transaction is in a pruned block")
+
+// PruneConfig configures history pruning. KeepRecent is how many of the
+// most recent blocks keep full transaction bodies.
+type PruneConfig struct {
+	KeepRecent int64 `json:"keepRecent"`
+}
+
+// PruneState records what a pruned chain no longer holds: every block at
+// or below Through carries only its header, and Snapshot is the state
+// snapshot taken over the discarded range. It serializes alongside the
+// blocks so a restart knows the missing bodies are deliberate, not
+// corruption.
+type PruneState struct {
+	Through  int64      `json:"through"`
+	Snapshot Checkpoint `json:"snapshot"`
+}
+
+// Prune discards the transaction bodies of every block older than the
+// retention window, keeping headers — so hash linkage, Merkle roots, and
+// Height are untouched — and recording a state snapshot over the pruned
+// range first. It returns how many blocks were newly pruned. Looking up a
+// pruned transaction fails with ErrPruned; IsChainValid keeps validating
+// header linkage across the pruned region and skips the body checks there.
+// Bodies are dropped in place: callers still holding those Block pointers
+// from an earlier Blocks() snapshot observe the change.
+func (bc *Blockchain) Prune(cfg PruneConfig) (int, error) {
+	if cfg.KeepRecent <= 0 {
+		return 0, fmt.Errorf("ledger: prune retention must keep at least 1 block, got %d", cfg.KeepRecent)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	cutoff := bc.headIndex() - cfg.KeepRecent
+	if len(bc.blocks) == 0 || cutoff < bc.blocks[0].Index {
+		return 0, nil
+	}
+	// Snapshot before discarding anything: the snapshot is what stands in
+	// for the history the prune removes.
+	root := ""
+	if bc.checkpoint != nil {
+		root = bc.checkpoint.StateRoot
+	}
+	var snapshotHash string
+	for _, block := range bc.blocks {
+		if block.Index > cutoff {
+			break
+		}
+		root = stateRootAfter(root, block.Hash)
+		snapshotHash = block.Hash
+	}
+	pruned := 0
+	for _, block := range bc.blocks {
+		if block.Index > cutoff {
+			break
+		}
+		if block.Pruned {
+			continue
+		}
+		block.Transactions = nil
+		block.Pruned = true
+		pruned++
+	}
+	bc.pruned = &PruneState{
+		Through:  cutoff,
+		Snapshot: Checkpoint{Index: cutoff, BlockHash: snapshotHash, StateRoot: root},
+	}
+	return pruned, nil
+}
+
+// PruneState returns a copy of the chain's pruning state, or nil when the
+// chain has never been pruned.
+func (bc *Blockchain) PruneState() *PruneState {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	if bc.pruned == nil {
+		return nil
+	}
+	state := *bc.pruned
+	return &state
+}
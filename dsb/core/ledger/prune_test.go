@@ -0,0 +1,152 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+// prunedChain builds a chain of n one-transaction blocks and returns it
+// with the transaction IDs in block order.
+func prunedChain(t *testing.T, n int) (*ledger.Blockchain, []string) {
+	t.Helper()
+	bc := ledger.NewBlockchain()
+	txIDs := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		txs := signedBatch(t, 1)
+		if _, err := bc.AddBlock(txs); err != nil {
+			t.Fatalf("AddBlock %d: %v", i, err)
+		}
+		txIDs = append(txIDs, txs[0].ID)
+	}
+	return bc, txIDs
+}
+
+func TestPrune_KeepsHeadersAndRecentBodies(t *testing.T) {
+	goldenEnv(t)
+	bc, txIDs := prunedChain(t, 200)
+	heightBefore := bc.Height()
+
+	pruned, err := bc.Prune(ledger.PruneConfig{KeepRecent: 50})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	// Head is block 200; blocks 0..150 are outside the 50-block window.
+	if pruned != 151 {
+		t.Errorf("pruned %d blocks, want 151", pruned)
+	}
+	if bc.Height() != heightBefore {
+		t.Errorf("height changed from %d to %d", heightBefore, bc.Height())
+	}
+	if err := bc.IsChainValid(); err != nil {
+		t.Errorf("pruned chain failed validation: %v", err)
+	}
+	state := bc.PruneState()
+	if state == nil || state.Through != 150 {
+		t.Fatalf("prune state %+v, want Through 150", state)
+	}
+	if state.Snapshot.Index != 150 || state.Snapshot.BlockHash == "" || state.Snapshot.StateRoot == "" {
+		t.Errorf("prune snapshot %+v is incomplete", state.Snapshot)
+	}
+	for _, block := range bc.Blocks() {
+		if block.Index <= state.Through {
+			if !block.Pruned || len(block.Transactions) != 0 {
+				t.Fatalf("block %d kept its body after pruning", block.Index)
+			}
+		} else if block.Pruned || len(block.Transactions) == 0 {
+			t.Fatalf("recent block %d lost its body", block.Index)
+		}
+	}
+
+	// Pruned lookups fail typed; recent ones still resolve.
+	if _, _, err := bc.GetTransactionByID(txIDs[0]); !errors.Is(err, ledger.ErrPruned) {
+		t.Errorf("pruned lookup: %v, want ErrPruned", err)
+	}
+	if _, _, err := bc.GetTransactionByID("tx-never-existed"); errors.Is(err, ledger.ErrPruned) {
+		t.Error("unknown transaction misreported as pruned")
+	}
+	if _, block, err := bc.GetTransactionByID(txIDs[len(txIDs)-1]); err != nil || block.Index != 200 {
+		t.Errorf("recent lookup failed: %v (block %v)", err, block)
+	}
+}
+
+func TestPrune_ChainStillGrowsAndRejectsBadConfig(t *testing.T) {
+	goldenEnv(t)
+	bc, _ := prunedChain(t, 10)
+	if _, err := bc.Prune(ledger.PruneConfig{KeepRecent: 0}); err == nil {
+		t.Error("non-positive retention was accepted")
+	}
+	if _, err := bc.Prune(ledger.PruneConfig{KeepRecent: 4}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if _, err := bc.AddBlock(signedBatch(t, 1)); err != nil {
+		t.Fatalf("AddBlock after prune: %v", err)
+	}
+	if err := bc.IsChainValid(); err != nil {
+		t.Errorf("chain invalid after post-prune append: %v", err)
+	}
+
+	// A peer block claiming to be pruned would dodge body validation.
+	head := bc.LatestBlock()
+	forged := *head
+	forged.Index = head.Index + 1
+	forged.PrevHash = head.Hash
+	forged.Transactions = nil
+	forged.Pruned = true
+	forged.Hash = forged.CalculateBlockHash()
+	if err := bc.AddBlockFromPeer(&forged); err == nil {
+		t.Error("peer block marked pruned was accepted")
+	}
+}
+
+func TestPrune_SnapshotRestoreMatchesUnprunedState(t *testing.T) {
+	goldenEnv(t)
+	bc, txIDs := prunedChain(t, 60)
+
+	// Capture the unpruned chain's recent state before pruning discards
+	// anything: head identity and the state root over the whole chain.
+	headBefore := bc.LatestBlock().Hash
+	fullRoot, err := bc.Checkpoint(60)
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+
+	if _, err := bc.Prune(ledger.PruneConfig{KeepRecent: 20}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	state := bc.PruneState()
+
+	// Restore a node from the prune snapshot plus the retained tail.
+	var tail []*ledger.Block
+	for _, block := range bc.Blocks() {
+		if block.Index > state.Through {
+			tail = append(tail, block)
+		}
+	}
+	restored, err := ledger.LoadFromCheckpoint(state.Snapshot, tail)
+	if err != nil {
+		t.Fatalf("LoadFromCheckpoint: %v", err)
+	}
+	if restored.Height() != bc.Height() {
+		t.Errorf("restored height %d, want %d", restored.Height(), bc.Height())
+	}
+	if restored.LatestBlock().Hash != headBefore {
+		t.Error("restored head differs from the unpruned head")
+	}
+	restoredRoot, err := restored.Checkpoint(60)
+	if err != nil {
+		t.Fatalf("restored Checkpoint: %v", err)
+	}
+	if restoredRoot.StateRoot != fullRoot.StateRoot {
+		t.Errorf("restored state root %s, want the unpruned replay's %s",
+			restoredRoot.StateRoot, fullRoot.StateRoot)
+	}
+	// Recent transactions resolve identically on both nodes.
+	if _, block, err := restored.GetTransactionByID(txIDs[len(txIDs)-1]); err != nil || block.Index != 60 {
+		t.Errorf("recent lookup on restored node failed: %v (block %v)", err, block)
+	}
+	if err := restored.IsChainValid(); err != nil {
+		t.Errorf("restored chain failed validation: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReceiptStatus describes where a submitted transaction stands.
+type ReceiptStatus string
+
+// Receipt statuses.
+const (
+	// ReceiptStatusIncluded means the transaction was mined into a block.
+	ReceiptStatusIncluded ReceiptStatus = "included"
+	// ReceiptStatusPending means the transaction is queued in a mempool
+	// and carries no block details yet.
+	ReceiptStatusPending ReceiptStatus = "pending"
+)
+
+// ErrReceiptNotFound is returned by GetReceipt for transactions the chain
+// has never seen.
+var ErrReceiptNotFound = errors.New("ledger: no receipt for transaction")
+
+// Receipt records where a transaction landed, so callers that submit a
+// batch do not have to dig through the returned block for their entry. The
+// timestamp is the including block's.
+type Receipt struct {
+	TxID            string        `json:"txId"`
+	BlockIndex      int64         `json:"blockIndex"`
+	BlockHash       string        `json:"blockHash"`
+	PositionInBlock int           `json:"positionInBlock"`
+	Timestamp       int64         `json:"timestamp"`
+	Status          ReceiptStatus `json:"status"`
+}
+
+// PendingReceipt is the receipt for a transaction accepted into a mempool
+// but not yet mined: the transaction ID with no block details.
+func PendingReceipt(txID string) Receipt {
+	return Receipt{TxID: txID, Status: ReceiptStatusPending}
+}
+
+// BlockReceipts renders one included receipt per transaction in the block,
+// in block order.
+func BlockReceipts(block *Block) []Receipt {
+	receipts := make([]Receipt, len(block.Transactions))
+	for i := range block.Transactions {
+		receipts[i] = Receipt{
+			TxID:            block.Transactions[i].ID,
+			BlockIndex:      block.Index,
+			BlockHash:       block.Hash,
+			PositionInBlock: i,
+			Timestamp:       block.Timestamp,
+			Status:          ReceiptStatusIncluded,
+		}
+	}
+	return receipts
+}
+
+// AddBlockWithReceipts is AddBlock returning the receipt for every
+// included transaction alongside the block, for submit paths that answer
+// callers per transaction.
+func (bc *Blockchain) AddBlockWithReceipts(transactions []Transaction) (*Block, []Receipt, error) {
+	block, err := bc.AddBlock(transactions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return block, BlockReceipts(block), nil
+}
+
+// GetReceipt returns the inclusion receipt for a mined transaction, backed
+// by the transaction index. Because the index outlives pruned block
+// bodies, receipts remain available for transactions whose blocks were
+// pruned down to headers.
+func (bc *Blockchain) GetReceipt(txID string) (*Receipt, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	loc, ok := bc.txIndex[txID]
+	if !ok || len(bc.blocks) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrReceiptNotFound, txID)
+	}
+	block := bc.blocks[loc.blockIndex-bc.blocks[0].Index]
+	return &Receipt{
+		TxID:            txID,
+		BlockIndex:      block.Index,
+		BlockHash:       block.Hash,
+		PositionInBlock: loc.pos,
+		Timestamp:       block.Timestamp,
+		Status:          ReceiptStatusIncluded,
+	}, nil
+}
@@ -0,0 +1,125 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+func TestAddBlockWithReceipts_FieldsMatchTheBlock(t *testing.T) {
+	goldenEnv(t)
+	bc := ledger.NewBlockchain()
+	txs := signedBatch(t, 3)
+
+	block, receipts, err := bc.AddBlockWithReceipts(txs)
+	if err != nil {
+		t.Fatalf("AddBlockWithReceipts: %v", err)
+	}
+	if len(receipts) != len(txs) {
+		t.Fatalf("%d receipts for %d transactions", len(receipts), len(txs))
+	}
+	for i, receipt := range receipts {
+		if receipt.TxID != txs[i].ID {
+			t.Errorf("receipt %d is for %s, want %s", i, receipt.TxID, txs[i].ID)
+		}
+		if receipt.BlockIndex != block.Index || receipt.BlockHash != block.Hash {
+			t.Errorf("receipt %d points at block %d/%s, want %d/%s",
+				i, receipt.BlockIndex, receipt.BlockHash, block.Index, block.Hash)
+		}
+		if receipt.PositionInBlock != i {
+			t.Errorf("receipt %d has position %d", i, receipt.PositionInBlock)
+		}
+		if receipt.Timestamp != block.Timestamp {
+			t.Errorf("receipt %d timestamp %d, want the block's %d", i, receipt.Timestamp, block.Timestamp)
+		}
+		if receipt.Status != ledger.ReceiptStatusIncluded {
+			t.Errorf("receipt %d status %q", i, receipt.Status)
+		}
+	}
+
+	// GetReceipt answers the same from the index.
+	got, err := bc.GetReceipt(txs[1].ID)
+	if err != nil {
+		t.Fatalf("GetReceipt: %v", err)
+	}
+	if *got != receipts[1] {
+		t.Errorf("GetReceipt = %+v, want %+v", got, receipts[1])
+	}
+}
+
+func TestGetReceipt_UnknownTransaction(t *testing.T) {
+	goldenEnv(t)
+	bc := ledger.NewBlockchain()
+	if _, err := bc.GetReceipt("tx-never-submitted"); !errors.Is(err, ledger.ErrReceiptNotFound) {
+		t.Fatalf("GetReceipt: %v, want ErrReceiptNotFound", err)
+	}
+}
+
+func TestGetReceipt_SurvivesCheckpointReload(t *testing.T) {
+	goldenEnv(t)
+	bc := ledger.NewBlockchain()
+	var txID string
+	for i := 0; i < 5; i++ {
+		txs := signedBatch(t, 2)
+		if _, err := bc.AddBlock(txs); err != nil {
+			t.Fatalf("AddBlock: %v", err)
+		}
+		if i == 3 {
+			txID = txs[1].ID
+		}
+	}
+	want, err := bc.GetReceipt(txID)
+	if err != nil {
+		t.Fatalf("GetReceipt before reload: %v", err)
+	}
+
+	// Reload the node from a genesis checkpoint plus the serializable
+	// blocks, as a restart would.
+	cp, err := bc.Checkpoint(0)
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	var tail []*ledger.Block
+	for _, block := range bc.Blocks() {
+		if block.Index > 0 {
+			tail = append(tail, block)
+		}
+	}
+	restored, err := ledger.LoadFromCheckpoint(cp, tail)
+	if err != nil {
+		t.Fatalf("LoadFromCheckpoint: %v", err)
+	}
+	got, err := restored.GetReceipt(txID)
+	if err != nil {
+		t.Fatalf("GetReceipt after reload: %v", err)
+	}
+	if *got != *want {
+		t.Errorf("reloaded receipt %+v, want %+v", got, want)
+	}
+}
+
+func TestGetReceipt_AvailableForPrunedBlocks(t *testing.T) {
+	goldenEnv(t)
+	bc, txIDs := prunedChain(t, 30)
+	if _, err := bc.Prune(ledger.PruneConfig{KeepRecent: 5}); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	receipt, err := bc.GetReceipt(txIDs[0])
+	if err != nil {
+		t.Fatalf("GetReceipt for a pruned block: %v", err)
+	}
+	if receipt.BlockIndex != 1 || receipt.Status != ledger.ReceiptStatusIncluded {
+		t.Errorf("pruned-block receipt %+v", receipt)
+	}
+}
+
+func TestPendingReceipt(t *testing.T) {
+	receipt := ledger.PendingReceipt("tx-queued")
+	if receipt.TxID != "tx-queued" || receipt.Status != ledger.ReceiptStatusPending {
+		t.Errorf("PendingReceipt = %+v", receipt)
+	}
+	if receipt.BlockHash != "" || receipt.BlockIndex != 0 {
+		t.Errorf("pending receipt carries block details: %+v", receipt)
+	}
+}
@@ -0,0 +1,64 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// Internal test: forging the attack needs access to signingBytes, since
+// Sign refuses to produce a signature for a mismatched wallet.
+func TestVerifySignature_RejectsMismatchedSenderAddress(t *testing.T) {
+	victim, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	attacker, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	tx, err := NewTransaction(TxTypePostCreated, victim.Address, []byte("cid-post"))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	// The attacker signs the exact bytes the verifier will check — a valid
+	// signature for their key — while the transaction claims the victim's
+	// address.
+	sig, err := attacker.Sign(tx.signingBytes())
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	tx.SenderPublicKey = attacker.PublicKey
+	tx.Signature = sig
+
+	if !identity.VerifySignature(tx.SenderPublicKey, tx.signingBytes(), tx.Signature) {
+		t.Fatal("test setup broken: raw signature should verify for the attacker's key")
+	}
+	if tx.VerifySender() {
+		t.Error("VerifySender accepted a key that does not hash to the claimed address")
+	}
+	if tx.VerifySignature() {
+		t.Error("VerifySignature accepted a valid signature from the wrong identity")
+	}
+}
+
+func TestVerifySender_AcceptsMatchingKey(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	tx, err := NewTransaction(TxTypePostCreated, wallet.Address, []byte("cid-post"))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := tx.Sign(wallet); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if !tx.VerifySender() {
+		t.Error("VerifySender rejected the signing wallet's own key")
+	}
+	if !tx.VerifySignature() {
+		t.Error("VerifySignature rejected an honestly signed transaction")
+	}
+}
@@ -0,0 +1,72 @@
+package ledger_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func TestNewSignedTransaction_VerifiesAndMatchesWallet(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	tx, err := ledger.NewSignedTransaction(wallet, ledger.TxTypePostCreated, []byte("cid-one-call"),
+		ledger.WithFee(7))
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if !tx.VerifySignature() {
+		t.Error("one-call transaction does not verify")
+	}
+	if tx.SenderAddress != wallet.Address {
+		t.Errorf("sender = %s, want wallet address %s", tx.SenderAddress, wallet.Address)
+	}
+	if tx.ID == "" || tx.Fee != 7 {
+		t.Errorf("transaction fields not populated: id=%q fee=%d", tx.ID, tx.Fee)
+	}
+
+	// The result is accepted by the chain as-is.
+	bc := ledger.NewBlockchain()
+	if _, err := bc.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Errorf("AddBlock: %v", err)
+	}
+}
+
+func TestNewSignedTransaction_RejectsWalletWithoutKey(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	keyless := *wallet
+	keyless.PrivateKey = nil
+	if _, err := ledger.NewSignedTransaction(&keyless, ledger.TxTypePostCreated, []byte("cid")); err == nil {
+		t.Error("wallet without a private key produced a signed transaction")
+	}
+	if _, err := ledger.NewSignedTransaction(nil, ledger.TxTypePostCreated, []byte("cid")); err == nil {
+		t.Error("nil wallet produced a signed transaction")
+	}
+}
+
+func TestNewSignedTransaction_LegacyHexSenderStillVerifies(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	// A wallet loaded from pre-migration state carries the bare-hex
+	// address; its transactions must still verify and be accepted.
+	legacy := *wallet
+	legacy.Address = identity.NormalizeAddress(wallet.Address)
+	tx, err := ledger.NewSignedTransaction(&legacy, ledger.TxTypeFollowed, []byte("address-followed"))
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if !tx.VerifySignature() {
+		t.Error("transaction with a legacy hex sender does not verify")
+	}
+	bc := ledger.NewBlockchain()
+	if _, err := bc.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Errorf("AddBlock: %v", err)
+	}
+}
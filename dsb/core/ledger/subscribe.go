@@ -0,0 +1,41 @@
+package ledger
+
+// DefaultSubscribeBuffer is the channel capacity Subscribe uses when the
+// caller does not ask for one.
+const DefaultSubscribeBuffer = 16
+
+// Subscribe returns a channel that receives every block appended after the
+// call, and a cancel function that unregisters the subscription and closes
+// the channel. It is the channel-shaped sibling of OnBlockAdded for
+// callers structured around select loops, e.g. a push API streaming chain
+// events.
+//
+// The channel is buffered with the given capacity (DefaultSubscribeBuffer
+// when buffer is not positive). A subscriber that lets its buffer fill
+// misses the blocks appended meanwhile — the chain never blocks on a slow
+// consumer — so consumers that must not miss blocks should watch for index
+// gaps and re-read the chain.
+func (bc *Blockchain) Subscribe(buffer int) (<-chan *Block, func()) {
+	if buffer < 1 {
+		buffer = DefaultSubscribeBuffer
+	}
+	ch := make(chan *Block, buffer)
+	bc.subMu.Lock()
+	if bc.chanSubs == nil {
+		bc.chanSubs = make(map[int]chan *Block)
+	}
+	id := bc.nextSubID
+	bc.nextSubID++
+	bc.chanSubs[id] = ch
+	bc.subMu.Unlock()
+
+	cancel := func() {
+		bc.subMu.Lock()
+		defer bc.subMu.Unlock()
+		if sub, ok := bc.chanSubs[id]; ok {
+			delete(bc.chanSubs, id)
+			close(sub)
+		}
+	}
+	return ch, cancel
+}
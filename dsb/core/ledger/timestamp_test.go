@@ -0,0 +1,85 @@
+package ledger_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// signedWithTimestamp builds a fully signed transaction whose timestamp is
+// forced to ts, as a malicious or skewed peer would produce: the ID and
+// signature cover the forged timestamp, so only the sanity check can
+// reject it.
+func signedWithTimestamp(t *testing.T, ts int64) *ledger.Transaction {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, wallet.Address, []byte("cid-forged-ts"))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	tx.Timestamp = ts
+	tx.ID = tx.CalculateHash()
+	if err := tx.Sign(wallet); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return tx
+}
+
+func TestValidateTimestamp(t *testing.T) {
+	goldenEnv(t)
+	now := clock.Now()
+
+	cases := []struct {
+		name      string
+		timestamp int64
+		wantErr   bool
+	}{
+		{"zero", 0, true},
+		{"negative", -1, true},
+		{"current", now, false},
+		{"slightly future within drift", now + time.Minute.Nanoseconds(), false},
+		{"beyond drift", now + (3 * time.Minute).Nanoseconds(), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := signedWithTimestamp(t, tc.timestamp)
+			err := tx.ValidateTimestamp(now, 0)
+			if tc.wantErr && !errors.Is(err, ledger.ErrTxTimestampInvalid) {
+				t.Errorf("ValidateTimestamp(%d) = %v, want ErrTxTimestampInvalid", tc.timestamp, err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("ValidateTimestamp(%d) = %v, want nil", tc.timestamp, err)
+			}
+		})
+	}
+}
+
+func TestMempoolSubmit_TimestampSanity(t *testing.T) {
+	goldenEnv(t)
+	pool := ledger.NewMempool()
+
+	// A zero timestamp is rejected even with a valid signature over it.
+	if err := pool.Submit(signedWithTimestamp(t, 0)); !errors.Is(err, ledger.ErrTxTimestampInvalid) {
+		t.Errorf("zero-timestamp submit: %v, want ErrTxTimestampInvalid", err)
+	}
+
+	// A slightly future timestamp within the default drift is accepted.
+	future := signedWithTimestamp(t, clock.Now()+time.Minute.Nanoseconds())
+	if err := pool.Submit(future); err != nil {
+		t.Errorf("slightly-future submit: %v, want accepted", err)
+	}
+
+	// Tightening the drift makes the same skew unacceptable.
+	pool.SetMaxTimestampDrift(10 * time.Second)
+	skewed := signedWithTimestamp(t, clock.Now()+time.Minute.Nanoseconds())
+	if err := pool.Submit(skewed); !errors.Is(err, ledger.ErrTxTimestampInvalid) {
+		t.Errorf("submit beyond tightened drift: %v, want ErrTxTimestampInvalid", err)
+	}
+}
@@ -0,0 +1,209 @@
+// Package ledger implements the append-only chain that anchors the social
+// layer: signed transactions batched into hash-linked blocks. Content
+// itself lives on the DDS; transactions carry CIDs and addresses, keeping
+// the chain small.
+package ledger
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// TransactionType tags how a transaction's payload is interpreted.
+type TransactionType string
+
+// Transaction types understood by the social layer.
+const (
+	TxTypePostCreated    TransactionType = "post_created"
+	TxTypeProfileUpdated TransactionType = "profile_updated"
+	TxTypeFollowed       TransactionType = "followed"
+	TxTypeUnfollowed     TransactionType = "unfollowed"
+	TxTypeMessageSent    TransactionType = "message_sent"
+	TxTypeShareGranted   TransactionType = "share_granted"
+	TxTypeShareRevoked   TransactionType = "share_revoked"
+	TxTypeAttestation    TransactionType = "attestation"
+)
+
+// Transaction is a signed statement by a sender, e.g. "I published the post
+// at this CID". Payload interpretation depends on Type.
+type Transaction struct {
+	ID              string          `json:"id"`
+	Type            TransactionType `json:"type"`
+	SenderAddress   string          `json:"senderAddress"`
+	SenderPublicKey []byte          `json:"senderPublicKey,omitempty"`
+	Payload         []byte          `json:"payload,omitempty"`
+	Timestamp       int64           `json:"timestamp"`
+	// ValidUntil is the unix-nano time after which the transaction may no
+	// longer be included in a block. Zero means no expiry. It is covered
+	// by the signed bytes.
+	ValidUntil int64 `json:"validUntil,omitempty"`
+	// Fee is what the sender offers for inclusion, in the chain's fee
+	// unit. The mempool drains higher fee-per-weight transactions first.
+	// It is covered by the signed bytes.
+	Fee       uint64 `json:"fee,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// TxOption configures optional transaction fields before the ID is derived.
+type TxOption func(*Transaction)
+
+// WithValidUntil sets the expiry time (unix nanos) for the transaction.
+func WithValidUntil(validUntil int64) TxOption {
+	return func(tx *Transaction) {
+		tx.ValidUntil = validUntil
+	}
+}
+
+// WithFee sets the inclusion fee offered by the sender.
+func WithFee(fee uint64) TxOption {
+	return func(tx *Transaction) {
+		tx.Fee = fee
+	}
+}
+
+// IsExpired reports whether the transaction has expired relative to the
+// given unix-nano time. Transactions without an expiry never expire.
+func (tx *Transaction) IsExpired(now int64) bool {
+	return tx.ValidUntil != 0 && tx.ValidUntil < now
+}
+
+// DefaultMaxTimestampDrift is how far into the future a transaction's
+// timestamp may lie before it is rejected, covering ordinary clock skew
+// between nodes.
+const DefaultMaxTimestampDrift = 2 * time.Minute
+
+// ErrTxTimestampInvalid is returned for transactions whose timestamp fails
+// the sanity check: zero, negative, or further in the future than the
+// allowed drift.
+var ErrTxTimestampInvalid = errors.New("ledger: transaction timestamp is invalid")
+
+// ValidateTimestamp checks the transaction's timestamp against now (unix
+// nanos): locally created transactions are stamped by the clock, but one
+// deserialized off the network can claim any time at all. Zero and
+// negative timestamps are rejected outright; future ones are allowed up to
+// maxDrift of clock skew. A maxDrift of zero or less applies
+// DefaultMaxTimestampDrift.
+func (tx *Transaction) ValidateTimestamp(now int64, maxDrift time.Duration) error {
+	if maxDrift <= 0 {
+		maxDrift = DefaultMaxTimestampDrift
+	}
+	if tx.Timestamp <= 0 {
+		return fmt.Errorf("%w: %s has timestamp %d", ErrTxTimestampInvalid, tx.ID, tx.Timestamp)
+	}
+	if tx.Timestamp > now+maxDrift.Nanoseconds() {
+		return fmt.Errorf("%w: %s is %s in the future", ErrTxTimestampInvalid,
+			tx.ID, time.Duration(tx.Timestamp-now))
+	}
+	return nil
+}
+
+// NewTransaction creates an unsigned transaction stamped with the current
+// time. The caller must set SenderPublicKey and Sign it before submission.
+func NewTransaction(txType TransactionType, senderAddress string, payload []byte, opts ...TxOption) (*Transaction, error) {
+	if txType == "" {
+		return nil, fmt.Errorf("ledger: transaction requires a type")
+	}
+	if senderAddress == "" {
+		return nil, fmt.Errorf("ledger: transaction requires a sender address")
+	}
+	tx := &Transaction{
+		Type:          txType,
+		SenderAddress: senderAddress,
+		Payload:       payload,
+		Timestamp:     clock.Now(),
+	}
+	for _, opt := range opts {
+		opt(tx)
+	}
+	tx.ID = hashToHex(tx.signingBytes())
+	return tx, nil
+}
+
+// NewSignedTransaction builds and signs a transaction from the wallet in
+// one call: the sender address and public key come from the wallet, the ID
+// is derived, and the signature is applied. It replaces the manual
+// NewTransaction → set key → Sign dance. (It lives here rather than as a
+// Wallet method because identity cannot import ledger.)
+func NewSignedTransaction(wallet *identity.Wallet, txType TransactionType, payload []byte, opts ...TxOption) (*Transaction, error) {
+	if wallet == nil {
+		return nil, fmt.Errorf("ledger: signed transaction requires a wallet")
+	}
+	tx, err := NewTransaction(txType, wallet.Address, payload, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Sign(wallet); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// signingBytes returns the canonical bytes covered by the transaction
+// signature and used to derive the transaction ID: length-prefixed fields
+// with the timestamp as decimal unix nanoseconds, so the same transaction
+// serializes identically on every machine. (An earlier format rendered the
+// timestamp via time.Time's String, which embeds the local zone and broke
+// cross-node verification.)
+func (tx *Transaction) signingBytes() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "tx|v1|ts=%d|%d:%s|%d:%s|%d:%s|validUntil=%d|fee=%d",
+		tx.Timestamp, len(tx.Type), tx.Type, len(tx.SenderAddress), tx.SenderAddress,
+		len(tx.Payload), tx.Payload, tx.ValidUntil, tx.Fee)
+	return b.Bytes()
+}
+
+// CalculateHash returns the hex digest of the transaction's signed bytes.
+func (tx *Transaction) CalculateHash() string {
+	return hashToHex(tx.signingBytes())
+}
+
+// Sign signs the transaction with the wallet's key, recording the wallet's
+// public key for verification.
+func (tx *Transaction) Sign(wallet *identity.Wallet) error {
+	if !identity.AddressesEqual(wallet.Address, tx.SenderAddress) {
+		return fmt.Errorf("ledger: wallet %s cannot sign transaction from %s", wallet.Address, tx.SenderAddress)
+	}
+	tx.SenderPublicKey = wallet.PublicKey
+	sig, err := wallet.Sign(tx.signingBytes())
+	if err != nil {
+		return fmt.Errorf("ledger: failed to sign transaction %s: %w", tx.ID, err)
+	}
+	tx.Signature = sig
+	return nil
+}
+
+// VerifySender reports whether SenderPublicKey actually hashes to the
+// claimed SenderAddress. Without this check a transaction could carry a
+// valid signature for one key while claiming to be from a different
+// address.
+func (tx *Transaction) VerifySender() bool {
+	if len(tx.SenderPublicKey) == 0 {
+		return false
+	}
+	return identity.AddressesEqual(identity.PublicKeyToAddress(tx.SenderPublicKey), tx.SenderAddress)
+}
+
+// VerifySignature reports whether the transaction carries a valid signature
+// by the holder of SenderPublicKey and that key belongs to the claimed
+// sender address.
+func (tx *Transaction) VerifySignature() bool {
+	if len(tx.Signature) == 0 || len(tx.SenderPublicKey) == 0 {
+		return false
+	}
+	if !tx.VerifySender() {
+		return false
+	}
+	return identity.VerifySignature(tx.SenderPublicKey, tx.signingBytes(), tx.Signature)
+}
+
+func hashToHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
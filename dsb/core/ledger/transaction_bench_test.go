@@ -0,0 +1,46 @@
+package ledger_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func benchWalletTx(b *testing.B) (*identity.Wallet, *ledger.Transaction) {
+	b.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		b.Fatalf("NewWallet: %v", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, wallet.Address, []byte("cid-benchmark-post"))
+	if err != nil {
+		b.Fatalf("NewTransaction: %v", err)
+	}
+	return wallet, tx
+}
+
+func BenchmarkTransactionSign(b *testing.B) {
+	wallet, tx := benchWalletTx(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := tx.Sign(wallet); err != nil {
+			b.Fatalf("Sign: %v", err)
+		}
+	}
+}
+
+func BenchmarkTransactionVerify(b *testing.B) {
+	wallet, tx := benchWalletTx(b)
+	if err := tx.Sign(wallet); err != nil {
+		b.Fatalf("Sign: %v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !tx.VerifySignature() {
+			b.Fatal("signature did not verify")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package ledger_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+func TestGetTransactionByID_CorrectAcrossManyBlocks(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	txIDs := make(map[string]int64, 50)
+	for i := 0; i < 50; i++ {
+		block := addSignedBlock(t, bc, fmt.Sprintf("post-%d", i))
+		txIDs[block.Transactions[0].ID] = block.Index
+	}
+
+	for id, blockIndex := range txIDs {
+		tx, block, err := bc.GetTransactionByID(id)
+		if err != nil {
+			t.Fatalf("GetTransactionByID(%s): %v", id, err)
+		}
+		if tx.ID != id {
+			t.Errorf("lookup returned transaction %s, want %s", tx.ID, id)
+		}
+		if block.Index != blockIndex {
+			t.Errorf("transaction %s located in block %d, want %d", id, block.Index, blockIndex)
+		}
+	}
+
+	if _, _, err := bc.GetTransactionByID("no-such-tx"); err == nil {
+		t.Error("lookup of unknown ID did not fail")
+	}
+}
+
+func TestGetTransactionsByIDs_SeparatesFoundAndMissing(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	var ids []string
+	for i := 0; i < 5; i++ {
+		block := addSignedBlock(t, bc, fmt.Sprintf("batch-%d", i))
+		ids = append(ids, block.Transactions[0].ID)
+	}
+	ids = append(ids, "missing-1", "missing-2", "missing-2")
+
+	found, missing := bc.GetTransactionsByIDs(ids)
+	if len(found) != 5 {
+		t.Errorf("found %d transactions, want 5", len(found))
+	}
+	for _, id := range ids[:5] {
+		if tx, ok := found[id]; !ok || tx.ID != id {
+			t.Errorf("batch lookup missing transaction %s", id)
+		}
+	}
+	if len(missing) != 2 {
+		t.Errorf("missing = %v, want the two distinct unknown IDs", missing)
+	}
+}
+
+func TestTransactionIndex_SurvivesCheckpointLoad(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	for i := 0; i < 6; i++ {
+		addSignedBlock(t, bc, fmt.Sprintf("pre-checkpoint-%d", i))
+	}
+	cp, err := bc.Checkpoint(3)
+	if err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	var tail []*ledger.Block
+	for i := int64(4); i <= 6; i++ {
+		block, err := bc.GetBlock(i)
+		if err != nil {
+			t.Fatalf("GetBlock(%d): %v", i, err)
+		}
+		tail = append(tail, block)
+	}
+
+	loaded, err := ledger.LoadFromCheckpoint(cp, tail)
+	if err != nil {
+		t.Fatalf("LoadFromCheckpoint: %v", err)
+	}
+	wantID := tail[1].Transactions[0].ID
+	tx, block, err := loaded.GetTransactionByID(wantID)
+	if err != nil {
+		t.Fatalf("GetTransactionByID after load: %v", err)
+	}
+	if tx.ID != wantID || block.Index != tail[1].Index {
+		t.Errorf("lookup after load returned tx %s in block %d, want %s in block %d",
+			tx.ID, block.Index, wantID, tail[1].Index)
+	}
+
+	// Transactions summarized by the checkpoint are no longer resolvable.
+	pre, err := bc.GetBlock(2)
+	if err != nil {
+		t.Fatalf("GetBlock(2): %v", err)
+	}
+	if _, _, err := loaded.GetTransactionByID(pre.Transactions[0].ID); err == nil {
+		t.Error("transaction behind the checkpoint resolved on the loaded chain")
+	}
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// VerifyTransactionsParallel checks every transaction signature, spreading
+// the ECDSA work across workers goroutines (GOMAXPROCS when workers < 1).
+// Verification short-circuits once a failure is found, and the returned
+// error deterministically names the lowest failing index regardless of
+// which worker found a failure first.
+func VerifyTransactionsParallel(txs []Transaction, workers int) error {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > len(txs) {
+		workers = len(txs)
+	}
+	if workers <= 1 {
+		for i := range txs {
+			if !txs[i].VerifySignature() {
+				return invalidSignatureError(txs, int64(i))
+			}
+		}
+		return nil
+	}
+
+	// Workers claim indices from a shared counter and stop once every
+	// index is claimed or someone failed below their next claim; failed
+	// tracks the minimum failing index seen.
+	var next atomic.Int64
+	var failed atomic.Int64
+	failed.Store(math.MaxInt64)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := next.Add(1) - 1
+				if i >= int64(len(txs)) || i > failed.Load() {
+					return
+				}
+				if txs[i].VerifySignature() {
+					continue
+				}
+				for {
+					current := failed.Load()
+					if i >= current || failed.CompareAndSwap(current, i) {
+						break
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if i := failed.Load(); i != math.MaxInt64 {
+		return invalidSignatureError(txs, i)
+	}
+	return nil
+}
+
+func invalidSignatureError(txs []Transaction, i int64) error {
+	return fmt.Errorf("ledger: transaction %s at index %d has an invalid signature", txs[i].ID, i)
+}
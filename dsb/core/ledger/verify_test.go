@@ -0,0 +1,93 @@
+package ledger_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// signedBatch builds n distinct signed transactions from one wallet.
+func signedBatch(tb testing.TB, n int) []ledger.Transaction {
+	tb.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		tb.Fatalf("NewWallet: %v", err)
+	}
+	txs := make([]ledger.Transaction, n)
+	for i := range txs {
+		tx, err := ledger.NewSignedTransaction(wallet, ledger.TxTypePostCreated, []byte(fmt.Sprintf("cid-%d", i)))
+		if err != nil {
+			tb.Fatalf("NewSignedTransaction: %v", err)
+		}
+		txs[i] = *tx
+	}
+	return txs
+}
+
+func TestVerifyTransactionsParallel_AcceptsValidBatch(t *testing.T) {
+	txs := signedBatch(t, 50)
+	for _, workers := range []int{0, 1, 4, 100} {
+		if err := ledger.VerifyTransactionsParallel(txs, workers); err != nil {
+			t.Errorf("workers=%d: %v", workers, err)
+		}
+	}
+	if err := ledger.VerifyTransactionsParallel(nil, 0); err != nil {
+		t.Errorf("empty batch: %v", err)
+	}
+}
+
+func TestVerifyTransactionsParallel_ReportsLowestFailingIndex(t *testing.T) {
+	txs := signedBatch(t, 64)
+	// Corrupt two signatures; the error must deterministically name the
+	// lower index no matter which worker trips first.
+	txs[17].Signature[0] ^= 0xff
+	txs[40].Signature[0] ^= 0xff
+	for run := 0; run < 20; run++ {
+		err := ledger.VerifyTransactionsParallel(txs, 8)
+		if err == nil {
+			t.Fatal("corrupted batch accepted")
+		}
+		if !strings.Contains(err.Error(), "index 17") {
+			t.Fatalf("error %q does not name the lowest failing index 17", err)
+		}
+	}
+}
+
+// Run with -race: concurrent batches over shared transactions must not
+// trip the detector.
+func TestVerifyTransactionsParallel_ConcurrentBatches(t *testing.T) {
+	txs := signedBatch(t, 32)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ledger.VerifyTransactionsParallel(txs, 4); err != nil {
+				t.Errorf("VerifyTransactionsParallel: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkVerifyTransactions_1000Tx(b *testing.B) {
+	txs := signedBatch(b, 1000)
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := ledger.VerifyTransactionsParallel(txs, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := ledger.VerifyTransactionsParallel(txs, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// txWaiters fans appended blocks out to WaitForTransaction callers. It
+// lives apart from the chain lock: waiters are notified from the
+// OnBlockAdded subscriber, which already runs outside it.
+type txWaiters struct {
+	mu     sync.Mutex
+	byTxID map[string][]chan *Block
+	hooked bool
+}
+
+// register adds a waiter channel for the transaction ID.
+func (w *txWaiters) register(txID string) chan *Block {
+	ch := make(chan *Block, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.byTxID == nil {
+		w.byTxID = make(map[string][]chan *Block)
+	}
+	w.byTxID[txID] = append(w.byTxID[txID], ch)
+	return ch
+}
+
+// unregister removes one waiter channel, e.g. after its context expired.
+func (w *txWaiters) unregister(txID string, ch chan *Block) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	list := w.byTxID[txID]
+	for i := range list {
+		if list[i] == ch {
+			w.byTxID[txID] = append(list[:i], list[i+1:]...)
+			break
+		}
+	}
+	if len(w.byTxID[txID]) == 0 {
+		delete(w.byTxID, txID)
+	}
+}
+
+// notify wakes every waiter for the block's transactions.
+func (w *txWaiters) notify(block *Block) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range block.Transactions {
+		txID := block.Transactions[i].ID
+		for _, ch := range w.byTxID[txID] {
+			ch <- block // buffered; each channel receives at most once
+		}
+		delete(w.byTxID, txID)
+	}
+}
+
+// WaitForTransaction blocks until a block containing the transaction is
+// appended, returning that block, or until ctx expires, returning its
+// error. A transaction already on the chain returns immediately. It is the
+// synchronous primitive for submit-then-wait flows that otherwise poll.
+func (bc *Blockchain) WaitForTransaction(ctx context.Context, txID string) (*Block, error) {
+	if txID == "" {
+		return nil, fmt.Errorf("ledger: cannot wait for an empty transaction ID")
+	}
+	bc.waiters.mu.Lock()
+	if !bc.waiters.hooked {
+		bc.waiters.hooked = true
+		bc.waiters.mu.Unlock()
+		bc.OnBlockAdded(bc.waiters.notify)
+	} else {
+		bc.waiters.mu.Unlock()
+	}
+
+	// Register before checking the chain, so a block appended between the
+	// check and the registration cannot be missed.
+	ch := bc.waiters.register(txID)
+	if _, block := func() (*Transaction, *Block) {
+		bc.mu.RLock()
+		defer bc.mu.RUnlock()
+		return bc.lookupTx(txID)
+	}(); block != nil {
+		bc.waiters.unregister(txID, ch)
+		return block, nil
+	}
+
+	select {
+	case block := <-ch:
+		return block, nil
+	case <-ctx.Done():
+		bc.waiters.unregister(txID, ch)
+		return nil, ctx.Err()
+	}
+}
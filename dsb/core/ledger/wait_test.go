@@ -0,0 +1,77 @@
+package ledger_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+func TestWaitForTransaction_UnblocksOnAppend(t *testing.T) {
+	goldenEnv(t)
+	bc := ledger.NewBlockchain()
+	txs := signedBatch(t, 1)
+
+	type result struct {
+		block *ledger.Block
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		block, err := bc.WaitForTransaction(context.Background(), txs[0].ID)
+		done <- result{block, err}
+	}()
+	// Give the waiter a moment to register before the block lands.
+	time.Sleep(10 * time.Millisecond)
+
+	added, err := bc.AddBlock(txs)
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("WaitForTransaction: %v", res.err)
+		}
+		if res.block.Hash != added.Hash {
+			t.Errorf("waiter got block %d (%s), want %d (%s)",
+				res.block.Index, res.block.Hash, added.Index, added.Hash)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("waiter was not unblocked by the append")
+	}
+}
+
+func TestWaitForTransaction_AlreadyOnChain(t *testing.T) {
+	goldenEnv(t)
+	bc := ledger.NewBlockchain()
+	txs := signedBatch(t, 1)
+	added, err := bc.AddBlock(txs)
+	if err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	block, err := bc.WaitForTransaction(context.Background(), txs[0].ID)
+	if err != nil {
+		t.Fatalf("WaitForTransaction: %v", err)
+	}
+	if block.Hash != added.Hash {
+		t.Errorf("got block %d, want the already-mined block %d", block.Index, added.Index)
+	}
+}
+
+func TestWaitForTransaction_ContextExpiry(t *testing.T) {
+	bc := ledger.NewBlockchain()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	block, err := bc.WaitForTransaction(ctx, "tx-that-never-arrives")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want context.DeadlineExceeded", err)
+	}
+	if block != nil {
+		t.Errorf("got block %v on timeout, want nil", block)
+	}
+}
@@ -0,0 +1,67 @@
+package ledger
+
+import "sort"
+
+// DefaultTxBaseWeight is the weight charged for any transaction before its
+// payload, when the weight table has no per-type entry.
+const DefaultTxBaseWeight = 100
+
+// DefaultMaxBlockWeight is the per-block weight budget unless
+// SetMaxBlockWeight overrides it.
+const DefaultMaxBlockWeight = 1 << 20
+
+// WeightTable prices transactions: a base weight per transaction type plus
+// a per-payload-byte charge. Types absent from Base cost DefaultBase. A
+// daemon config tunes the table through Blockchain.SetWeightTable.
+type WeightTable struct {
+	Base        map[TransactionType]uint64
+	DefaultBase uint64
+	PerByte     uint64
+}
+
+// DefaultWeightTable prices every type uniformly at DefaultTxBaseWeight
+// plus one weight per payload byte.
+func DefaultWeightTable() WeightTable {
+	return WeightTable{DefaultBase: DefaultTxBaseWeight, PerByte: 1}
+}
+
+// WeightOf returns the transaction's weight under this table.
+func (t WeightTable) WeightOf(tx *Transaction) uint64 {
+	base, ok := t.Base[tx.Type]
+	if !ok {
+		base = t.DefaultBase
+	}
+	return base + t.PerByte*uint64(len(tx.Payload))
+}
+
+// BlockWeight sums the transactions' weights under this table.
+func (t WeightTable) BlockWeight(transactions []Transaction) uint64 {
+	var total uint64
+	for i := range transactions {
+		total += t.WeightOf(&transactions[i])
+	}
+	return total
+}
+
+// Weight returns the transaction's weight under the default table. Chains
+// with a tuned table price through WeightTable.WeightOf instead.
+func (tx *Transaction) Weight() uint64 {
+	return DefaultWeightTable().WeightOf(tx)
+}
+
+// sortByFeeDensity orders transactions by fee per weight, highest first,
+// with ties (including the all-zero-fee case) broken by transaction ID so
+// the order is deterministic.
+func sortByFeeDensity(transactions []Transaction) {
+	sort.Slice(transactions, func(i, j int) bool {
+		a, b := &transactions[i], &transactions[j]
+		// Compare a.Fee/a.Weight() against b.Fee/b.Weight() without
+		// division: cross-multiply.
+		lhs := a.Fee * b.Weight()
+		rhs := b.Fee * a.Weight()
+		if lhs != rhs {
+			return lhs > rhs
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,113 @@
+package ledger_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func feeTx(t *testing.T, wallet *identity.Wallet, payload string, fee uint64) ledger.Transaction {
+	t.Helper()
+	tx, err := ledger.NewTransaction(ledger.TxTypePostCreated, wallet.Address, []byte(payload), ledger.WithFee(fee))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := tx.Sign(wallet); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return *tx
+}
+
+func TestWeight_DeterministicAndTableDriven(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	tx := feeTx(t, wallet, "cid-payload", 0)
+	if tx.Weight() != tx.Weight() {
+		t.Error("Weight() is not deterministic for the same transaction")
+	}
+	want := uint64(ledger.DefaultTxBaseWeight + len("cid-payload"))
+	if got := tx.Weight(); got != want {
+		t.Errorf("Weight() = %d, want %d", got, want)
+	}
+
+	table := ledger.WeightTable{
+		Base:        map[ledger.TransactionType]uint64{ledger.TxTypePostCreated: 10},
+		DefaultBase: 500,
+		PerByte:     2,
+	}
+	if got, want := table.WeightOf(&tx), uint64(10+2*len("cid-payload")); got != want {
+		t.Errorf("tuned WeightOf = %d, want %d", got, want)
+	}
+	other, err := ledger.NewTransaction(ledger.TxTypeFollowed, wallet.Address, nil)
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if got := table.WeightOf(other); got != 500 {
+		t.Errorf("WeightOf for unlisted type = %d, want the default base 500", got)
+	}
+}
+
+func TestAddBlock_RejectsOverweightBlock(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	chain := ledger.NewBlockchain()
+	chain.SetMaxBlockWeight(2*ledger.DefaultTxBaseWeight + 10)
+
+	light := []ledger.Transaction{feeTx(t, wallet, "a", 0)}
+	if _, err := chain.AddBlock(light); err != nil {
+		t.Fatalf("AddBlock(light): %v", err)
+	}
+
+	heavy := []ledger.Transaction{
+		feeTx(t, wallet, "bb", 0),
+		feeTx(t, wallet, "cc", 0),
+		feeTx(t, wallet, strings.Repeat("d", 20), 0),
+	}
+	_, err = chain.AddBlock(heavy)
+	if err == nil {
+		t.Fatal("overweight block was accepted")
+	}
+	if !strings.Contains(err.Error(), "weight") {
+		t.Errorf("rejection does not mention weight: %v", err)
+	}
+	if err := chain.IsChainValid(); err != nil {
+		t.Errorf("chain invalid after rejected block: %v", err)
+	}
+}
+
+func TestPending_DrainsHighestFeePerWeightFirst(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	pool := ledger.NewMempool()
+	// Same payload size, so fee order is density order: 50, 10, 0.
+	cheap := feeTx(t, wallet, "payload-a", 0)
+	mid := feeTx(t, wallet, "payload-b", 10)
+	rich := feeTx(t, wallet, "payload-c", 50)
+	// A big payload dilutes a big fee below mid's density.
+	diluted := feeTx(t, wallet, strings.Repeat("x", 1000), 20)
+	for _, tx := range []ledger.Transaction{cheap, mid, rich, diluted} {
+		tx := tx
+		if err := pool.Submit(&tx); err != nil {
+			t.Fatalf("Submit: %v", err)
+		}
+	}
+
+	pending := pool.Pending()
+	if len(pending) != 4 {
+		t.Fatalf("Pending returned %d transactions, want 4", len(pending))
+	}
+	wantOrder := []string{rich.ID, mid.ID, diluted.ID, cheap.ID}
+	for i, want := range wantOrder {
+		if pending[i].ID != want {
+			t.Errorf("Pending[%d].ID = %s, want %s", i, pending[i].ID, want)
+		}
+	}
+}
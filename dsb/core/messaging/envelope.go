@@ -0,0 +1,97 @@
+// Package messaging implements private direct messages over the DDS. The
+// message body is encrypted once under a random content key; that key is
+// wrapped per recipient via ECDH against their identity key. The resulting
+// envelope is published as ordinary DDS content, and a TxTypeMessageSent
+// transaction on the ledger points at it with hashed recipient hints so
+// recipients can find their mail without the chain revealing who they are.
+package messaging
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// RecipientKey wraps the message content key for one recipient.
+type RecipientKey struct {
+	// Hint is sha256(salt || recipientAddress); only the recipient can
+	// recognize it as theirs.
+	Hint string `json:"hint"`
+	// EphemeralPub is the sender's one-time ECDH public key for this
+	// recipient.
+	EphemeralPub []byte `json:"ephemeralPub"`
+	Nonce        []byte `json:"nonce"`
+	WrappedKey   []byte `json:"wrappedKey"`
+}
+
+// Envelope is the published form of a message.
+type Envelope struct {
+	SenderAddress   string         `json:"senderAddress"`
+	SenderPublicKey []byte         `json:"senderPublicKey"`
+	Salt            []byte         `json:"salt"`
+	Nonce           []byte         `json:"nonce"`
+	Ciphertext      []byte         `json:"ciphertext"`
+	Recipients      []RecipientKey `json:"recipients"`
+	Timestamp       int64          `json:"timestamp"`
+	Signature       []byte         `json:"signature,omitempty"`
+}
+
+// RecipientHint computes the hashed hint for an address under the
+// envelope's salt.
+func RecipientHint(salt []byte, address string) string {
+	sum := sha256.Sum256(append(append([]byte{}, salt...), []byte(address)...))
+	return hex.EncodeToString(sum[:])
+}
+
+// signingBytes returns the canonical bytes covered by the sender signature.
+func (e *Envelope) signingBytes() ([]byte, error) {
+	unsigned := *e
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("messaging: failed to serialize envelope for signing: %w", err)
+	}
+	return data, nil
+}
+
+// VerifySignature reports whether the envelope was signed by the claimed
+// sender.
+func (e *Envelope) VerifySignature() bool {
+	if len(e.Signature) == 0 || len(e.SenderPublicKey) == 0 {
+		return false
+	}
+	if !identity.AddressesEqual(identity.PublicKeyToAddress(e.SenderPublicKey), e.SenderAddress) {
+		return false
+	}
+	data, err := e.signingBytes()
+	if err != nil {
+		return false
+	}
+	return identity.VerifySignature(e.SenderPublicKey, data, e.Signature)
+}
+
+// newGCM builds an AES-GCM sealer for a 32-byte key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("messaging: bad cipher key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// wrapKeyFor derives a key-encryption key for recipientPub via ephemeral
+// ECDH and seals contentKey with it.
+func wrapKeyFor(recipientPub []byte, contentKey []byte) (ephemeralPub, nonce, wrapped []byte, err error) {
+	return identity.WrapKey(recipientPub, contentKey)
+}
+
+// unwrapKey recovers the content key from a RecipientKey using the
+// recipient's wallet.
+func unwrapKey(wallet *identity.Wallet, rk *RecipientKey) ([]byte, error) {
+	return identity.UnwrapKey(wallet, rk.EphemeralPub, rk.Nonce, rk.WrappedKey)
+}
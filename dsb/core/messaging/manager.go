@@ -0,0 +1,230 @@
+package messaging
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// EnvelopeContentType is the content type envelopes are published under.
+const EnvelopeContentType = "application/x-dsb-envelope"
+
+// KeyDirectory resolves an address to the owner's compressed public key so
+// senders can wrap message keys for recipients they only know by address.
+type KeyDirectory interface {
+	PublicKey(address string) ([]byte, error)
+}
+
+// MemoryKeyDirectory is an in-process KeyDirectory fed by registration.
+type MemoryKeyDirectory struct {
+	mu   sync.RWMutex
+	keys map[string][]byte
+}
+
+// NewMemoryKeyDirectory returns an empty directory.
+func NewMemoryKeyDirectory() *MemoryKeyDirectory {
+	return &MemoryKeyDirectory{keys: make(map[string][]byte)}
+}
+
+// Register records a wallet's public key under its address.
+func (d *MemoryKeyDirectory) Register(wallet *identity.Wallet) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.keys[wallet.Address] = wallet.PublicKey
+}
+
+// PublicKey implements KeyDirectory.
+func (d *MemoryKeyDirectory) PublicKey(address string) ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	key, ok := d.keys[address]
+	if !ok {
+		return nil, fmt.Errorf("messaging: no public key known for %s", address)
+	}
+	return key, nil
+}
+
+// MessagePointer is the TxTypeMessageSent payload: where the envelope lives
+// and hashed hints for its recipients.
+type MessagePointer struct {
+	EnvelopeCID string   `json:"envelopeCid"`
+	Hints       []string `json:"hints"`
+}
+
+func init() {
+	ledger.RegisterPayloadCodec(ledger.TxTypeMessageSent,
+		ledger.JSONPayloadCodec(func() interface{} { return &MessagePointer{} }))
+}
+
+// InboxMessage is a decrypted message addressed to the inbox owner.
+type InboxMessage struct {
+	SenderAddress string
+	Body          []byte
+	EnvelopeCID   string
+	Timestamp     int64
+}
+
+// MessageManager sends and receives private messages through the DDS.
+type MessageManager struct {
+	dds       *service.DDSCoreService
+	directory KeyDirectory
+}
+
+// NewMessageManager returns a manager publishing through dds and resolving
+// recipient keys via directory.
+func NewMessageManager(dds *service.DDSCoreService, directory KeyDirectory) *MessageManager {
+	return &MessageManager{dds: dds, directory: directory}
+}
+
+// Send encrypts body for the recipient addresses, publishes the envelope,
+// and returns its CID together with a signed TxTypeMessageSent transaction
+// ready for inclusion on the ledger.
+func (m *MessageManager) Send(wallet *identity.Wallet, recipients []string, body []byte) (string, *ledger.Transaction, error) {
+	if len(recipients) == 0 {
+		return "", nil, fmt.Errorf("messaging: message requires at least one recipient")
+	}
+	contentKey := make([]byte, 32)
+	if _, err := rand.Read(contentKey); err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to generate content key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to generate salt: %w", err)
+	}
+	gcm, err := newGCM(contentKey)
+	if err != nil {
+		return "", nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to generate nonce: %w", err)
+	}
+
+	envelope := &Envelope{
+		SenderAddress:   wallet.Address,
+		SenderPublicKey: wallet.PublicKey,
+		Salt:            salt,
+		Nonce:           nonce,
+		Ciphertext:      gcm.Seal(nil, nonce, body, nil),
+		Timestamp:       clock.Now(),
+	}
+	hints := make([]string, 0, len(recipients))
+	for _, addr := range recipients {
+		pub, err := m.directory.PublicKey(addr)
+		if err != nil {
+			return "", nil, err
+		}
+		ephemeralPub, keyNonce, wrapped, err := wrapKeyFor(pub, contentKey)
+		if err != nil {
+			return "", nil, fmt.Errorf("messaging: failed to wrap key for %s: %w", addr, err)
+		}
+		hint := RecipientHint(salt, addr)
+		hints = append(hints, hint)
+		envelope.Recipients = append(envelope.Recipients, RecipientKey{
+			Hint:         hint,
+			EphemeralPub: ephemeralPub,
+			Nonce:        keyNonce,
+			WrappedKey:   wrapped,
+		})
+	}
+
+	signing, err := envelope.signingBytes()
+	if err != nil {
+		return "", nil, err
+	}
+	if envelope.Signature, err = wallet.Sign(signing); err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to sign envelope: %w", err)
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to serialize envelope: %w", err)
+	}
+	cid, err := m.dds.Publish(data, EnvelopeContentType)
+	if err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to publish envelope: %w", err)
+	}
+
+	payload, err := json.Marshal(MessagePointer{EnvelopeCID: cid, Hints: hints})
+	if err != nil {
+		return "", nil, fmt.Errorf("messaging: failed to serialize message pointer: %w", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypeMessageSent, wallet.Address, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := tx.Sign(wallet); err != nil {
+		return "", nil, err
+	}
+	return cid, tx, nil
+}
+
+// Inbox scans the chain for messages addressed to the wallet and decrypts
+// them. Messages whose envelopes cannot be retrieved, verified, or
+// decrypted are skipped.
+func (m *MessageManager) Inbox(wallet *identity.Wallet, bc *ledger.Blockchain) ([]InboxMessage, error) {
+	var inbox []InboxMessage
+	for _, block := range bc.Blocks() {
+		for i := range block.Transactions {
+			tx := &block.Transactions[i]
+			if tx.Type != ledger.TxTypeMessageSent {
+				continue
+			}
+			var ptr MessagePointer
+			if err := json.Unmarshal(tx.Payload, &ptr); err != nil {
+				continue
+			}
+			msg, err := m.open(wallet, ptr.EnvelopeCID)
+			if err != nil {
+				continue
+			}
+			inbox = append(inbox, *msg)
+		}
+	}
+	return inbox, nil
+}
+
+// open retrieves an envelope and attempts decryption for the wallet.
+func (m *MessageManager) open(wallet *identity.Wallet, envelopeCID string) (*InboxMessage, error) {
+	data, err := m.dds.Retrieve(envelopeCID)
+	if err != nil {
+		return nil, err
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("messaging: invalid envelope %s: %w", envelopeCID, err)
+	}
+	if !envelope.VerifySignature() {
+		return nil, fmt.Errorf("messaging: envelope %s has an invalid sender signature", envelopeCID)
+	}
+	myHint := RecipientHint(envelope.Salt, wallet.Address)
+	for i := range envelope.Recipients {
+		if envelope.Recipients[i].Hint != myHint {
+			continue
+		}
+		contentKey, err := unwrapKey(wallet, &envelope.Recipients[i])
+		if err != nil {
+			return nil, err
+		}
+		gcm, err := newGCM(contentKey)
+		if err != nil {
+			return nil, err
+		}
+		body, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+		if err != nil {
+			return nil, fmt.Errorf("messaging: envelope %s failed authentication: %w", envelopeCID, err)
+		}
+		return &InboxMessage{
+			SenderAddress: envelope.SenderAddress,
+			Body:          body,
+			EnvelopeCID:   envelopeCID,
+			Timestamp:     envelope.Timestamp,
+		}, nil
+	}
+	return nil, fmt.Errorf("messaging: envelope %s is not addressed to %s", envelopeCID, wallet.Address)
+}
@@ -0,0 +1,137 @@
+package messaging_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/core/messaging"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+type messagingFixture struct {
+	manager   *messaging.MessageManager
+	dds       *service.DDSCoreService
+	directory *messaging.MemoryKeyDirectory
+	chain     *ledger.Blockchain
+}
+
+func newMessagingFixture(t *testing.T) *messagingFixture {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(128)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-messaging", "127.0.0.1:9030"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	directory := messaging.NewMemoryKeyDirectory()
+	return &messagingFixture{
+		manager:   messaging.NewMessageManager(dds, directory),
+		dds:       dds,
+		directory: directory,
+		chain:     ledger.NewBlockchain(),
+	}
+}
+
+func newRegisteredWallet(t *testing.T, f *messagingFixture) *identity.Wallet {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	f.directory.Register(wallet)
+	return wallet
+}
+
+func TestSend_RecipientsDecryptNonRecipientCannot(t *testing.T) {
+	f := newMessagingFixture(t)
+	sender := newRegisteredWallet(t, f)
+	alice := newRegisteredWallet(t, f)
+	bob := newRegisteredWallet(t, f)
+	eve := newRegisteredWallet(t, f)
+
+	body := []byte("meet at the usual block height")
+	cid, tx, err := f.manager.Send(sender, []string{alice.Address, bob.Address}, body)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	for _, recipient := range []*identity.Wallet{alice, bob} {
+		inbox, err := f.manager.Inbox(recipient, f.chain)
+		if err != nil {
+			t.Fatalf("Inbox: %v", err)
+		}
+		if len(inbox) != 1 {
+			t.Fatalf("inbox for recipient has %d messages, want 1", len(inbox))
+		}
+		if string(inbox[0].Body) != string(body) {
+			t.Errorf("decrypted body = %q, want %q", inbox[0].Body, body)
+		}
+		if inbox[0].SenderAddress != sender.Address || inbox[0].EnvelopeCID != cid {
+			t.Errorf("inbox message metadata = %+v", inbox[0])
+		}
+	}
+
+	eveInbox, err := f.manager.Inbox(eve, f.chain)
+	if err != nil {
+		t.Fatalf("Inbox(eve): %v", err)
+	}
+	if len(eveInbox) != 0 {
+		t.Errorf("non-recipient inbox has %d messages, want 0", len(eveInbox))
+	}
+}
+
+func TestSend_TamperedEnvelopeDetected(t *testing.T) {
+	f := newMessagingFixture(t)
+	sender := newRegisteredWallet(t, f)
+	alice := newRegisteredWallet(t, f)
+
+	_, tx, err := f.manager.Send(sender, []string{alice.Address}, []byte("original"))
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+
+	// Republish a tampered envelope under a new CID and point a forged
+	// transaction at it; signature verification must reject it.
+	tampered := []byte(`{"senderAddress":"forged","ciphertext":"AAAA"}`)
+	forgedCID, err := f.dds.Publish(tampered, messaging.EnvelopeContentType)
+	if err != nil {
+		t.Fatalf("Publish tampered: %v", err)
+	}
+	mallory := newRegisteredWallet(t, f)
+	forgedTx, err := ledger.NewTransaction(ledger.TxTypeMessageSent, mallory.Address,
+		[]byte(`{"envelopeCid":"`+forgedCID+`","hints":[]}`))
+	if err != nil {
+		t.Fatalf("NewTransaction: %v", err)
+	}
+	if err := forgedTx.Sign(mallory); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*forgedTx}); err != nil {
+		t.Fatalf("AddBlock(forged): %v", err)
+	}
+
+	inbox, err := f.manager.Inbox(alice, f.chain)
+	if err != nil {
+		t.Fatalf("Inbox: %v", err)
+	}
+	if len(inbox) != 1 {
+		t.Fatalf("inbox has %d messages, want only the legitimate 1", len(inbox))
+	}
+	if string(inbox[0].Body) != "original" {
+		t.Errorf("decrypted body = %q, want %q", inbox[0].Body, "original")
+	}
+}
@@ -0,0 +1,200 @@
+// Package search maintains a local full-text index over content a node has
+// already retrieved — posts and profiles — so users can search "things
+// I've seen" without re-downloading them. The index is an in-memory
+// inverted index with optional JSON file persistence; retrieval hooks feed
+// it documents as content flows through the node.
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Document is one indexed piece of content.
+type Document struct {
+	CID       string `json:"cid"`
+	Author    string `json:"author"`
+	Kind      string `json:"kind"`
+	Text      string `json:"text"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Hit is one search result.
+type Hit struct {
+	Document
+	// Score counts how many query tokens matched the document.
+	Score int
+}
+
+// Indexer builds and queries the inverted index. It is safe for concurrent
+// use by retrieval goroutines.
+type Indexer struct {
+	mu   sync.RWMutex
+	docs map[string]Document
+	// index maps each token to the set of CIDs containing it.
+	index map[string]map[string]bool
+}
+
+// NewIndexer returns an empty index.
+func NewIndexer() *Indexer {
+	return &Indexer{
+		docs:  make(map[string]Document),
+		index: make(map[string]map[string]bool),
+	}
+}
+
+// tokenize lowercases text and splits it on non-alphanumeric runes.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Add indexes a document, replacing any previous document with the same
+// CID.
+func (ix *Indexer) Add(doc Document) error {
+	if doc.CID == "" {
+		return fmt.Errorf("search: document requires a CID")
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(doc.CID)
+	ix.docs[doc.CID] = doc
+	for _, token := range tokenize(doc.Text) {
+		set, ok := ix.index[token]
+		if !ok {
+			set = make(map[string]bool)
+			ix.index[token] = set
+		}
+		set[doc.CID] = true
+	}
+	return nil
+}
+
+// remove unindexes a CID. Callers must hold the write lock.
+func (ix *Indexer) remove(cid string) {
+	doc, ok := ix.docs[cid]
+	if !ok {
+		return
+	}
+	for _, token := range tokenize(doc.Text) {
+		if set := ix.index[token]; set != nil {
+			delete(set, cid)
+			if len(set) == 0 {
+				delete(ix.index, token)
+			}
+		}
+	}
+	delete(ix.docs, cid)
+}
+
+// Len returns the number of indexed documents.
+func (ix *Indexer) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Search returns up to limit documents matching every query token, where a
+// query token matches by prefix (lowercase-folded). Results are ordered by
+// score, then recency.
+func (ix *Indexer) Search(query string, limit int) ([]Hit, error) {
+	return ix.SearchByAuthor("", query, limit)
+}
+
+// SearchByAuthor is Search restricted to one author's documents. An empty
+// author means no filter.
+func (ix *Indexer) SearchByAuthor(author, query string, limit int) ([]Hit, error) {
+	tokens := tokenize(query)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("search: query contains no searchable tokens")
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	// scores counts, per CID, how many query tokens matched.
+	scores := make(map[string]int)
+	for _, token := range tokens {
+		matched := make(map[string]bool)
+		for indexed, cids := range ix.index {
+			if !strings.HasPrefix(indexed, token) {
+				continue
+			}
+			for cid := range cids {
+				matched[cid] = true
+			}
+		}
+		for cid := range matched {
+			scores[cid]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for cid, score := range scores {
+		if score < len(tokens) {
+			continue
+		}
+		doc := ix.docs[cid]
+		if author != "" && doc.Author != author {
+			continue
+		}
+		hits = append(hits, Hit{Document: doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Timestamp != hits[j].Timestamp {
+			return hits[i].Timestamp > hits[j].Timestamp
+		}
+		return hits[i].CID < hits[j].CID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// Save writes the indexed documents to path as JSON. The inverted index is
+// derived state and is rebuilt on load.
+func (ix *Indexer) Save(path string) error {
+	ix.mu.RLock()
+	docs := make([]Document, 0, len(ix.docs))
+	for _, doc := range ix.docs {
+		docs = append(docs, doc)
+	}
+	ix.mu.RUnlock()
+	sort.Slice(docs, func(i, j int) bool { return docs[i].CID < docs[j].CID })
+	data, err := json.Marshal(docs)
+	if err != nil {
+		return fmt.Errorf("search: failed to serialize index: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("search: failed to write index file: %w", err)
+	}
+	return nil
+}
+
+// Load reads documents previously written by Save and rebuilds the index.
+func Load(path string) (*Indexer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("search: failed to read index file: %w", err)
+	}
+	var docs []Document
+	if err := json.Unmarshal(data, &docs); err != nil {
+		return nil, fmt.Errorf("search: invalid index file: %w", err)
+	}
+	ix := NewIndexer()
+	for _, doc := range docs {
+		if err := ix.Add(doc); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
@@ -0,0 +1,126 @@
+package search_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/search"
+)
+
+func seededIndexer(t *testing.T) *search.Indexer {
+	t.Helper()
+	ix := search.NewIndexer()
+	docs := []search.Document{
+		{CID: "cid-1", Author: "addr-ada", Kind: "post", Text: "Merkle trees make tamper-evidence cheap", Timestamp: 100},
+		{CID: "cid-2", Author: "addr-bob", Kind: "post", Text: "my cat discovered the merkle garden", Timestamp: 200},
+		{CID: "cid-3", Author: "addr-ada", Kind: "profile", Text: "Ada, systems programmer, gardener", Timestamp: 300},
+		{CID: "cid-4", Author: "addr-bob", Kind: "post", Text: "nothing to see here", Timestamp: 400},
+	}
+	for _, doc := range docs {
+		if err := ix.Add(doc); err != nil {
+			t.Fatalf("Add(%s): %v", doc.CID, err)
+		}
+	}
+	return ix
+}
+
+func TestSearch_KeywordAndPrefix(t *testing.T) {
+	ix := seededIndexer(t)
+	hits, err := ix.Search("merkle", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits for %q, want 2", len(hits), "merkle")
+	}
+	// Prefix matching: "garden" matches both "garden" and "gardener".
+	hits, err = ix.Search("garden", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("got %d hits for %q, want 2", len(hits), "garden")
+	}
+	// Multi-token queries require every token.
+	hits, err = ix.Search("merkle cat", 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 1 || hits[0].CID != "cid-2" {
+		t.Fatalf("hits for %q = %v, want only cid-2", "merkle cat", hits)
+	}
+}
+
+func TestSearch_AuthorFilterAndLimit(t *testing.T) {
+	ix := seededIndexer(t)
+	hits, err := ix.SearchByAuthor("addr-ada", "merkle", 10)
+	if err != nil {
+		t.Fatalf("SearchByAuthor: %v", err)
+	}
+	if len(hits) != 1 || hits[0].CID != "cid-1" {
+		t.Fatalf("author-filtered hits = %v, want only cid-1", hits)
+	}
+	hits, err = ix.Search("merkle", 1)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(hits) != 1 {
+		t.Errorf("limit 1 returned %d hits", len(hits))
+	}
+}
+
+func TestIndexer_PersistenceRoundTrip(t *testing.T) {
+	ix := seededIndexer(t)
+	path := filepath.Join(t.TempDir(), "index.json")
+	if err := ix.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := search.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if loaded.Len() != ix.Len() {
+		t.Fatalf("loaded %d documents, want %d", loaded.Len(), ix.Len())
+	}
+	hits, err := loaded.Search("merkle", 10)
+	if err != nil {
+		t.Fatalf("Search after load: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Errorf("got %d hits after reload, want 2", len(hits))
+	}
+}
+
+// Run with -race: concurrent indexing and searching must be safe.
+func TestIndexer_ConcurrentAddAndSearch(t *testing.T) {
+	ix := search.NewIndexer()
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				doc := search.Document{
+					CID:    fmt.Sprintf("cid-%d-%d", w, i),
+					Author: fmt.Sprintf("addr-%d", w),
+					Kind:   "post",
+					Text:   fmt.Sprintf("concurrent document %d from worker %d", i, w),
+				}
+				if err := ix.Add(doc); err != nil {
+					t.Errorf("Add: %v", err)
+					return
+				}
+				if _, err := ix.Search("concurrent", 5); err != nil {
+					t.Errorf("Search: %v", err)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if ix.Len() != 100 {
+		t.Errorf("indexed %d documents, want 100", ix.Len())
+	}
+}
@@ -0,0 +1,116 @@
+// Package sharing implements after-the-fact access grants for encrypted
+// content. The author of a piece of content can wrap its content key for
+// another address and publish the resulting ShareGrant through the DDS; a
+// TxTypeShareGranted transaction points at the grant, and a later
+// TxTypeShareRevoked transaction withdraws it. Compliant nodes refuse to
+// serve revoked grants.
+package sharing
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// ShareGrant gives one grantee the content key for one piece of content.
+// The key is wrapped under the grantee's identity key, and the whole grant
+// is signed by the content author.
+type ShareGrant struct {
+	ContentCID      string `json:"contentCid"`
+	GranteeAddress  string `json:"granteeAddress"`
+	AuthorAddress   string `json:"authorAddress"`
+	AuthorPublicKey []byte `json:"authorPublicKey"`
+	EphemeralPub    []byte `json:"ephemeralPub"`
+	Nonce           []byte `json:"nonce"`
+	WrappedKey      []byte `json:"wrappedKey"`
+	Timestamp       int64  `json:"timestamp"`
+	Signature       []byte `json:"signature,omitempty"`
+}
+
+// NewShareGrant wraps contentKey for the holder of granteePub and signs the
+// grant with the author's wallet.
+func NewShareGrant(author *identity.Wallet, contentCID, granteeAddress string, granteePub, contentKey []byte) (*ShareGrant, error) {
+	if contentCID == "" {
+		return nil, fmt.Errorf("sharing: grant requires a content CID")
+	}
+	ephemeralPub, nonce, wrapped, err := identity.WrapKey(granteePub, contentKey)
+	if err != nil {
+		return nil, fmt.Errorf("sharing: failed to wrap key for %s: %w", granteeAddress, err)
+	}
+	g := &ShareGrant{
+		ContentCID:      contentCID,
+		GranteeAddress:  granteeAddress,
+		AuthorAddress:   author.Address,
+		AuthorPublicKey: author.PublicKey,
+		EphemeralPub:    ephemeralPub,
+		Nonce:           nonce,
+		WrappedKey:      wrapped,
+		Timestamp:       clock.Now(),
+	}
+	signing, err := g.signingBytes()
+	if err != nil {
+		return nil, err
+	}
+	if g.Signature, err = author.Sign(signing); err != nil {
+		return nil, fmt.Errorf("sharing: failed to sign grant: %w", err)
+	}
+	return g, nil
+}
+
+// signingBytes returns the canonical bytes covered by the author signature.
+func (g *ShareGrant) signingBytes() ([]byte, error) {
+	unsigned := *g
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("sharing: failed to serialize grant for signing: %w", err)
+	}
+	return data, nil
+}
+
+// VerifySignature reports whether the grant was signed by the claimed
+// author.
+func (g *ShareGrant) VerifySignature() bool {
+	if len(g.Signature) == 0 || len(g.AuthorPublicKey) == 0 {
+		return false
+	}
+	if !identity.AddressesEqual(identity.PublicKeyToAddress(g.AuthorPublicKey), g.AuthorAddress) {
+		return false
+	}
+	data, err := g.signingBytes()
+	if err != nil {
+		return false
+	}
+	return identity.VerifySignature(g.AuthorPublicKey, data, g.Signature)
+}
+
+// UnwrapKey recovers the content key using the grantee's wallet.
+func (g *ShareGrant) UnwrapKey(wallet *identity.Wallet) ([]byte, error) {
+	if wallet.Address != g.GranteeAddress {
+		return nil, fmt.Errorf("sharing: grant for %s is not addressed to %s", g.GranteeAddress, wallet.Address)
+	}
+	key, err := identity.UnwrapKey(wallet, g.EphemeralPub, g.Nonce, g.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("sharing: failed to unwrap content key: %w", err)
+	}
+	return key, nil
+}
+
+// ToJSON serializes the grant for publication.
+func (g *ShareGrant) ToJSON() ([]byte, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, fmt.Errorf("sharing: failed to serialize grant: %w", err)
+	}
+	return data, nil
+}
+
+// GrantFromJSON deserializes a published grant.
+func GrantFromJSON(data []byte) (*ShareGrant, error) {
+	var g ShareGrant
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("sharing: invalid grant JSON: %w", err)
+	}
+	return &g, nil
+}
@@ -0,0 +1,83 @@
+package sharing
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Sentinel errors returned by GrantIndex lookups.
+var (
+	// ErrGrantNotFound means no grant exists for the content/grantee pair.
+	ErrGrantNotFound = errors.New("sharing: grant not found")
+	// ErrGrantRevoked means a grant existed but has been revoked; compliant
+	// nodes must not serve it.
+	ErrGrantRevoked = errors.New("sharing: grant revoked")
+	// ErrGrantInvalid means a grant failed signature verification.
+	ErrGrantInvalid = errors.New("sharing: grant signature invalid")
+)
+
+// grantKey identifies a grant by content and grantee.
+type grantKey struct {
+	contentCID string
+	grantee    string
+}
+
+// GrantIndex is a node's view of the grants it will serve. Grants are
+// verified on admission; revocations are honored by refusing subsequent
+// lookups rather than deleting, so a re-added grant stays refused.
+type GrantIndex struct {
+	mu      sync.RWMutex
+	grants  map[grantKey]*ShareGrant
+	revoked map[grantKey]bool
+}
+
+// NewGrantIndex returns an empty index.
+func NewGrantIndex() *GrantIndex {
+	return &GrantIndex{
+		grants:  make(map[grantKey]*ShareGrant),
+		revoked: make(map[grantKey]bool),
+	}
+}
+
+// Add admits a grant after verifying its author signature. Adding a grant
+// for a revoked content/grantee pair succeeds but the pair stays refused.
+func (idx *GrantIndex) Add(grant *ShareGrant) error {
+	if !grant.VerifySignature() {
+		return fmt.Errorf("%w: content %s grantee %s", ErrGrantInvalid, grant.ContentCID, grant.GranteeAddress)
+	}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.grants[grantKey{grant.ContentCID, grant.GranteeAddress}] = grant
+	return nil
+}
+
+// Revoke marks the grant for the content/grantee pair as withdrawn. Only
+// the grant's author may revoke it; revoking a pair with no known grant is
+// recorded so a grant arriving later is refused too.
+func (idx *GrantIndex) Revoke(contentCID, granteeAddress, requesterAddress string) error {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	key := grantKey{contentCID, granteeAddress}
+	if grant, ok := idx.grants[key]; ok && grant.AuthorAddress != requesterAddress {
+		return fmt.Errorf("sharing: only the author %s may revoke this grant", grant.AuthorAddress)
+	}
+	idx.revoked[key] = true
+	return nil
+}
+
+// Lookup returns the grant for the content/grantee pair, refusing revoked
+// pairs with ErrGrantRevoked.
+func (idx *GrantIndex) Lookup(contentCID, granteeAddress string) (*ShareGrant, error) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	key := grantKey{contentCID, granteeAddress}
+	if idx.revoked[key] {
+		return nil, fmt.Errorf("%w: content %s grantee %s", ErrGrantRevoked, contentCID, granteeAddress)
+	}
+	grant, ok := idx.grants[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: content %s grantee %s", ErrGrantNotFound, contentCID, granteeAddress)
+	}
+	return grant, nil
+}
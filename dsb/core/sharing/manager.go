@@ -0,0 +1,114 @@
+package sharing
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// GrantContentType is the content type grants are published under.
+const GrantContentType = "application/x-dsb-grant"
+
+// GrantPointer is the TxTypeShareGranted payload.
+type GrantPointer struct {
+	GrantCID   string `json:"grantCid"`
+	ContentCID string `json:"contentCid"`
+}
+
+// RevocationNotice is the TxTypeShareRevoked payload.
+type RevocationNotice struct {
+	ContentCID     string `json:"contentCid"`
+	GranteeAddress string `json:"granteeAddress"`
+}
+
+func init() {
+	ledger.RegisterPayloadCodec(ledger.TxTypeShareGranted,
+		ledger.JSONPayloadCodec(func() interface{} { return &GrantPointer{} }))
+	ledger.RegisterPayloadCodec(ledger.TxTypeShareRevoked,
+		ledger.JSONPayloadCodec(func() interface{} { return &RevocationNotice{} }))
+}
+
+// ShareManager publishes grants through the DDS and maintains the local
+// grant index consulted during decryption.
+type ShareManager struct {
+	dds   *service.DDSCoreService
+	index *GrantIndex
+}
+
+// NewShareManager returns a manager publishing through dds.
+func NewShareManager(dds *service.DDSCoreService) *ShareManager {
+	return &ShareManager{dds: dds, index: NewGrantIndex()}
+}
+
+// Index exposes the manager's grant index, e.g. for nodes applying
+// revocations observed on the ledger.
+func (m *ShareManager) Index() *GrantIndex {
+	return m.index
+}
+
+// Grant wraps contentKey for the grantee, publishes the signed grant, adds
+// it to the local index, and returns the grant CID together with a signed
+// TxTypeShareGranted transaction ready for inclusion on the ledger.
+func (m *ShareManager) Grant(author *identity.Wallet, contentCID, granteeAddress string, granteePub, contentKey []byte) (string, *ledger.Transaction, error) {
+	grant, err := NewShareGrant(author, contentCID, granteeAddress, granteePub, contentKey)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := grant.ToJSON()
+	if err != nil {
+		return "", nil, err
+	}
+	cid, err := m.dds.Publish(data, GrantContentType)
+	if err != nil {
+		return "", nil, fmt.Errorf("sharing: failed to publish grant: %w", err)
+	}
+	if err := m.index.Add(grant); err != nil {
+		return "", nil, err
+	}
+	payload, err := json.Marshal(GrantPointer{GrantCID: cid, ContentCID: contentCID})
+	if err != nil {
+		return "", nil, fmt.Errorf("sharing: failed to serialize grant pointer: %w", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypeShareGranted, author.Address, payload)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := tx.Sign(author); err != nil {
+		return "", nil, err
+	}
+	return cid, tx, nil
+}
+
+// Revoke withdraws the grant for the content/grantee pair and returns a
+// signed TxTypeShareRevoked transaction announcing the revocation.
+func (m *ShareManager) Revoke(author *identity.Wallet, contentCID, granteeAddress string) (*ledger.Transaction, error) {
+	if err := m.index.Revoke(contentCID, granteeAddress, author.Address); err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(RevocationNotice{ContentCID: contentCID, GranteeAddress: granteeAddress})
+	if err != nil {
+		return nil, fmt.Errorf("sharing: failed to serialize revocation: %w", err)
+	}
+	tx, err := ledger.NewTransaction(ledger.TxTypeShareRevoked, author.Address, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Sign(author); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// ContentKey recovers the content key for contentCID on behalf of wallet by
+// consulting the grant index. Callers use this when the content's own
+// key material does not include the wallet.
+func (m *ShareManager) ContentKey(wallet *identity.Wallet, contentCID string) ([]byte, error) {
+	grant, err := m.index.Lookup(contentCID, wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	return grant.UnwrapKey(wallet)
+}
@@ -0,0 +1,128 @@
+package sharing_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/core/sharing"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func newShareManager(t *testing.T) *sharing.ShareManager {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(128)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-sharing", "127.0.0.1:9090"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	return sharing.NewShareManager(dds)
+}
+
+func newWallet(t *testing.T) *identity.Wallet {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	return wallet
+}
+
+func randomKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("rand.Read: %v", err)
+	}
+	return key
+}
+
+func TestGrant_GranteeRecoversContentKey(t *testing.T) {
+	m := newShareManager(t)
+	author := newWallet(t)
+	grantee := newWallet(t)
+	contentKey := randomKey(t)
+
+	cid, tx, err := m.Grant(author, "cid-secret-post", grantee.Address, grantee.PublicKey, contentKey)
+	if err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+	if cid == "" {
+		t.Fatal("Grant returned an empty CID")
+	}
+	if tx.Type != ledger.TxTypeShareGranted {
+		t.Errorf("tx type = %s, want %s", tx.Type, ledger.TxTypeShareGranted)
+	}
+	if !tx.VerifySignature() {
+		t.Error("grant transaction signature does not verify")
+	}
+
+	got, err := m.ContentKey(grantee, "cid-secret-post")
+	if err != nil {
+		t.Fatalf("ContentKey: %v", err)
+	}
+	if !bytes.Equal(got, contentKey) {
+		t.Error("recovered content key does not match the original")
+	}
+
+	// A wallet without a grant gets nothing.
+	stranger := newWallet(t)
+	if _, err := m.ContentKey(stranger, "cid-secret-post"); !errors.Is(err, sharing.ErrGrantNotFound) {
+		t.Errorf("stranger lookup = %v, want ErrGrantNotFound", err)
+	}
+}
+
+func TestRevoke_IndexRefusesRevokedGrant(t *testing.T) {
+	m := newShareManager(t)
+	author := newWallet(t)
+	grantee := newWallet(t)
+
+	if _, _, err := m.Grant(author, "cid-revocable", grantee.Address, grantee.PublicKey, randomKey(t)); err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+	tx, err := m.Revoke(author, "cid-revocable", grantee.Address)
+	if err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if tx.Type != ledger.TxTypeShareRevoked {
+		t.Errorf("tx type = %s, want %s", tx.Type, ledger.TxTypeShareRevoked)
+	}
+	if _, err := m.ContentKey(grantee, "cid-revocable"); !errors.Is(err, sharing.ErrGrantRevoked) {
+		t.Errorf("lookup after revoke = %v, want ErrGrantRevoked", err)
+	}
+
+	// Only the author may revoke.
+	if _, _, err := m.Grant(author, "cid-other", grantee.Address, grantee.PublicKey, randomKey(t)); err != nil {
+		t.Fatalf("Grant: %v", err)
+	}
+	if _, err := m.Revoke(grantee, "cid-other", grantee.Address); err == nil {
+		t.Error("non-author revocation was accepted")
+	}
+}
+
+func TestGrantIndex_RejectsForgedSignature(t *testing.T) {
+	author := newWallet(t)
+	grantee := newWallet(t)
+	grant, err := sharing.NewShareGrant(author, "cid-forged", grantee.Address, grantee.PublicKey, randomKey(t))
+	if err != nil {
+		t.Fatalf("NewShareGrant: %v", err)
+	}
+	// Tamper with the grantee after signing.
+	grant.GranteeAddress = newWallet(t).Address
+
+	idx := sharing.NewGrantIndex()
+	if err := idx.Add(grant); !errors.Is(err, sharing.ErrGrantInvalid) {
+		t.Errorf("Add(tampered grant) = %v, want ErrGrantInvalid", err)
+	}
+}
@@ -0,0 +1,187 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// PostBackup is one post inside an account bundle: its chain CID, and the
+// body when the exporting node could still retrieve it.
+type PostBackup struct {
+	ContentCID  string `json:"contentCid"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// AccountBundle is a user's whole social identity in one signed, portable
+// document: the latest profile, the CID history it evolved through, posts
+// with bodies, and the follow list. The signature covers every field, so a
+// bundle restored onto a new node is tamper-evident.
+type AccountBundle struct {
+	OwnerAddress   string `json:"ownerAddress"`
+	OwnerPublicKey []byte `json:"ownerPublicKey"`
+	ExportedAt     int64  `json:"exportedAt"`
+	// Profile is the latest profile document byte-for-byte as published —
+	// kept as raw bytes (base64 in the bundle) so republishing lands it
+	// under its original content-addressed CID.
+	Profile []byte `json:"profile,omitempty"`
+	// ProfileHistory lists profile version CIDs oldest to newest; the
+	// last entry is the CID the Profile field was retrieved from.
+	ProfileHistory []string     `json:"profileHistory,omitempty"`
+	Posts          []PostBackup `json:"posts,omitempty"`
+	Follows        []string     `json:"follows,omitempty"`
+	Signature      []byte       `json:"signature,omitempty"`
+}
+
+// signingBytes returns the canonical bytes the bundle signature covers:
+// the bundle's JSON with the signature field blanked.
+func (b *AccountBundle) signingBytes() ([]byte, error) {
+	unsigned := *b
+	unsigned.Signature = nil
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to serialize account bundle: %w", err)
+	}
+	return data, nil
+}
+
+// ExportAccount walks the chain for the wallet's activity, retrieves the
+// referenced content from the DDS, and writes a signed JSON bundle to w.
+// Content the DDS can no longer retrieve is exported as its CID alone.
+func ExportAccount(w io.Writer, wallet *identity.Wallet, bc *ledger.Blockchain, dds *service.DDSCoreService) error {
+	if wallet == nil || bc == nil || dds == nil {
+		return fmt.Errorf("user: account export requires a wallet, a chain, and a DDS")
+	}
+	bundle := &AccountBundle{
+		OwnerAddress:   wallet.Address,
+		OwnerPublicKey: wallet.PublicKey,
+		ExportedAt:     clock.Now(),
+	}
+	follows := make(map[string]bool)
+	for _, block := range bc.Blocks() {
+		for i := range block.Transactions {
+			tx := &block.Transactions[i]
+			if !identity.AddressesEqual(tx.SenderAddress, wallet.Address) {
+				continue
+			}
+			switch tx.Type {
+			case ledger.TxTypeProfileUpdated:
+				bundle.ProfileHistory = append(bundle.ProfileHistory, string(tx.Payload))
+			case ledger.TxTypePostCreated:
+				bundle.Posts = append(bundle.Posts, exportPost(dds, string(tx.Payload)))
+			case ledger.TxTypeFollowed:
+				follows[string(tx.Payload)] = true
+			case ledger.TxTypeUnfollowed:
+				delete(follows, string(tx.Payload))
+			}
+		}
+	}
+	for addr := range follows {
+		bundle.Follows = append(bundle.Follows, addr)
+	}
+	sort.Strings(bundle.Follows)
+	if n := len(bundle.ProfileHistory); n > 0 {
+		if data, err := dds.Retrieve(bundle.ProfileHistory[n-1]); err == nil {
+			bundle.Profile = data
+		}
+	}
+
+	data, err := bundle.signingBytes()
+	if err != nil {
+		return err
+	}
+	sig, err := wallet.Sign(data)
+	if err != nil {
+		return fmt.Errorf("user: failed to sign account bundle: %w", err)
+	}
+	bundle.Signature = sig
+	out, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("user: failed to serialize account bundle: %w", err)
+	}
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("user: failed to write account bundle: %w", err)
+	}
+	return nil
+}
+
+// exportPost retrieves one post's body and declared content type; a post
+// the DDS no longer holds is backed up as its CID alone.
+func exportPost(dds *service.DDSCoreService, cid string) PostBackup {
+	post := PostBackup{ContentCID: cid}
+	if manifest, err := dds.FetchManifest(cid); err == nil {
+		post.ContentType = manifest.ContentType
+	}
+	if body, err := dds.Retrieve(cid); err == nil {
+		post.Body = body
+	}
+	return post
+}
+
+// ImportAccount reads a bundle written by ExportAccount. With verify set
+// it checks that the embedded public key belongs to the claimed address
+// and that the signature covers the bundle, so tampering anywhere — a
+// swapped post body, an added follow — is rejected.
+func ImportAccount(r io.Reader, verify bool) (*AccountBundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to read account bundle: %w", err)
+	}
+	bundle := &AccountBundle{}
+	if err := json.Unmarshal(data, bundle); err != nil {
+		return nil, fmt.Errorf("user: failed to parse account bundle: %w", err)
+	}
+	if !verify {
+		return bundle, nil
+	}
+	if len(bundle.OwnerPublicKey) == 0 || len(bundle.Signature) == 0 {
+		return nil, fmt.Errorf("user: account bundle for %s is unsigned", bundle.OwnerAddress)
+	}
+	if !identity.AddressesEqual(identity.PublicKeyToAddress(bundle.OwnerPublicKey), bundle.OwnerAddress) {
+		return nil, fmt.Errorf("user: account bundle public key does not belong to %s", bundle.OwnerAddress)
+	}
+	signed, err := bundle.signingBytes()
+	if err != nil {
+		return nil, err
+	}
+	if !identity.VerifySignature(bundle.OwnerPublicKey, signed, bundle.Signature) {
+		return nil, fmt.Errorf("user: account bundle signature for %s is invalid", bundle.OwnerAddress)
+	}
+	return bundle, nil
+}
+
+// Republish pushes the bundle's retrievable content into a DDS — the
+// restore half of account portability. Content-addressed publishing means
+// each piece lands under its original CID; entries exported without a body
+// are skipped and reported so the caller can fetch them elsewhere.
+func (b *AccountBundle) Republish(dds *service.DDSCoreService) (published, skipped []string, err error) {
+	if dds == nil {
+		return nil, nil, fmt.Errorf("user: republish requires a DDS")
+	}
+	if len(b.Profile) > 0 {
+		cid, err := dds.Publish(b.Profile, ProfileContentType)
+		if err != nil {
+			return published, skipped, fmt.Errorf("user: failed to republish profile: %w", err)
+		}
+		published = append(published, cid)
+	}
+	for _, post := range b.Posts {
+		if len(post.Body) == 0 {
+			skipped = append(skipped, post.ContentCID)
+			continue
+		}
+		cid, err := dds.Publish(post.Body, post.ContentType)
+		if err != nil {
+			return published, skipped, fmt.Errorf("user: failed to republish post %s: %w", post.ContentCID, err)
+		}
+		published = append(published, cid)
+	}
+	return published, skipped, nil
+}
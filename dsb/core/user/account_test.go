@@ -0,0 +1,178 @@
+package user_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+type accountFixture struct {
+	wallet  *identity.Wallet
+	chain   *ledger.Blockchain
+	manager *user.ProfileManager
+	dds     *service.DDSCoreService
+	postCID string
+}
+
+func newDDS(t *testing.T, name string) *service.DDSCoreService {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	return service.NewDDSCoreService(
+		p2p.NewNode("node-"+name, "127.0.0.1:9060"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+}
+
+// newAccountFixture builds a small account: one profile version, one post,
+// and two follows (one later unfollowed), all recorded on the chain.
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	f := &accountFixture{wallet: wallet, chain: ledger.NewBlockchain(), dds: newDDS(t, "account-test")}
+	f.manager = user.NewProfileManager(f.dds)
+
+	profile, err := user.NewProfile(wallet.Address, "Ada", "backs up her account")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	profileCID, err := f.manager.PublishProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	f.postCID, err = f.dds.Publish([]byte("my first post"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	f.addTx(t, ledger.TxTypeProfileUpdated, profileCID)
+	f.addTx(t, ledger.TxTypePostCreated, f.postCID)
+	f.addTx(t, ledger.TxTypeFollowed, "address-kept")
+	f.addTx(t, ledger.TxTypeFollowed, "address-dropped")
+	f.addTx(t, ledger.TxTypeUnfollowed, "address-dropped")
+	return f
+}
+
+func (f *accountFixture) addTx(t *testing.T, txType ledger.TransactionType, payload string) {
+	t.Helper()
+	tx, err := ledger.NewSignedTransaction(f.wallet, txType, []byte(payload))
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+}
+
+func (f *accountFixture) export(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := user.ExportAccount(&buf, f.wallet, f.chain, f.dds); err != nil {
+		t.Fatalf("ExportAccount: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExportAccount_BundleContents(t *testing.T) {
+	f := newAccountFixture(t)
+	bundle, err := user.ImportAccount(bytes.NewReader(f.export(t)), true)
+	if err != nil {
+		t.Fatalf("ImportAccount: %v", err)
+	}
+	if bundle.OwnerAddress != f.wallet.Address {
+		t.Errorf("owner %s, want %s", bundle.OwnerAddress, f.wallet.Address)
+	}
+	if len(bundle.ProfileHistory) != 1 {
+		t.Errorf("profile history %v, want one CID", bundle.ProfileHistory)
+	}
+	profile, err := user.FromJSON(bundle.Profile)
+	if err != nil {
+		t.Fatalf("bundle profile does not parse: %v", err)
+	}
+	if profile.DisplayName != "Ada" || !profile.VerifySignature() {
+		t.Errorf("bundle profile %q lost its signature", profile.DisplayName)
+	}
+	if len(bundle.Posts) != 1 || bundle.Posts[0].ContentCID != f.postCID {
+		t.Fatalf("posts %+v, want the one published post", bundle.Posts)
+	}
+	if string(bundle.Posts[0].Body) != "my first post" || bundle.Posts[0].ContentType != "text/plain" {
+		t.Errorf("post backup %+v lost its body or type", bundle.Posts[0])
+	}
+	if len(bundle.Follows) != 1 || bundle.Follows[0] != "address-kept" {
+		t.Errorf("follows %v, want the unfollow applied", bundle.Follows)
+	}
+}
+
+func TestImportAccount_TamperDetection(t *testing.T) {
+	f := newAccountFixture(t)
+	exported := f.export(t)
+
+	tampered := bytes.Replace(exported, []byte("address-kept"), []byte("address-evil"), 1)
+	if _, err := user.ImportAccount(bytes.NewReader(tampered), true); err == nil {
+		t.Error("tampered bundle passed verification")
+	}
+	// Without verification the same bytes parse — callers opting out own
+	// the risk.
+	if _, err := user.ImportAccount(bytes.NewReader(tampered), false); err != nil {
+		t.Errorf("unverified import failed: %v", err)
+	}
+
+	// A bundle re-signed by a different key must not verify against the
+	// claimed address.
+	other, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	swapped := bytes.Replace(exported, []byte(f.wallet.Address), []byte(other.Address), 1)
+	if _, err := user.ImportAccount(bytes.NewReader(swapped), true); err == nil {
+		t.Error("bundle with a swapped owner address passed verification")
+	}
+}
+
+func TestAccountBundle_RepublishRoundTrip(t *testing.T) {
+	f := newAccountFixture(t)
+	bundle, err := user.ImportAccount(bytes.NewReader(f.export(t)), true)
+	if err != nil {
+		t.Fatalf("ImportAccount: %v", err)
+	}
+
+	fresh := newDDS(t, "account-restore")
+	published, skipped, err := bundle.Republish(fresh)
+	if err != nil {
+		t.Fatalf("Republish: %v", err)
+	}
+	if len(skipped) != 0 {
+		t.Errorf("republish skipped %v", skipped)
+	}
+	if len(published) != 2 {
+		t.Fatalf("republished %d pieces, want profile and post", len(published))
+	}
+	// Content addressing puts the post back under its original CID.
+	got, err := fresh.Retrieve(f.postCID)
+	if err != nil {
+		t.Fatalf("Retrieve on fresh node: %v", err)
+	}
+	if string(got) != "my first post" {
+		t.Errorf("restored post body %q", got)
+	}
+	restored, err := user.NewProfileManager(fresh).RetrieveProfile(bundle.ProfileHistory[0])
+	if err != nil {
+		t.Fatalf("RetrieveProfile on fresh node: %v", err)
+	}
+	if restored.DisplayName != "Ada" {
+		t.Errorf("restored profile %q", restored.DisplayName)
+	}
+}
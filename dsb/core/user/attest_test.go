@@ -0,0 +1,61 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/attest"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func TestRetrieveProfile_AttachesTrustedAttestations(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	attester, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	index := attest.NewAttestationIndex(attester.Address)
+	a, err := attest.NewAttestation(attester, wallet.Address, attest.ClaimVerifiedHuman, 0)
+	if err != nil {
+		t.Fatalf("NewAttestation: %v", err)
+	}
+	index.Apply(a)
+	manager.SetAttestationSource(index)
+
+	cid, err := manager.PublishProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	got, err := manager.RetrieveProfile(cid)
+	if err != nil {
+		t.Fatalf("RetrieveProfile: %v", err)
+	}
+	if len(got.Attestations) != 1 || got.Attestations[0].Claim != attest.ClaimVerifiedHuman {
+		t.Errorf("attestations = %v, want one verified-human badge", got.Attestations)
+	}
+
+	// Without a source the profile carries no badges.
+	var plain *user.ProfileManager = newTestManager(t)
+	cid2, err := plain.PublishProfile(mustProfile(t, wallet.Address), wallet)
+	if err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	got2, err := plain.RetrieveProfile(cid2)
+	if err != nil {
+		t.Fatalf("RetrieveProfile: %v", err)
+	}
+	if len(got2.Attestations) != 0 {
+		t.Errorf("unenriched profile carries attestations: %v", got2.Attestations)
+	}
+}
+
+func mustProfile(t *testing.T, owner string) *user.Profile {
+	t.Helper()
+	profile, err := user.NewProfile(owner, "Ada", "second profile")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	return profile
+}
@@ -0,0 +1,144 @@
+package user
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// BlockList is the local user's record of addresses they do not want to
+// hear from. Blocked addresses are hidden entirely; muted addresses still
+// appear where context demands (e.g. feed continuity) but are flagged so
+// clients can de-emphasize them. The list is local policy — nothing is
+// published to the chain — and optionally persists itself as JSON so it
+// survives restarts.
+type BlockList struct {
+	mu      sync.RWMutex
+	blocked map[string]bool
+	muted   map[string]bool
+	// path, when non-empty, is rewritten after every mutation.
+	path string
+}
+
+// blockListJSON is the persisted form: sorted address lists, so the file
+// diffs cleanly under version control or sync tools.
+type blockListJSON struct {
+	Blocked []string `json:"blocked,omitempty"`
+	Muted   []string `json:"muted,omitempty"`
+}
+
+// NewBlockList returns an empty, in-memory block list.
+func NewBlockList() *BlockList {
+	return &BlockList{
+		blocked: make(map[string]bool),
+		muted:   make(map[string]bool),
+	}
+}
+
+// LoadBlockList returns a block list persisted at path, loading the
+// existing file when present and starting empty otherwise. Every later
+// mutation rewrites the file.
+func LoadBlockList(path string) (*BlockList, error) {
+	list := NewBlockList()
+	list.path = path
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return list, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to read block list %s: %w", path, err)
+	}
+	var persisted blockListJSON
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return nil, fmt.Errorf("user: failed to parse block list %s: %w", path, err)
+	}
+	for _, addr := range persisted.Blocked {
+		list.blocked[addr] = true
+	}
+	for _, addr := range persisted.Muted {
+		list.muted[addr] = true
+	}
+	return list, nil
+}
+
+// Block hides the address entirely. Blocking an already-blocked address is
+// a no-op.
+func (l *BlockList) Block(addr string) error {
+	return l.set(l.blocked, addr, true)
+}
+
+// Unblock restores the address.
+func (l *BlockList) Unblock(addr string) error {
+	return l.set(l.blocked, addr, false)
+}
+
+// Mute keeps the address's content but flags it. Muting is independent of
+// blocking.
+func (l *BlockList) Mute(addr string) error {
+	return l.set(l.muted, addr, true)
+}
+
+// Unmute removes the mute flag.
+func (l *BlockList) Unmute(addr string) error {
+	return l.set(l.muted, addr, false)
+}
+
+// IsBlocked reports whether the address is blocked.
+func (l *BlockList) IsBlocked(addr string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.blocked[addr]
+}
+
+// IsMuted reports whether the address is muted.
+func (l *BlockList) IsMuted(addr string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.muted[addr]
+}
+
+// set applies one mutation and persists the new state when a path is
+// configured.
+func (l *BlockList) set(target map[string]bool, addr string, on bool) error {
+	if addr == "" {
+		return fmt.Errorf("user: block list requires an address")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if on {
+		target[addr] = true
+	} else {
+		delete(target, addr)
+	}
+	return l.persistLocked()
+}
+
+// persistLocked rewrites the backing file; callers must hold l.mu.
+func (l *BlockList) persistLocked() error {
+	if l.path == "" {
+		return nil
+	}
+	persisted := blockListJSON{
+		Blocked: sortedKeys(l.blocked),
+		Muted:   sortedKeys(l.muted),
+	}
+	data, err := json.MarshalIndent(&persisted, "", "  ")
+	if err != nil {
+		return fmt.Errorf("user: failed to serialize block list: %w", err)
+	}
+	if err := os.WriteFile(l.path, data, 0o600); err != nil {
+		return fmt.Errorf("user: failed to write block list %s: %w", l.path, err)
+	}
+	return nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
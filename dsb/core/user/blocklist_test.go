@@ -0,0 +1,67 @@
+package user_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+func TestBlockList_BlockAndMuteAreIndependent(t *testing.T) {
+	list := user.NewBlockList()
+	if err := list.Block("addr-blocked"); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	if err := list.Mute("addr-muted"); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+	if !list.IsBlocked("addr-blocked") || list.IsMuted("addr-blocked") {
+		t.Error("blocked address has wrong flags")
+	}
+	if !list.IsMuted("addr-muted") || list.IsBlocked("addr-muted") {
+		t.Error("muted address has wrong flags")
+	}
+	if err := list.Unblock("addr-blocked"); err != nil {
+		t.Fatalf("Unblock: %v", err)
+	}
+	if err := list.Unmute("addr-muted"); err != nil {
+		t.Fatalf("Unmute: %v", err)
+	}
+	if list.IsBlocked("addr-blocked") || list.IsMuted("addr-muted") {
+		t.Error("flags survived removal")
+	}
+	if err := list.Block(""); err == nil {
+		t.Error("empty address accepted")
+	}
+}
+
+func TestBlockList_PersistenceRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.json")
+	list, err := user.LoadBlockList(path)
+	if err != nil {
+		t.Fatalf("LoadBlockList on missing file: %v", err)
+	}
+	if err := list.Block("addr-1"); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	if err := list.Mute("addr-2"); err != nil {
+		t.Fatalf("Mute: %v", err)
+	}
+	if err := list.Block("addr-3"); err != nil {
+		t.Fatalf("Block: %v", err)
+	}
+	if err := list.Unblock("addr-3"); err != nil {
+		t.Fatalf("Unblock: %v", err)
+	}
+
+	reloaded, err := user.LoadBlockList(path)
+	if err != nil {
+		t.Fatalf("LoadBlockList: %v", err)
+	}
+	if !reloaded.IsBlocked("addr-1") || !reloaded.IsMuted("addr-2") {
+		t.Error("persisted entries lost across reload")
+	}
+	if reloaded.IsBlocked("addr-3") {
+		t.Error("unblocked address persisted as blocked")
+	}
+}
@@ -0,0 +1,125 @@
+package user_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// gaugedStorage tracks the highest number of concurrent GetManifest calls,
+// slowing each one slightly so overlap is observable.
+type gaugedStorage struct {
+	storage.Storage
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (g *gaugedStorage) GetManifest(id string) (*chunking.Manifest, error) {
+	g.mu.Lock()
+	g.current++
+	if g.current > g.max {
+		g.max = g.current
+	}
+	g.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	defer func() {
+		g.mu.Lock()
+		g.current--
+		g.mu.Unlock()
+	}()
+	return g.Storage.GetManifest(id)
+}
+
+func (g *gaugedStorage) maxConcurrent() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.max
+}
+
+func newBulkFixture(t *testing.T) (*user.ProfileManager, *gaugedStorage) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	store := &gaugedStorage{Storage: storage.NewInMemoryStorage()}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-bulk", "127.0.0.1:9120"),
+		store, chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	return user.NewProfileManager(dds), store
+}
+
+func publishTestProfile(t *testing.T, manager *user.ProfileManager, name string) string {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	profile, err := user.NewProfile(wallet.Address, name, "bio for "+name)
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	cid, err := manager.PublishProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	return cid
+}
+
+func TestRetrieveProfiles_MixedBatch(t *testing.T) {
+	manager, _ := newBulkFixture(t)
+	var cids []string
+	for i := 0; i < 3; i++ {
+		cids = append(cids, publishTestProfile(t, manager, fmt.Sprintf("Author %d", i)))
+	}
+	cids = append(cids, "cid-does-not-exist")
+
+	profiles, failures := manager.RetrieveProfiles(cids)
+	if len(profiles) != 3 {
+		t.Errorf("got %d profiles, want 3", len(profiles))
+	}
+	for _, cid := range cids[:3] {
+		if profiles[cid] == nil {
+			t.Errorf("profile %s missing from results", cid)
+		}
+	}
+	if len(failures) != 1 {
+		t.Fatalf("got %d failures, want 1: %v", len(failures), failures)
+	}
+	if failures["cid-does-not-exist"] == nil {
+		t.Error("missing profile did not report an error")
+	}
+}
+
+func TestRetrieveProfiles_BoundedParallelism(t *testing.T) {
+	manager, store := newBulkFixture(t)
+	var cids []string
+	for i := 0; i < 12; i++ {
+		cids = append(cids, publishTestProfile(t, manager, fmt.Sprintf("Crowd %d", i)))
+	}
+
+	const limit = 2
+	manager.SetBulkParallelism(limit)
+	profiles, failures := manager.RetrieveProfiles(cids)
+	if len(failures) != 0 {
+		t.Fatalf("unexpected failures: %v", failures)
+	}
+	if len(profiles) != len(cids) {
+		t.Fatalf("got %d profiles, want %d", len(profiles), len(cids))
+	}
+	if got := store.maxConcurrent(); got > limit {
+		t.Errorf("observed %d concurrent fetches, limit is %d", got, limit)
+	}
+}
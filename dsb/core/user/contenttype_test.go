@@ -0,0 +1,84 @@
+package user_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+// newManagerWithDDS is like newTestManager but also hands back the DDS
+// service, so tests can publish non-profile content alongside.
+func newManagerWithDDS(t *testing.T) (*user.ProfileManager, *service.DDSCoreService) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-contenttype-test", "127.0.0.1:9021"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	return user.NewProfileManager(dds), dds
+}
+
+func TestRetrieveProfile_WrongContentTypeIsTyped(t *testing.T) {
+	manager, dds := newManagerWithDDS(t)
+	cid, err := dds.Publish([]byte("an ordinary text post"), "text/plain")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	_, err = manager.RetrieveProfile(cid)
+	var wrongType *user.ErrWrongContentType
+	if !errors.As(err, &wrongType) {
+		t.Fatalf("error %v is not *ErrWrongContentType", err)
+	}
+	if wrongType.CID != cid || wrongType.Got != "text/plain" || wrongType.Expected != user.ProfileContentType {
+		t.Errorf("typed error carries %+v", wrongType)
+	}
+}
+
+func TestRetrieveProfile_LegacyManifestWithoutTypeSniffs(t *testing.T) {
+	manager, dds := newManagerWithDDS(t)
+	wallet, profile := newTestWalletProfile(t)
+	if err := profile.Sign(wallet); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	data, err := profile.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	// Published without content-type metadata, as older nodes did.
+	cid, err := dds.Publish(data, "")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	got, err := manager.RetrieveProfile(cid)
+	if err != nil {
+		t.Fatalf("RetrieveProfile on legacy manifest: %v", err)
+	}
+	if got.OwnerAddress != wallet.Address {
+		t.Errorf("retrieved owner %s, want %s", got.OwnerAddress, wallet.Address)
+	}
+
+	// Metadata-less content that does not sniff as a profile still gets
+	// the typed error, not an unmarshal failure.
+	postCID, err := dds.Publish([]byte("just text, no type metadata"), "")
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	_, err = manager.RetrieveProfile(postCID)
+	var wrongType *user.ErrWrongContentType
+	if !errors.As(err, &wrongType) {
+		t.Fatalf("error %v is not *ErrWrongContentType", err)
+	}
+	if wrongType.Got != "unknown" {
+		t.Errorf("Got = %q, want unknown for a metadata-less manifest", wrongType.Got)
+	}
+}
@@ -0,0 +1,50 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidProfileJSON wraps the underlying JSON decoding error when
+// profile bytes cannot be parsed at all; use errors.As to recover e.g. the
+// *json.SyntaxError beneath it.
+var ErrInvalidProfileJSON = errors.New("user: invalid profile JSON")
+
+// Semantic validation failures on an otherwise well-formed profile
+// document. These are deliberately distinct from ErrInvalidProfileJSON so
+// callers can tell malformed bytes from bad field values.
+var (
+	ErrProfileMissingOwner       = errors.New("user: profile is missing an owner address")
+	ErrProfileMissingDisplayName = errors.New("user: profile requires a display name")
+	ErrProfileFieldTooLong       = errors.New("user: profile field exceeds its length limit")
+	ErrProfileInvalidVersion     = errors.New("user: profile version must be at least 1")
+)
+
+// ErrProfileTombstoned is returned when an operation encounters a profile
+// deleted by its owner. It carries only the owner address so callers can
+// render a "deleted account" placeholder without leaking profile content.
+type ErrProfileTombstoned struct {
+	OwnerAddress string
+}
+
+// Error implements error.
+func (e *ErrProfileTombstoned) Error() string {
+	return fmt.Sprintf("user: profile for %s has been tombstoned", e.OwnerAddress)
+}
+
+// ErrWrongContentType is returned when a CID resolves to content of a
+// different type than the caller expected — e.g. a post CID passed to
+// RetrieveProfile — before any deserialization is attempted, so the caller
+// sees the actual mismatch rather than a confusing unmarshal failure.
+type ErrWrongContentType struct {
+	CID      string
+	Expected string
+	// Got is the manifest's declared content type, or "unknown" when the
+	// manifest carries no metadata and the content failed the sniff.
+	Got string
+}
+
+// Error implements error.
+func (e *ErrWrongContentType) Error() string {
+	return fmt.Sprintf("user: content %s has type %s, expected %s", e.CID, e.Got, e.Expected)
+}
@@ -0,0 +1,103 @@
+package user
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidLanguageTag reports a localized-field key that does not parse
+// as a basic BCP-47 language tag.
+var ErrInvalidLanguageTag = fmt.Errorf("user: invalid language tag")
+
+// LocalizedFields carries the translatable profile fields for one
+// language. Both fields are optional; an empty field falls back to the
+// profile's default.
+type LocalizedFields struct {
+	DisplayName string `json:"displayName,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+}
+
+// ValidLanguageTag reports whether tag satisfies the basic BCP-47 grammar
+// this package accepts: a 2-8 letter primary subtag followed by any number
+// of 1-8 character alphanumeric subtags, separated by single hyphens
+// (e.g. "en", "pt-BR", "zh-Hant-TW"). Full registry validation is out of
+// scope; this catches structural garbage before it is signed into a
+// profile.
+func ValidLanguageTag(tag string) bool {
+	subtags := strings.Split(tag, "-")
+	for i, sub := range subtags {
+		if len(sub) < 1 || len(sub) > 8 {
+			return false
+		}
+		for _, r := range sub {
+			alpha := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+			digit := r >= '0' && r <= '9'
+			if i == 0 && !alpha {
+				return false
+			}
+			if !alpha && !digit {
+				return false
+			}
+		}
+	}
+	return len(subtags[0]) >= 2
+}
+
+// SetLocalized records (or, when both fields are empty, removes) the
+// translation for the given language tag, bumping the profile version like
+// any other update. Field limits match the default fields.
+//
+// The Localized map serializes deterministically — encoding/json sorts map
+// keys — so signatures over profiles with localizations are stable no
+// matter the insertion order.
+func (p *Profile) SetLocalized(lang string, fields LocalizedFields) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Tombstoned {
+		return &ErrProfileTombstoned{OwnerAddress: p.OwnerAddress}
+	}
+	if !ValidLanguageTag(lang) {
+		return fmt.Errorf("%w: %q", ErrInvalidLanguageTag, lang)
+	}
+	if len(fields.DisplayName) > MaxDisplayNameLength {
+		return fmt.Errorf("user: localized display name for %s exceeds %d characters", lang, MaxDisplayNameLength)
+	}
+	if len(fields.Bio) > MaxBioLength {
+		return fmt.Errorf("user: localized bio for %s exceeds %d characters", lang, MaxBioLength)
+	}
+	if fields == (LocalizedFields{}) {
+		delete(p.Localized, lang)
+	} else {
+		if p.Localized == nil {
+			p.Localized = make(map[string]LocalizedFields)
+		}
+		p.Localized[lang] = fields
+	}
+	p.bumpVersion()
+	return nil
+}
+
+// Localize returns the display name and bio for the given language,
+// falling back per field: the exact tag first, then the primary subtag
+// ("pt-BR" falls back to "pt"), then the profile's default fields.
+func (p *Profile) Localize(lang string) (display, bio string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	display, bio = p.DisplayName, p.Bio
+	candidates := []string{lang}
+	if primary, _, ok := strings.Cut(lang, "-"); ok {
+		candidates = []string{primary, lang}
+	}
+	// Apply the weakest match first so more specific tags override it.
+	for _, tag := range candidates {
+		if fields, ok := p.Localized[tag]; ok {
+			if fields.DisplayName != "" {
+				display = fields.DisplayName
+			}
+			if fields.Bio != "" {
+				bio = fields.Bio
+			}
+		}
+	}
+	return display, bio
+}
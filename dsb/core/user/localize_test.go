@@ -0,0 +1,142 @@
+package user_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+func TestValidLanguageTag(t *testing.T) {
+	valid := []string{"en", "pt-BR", "zh-Hant-TW", "de-DE-1996", "fra"}
+	for _, tag := range valid {
+		if !user.ValidLanguageTag(tag) {
+			t.Errorf("ValidLanguageTag(%q) = false, want true", tag)
+		}
+	}
+	invalid := []string{"", "e", "en-", "-en", "en--US", "1n", "en_US", "verylongsubtag", "en US"}
+	for _, tag := range invalid {
+		if user.ValidLanguageTag(tag) {
+			t.Errorf("ValidLanguageTag(%q) = true, want false", tag)
+		}
+	}
+}
+
+func TestSetLocalized_RejectsInvalidTagAndOversizedFields(t *testing.T) {
+	profile, err := user.NewProfile("addr-l10n", "Default Name", "default bio")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	err = profile.SetLocalized("en_US", user.LocalizedFields{DisplayName: "Name"})
+	if !errors.Is(err, user.ErrInvalidLanguageTag) {
+		t.Errorf("SetLocalized with bad tag = %v, want ErrInvalidLanguageTag", err)
+	}
+	err = profile.SetLocalized("en", user.LocalizedFields{DisplayName: strings.Repeat("x", user.MaxDisplayNameLength+1)})
+	if err == nil {
+		t.Error("oversized localized display name was accepted")
+	}
+	err = profile.SetLocalized("en", user.LocalizedFields{Bio: strings.Repeat("x", user.MaxBioLength+1)})
+	if err == nil {
+		t.Error("oversized localized bio was accepted")
+	}
+}
+
+func TestLocalize_FallbackChain(t *testing.T) {
+	profile, err := user.NewProfile("addr-l10n", "Default Name", "default bio")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	if err := profile.SetLocalized("pt", user.LocalizedFields{DisplayName: "Nome", Bio: "biografia"}); err != nil {
+		t.Fatalf("SetLocalized(pt): %v", err)
+	}
+	if err := profile.SetLocalized("pt-BR", user.LocalizedFields{DisplayName: "Nome BR"}); err != nil {
+		t.Fatalf("SetLocalized(pt-BR): %v", err)
+	}
+
+	// Exact match wins per field; the missing bio falls back to "pt".
+	if display, bio := profile.Localize("pt-BR"); display != "Nome BR" || bio != "biografia" {
+		t.Errorf("Localize(pt-BR) = (%q, %q), want (Nome BR, biografia)", display, bio)
+	}
+	// Primary-subtag match.
+	if display, _ := profile.Localize("pt-PT"); display != "Nome" {
+		t.Errorf("Localize(pt-PT) display = %q, want Nome", display)
+	}
+	// No match at all falls back to the default fields.
+	if display, bio := profile.Localize("ja"); display != "Default Name" || bio != "default bio" {
+		t.Errorf("Localize(ja) = (%q, %q), want defaults", display, bio)
+	}
+}
+
+func TestSetLocalized_EmptyFieldsRemoveAndVersionBumps(t *testing.T) {
+	profile, err := user.NewProfile("addr-l10n", "Default Name", "")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	before := profile.Version
+	if err := profile.SetLocalized("es", user.LocalizedFields{DisplayName: "Nombre"}); err != nil {
+		t.Fatalf("SetLocalized: %v", err)
+	}
+	if profile.Version != before+1 {
+		t.Errorf("version after SetLocalized = %d, want %d", profile.Version, before+1)
+	}
+	if err := profile.SetLocalized("es", user.LocalizedFields{}); err != nil {
+		t.Fatalf("SetLocalized(clear): %v", err)
+	}
+	if _, ok := profile.Localized["es"]; ok {
+		t.Error("empty LocalizedFields did not remove the entry")
+	}
+}
+
+func TestLocalizedJSON_DeterministicAcrossInsertionOrders(t *testing.T) {
+	build := func(tags []string) *user.Profile {
+		profile, err := user.NewProfile("addr-l10n", "Default Name", "default bio")
+		if err != nil {
+			t.Fatalf("NewProfile: %v", err)
+		}
+		for _, tag := range tags {
+			if err := profile.SetLocalized(tag, user.LocalizedFields{DisplayName: "name-" + tag}); err != nil {
+				t.Fatalf("SetLocalized(%s): %v", tag, err)
+			}
+		}
+		// Pin the fields bumpVersion varies, so only map ordering could
+		// make the encodings differ.
+		profile.Timestamp = 42
+		return profile
+	}
+	forward := build([]string{"de", "en", "fr"})
+	backward := build([]string{"fr", "en", "de"})
+
+	a, err := forward.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	b, err := backward.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	if !bytes.Equal(a, b) {
+		t.Errorf("localized profiles serialize differently across insertion orders:\n%s\n%s", a, b)
+	}
+}
+
+func TestUpdateAndPublishLocalizedProfile_RoundTrip(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+
+	cid, err := manager.UpdateAndPublishLocalizedProfile(profile, wallet, "fr", user.LocalizedFields{Bio: "biographie"})
+	if err != nil {
+		t.Fatalf("UpdateAndPublishLocalizedProfile: %v", err)
+	}
+	got, err := manager.RetrieveProfile(cid)
+	if err != nil {
+		t.Fatalf("RetrieveProfile: %v", err)
+	}
+	if _, bio := got.Localize("fr"); bio != "biographie" {
+		t.Errorf("retrieved profile Localize(fr) bio = %q, want biographie", bio)
+	}
+}
@@ -0,0 +1,303 @@
+package user
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/attest"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// ProfileContentType is the content type profiles are published under.
+const ProfileContentType = "application/json"
+
+// DefaultBulkParallelism is how many profiles RetrieveProfiles fetches
+// concurrently unless overridden with SetBulkParallelism.
+const DefaultBulkParallelism = 4
+
+// ProfileManager publishes profiles to the DDS and retrieves them back,
+// keeping the registry's view of each owner's latest version current.
+type ProfileManager struct {
+	dds      *service.DDSCoreService
+	registry *ProfileRegistry
+
+	bulkParallelism int
+
+	// attestations, when set, enriches retrieved profiles with the
+	// subject's active badges. See SetAttestationSource.
+	attestations AttestationSource
+
+	// pictureValidator, when set, checks that a profile's picture CID
+	// resolves before publishing. See SetPictureValidator.
+	pictureValidator ManifestFetcher
+
+	// ledger, when set, must accept each new profile version before
+	// UpdateProfile makes it caller-visible. See SetLedgerRecorder.
+	ledger LedgerRecorder
+}
+
+// AttestationSource provides the active attestations for a subject
+// address; *attest.AttestationIndex implements it.
+type AttestationSource interface {
+	AttestationsFor(subject string) []attest.Attestation
+}
+
+// ManifestFetcher resolves a CID to its manifest without downloading the
+// content; *service.DDSCoreService implements it.
+type ManifestFetcher interface {
+	FetchManifest(cid string) (*chunking.Manifest, error)
+}
+
+// LedgerRecorder records an accepted profile version on a ledger. It is a
+// consumer-side seam — the user package does not depend on the ledger
+// package — typically submitting a profile-updated transaction carrying
+// the new CID.
+type LedgerRecorder interface {
+	RecordProfileUpdate(ownerAddress, cid string) error
+}
+
+// NewProfileManager returns a manager publishing through dds.
+func NewProfileManager(dds *service.DDSCoreService) *ProfileManager {
+	return &ProfileManager{
+		dds:             dds,
+		registry:        NewProfileRegistry(),
+		bulkParallelism: DefaultBulkParallelism,
+	}
+}
+
+// SetBulkParallelism bounds how many concurrent fetches RetrieveProfiles
+// performs. Values below 1 are treated as 1.
+func (m *ProfileManager) SetBulkParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	m.bulkParallelism = n
+}
+
+// SetAttestationSource makes RetrieveProfile attach the owner's active
+// attestations (from attesters the source trusts) to retrieved profiles.
+// A nil source disables enrichment.
+func (m *ProfileManager) SetAttestationSource(source AttestationSource) {
+	m.attestations = source
+}
+
+// SetPictureValidator makes PublishProfile confirm that a non-empty
+// ProfilePictureCID resolves to a DDS manifest before the profile version
+// is signed and published, so profiles cannot reference dangling or
+// malformed picture CIDs. Passing the manager's own DDSCoreService is the
+// usual choice; a nil fetcher disables the check (the default).
+func (m *ProfileManager) SetPictureValidator(fetcher ManifestFetcher) {
+	m.pictureValidator = fetcher
+}
+
+// SetLedgerRecorder makes UpdateProfile register each new version on the
+// ledger before the update becomes caller-visible; a ledger rejection
+// rolls the update back. A nil recorder disables registration (the
+// default).
+func (m *ProfileManager) SetLedgerRecorder(rec LedgerRecorder) {
+	m.ledger = rec
+}
+
+// Registry exposes the manager's profile registry.
+func (m *ProfileManager) Registry() *ProfileRegistry {
+	return m.registry
+}
+
+// PublishProfile signs and publishes a profile version, linking it to the
+// owner's previously registered version and registering the new CID.
+func (m *ProfileManager) PublishProfile(profile *Profile, wallet *identity.Wallet) (string, error) {
+	cid, err := m.publishVersion(profile, wallet)
+	if err != nil {
+		return "", err
+	}
+	if err := m.registry.Register(profile, cid); err != nil {
+		return "", err
+	}
+	return cid, nil
+}
+
+// publishVersion signs and publishes one profile version to the DDS
+// without registering it, so callers sequencing further acceptance steps
+// (ledger registration) can register only once every step has passed.
+func (m *ProfileManager) publishVersion(profile *Profile, wallet *identity.Wallet) (string, error) {
+	if m.pictureValidator != nil && profile.ProfilePictureCID != "" {
+		if _, err := m.pictureValidator.FetchManifest(profile.ProfilePictureCID); err != nil {
+			return "", fmt.Errorf("user: profile picture CID %s for %s does not resolve: %w",
+				profile.ProfilePictureCID, profile.OwnerAddress, err)
+		}
+	}
+	if prev, ok := m.registry.Latest(profile.OwnerAddress); ok {
+		profile.PreviousCID = prev.CID
+	}
+	if err := profile.Sign(wallet); err != nil {
+		return "", err
+	}
+	data, err := profile.ToJSON()
+	if err != nil {
+		return "", err
+	}
+	cid, err := m.dds.Publish(data, ProfileContentType)
+	if err != nil {
+		return "", fmt.Errorf("user: failed to publish profile for %s: %w", profile.OwnerAddress, err)
+	}
+	return cid, nil
+}
+
+// UpdateResult reports what an UpdateProfile call did. Profile is the
+// state the caller should keep using: the accepted new version on
+// success, the original untouched one when RolledBack is set.
+type UpdateResult struct {
+	// NewCID is the published CID of the accepted version, empty when the
+	// update was rolled back.
+	NewCID  string
+	Profile *Profile
+	// RolledBack reports that the staged version was discarded — the
+	// update was invalid, the DDS publish failed, or the ledger rejected
+	// it — and the caller-visible profile was left untouched.
+	RolledBack bool
+	Err        error
+}
+
+// UpdateProfile applies an update optimistically: the change is staged on
+// a clone, published, registered on the ledger when a recorder is
+// configured, and only then swapped into the profile the caller holds. A
+// failure at any step rolls back by discarding the clone, so the caller's
+// profile never reflects a version the rest of the system refused.
+func (m *ProfileManager) UpdateProfile(profile *Profile, wallet *identity.Wallet, displayName, bio string) UpdateResult {
+	staged := profile.Clone()
+	if err := staged.Update(displayName, bio); err != nil {
+		return UpdateResult{Profile: profile, RolledBack: true, Err: err}
+	}
+	cid, err := m.publishVersion(staged, wallet)
+	if err != nil {
+		return UpdateResult{Profile: profile, RolledBack: true, Err: err}
+	}
+	// Published but unregistered content is harmless if a later step
+	// fails: nothing links to the CID, and the next accepted update reuses
+	// the same previous-version link.
+	if m.ledger != nil {
+		if err := m.ledger.RecordProfileUpdate(staged.OwnerAddress, cid); err != nil {
+			return UpdateResult{Profile: profile, RolledBack: true,
+				Err: fmt.Errorf("user: ledger rejected profile update for %s: %w", staged.OwnerAddress, err)}
+		}
+	}
+	if err := m.registry.Register(staged, cid); err != nil {
+		return UpdateResult{Profile: profile, RolledBack: true, Err: err}
+	}
+	profile.replaceWith(staged)
+	return UpdateResult{NewCID: cid, Profile: profile}
+}
+
+// UpdateAndPublishProfile applies an update to the profile and publishes
+// the new version.
+//
+// Deprecated: the profile is mutated in place before publishing, so a
+// failed publish leaves the caller holding a bumped, unsigned version the
+// registry never accepted. Use UpdateProfile, which stages the update on
+// a clone and leaves the profile untouched unless every step succeeds.
+func (m *ProfileManager) UpdateAndPublishProfile(profile *Profile, wallet *identity.Wallet, displayName, bio string) (string, error) {
+	if err := profile.Update(displayName, bio); err != nil {
+		return "", err
+	}
+	return m.PublishProfile(profile, wallet)
+}
+
+// UpdateAndPublishLocalizedProfile records (or clears) the translation for
+// one language tag and publishes the new version.
+func (m *ProfileManager) UpdateAndPublishLocalizedProfile(profile *Profile, wallet *identity.Wallet, lang string, fields LocalizedFields) (string, error) {
+	if err := profile.SetLocalized(lang, fields); err != nil {
+		return "", err
+	}
+	return m.PublishProfile(profile, wallet)
+}
+
+// TombstoneProfile publishes a signed tombstone version of the profile,
+// terminally deleting it from the registry's point of view.
+func (m *ProfileManager) TombstoneProfile(current *Profile, wallet *identity.Wallet) (string, error) {
+	if err := current.Tombstone(); err != nil {
+		return "", err
+	}
+	return m.PublishProfile(current, wallet)
+}
+
+// RetrieveProfile retrieves and deserializes a published profile. A
+// tombstoned profile yields ErrProfileTombstoned carrying only the owner
+// address; use RetrieveProfileRaw to inspect the tombstone itself.
+func (m *ProfileManager) RetrieveProfile(cid string) (*Profile, error) {
+	profile, err := m.RetrieveProfileRaw(cid)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Tombstoned {
+		return nil, &ErrProfileTombstoned{OwnerAddress: profile.OwnerAddress}
+	}
+	if m.attestations != nil {
+		profile.Attestations = m.attestations.AttestationsFor(profile.OwnerAddress)
+	}
+	return profile, nil
+}
+
+// RetrieveProfiles fetches many profiles concurrently with bounded
+// parallelism, e.g. the authors of a feed page. Successes and failures are
+// returned separately so one bad profile does not fail the batch; duplicate
+// CIDs are fetched once.
+func (m *ProfileManager) RetrieveProfiles(cids []string) (map[string]*Profile, map[string]error) {
+	profiles := make(map[string]*Profile, len(cids))
+	failures := make(map[string]error)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, m.bulkParallelism)
+	seen := make(map[string]bool, len(cids))
+	for _, cid := range cids {
+		if seen[cid] {
+			continue
+		}
+		seen[cid] = true
+		wg.Add(1)
+		go func(cid string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			profile, err := m.RetrieveProfile(cid)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failures[cid] = err
+				return
+			}
+			profiles[cid] = profile
+		}(cid)
+	}
+	wg.Wait()
+	return profiles, failures
+}
+
+// RetrieveProfileRaw retrieves a profile version without tombstone
+// filtering, for auditors walking version history. The manifest's declared
+// content type is checked before deserialization — a post CID passed by
+// mistake yields *ErrWrongContentType, not an unmarshal failure; manifests
+// without type metadata fall back to a cheap profile sniff.
+func (m *ProfileManager) RetrieveProfileRaw(cid string) (*Profile, error) {
+	if manifest, err := m.dds.FetchManifest(cid); err == nil {
+		if manifest.ContentType != "" && manifest.ContentType != ProfileContentType {
+			return nil, &ErrWrongContentType{CID: cid, Expected: ProfileContentType, Got: manifest.ContentType}
+		}
+	}
+	data, err := m.dds.Retrieve(cid)
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to retrieve profile %s: %w", cid, err)
+	}
+	if !LooksLikeProfile(data) {
+		return nil, &ErrWrongContentType{CID: cid, Expected: ProfileContentType, Got: "unknown"}
+	}
+	profile, err := FromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if !profile.VerifySignature() {
+		return nil, fmt.Errorf("user: profile %s has an invalid owner signature", cid)
+	}
+	return profile, nil
+}
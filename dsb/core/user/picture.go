@@ -0,0 +1,133 @@
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// Limits enforced on profile pictures before publication.
+const (
+	// MaxPictureBytes is the largest accepted original image.
+	MaxPictureBytes = 5 << 20
+	// MaxPictureDim is the largest accepted width or height in pixels.
+	MaxPictureDim = 4096
+	// ThumbnailMaxDim is the bounding box the generated thumbnail fits in.
+	ThumbnailMaxDim = 128
+)
+
+// GenerateThumbnail decodes a JPEG or PNG image and returns a copy
+// downscaled to fit within maxDim×maxDim, preserving aspect ratio and
+// re-encoding in the source format. Images already within the bound are
+// re-encoded unscaled.
+func GenerateThumbnail(raw []byte, maxDim int) ([]byte, error) {
+	if maxDim <= 0 {
+		return nil, fmt.Errorf("user: thumbnail dimension must be positive, got %d", maxDim)
+	}
+	src, format, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to decode image: %w", err)
+	}
+	if format != "jpeg" && format != "png" {
+		return nil, fmt.Errorf("user: unsupported image format %q", format)
+	}
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := thumbnailDims(w, h, maxDim)
+	thumb := scaleNearest(src, tw, th)
+
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	case "png":
+		err = png.Encode(&buf, thumb)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// thumbnailDims scales (w, h) down to fit in maxDim×maxDim, never up.
+func thumbnailDims(w, h, maxDim int) (int, int) {
+	if w <= maxDim && h <= maxDim {
+		return w, h
+	}
+	if w >= h {
+		return maxDim, maxInt(1, h*maxDim/w)
+	}
+	return maxInt(1, w*maxDim/h), maxDim
+}
+
+// scaleNearest resizes src to w×h with nearest-neighbor sampling. Quality
+// is secondary for tiny avatars; avoiding external dependencies is not.
+func scaleNearest(src image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sb := src.Bounds()
+	for y := 0; y < h; y++ {
+		sy := sb.Min.Y + y*sb.Dy()/h
+		for x := 0; x < w; x++ {
+			sx := sb.Min.X + x*sb.Dx()/w
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// PublishProfilePicture validates and publishes both the original image and
+// a generated thumbnail, records their CIDs on the profile as a new signed
+// version, and publishes that version. It returns the profile version's CID.
+func (m *ProfileManager) PublishProfilePicture(profile *Profile, wallet *identity.Wallet, raw []byte) (string, error) {
+	if profile.Tombstoned {
+		return "", &ErrProfileTombstoned{OwnerAddress: profile.OwnerAddress}
+	}
+	if len(raw) == 0 {
+		return "", fmt.Errorf("user: profile picture is empty")
+	}
+	if len(raw) > MaxPictureBytes {
+		return "", fmt.Errorf("user: profile picture exceeds %d bytes", MaxPictureBytes)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return "", fmt.Errorf("user: failed to decode image: %w", err)
+	}
+	if cfg.Width > MaxPictureDim || cfg.Height > MaxPictureDim {
+		return "", fmt.Errorf("user: profile picture %dx%d exceeds %d pixels per side",
+			cfg.Width, cfg.Height, MaxPictureDim)
+	}
+	thumb, err := GenerateThumbnail(raw, ThumbnailMaxDim)
+	if err != nil {
+		return "", err
+	}
+	contentType := "image/" + format
+	pictureCID, err := m.dds.Publish(raw, contentType)
+	if err != nil {
+		return "", fmt.Errorf("user: failed to publish profile picture: %w", err)
+	}
+	thumbCID, err := m.dds.Publish(thumb, contentType)
+	if err != nil {
+		return "", fmt.Errorf("user: failed to publish picture thumbnail: %w", err)
+	}
+
+	profile.mu.Lock()
+	profile.ProfilePictureCID = pictureCID
+	profile.ProfilePictureThumbCID = thumbCID
+	profile.Version++
+	profile.Timestamp = clock.Now()
+	profile.Signature = nil
+	profile.mu.Unlock()
+	return m.PublishProfile(profile, wallet)
+}
@@ -0,0 +1,117 @@
+package user_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+// newPictureFixture is like newTestManager but also returns the DDS so
+// tests can pull published images back out.
+func newPictureFixture(t *testing.T) (*user.ProfileManager, *service.DDSCoreService) {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(1 << 10)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-picture-test", "127.0.0.1:9110"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	return user.NewProfileManager(dds), dds
+}
+
+// testPNG renders a w×h gradient image so scaling has real pixel variety.
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x % 256), G: uint8(y % 256), B: 64, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateThumbnail_FitsBoundingBox(t *testing.T) {
+	raw := testPNG(t, 640, 480)
+	thumb, err := user.GenerateThumbnail(raw, user.ThumbnailMaxDim)
+	if err != nil {
+		t.Fatalf("GenerateThumbnail: %v", err)
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("DecodeConfig: %v", err)
+	}
+	if format != "png" {
+		t.Errorf("thumbnail format = %s, want png", format)
+	}
+	if cfg.Width != user.ThumbnailMaxDim {
+		t.Errorf("thumbnail width = %d, want %d", cfg.Width, user.ThumbnailMaxDim)
+	}
+	if want := 480 * user.ThumbnailMaxDim / 640; cfg.Height != want {
+		t.Errorf("thumbnail height = %d, want %d", cfg.Height, want)
+	}
+}
+
+func TestGenerateThumbnail_RejectsGarbage(t *testing.T) {
+	if _, err := user.GenerateThumbnail([]byte("not an image"), user.ThumbnailMaxDim); err == nil {
+		t.Error("garbage bytes were accepted")
+	}
+}
+
+func TestPublishProfilePicture_DualCIDsSurviveRoundTrip(t *testing.T) {
+	manager, dds := newPictureFixture(t)
+	wallet, profile := newTestWalletProfile(t)
+
+	raw := testPNG(t, 300, 300)
+	cid, err := manager.PublishProfilePicture(profile, wallet, raw)
+	if err != nil {
+		t.Fatalf("PublishProfilePicture: %v", err)
+	}
+	got, err := manager.RetrieveProfile(cid)
+	if err != nil {
+		t.Fatalf("RetrieveProfile: %v", err)
+	}
+	if got.ProfilePictureCID == "" || got.ProfilePictureThumbCID == "" {
+		t.Fatalf("round-tripped profile missing picture CIDs: %+v", got)
+	}
+	if got.ProfilePictureCID == got.ProfilePictureThumbCID {
+		t.Error("thumbnail CID equals original CID")
+	}
+
+	thumb, err := dds.Retrieve(got.ProfilePictureThumbCID)
+	if err != nil {
+		t.Fatalf("Retrieve thumbnail: %v", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("DecodeConfig: %v", err)
+	}
+	if cfg.Width > user.ThumbnailMaxDim || cfg.Height > user.ThumbnailMaxDim {
+		t.Errorf("thumbnail %dx%d exceeds bound %d", cfg.Width, cfg.Height, user.ThumbnailMaxDim)
+	}
+}
+
+func TestPublishProfilePicture_RejectsOversizedDimensions(t *testing.T) {
+	manager, _ := newPictureFixture(t)
+	wallet, profile := newTestWalletProfile(t)
+	raw := testPNG(t, user.MaxPictureDim+1, 10)
+	if _, err := manager.PublishProfilePicture(profile, wallet, raw); err == nil {
+		t.Error("oversized image was accepted")
+	}
+}
@@ -0,0 +1,49 @@
+package user_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPublishProfile_ValidPictureCIDPasses(t *testing.T) {
+	manager, dds := newPictureFixture(t)
+	manager.SetPictureValidator(dds)
+	wallet, profile := newTestWalletProfile(t)
+
+	pictureCID, err := dds.Publish(testPNG(t, 32, 32), "image/png")
+	if err != nil {
+		t.Fatalf("Publish picture: %v", err)
+	}
+	profile.ProfilePictureCID = pictureCID
+
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile with resolvable picture CID: %v", err)
+	}
+}
+
+func TestPublishProfile_DanglingPictureCIDRejected(t *testing.T) {
+	manager, dds := newPictureFixture(t)
+	manager.SetPictureValidator(dds)
+	wallet, profile := newTestWalletProfile(t)
+	profile.ProfilePictureCID = "no-such-manifest"
+
+	_, err := manager.PublishProfile(profile, wallet)
+	if err == nil {
+		t.Fatal("PublishProfile accepted a dangling picture CID")
+	}
+	if !strings.Contains(err.Error(), "does not resolve") {
+		t.Errorf("error does not name the unresolvable picture CID: %v", err)
+	}
+}
+
+// Without a configured validator the picture CID stays opaque, preserving
+// the historical behavior.
+func TestPublishProfile_NoValidatorSkipsPictureCheck(t *testing.T) {
+	manager, _ := newPictureFixture(t)
+	wallet, profile := newTestWalletProfile(t)
+	profile.ProfilePictureCID = "no-such-manifest"
+
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile without validator: %v", err)
+	}
+}
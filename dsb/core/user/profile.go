@@ -0,0 +1,336 @@
+// Package user implements the social identity layer: profiles owned by
+// wallet addresses, published as content through the DDS, and tracked by a
+// registry that resolves each owner's latest version.
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/attest"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// Field limits enforced on profile creation and update.
+const (
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 500
+)
+
+// TombstoneDisplayName replaces the display name of a deleted profile.
+const TombstoneDisplayName = "[deleted]"
+
+// Profile is a user's public identity. Each published version is immutable
+// content on the DDS; PreviousCID links versions into an auditable history.
+type Profile struct {
+	OwnerAddress      string `json:"ownerAddress"`
+	OwnerPublicKey    []byte `json:"ownerPublicKey,omitempty"`
+	DisplayName       string `json:"displayName"`
+	Bio               string `json:"bio,omitempty"`
+	ProfilePictureCID string `json:"profilePictureCid,omitempty"`
+	// ProfilePictureThumbCID points at a downscaled copy of the picture so
+	// feed views need not download the full-resolution original.
+	ProfilePictureThumbCID string `json:"profilePictureThumbCid,omitempty"`
+	Version                int64  `json:"version"`
+	Timestamp              int64  `json:"timestamp"`
+	PreviousCID            string `json:"previousCid,omitempty"`
+
+	// DeviceID identifies the client that produced this version. When set,
+	// every version bump also increments this device's counter in
+	// VersionVector, letting the registry tell sequential updates from
+	// concurrent ones made on different devices. Both fields are covered by
+	// the owner signature; encoding/json sorts map keys, so the signed
+	// bytes stay deterministic.
+	DeviceID      string           `json:"deviceId,omitempty"`
+	VersionVector map[string]int64 `json:"versionVector,omitempty"`
+
+	// Localized carries per-language overrides of the display name and
+	// bio, keyed by BCP-47 language tag. It is covered by the owner
+	// signature; encoding/json sorts map keys, so the signed bytes are
+	// deterministic.
+	Localized map[string]LocalizedFields `json:"localized,omitempty"`
+
+	// Tombstoned marks the profile as deleted by its owner. A tombstone is
+	// the terminal version: the registry refuses any update after it.
+	Tombstoned   bool  `json:"tombstoned,omitempty"`
+	TombstonedAt int64 `json:"tombstonedAt,omitempty"`
+
+	Signature []byte `json:"signature,omitempty"`
+
+	// Attestations holds the active badges attached by the local node's
+	// attestation source during retrieval. It is local enrichment, never
+	// serialized with the profile and not covered by the owner signature.
+	Attestations []attest.Attestation `json:"-"`
+
+	// mu serializes mutations and signing, so a profile pointer shared
+	// across goroutines (e.g. a cached working copy) cannot be corrupted by
+	// concurrent updates. Do not copy a Profile by assignment; serialize it
+	// (ToJSON/FromJSON) to clone.
+	mu sync.Mutex
+}
+
+// Validate checks the field rules every entry point shares — create,
+// update, and deserialization: a non-empty owner address and display name,
+// the display-name and bio length limits, and a version of at least 1. The
+// first violated rule is reported with its semantic sentinel, so the three
+// entry points reject the same bad profile identically.
+func (p *Profile) Validate() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.validate()
+}
+
+// validate is Validate without locking, for callers already holding p.mu.
+func (p *Profile) validate() error {
+	if p.OwnerAddress == "" {
+		return ErrProfileMissingOwner
+	}
+	if p.Version < 1 {
+		return fmt.Errorf("%w: got %d", ErrProfileInvalidVersion, p.Version)
+	}
+	if p.DisplayName == "" {
+		return ErrProfileMissingDisplayName
+	}
+	if len(p.DisplayName) > MaxDisplayNameLength {
+		return fmt.Errorf("%w: display name exceeds %d characters", ErrProfileFieldTooLong, MaxDisplayNameLength)
+	}
+	if len(p.Bio) > MaxBioLength {
+		return fmt.Errorf("%w: bio exceeds %d characters", ErrProfileFieldTooLong, MaxBioLength)
+	}
+	return nil
+}
+
+// NewProfile creates the first version of a profile. An empty bio is
+// stored as empty — the same meaning an empty bio has on Update — while
+// the display name is required from the start.
+func NewProfile(ownerAddress, displayName, bio string) (*Profile, error) {
+	p := &Profile{
+		OwnerAddress: ownerAddress,
+		DisplayName:  displayName,
+		Bio:          bio,
+		Version:      1,
+		Timestamp:    clock.Now(),
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Update applies a new display name and bio, bumping the version. The two
+// fields deliberately differ on empty input, matching their create-time
+// rules: a display name is mandatory, so an empty displayName means "no
+// change"; a bio is optional, so the given bio — empty included — always
+// replaces the old one.
+func (p *Profile) Update(displayName, bio string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Tombstoned {
+		return &ErrProfileTombstoned{OwnerAddress: p.OwnerAddress}
+	}
+	// Stage the change, run the shared rules, and roll back on rejection so
+	// a failed update leaves the profile untouched.
+	oldName, oldBio := p.DisplayName, p.Bio
+	if displayName != "" {
+		p.DisplayName = displayName
+	}
+	p.Bio = bio
+	if err := p.validate(); err != nil {
+		p.DisplayName, p.Bio = oldName, oldBio
+		return err
+	}
+	p.bumpVersion()
+	return nil
+}
+
+// bumpVersion advances the profile to a new unsigned version, crediting
+// the local device's version-vector counter when a DeviceID is set.
+// Callers must hold p.mu.
+func (p *Profile) bumpVersion() {
+	p.Version++
+	if p.DeviceID != "" {
+		if p.VersionVector == nil {
+			p.VersionVector = make(map[string]int64, 1)
+		}
+		p.VersionVector[p.DeviceID]++
+	}
+	p.Timestamp = clock.Now()
+	p.Signature = nil
+}
+
+// Tombstone converts the profile into its terminal deleted version:
+// placeholder display name, cleared bio and picture, bumped version.
+func (p *Profile) Tombstone() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Tombstoned {
+		return &ErrProfileTombstoned{OwnerAddress: p.OwnerAddress}
+	}
+	p.DisplayName = TombstoneDisplayName
+	p.Bio = ""
+	p.ProfilePictureCID = ""
+	p.ProfilePictureThumbCID = ""
+	p.Localized = nil
+	p.bumpVersion()
+	p.Tombstoned = true
+	p.TombstonedAt = p.Timestamp
+	return nil
+}
+
+// Clone returns a copy of the profile that shares no mutable state with
+// the original: maps and byte slices are duplicated and the mutex is not
+// copied (copying it by assignment would trip the race detector and vet).
+// Update paths stage changes on a clone so the original survives a
+// rejected version intact.
+func (p *Profile) Clone() *Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c := &Profile{
+		OwnerAddress:           p.OwnerAddress,
+		OwnerPublicKey:         append([]byte(nil), p.OwnerPublicKey...),
+		DisplayName:            p.DisplayName,
+		Bio:                    p.Bio,
+		ProfilePictureCID:      p.ProfilePictureCID,
+		ProfilePictureThumbCID: p.ProfilePictureThumbCID,
+		Version:                p.Version,
+		Timestamp:              p.Timestamp,
+		PreviousCID:            p.PreviousCID,
+		DeviceID:               p.DeviceID,
+		Tombstoned:             p.Tombstoned,
+		TombstonedAt:           p.TombstonedAt,
+		Signature:              append([]byte(nil), p.Signature...),
+		Attestations:           append([]attest.Attestation(nil), p.Attestations...),
+	}
+	if p.VersionVector != nil {
+		c.VersionVector = make(map[string]int64, len(p.VersionVector))
+		for device, counter := range p.VersionVector {
+			c.VersionVector[device] = counter
+		}
+	}
+	if p.Localized != nil {
+		c.Localized = make(map[string]LocalizedFields, len(p.Localized))
+		for lang, fields := range p.Localized {
+			c.Localized[lang] = fields
+		}
+	}
+	return c
+}
+
+// replaceWith copies other's state into p, so an accepted staged clone
+// becomes visible through the pointer callers already hold. The staged
+// side is cloned first, leaving the two profiles sharing nothing mutable.
+func (p *Profile) replaceWith(other *Profile) {
+	c := other.Clone()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.OwnerAddress = c.OwnerAddress
+	p.OwnerPublicKey = c.OwnerPublicKey
+	p.DisplayName = c.DisplayName
+	p.Bio = c.Bio
+	p.ProfilePictureCID = c.ProfilePictureCID
+	p.ProfilePictureThumbCID = c.ProfilePictureThumbCID
+	p.Version = c.Version
+	p.Timestamp = c.Timestamp
+	p.PreviousCID = c.PreviousCID
+	p.DeviceID = c.DeviceID
+	p.VersionVector = c.VersionVector
+	p.Localized = c.Localized
+	p.Tombstoned = c.Tombstoned
+	p.TombstonedAt = c.TombstonedAt
+	p.Signature = c.Signature
+	p.Attestations = c.Attestations
+}
+
+// signingBytes returns the canonical bytes covered by the profile
+// signature: the JSON encoding with the signature field cleared. The
+// signature is blanked in place rather than on a copy so new fields are
+// always covered without field-by-field copying (and the mutex is never
+// copied); callers must hold p.mu.
+func (p *Profile) signingBytes() ([]byte, error) {
+	sig := p.Signature
+	p.Signature = nil
+	data, err := json.Marshal(p)
+	p.Signature = sig
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to serialize profile for signing: %w", err)
+	}
+	return data, nil
+}
+
+// Sign signs the profile with the owner's wallet, recording the wallet's
+// public key so the signature can be verified after retrieval.
+func (p *Profile) Sign(wallet *identity.Wallet) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !identity.AddressesEqual(wallet.Address, p.OwnerAddress) {
+		return fmt.Errorf("user: wallet %s cannot sign profile owned by %s", wallet.Address, p.OwnerAddress)
+	}
+	p.OwnerPublicKey = wallet.PublicKey
+	data, err := p.signingBytes()
+	if err != nil {
+		return err
+	}
+	sig, err := wallet.Sign(data)
+	if err != nil {
+		return fmt.Errorf("user: failed to sign profile: %w", err)
+	}
+	p.Signature = sig
+	return nil
+}
+
+// VerifySignature reports whether the profile carries a valid owner
+// signature and the public key matches the claimed owner address.
+func (p *Profile) VerifySignature() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.Signature) == 0 || len(p.OwnerPublicKey) == 0 {
+		return false
+	}
+	if !identity.AddressesEqual(identity.PublicKeyToAddress(p.OwnerPublicKey), p.OwnerAddress) {
+		return false
+	}
+	data, err := p.signingBytes()
+	if err != nil {
+		return false
+	}
+	return identity.VerifySignature(p.OwnerPublicKey, data, p.Signature)
+}
+
+// ToJSON serializes the profile for publishing.
+func (p *Profile) ToJSON() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("user: failed to serialize profile: %w", err)
+	}
+	return data, nil
+}
+
+// LooksLikeProfile cheaply sniffs whether the bytes plausibly hold a
+// profile document — a JSON object mentioning the ownerAddress field —
+// without unmarshaling. It exists for content retrieved from manifests
+// published before content-type metadata, where the declared type cannot
+// be consulted.
+func LooksLikeProfile(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{' && bytes.Contains(trimmed, []byte(`"ownerAddress"`))
+}
+
+// FromJSON deserializes a published profile and checks its basic shape.
+// Malformed bytes yield ErrInvalidProfileJSON wrapping the underlying JSON
+// error; a well-formed document with bad fields yields one of the semantic
+// sentinels.
+func FromJSON(data []byte) (*Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProfileJSON, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,38 @@
+package user_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+func TestFromJSON_SyntaxErrorIsTypedAndUnwrappable(t *testing.T) {
+	_, err := user.FromJSON([]byte(`{"ownerAddress": "abc", `))
+	if !errors.Is(err, user.ErrInvalidProfileJSON) {
+		t.Fatalf("want ErrInvalidProfileJSON, got %v", err)
+	}
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("underlying *json.SyntaxError not preserved: %v", err)
+	}
+}
+
+func TestFromJSON_SemanticErrorsAreDistinctSentinels(t *testing.T) {
+	_, err := user.FromJSON([]byte(`{"displayName": "no owner", "version": 1}`))
+	if !errors.Is(err, user.ErrProfileMissingOwner) {
+		t.Fatalf("want ErrProfileMissingOwner, got %v", err)
+	}
+	if errors.Is(err, user.ErrInvalidProfileJSON) {
+		t.Error("semantic error must not match ErrInvalidProfileJSON")
+	}
+
+	_, err = user.FromJSON([]byte(`{"ownerAddress": "abc", "version": 0}`))
+	if !errors.Is(err, user.ErrProfileInvalidVersion) {
+		t.Fatalf("want ErrProfileInvalidVersion, got %v", err)
+	}
+	if errors.Is(err, user.ErrInvalidProfileJSON) {
+		t.Error("semantic error must not match ErrInvalidProfileJSON")
+	}
+}
@@ -0,0 +1,205 @@
+package user_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// newTestManager wires a ProfileManager over an in-memory DDS node.
+func newTestManager(t *testing.T) *user.ProfileManager {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-profile-test", "127.0.0.1:9020"),
+		storage.NewInMemoryStorage(), chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	return user.NewProfileManager(dds)
+}
+
+func newTestWalletProfile(t *testing.T) (*identity.Wallet, *user.Profile) {
+	t.Helper()
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	profile, err := user.NewProfile(wallet.Address, "Ada", "first bio")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	return wallet, profile
+}
+
+func TestProfile_PublishAndRetrieveRoundTrip(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+
+	cid, err := manager.PublishProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	got, err := manager.RetrieveProfile(cid)
+	if err != nil {
+		t.Fatalf("RetrieveProfile: %v", err)
+	}
+	if got.OwnerAddress != wallet.Address || got.DisplayName != "Ada" || got.Version != 1 {
+		t.Errorf("retrieved profile %+v does not match published", got)
+	}
+}
+
+func TestTombstoneProfile_RetrievalReturnsTypedError(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+
+	tombCID, err := manager.TombstoneProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("TombstoneProfile: %v", err)
+	}
+
+	_, err = manager.RetrieveProfile(tombCID)
+	var tombErr *user.ErrProfileTombstoned
+	if !errors.As(err, &tombErr) {
+		t.Fatalf("want ErrProfileTombstoned, got %v", err)
+	}
+	if tombErr.OwnerAddress != wallet.Address {
+		t.Errorf("tombstone error owner = %s, want %s", tombErr.OwnerAddress, wallet.Address)
+	}
+}
+
+func TestTombstoneProfile_RegistryRejectsLaterUpdate(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	if _, err := manager.TombstoneProfile(profile, wallet); err != nil {
+		t.Fatalf("TombstoneProfile: %v", err)
+	}
+
+	// A forged "later" version must be refused by the registry.
+	later, err := user.NewProfile(wallet.Address, "Back Again", "resurrected")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	later.Version = profile.Version + 1
+	if _, err := manager.PublishProfile(later, wallet); err == nil {
+		t.Fatal("want registry rejection after tombstone, got nil")
+	}
+}
+
+func TestTombstoneProfile_HistoryWalkStillWorks(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+	if _, err := manager.UpdateAndPublishProfile(profile, wallet, "Ada L.", "updated bio"); err != nil {
+		t.Fatalf("UpdateAndPublishProfile: %v", err)
+	}
+	tombCID, err := manager.TombstoneProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("TombstoneProfile: %v", err)
+	}
+
+	// Auditors can still walk the full chain through raw retrieval.
+	var versions []int64
+	for cid := tombCID; cid != ""; {
+		p, err := manager.RetrieveProfileRaw(cid)
+		if err != nil {
+			t.Fatalf("RetrieveProfileRaw(%s): %v", cid, err)
+		}
+		versions = append(versions, p.Version)
+		cid = p.PreviousCID
+	}
+	if len(versions) != 3 || versions[0] != 3 || versions[2] != 1 {
+		t.Errorf("history versions = %v, want [3 2 1]", versions)
+	}
+
+	// The tombstone version itself carries the placeholder name.
+	tomb, err := manager.RetrieveProfileRaw(tombCID)
+	if err != nil {
+		t.Fatalf("RetrieveProfileRaw(tombstone): %v", err)
+	}
+	if !tomb.Tombstoned || tomb.DisplayName != user.TombstoneDisplayName || tomb.Bio != "" {
+		t.Errorf("tombstone version = %+v, want cleared fields", tomb)
+	}
+}
+
+// Run with -race: a profile pointer shared across goroutines must survive
+// concurrent updates without losing version bumps or corrupting fields.
+func TestProfile_ConcurrentUpdates(t *testing.T) {
+	_, profile := newTestWalletProfile(t)
+	profile.DeviceID = "device-a"
+
+	const updates = 64
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := profile.Update(fmt.Sprintf("Ada %d", i), "concurrent bio"); err != nil {
+				t.Errorf("Update: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if profile.Version != 1+updates {
+		t.Errorf("version = %d, want %d (every update counted once)", profile.Version, 1+updates)
+	}
+	if got := profile.VersionVector["device-a"]; got != updates {
+		t.Errorf("device counter = %d, want %d", got, updates)
+	}
+	if _, err := profile.ToJSON(); err != nil {
+		t.Errorf("profile no longer serializes: %v", err)
+	}
+}
+
+// The empty-input rules are part of the API contract: a bio is optional
+// (empty creates empty, empty update clears), a display name is mandatory
+// (empty update means "keep the current one").
+func TestProfile_EmptyFieldSemantics(t *testing.T) {
+	profile, err := user.NewProfile("addr-empty-bio", "Ada", "")
+	if err != nil {
+		t.Fatalf("NewProfile with empty bio: %v", err)
+	}
+	if profile.Bio != "" {
+		t.Errorf("created bio = %q, want empty", profile.Bio)
+	}
+
+	if err := profile.Update("", "now with a bio"); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if profile.DisplayName != "Ada" {
+		t.Errorf("empty display name changed the stored one to %q", profile.DisplayName)
+	}
+	if profile.Bio != "now with a bio" {
+		t.Errorf("bio = %q, want the updated text", profile.Bio)
+	}
+
+	if err := profile.Update("Ada L.", ""); err != nil {
+		t.Fatalf("Update to empty bio: %v", err)
+	}
+	if profile.DisplayName != "Ada L." || profile.Bio != "" {
+		t.Errorf("after clearing: %q / %q, want new name and empty bio", profile.DisplayName, profile.Bio)
+	}
+	if profile.Version != 3 {
+		t.Errorf("version = %d, want 3 after two updates", profile.Version)
+	}
+}
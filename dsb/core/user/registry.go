@@ -0,0 +1,85 @@
+package user
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RegistryEntry records the latest known version of one owner's profile.
+type RegistryEntry struct {
+	CID        string
+	Version    int64
+	Tombstoned bool
+
+	// Vector is the version vector of the registered profile, nil for
+	// profiles published without a device identity.
+	Vector map[string]int64
+}
+
+// ProfileRegistry tracks the latest profile version per owner address. A
+// tombstoned profile is terminal: no later version is accepted for that
+// owner.
+type ProfileRegistry struct {
+	mu     sync.RWMutex
+	latest map[string]RegistryEntry
+}
+
+// NewProfileRegistry returns an empty registry.
+func NewProfileRegistry() *ProfileRegistry {
+	return &ProfileRegistry{latest: make(map[string]RegistryEntry)}
+}
+
+// Register records profile (published at cid) as the owner's latest
+// version. Stale versions and updates after a tombstone are rejected. When
+// both the registered entry and the incoming profile carry version
+// vectors, ordering is decided by the vectors: an incomparable pair means
+// two devices updated the same base version concurrently, and Register
+// surfaces *ErrProfileConflict with both CIDs instead of silently picking
+// a winner.
+func (r *ProfileRegistry) Register(profile *Profile, cid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.latest[profile.OwnerAddress]
+	if ok {
+		if existing.Tombstoned {
+			return &ErrProfileTombstoned{OwnerAddress: profile.OwnerAddress}
+		}
+		if len(existing.Vector) > 0 && len(profile.VersionVector) > 0 {
+			switch CompareVersionVectors(profile.VersionVector, existing.Vector) {
+			case VectorConcurrent:
+				return &ErrProfileConflict{
+					OwnerAddress: profile.OwnerAddress,
+					ExistingCID:  existing.CID,
+					IncomingCID:  cid,
+				}
+			case VectorBefore, VectorEqual:
+				return fmt.Errorf("user: profile version %d for %s does not dominate registered version %d",
+					profile.Version, profile.OwnerAddress, existing.Version)
+			}
+		} else if profile.Version <= existing.Version {
+			return fmt.Errorf("user: profile version %d for %s is not newer than registered version %d",
+				profile.Version, profile.OwnerAddress, existing.Version)
+		}
+	}
+	entry := RegistryEntry{
+		CID:        cid,
+		Version:    profile.Version,
+		Tombstoned: profile.Tombstoned,
+	}
+	if len(profile.VersionVector) > 0 {
+		entry.Vector = make(map[string]int64, len(profile.VersionVector))
+		for device, counter := range profile.VersionVector {
+			entry.Vector[device] = counter
+		}
+	}
+	r.latest[profile.OwnerAddress] = entry
+	return nil
+}
+
+// Latest returns the latest registered entry for an owner.
+func (r *ProfileRegistry) Latest(ownerAddress string) (RegistryEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.latest[ownerAddress]
+	return entry, ok
+}
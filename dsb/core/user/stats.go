@@ -0,0 +1,172 @@
+package user
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+)
+
+// ErrNoActivity is returned by Stats for an address the chain has never
+// seen act or be acted upon.
+var ErrNoActivity = errors.New("user: address has no recorded activity")
+
+// UserStats summarizes one address's social activity as derived from the
+// chain: counts of followers, followees, and posts, and the timestamp of
+// the address's most recent transaction.
+type UserStats struct {
+	Address      string `json:"address"`
+	Followers    int    `json:"followers"`
+	Following    int    `json:"following"`
+	Posts        int    `json:"posts"`
+	LastActiveAt int64  `json:"lastActiveAt"`
+}
+
+// StatsIndex maintains per-address activity statistics incrementally from
+// chain transactions, so clients get counts without replaying the chain
+// per query. It is safe for concurrent use and can follow a live chain
+// via Blockchain.OnBlockAdded. The chain today only appends; a consumer
+// that detects a history rewrite recovers by building a fresh index with
+// BuildFromChain.
+type StatsIndex struct {
+	mu    sync.RWMutex
+	stats map[string]*UserStats
+	// follows records the current follow edges (follower → followee set),
+	// so duplicate follow transactions do not double-count and unfollows
+	// without a matching follow change nothing.
+	follows    map[string]map[string]bool
+	tombstoned map[string]bool
+}
+
+// NewStatsIndex returns an empty index.
+func NewStatsIndex() *StatsIndex {
+	return &StatsIndex{
+		stats:      make(map[string]*UserStats),
+		follows:    make(map[string]map[string]bool),
+		tombstoned: make(map[string]bool),
+	}
+}
+
+// Stats returns the statistics for an address, or ErrNoActivity when the
+// chain never recorded any.
+func (ix *StatsIndex) Stats(addr string) (UserStats, error) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	s, ok := ix.stats[addr]
+	if !ok {
+		return UserStats{}, ErrNoActivity
+	}
+	return *s, nil
+}
+
+// TopByFollowers returns up to n addresses ordered by follower count,
+// highest first (ties by address for stable output). Tombstoned owners
+// are excluded: a deleted profile should not surface on leaderboards.
+func (ix *StatsIndex) TopByFollowers(n int) []UserStats {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	top := make([]UserStats, 0, len(ix.stats))
+	for addr, s := range ix.stats {
+		if ix.tombstoned[addr] {
+			continue
+		}
+		top = append(top, *s)
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Followers != top[j].Followers {
+			return top[i].Followers > top[j].Followers
+		}
+		return top[i].Address < top[j].Address
+	})
+	if n >= 0 && len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// SetTombstoned marks an address's profile as deleted or restores it.
+// Tombstones live on the DDS rather than the chain, so the profile layer
+// reports them to the index explicitly.
+func (ix *StatsIndex) SetTombstoned(addr string, tombstoned bool) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if tombstoned {
+		ix.tombstoned[addr] = true
+		return
+	}
+	delete(ix.tombstoned, addr)
+}
+
+// ApplyTransaction folds one transaction into the index. Transaction
+// types without statistical meaning still refresh the sender's
+// last-active timestamp.
+func (ix *StatsIndex) ApplyTransaction(tx *ledger.Transaction) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	sender := ix.entry(tx.SenderAddress)
+	if tx.Timestamp > sender.LastActiveAt {
+		sender.LastActiveAt = tx.Timestamp
+	}
+	switch tx.Type {
+	case ledger.TxTypePostCreated:
+		sender.Posts++
+	case ledger.TxTypeFollowed:
+		followee := string(tx.Payload)
+		if followee == "" || followee == tx.SenderAddress {
+			return
+		}
+		edges := ix.follows[tx.SenderAddress]
+		if edges == nil {
+			edges = make(map[string]bool)
+			ix.follows[tx.SenderAddress] = edges
+		}
+		if edges[followee] {
+			return
+		}
+		edges[followee] = true
+		sender.Following++
+		ix.entry(followee).Followers++
+	case ledger.TxTypeUnfollowed:
+		followee := string(tx.Payload)
+		edges := ix.follows[tx.SenderAddress]
+		if !edges[followee] {
+			return
+		}
+		delete(edges, followee)
+		sender.Following--
+		ix.entry(followee).Followers--
+	}
+}
+
+// entry returns the (created if needed) mutable stats for addr. Callers
+// must hold the write lock.
+func (ix *StatsIndex) entry(addr string) *UserStats {
+	s, ok := ix.stats[addr]
+	if !ok {
+		s = &UserStats{Address: addr}
+		ix.stats[addr] = s
+	}
+	return s
+}
+
+// BuildFromChain replays every available block's transactions into the
+// index — the initial load path, and the recovery path when chain history
+// was rewritten under the index.
+func (ix *StatsIndex) BuildFromChain(chain *ledger.Blockchain) {
+	for _, block := range chain.Blocks() {
+		for i := range block.Transactions {
+			ix.ApplyTransaction(&block.Transactions[i])
+		}
+	}
+}
+
+// Follow registers the index on the chain's block-added hook so later
+// blocks are folded in as they are appended.
+func (ix *StatsIndex) Follow(chain *ledger.Blockchain) {
+	chain.OnBlockAdded(func(block *ledger.Block) {
+		for i := range block.Transactions {
+			ix.ApplyTransaction(&block.Transactions[i])
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package user_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/ledger"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// statsFixture scripts a chain with three users while an incremental
+// index follows it.
+type statsFixture struct {
+	chain *ledger.Blockchain
+	index *user.StatsIndex
+	alice *identity.Wallet
+	bob   *identity.Wallet
+	carol *identity.Wallet
+}
+
+func newStatsFixture(t *testing.T) *statsFixture {
+	t.Helper()
+	f := &statsFixture{
+		chain: ledger.NewBlockchain(),
+		index: user.NewStatsIndex(),
+	}
+	f.index.Follow(f.chain)
+	for _, w := range []**identity.Wallet{&f.alice, &f.bob, &f.carol} {
+		wallet, err := identity.NewWallet()
+		if err != nil {
+			t.Fatalf("NewWallet: %v", err)
+		}
+		*w = wallet
+	}
+	return f
+}
+
+func (f *statsFixture) addTx(t *testing.T, wallet *identity.Wallet, txType ledger.TransactionType, payload []byte) *ledger.Transaction {
+	t.Helper()
+	tx, err := ledger.NewSignedTransaction(wallet, txType, payload)
+	if err != nil {
+		t.Fatalf("NewSignedTransaction: %v", err)
+	}
+	if _, err := f.chain.AddBlock([]ledger.Transaction{*tx}); err != nil {
+		t.Fatalf("AddBlock: %v", err)
+	}
+	return tx
+}
+
+func TestStatsIndex_CountsAndLastActivity(t *testing.T) {
+	f := newStatsFixture(t)
+	f.addTx(t, f.alice, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	f.addTx(t, f.carol, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	f.addTx(t, f.bob, ledger.TxTypePostCreated, []byte("cid-post-1"))
+	last := f.addTx(t, f.bob, ledger.TxTypePostCreated, []byte("cid-post-2"))
+
+	stats, err := f.index.Stats(f.bob.Address)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Followers != 2 || stats.Following != 0 || stats.Posts != 2 {
+		t.Errorf("bob's stats = %+v, want 2 followers, 0 following, 2 posts", stats)
+	}
+	if stats.LastActiveAt != last.Timestamp {
+		t.Errorf("LastActiveAt = %d, want the final post's timestamp %d", stats.LastActiveAt, last.Timestamp)
+	}
+	if _, err := f.index.Stats("address-never-seen"); !errors.Is(err, user.ErrNoActivity) {
+		t.Errorf("unknown address error = %v, want ErrNoActivity", err)
+	}
+}
+
+func TestStatsIndex_UnfollowAndDuplicateFollow(t *testing.T) {
+	f := newStatsFixture(t)
+	f.addTx(t, f.alice, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	// A duplicate follow must not double-count.
+	f.addTx(t, f.alice, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	// An unfollow without a matching follow must not go negative.
+	f.addTx(t, f.carol, ledger.TxTypeUnfollowed, []byte(f.bob.Address))
+
+	stats, err := f.index.Stats(f.bob.Address)
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Followers != 1 {
+		t.Errorf("followers = %d, want 1 after duplicate follow and spurious unfollow", stats.Followers)
+	}
+
+	f.addTx(t, f.alice, ledger.TxTypeUnfollowed, []byte(f.bob.Address))
+	stats, _ = f.index.Stats(f.bob.Address)
+	aliceStats, _ := f.index.Stats(f.alice.Address)
+	if stats.Followers != 0 || aliceStats.Following != 0 {
+		t.Errorf("after unfollow: bob has %d followers, alice follows %d, want 0/0",
+			stats.Followers, aliceStats.Following)
+	}
+}
+
+func TestStatsIndex_TopByFollowersExcludesTombstoned(t *testing.T) {
+	f := newStatsFixture(t)
+	f.addTx(t, f.alice, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	f.addTx(t, f.carol, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	f.addTx(t, f.bob, ledger.TxTypeFollowed, []byte(f.carol.Address))
+
+	top := f.index.TopByFollowers(1)
+	if len(top) != 1 || top[0].Address != f.bob.Address {
+		t.Fatalf("TopByFollowers(1) = %+v, want bob first", top)
+	}
+
+	f.index.SetTombstoned(f.bob.Address, true)
+	top = f.index.TopByFollowers(1)
+	if len(top) != 1 || top[0].Address == f.bob.Address {
+		t.Errorf("TopByFollowers after tombstone = %+v, want bob excluded", top)
+	}
+}
+
+// The incremental index must agree with a from-scratch replay of the same
+// chain for every address it has seen.
+func TestStatsIndex_IncrementalMatchesRebuild(t *testing.T) {
+	f := newStatsFixture(t)
+	f.addTx(t, f.alice, ledger.TxTypeFollowed, []byte(f.bob.Address))
+	f.addTx(t, f.bob, ledger.TxTypePostCreated, []byte("cid-1"))
+	f.addTx(t, f.carol, ledger.TxTypeFollowed, []byte(f.alice.Address))
+	f.addTx(t, f.alice, ledger.TxTypeFollowed, []byte(f.carol.Address))
+	f.addTx(t, f.alice, ledger.TxTypeUnfollowed, []byte(f.bob.Address))
+	f.addTx(t, f.carol, ledger.TxTypePostCreated, []byte("cid-2"))
+
+	rebuilt := user.NewStatsIndex()
+	rebuilt.BuildFromChain(f.chain)
+	for _, addr := range []string{f.alice.Address, f.bob.Address, f.carol.Address} {
+		incremental, err := f.index.Stats(addr)
+		if err != nil {
+			t.Fatalf("incremental Stats(%s): %v", addr, err)
+		}
+		replayed, err := rebuilt.Stats(addr)
+		if err != nil {
+			t.Fatalf("rebuilt Stats(%s): %v", addr, err)
+		}
+		if incremental != replayed {
+			t.Errorf("stats diverge for %s: incremental %+v, rebuilt %+v", addr, incremental, replayed)
+		}
+	}
+}
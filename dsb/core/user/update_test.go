@@ -0,0 +1,137 @@
+package user_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+// recorderStub is a LedgerRecorder accepting or rejecting every version.
+type recorderStub struct {
+	records []string
+	fail    error
+}
+
+func (r *recorderStub) RecordProfileUpdate(owner, cid string) error {
+	if r.fail != nil {
+		return r.fail
+	}
+	r.records = append(r.records, cid)
+	return nil
+}
+
+// newFailingManager builds a manager whose DDS publishes cannot store
+// anything, so every publish fails.
+func newFailingManager(t *testing.T) *user.ProfileManager {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	dds := service.NewDDSCoreService(
+		p2p.NewNode("node-update-fail", "127.0.0.1:9021"),
+		storage.NewQuotaStorage(storage.NewInMemoryStorage(), 1, nil),
+		chunker, originator.NewMockOriginator(),
+		p2p.NewMockPeerDiscovery(), p2p.NewMockP2PService(),
+	)
+	return user.NewProfileManager(dds)
+}
+
+func TestUpdateProfile_SuccessSwapsAtomically(t *testing.T) {
+	manager := newTestManager(t)
+	recorder := &recorderStub{}
+	manager.SetLedgerRecorder(recorder)
+	wallet, profile := newTestWalletProfile(t)
+	if _, err := manager.PublishProfile(profile, wallet); err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+
+	result := manager.UpdateProfile(profile, wallet, "Ada L.", "updated bio")
+	if result.Err != nil || result.RolledBack {
+		t.Fatalf("UpdateProfile = %+v", result)
+	}
+	if result.NewCID == "" || result.Profile != profile {
+		t.Fatalf("result carries CID %q and a different profile pointer", result.NewCID)
+	}
+	if profile.Version != 2 || profile.DisplayName != "Ada L." || profile.Bio != "updated bio" {
+		t.Errorf("profile after update: v%d %q / %q", profile.Version, profile.DisplayName, profile.Bio)
+	}
+	if !profile.VerifySignature() {
+		t.Error("accepted version is not carrying a valid signature")
+	}
+	entry, ok := manager.Registry().Latest(profile.OwnerAddress)
+	if !ok || entry.CID != result.NewCID {
+		t.Errorf("registry latest is %+v, want the new CID %s", entry, result.NewCID)
+	}
+	if len(recorder.records) != 1 || recorder.records[0] != result.NewCID {
+		t.Errorf("ledger recorded %v, want [%s]", recorder.records, result.NewCID)
+	}
+}
+
+func TestUpdateProfile_PublishFailureLeavesProfileUntouched(t *testing.T) {
+	manager := newFailingManager(t)
+	wallet, profile := newTestWalletProfile(t)
+
+	result := manager.UpdateProfile(profile, wallet, "Ada L.", "never visible")
+	if result.Err == nil || !result.RolledBack {
+		t.Fatalf("UpdateProfile over broken storage = %+v", result)
+	}
+	if result.NewCID != "" {
+		t.Errorf("rolled-back result carries CID %q", result.NewCID)
+	}
+	if profile.Version != 1 || profile.DisplayName != "Ada" || profile.Bio != "first bio" {
+		t.Errorf("failed update mutated the profile: v%d %q / %q", profile.Version, profile.DisplayName, profile.Bio)
+	}
+	if len(profile.Signature) != 0 {
+		t.Error("failed update left a signature on the caller's profile")
+	}
+	if _, ok := manager.Registry().Latest(profile.OwnerAddress); ok {
+		t.Error("failed update registered a version")
+	}
+}
+
+func TestUpdateProfile_LedgerRejectionRollsBack(t *testing.T) {
+	manager := newTestManager(t)
+	rejection := errors.New("mempool full")
+	manager.SetLedgerRecorder(&recorderStub{fail: rejection})
+	wallet, profile := newTestWalletProfile(t)
+	firstCID, err := manager.PublishProfile(profile, wallet)
+	if err != nil {
+		t.Fatalf("PublishProfile: %v", err)
+	}
+
+	result := manager.UpdateProfile(profile, wallet, "Ada L.", "rejected by ledger")
+	if !result.RolledBack || !errors.Is(result.Err, rejection) {
+		t.Fatalf("UpdateProfile under ledger rejection = %+v", result)
+	}
+	if profile.Version != 1 || profile.DisplayName != "Ada" {
+		t.Errorf("ledger rejection mutated the profile: v%d %q", profile.Version, profile.DisplayName)
+	}
+	entry, ok := manager.Registry().Latest(profile.OwnerAddress)
+	if !ok || entry.CID != firstCID {
+		t.Errorf("registry latest is %+v, want the pre-update CID %s", entry, firstCID)
+	}
+}
+
+func TestUpdateProfile_InvalidUpdateRollsBack(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	tooLong := make([]byte, user.MaxDisplayNameLength+1)
+	for i := range tooLong {
+		tooLong[i] = 'x'
+	}
+
+	result := manager.UpdateProfile(profile, wallet, string(tooLong), "bio")
+	if !result.RolledBack || !errors.Is(result.Err, user.ErrProfileFieldTooLong) {
+		t.Fatalf("UpdateProfile with an oversized name = %+v", result)
+	}
+	if profile.Version != 1 || profile.DisplayName != "Ada" {
+		t.Errorf("invalid update mutated the profile: v%d %q", profile.Version, profile.DisplayName)
+	}
+}
@@ -0,0 +1,76 @@
+package user_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+// TestValidate_EntryPointsRejectIdentically drives the same invalid field
+// values through every entry point that accepts a profile — NewProfile,
+// Update, and FromJSON — and checks they all report the same sentinel. The
+// rules live in a single Validate, so a divergence here means an entry
+// point stopped calling it.
+func TestValidate_EntryPointsRejectIdentically(t *testing.T) {
+	longName := strings.Repeat("n", user.MaxDisplayNameLength+1)
+	longBio := strings.Repeat("b", user.MaxBioLength+1)
+
+	cases := []struct {
+		name        string
+		displayName string
+		bio         string
+		want        error
+	}{
+		{"missing display name", "", "bio", user.ErrProfileMissingDisplayName},
+		{"display name too long", longName, "bio", user.ErrProfileFieldTooLong},
+		{"bio too long", "Alice", longBio, user.ErrProfileFieldTooLong},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := user.NewProfile("owner-addr", tc.displayName, tc.bio); !errors.Is(err, tc.want) {
+				t.Errorf("NewProfile: %v, want %v", err, tc.want)
+			}
+
+			p, err := user.NewProfile("owner-addr", "Alice", "fine")
+			if err != nil {
+				t.Fatalf("NewProfile: %v", err)
+			}
+			// Update treats an empty display name as "no change", so the
+			// missing-name rule cannot trip there; the length rules must.
+			if tc.displayName != "" || tc.want != user.ErrProfileMissingDisplayName {
+				if err := p.Update(tc.displayName, tc.bio); !errors.Is(err, tc.want) {
+					t.Errorf("Update: %v, want %v", err, tc.want)
+				}
+				if p.DisplayName != "Alice" || p.Bio != "fine" || p.Version != 1 {
+					t.Errorf("rejected update mutated the profile: %+v", p)
+				}
+			}
+
+			doc := fmt.Sprintf(`{"ownerAddress": "owner-addr", "displayName": %q, "bio": %q, "version": 1}`,
+				tc.displayName, tc.bio)
+			if _, err := user.FromJSON([]byte(doc)); !errors.Is(err, tc.want) {
+				t.Errorf("FromJSON: %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidate_AcceptsWellFormedProfile(t *testing.T) {
+	p, err := user.NewProfile("owner-addr", "Alice", "hello")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate on a fresh profile: %v", err)
+	}
+	data, err := p.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	if _, err := user.FromJSON(data); err != nil {
+		t.Errorf("FromJSON round trip: %v", err)
+	}
+}
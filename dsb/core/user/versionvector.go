@@ -0,0 +1,118 @@
+package user
+
+import (
+	"fmt"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+// VectorOrder is the outcome of comparing two version vectors.
+type VectorOrder int
+
+// Possible orderings of two version vectors. VectorConcurrent means
+// neither vector dominates the other: the versions were produced
+// independently, e.g. by two devices updating the same base version.
+const (
+	VectorEqual VectorOrder = iota
+	VectorBefore
+	VectorAfter
+	VectorConcurrent
+)
+
+// CompareVersionVectors orders two version vectors. A missing device key
+// counts as zero, so vectors from devices that have never seen each other
+// remain comparable when one side is empty.
+func CompareVersionVectors(a, b map[string]int64) VectorOrder {
+	aAhead, bAhead := false, false
+	for device, av := range a {
+		if av > b[device] {
+			aAhead = true
+		}
+	}
+	for device, bv := range b {
+		if bv > a[device] {
+			bAhead = true
+		}
+	}
+	switch {
+	case aAhead && bAhead:
+		return VectorConcurrent
+	case aAhead:
+		return VectorAfter
+	case bAhead:
+		return VectorBefore
+	default:
+		return VectorEqual
+	}
+}
+
+// ErrProfileConflict reports two profile versions produced concurrently on
+// different devices, neither of whose version vectors dominates the other.
+// It carries both CIDs so the caller can fetch both versions and reconcile
+// them, e.g. with MergeProfiles.
+type ErrProfileConflict struct {
+	OwnerAddress string
+	ExistingCID  string
+	IncomingCID  string
+}
+
+// Error implements error.
+func (e *ErrProfileConflict) Error() string {
+	return fmt.Sprintf("user: concurrent profile versions for %s: %s and %s are incomparable",
+		e.OwnerAddress, e.ExistingCID, e.IncomingCID)
+}
+
+// MergeProfiles reconciles two concurrent versions of the same owner's
+// profile into an unsigned next version. For each differing scalar field
+// the resolver picks the winning value, called with the JSON field name
+// and both candidates. The merged version vector is the pointwise maximum
+// of both inputs plus a credit for a's device, so it strictly dominates
+// both and registers cleanly as the successor.
+func MergeProfiles(a, b *Profile, resolver func(field string, av, bv string) string) (*Profile, error) {
+	if a.OwnerAddress != b.OwnerAddress {
+		return nil, fmt.Errorf("user: cannot merge profiles owned by %s and %s",
+			a.OwnerAddress, b.OwnerAddress)
+	}
+	if a.Tombstoned || b.Tombstoned {
+		return nil, &ErrProfileTombstoned{OwnerAddress: a.OwnerAddress}
+	}
+	pick := func(field, av, bv string) string {
+		if av == bv {
+			return av
+		}
+		return resolver(field, av, bv)
+	}
+	merged := a.Clone()
+	merged.DisplayName = pick("displayName", a.DisplayName, b.DisplayName)
+	merged.Bio = pick("bio", a.Bio, b.Bio)
+	merged.ProfilePictureCID = pick("profilePictureCid", a.ProfilePictureCID, b.ProfilePictureCID)
+	merged.ProfilePictureThumbCID = pick("profilePictureThumbCid", a.ProfilePictureThumbCID, b.ProfilePictureThumbCID)
+
+	vector := make(map[string]int64, len(a.VersionVector)+len(b.VersionVector))
+	for device, counter := range a.VersionVector {
+		vector[device] = counter
+	}
+	for device, counter := range b.VersionVector {
+		if counter > vector[device] {
+			vector[device] = counter
+		}
+	}
+	switch {
+	case a.DeviceID != "":
+		vector[a.DeviceID]++
+	case b.DeviceID != "":
+		// The merging side has no device identity; credit b's so the
+		// result still strictly dominates both inputs.
+		vector[b.DeviceID]++
+	}
+	merged.VersionVector = vector
+
+	merged.Version = a.Version
+	if b.Version > merged.Version {
+		merged.Version = b.Version
+	}
+	merged.Version++
+	merged.Timestamp = clock.Now()
+	merged.Signature = nil
+	return merged, nil
+}
@@ -0,0 +1,134 @@
+package user_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+)
+
+func TestCompareVersionVectors(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b map[string]int64
+		want user.VectorOrder
+	}{
+		{"both empty", nil, nil, user.VectorEqual},
+		{"equal", map[string]int64{"a": 2, "b": 1}, map[string]int64{"a": 2, "b": 1}, user.VectorEqual},
+		{"a ahead", map[string]int64{"a": 3, "b": 1}, map[string]int64{"a": 2, "b": 1}, user.VectorAfter},
+		{"b ahead on new device", map[string]int64{"a": 2}, map[string]int64{"a": 2, "b": 1}, user.VectorBefore},
+		{"concurrent", map[string]int64{"a": 3, "b": 1}, map[string]int64{"a": 2, "b": 2}, user.VectorConcurrent},
+		{"empty vs populated", nil, map[string]int64{"a": 1}, user.VectorBefore},
+	}
+	for _, tt := range tests {
+		if got := user.CompareVersionVectors(tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: CompareVersionVectors = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// cloneProfile copies a profile deeply enough that two "devices" can
+// update it independently. The copy goes through JSON because Profile
+// carries a mutex and must not be copied by assignment.
+func cloneProfile(t *testing.T, p *user.Profile, deviceID string) *user.Profile {
+	t.Helper()
+	data, err := p.ToJSON()
+	if err != nil {
+		t.Fatalf("ToJSON: %v", err)
+	}
+	clone, err := user.FromJSON(data)
+	if err != nil {
+		t.Fatalf("FromJSON: %v", err)
+	}
+	clone.DeviceID = deviceID
+	return clone
+}
+
+func TestRegistry_SequentialDeviceUpdatesResolve(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	profile.DeviceID = "device-a"
+
+	if _, err := manager.UpdateAndPublishProfile(profile, wallet, "Ada", "from device a"); err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if _, err := manager.UpdateAndPublishProfile(profile, wallet, "Ada", "again from device a"); err != nil {
+		t.Fatalf("second update: %v", err)
+	}
+	entry, ok := manager.Registry().Latest(profile.OwnerAddress)
+	if !ok {
+		t.Fatal("owner missing from registry")
+	}
+	if got := entry.Vector["device-a"]; got != 2 {
+		t.Errorf("device-a counter = %d, want 2", got)
+	}
+}
+
+func TestRegistry_ConcurrentDeviceUpdatesConflict(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	profile.DeviceID = "device-a"
+	if _, err := manager.UpdateAndPublishProfile(profile, wallet, "Ada", "base version"); err != nil {
+		t.Fatalf("base update: %v", err)
+	}
+
+	// Both devices start from the same base version and update
+	// independently.
+	onA := cloneProfile(t, profile, "device-a")
+	onB := cloneProfile(t, profile, "device-b")
+	if _, err := manager.UpdateAndPublishProfile(onA, wallet, "Ada", "edited on a"); err != nil {
+		t.Fatalf("device a update: %v", err)
+	}
+	_, err := manager.UpdateAndPublishProfile(onB, wallet, "Ada", "edited on b")
+	if err == nil {
+		t.Fatal("concurrent update was silently accepted")
+	}
+	var conflict *user.ErrProfileConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("error is not *ErrProfileConflict: %v", err)
+	}
+	if conflict.ExistingCID == "" || conflict.IncomingCID == "" || conflict.ExistingCID == conflict.IncomingCID {
+		t.Errorf("conflict does not carry both CIDs: %+v", conflict)
+	}
+}
+
+func TestMergeProfiles_ProducesDominatingVector(t *testing.T) {
+	manager := newTestManager(t)
+	wallet, profile := newTestWalletProfile(t)
+	profile.DeviceID = "device-a"
+	if _, err := manager.UpdateAndPublishProfile(profile, wallet, "Ada", "base version"); err != nil {
+		t.Fatalf("base update: %v", err)
+	}
+	onA := cloneProfile(t, profile, "device-a")
+	onB := cloneProfile(t, profile, "device-b")
+	if err := onA.Update("Ada L.", "edited on a"); err != nil {
+		t.Fatalf("device a update: %v", err)
+	}
+	if err := onB.Update("Ada", "edited on b"); err != nil {
+		t.Fatalf("device b update: %v", err)
+	}
+
+	merged, err := user.MergeProfiles(onA, onB, func(field, av, bv string) string {
+		if field == "bio" {
+			return bv
+		}
+		return av
+	})
+	if err != nil {
+		t.Fatalf("MergeProfiles: %v", err)
+	}
+	if merged.DisplayName != "Ada L." || merged.Bio != "edited on b" {
+		t.Errorf("resolver choices not applied: %q / %q", merged.DisplayName, merged.Bio)
+	}
+	if got := user.CompareVersionVectors(merged.VersionVector, onA.VersionVector); got != user.VectorAfter {
+		t.Errorf("merged vector does not dominate device a's: %v", got)
+	}
+	if got := user.CompareVersionVectors(merged.VersionVector, onB.VersionVector); got != user.VectorAfter {
+		t.Errorf("merged vector does not dominate device b's: %v", got)
+	}
+
+	// The merged version registers cleanly over either concurrent branch.
+	if _, err := manager.PublishProfile(merged, wallet); err != nil {
+		t.Errorf("publishing merged profile: %v", err)
+	}
+}
@@ -0,0 +1,6 @@
+// Package e2e holds end-to-end scenario tests that exercise several
+// subsystems together — profiles, the DDS, and the ledger — across
+// multiple simulated nodes sharing one in-process mock network. The
+// package contains no library code; it exists so the full user-visible
+// flows run under go test and appear in coverage reports.
+package e2e
@@ -0,0 +1,93 @@
+package e2e_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/chunking"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/originator"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/p2p"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/service"
+	"github.com/BigBossBooling/AIPet3/dsb/core/dds/storage"
+)
+
+// world is a simulated network of DDS nodes: one mock P2P service and one
+// discovery registry shared by every node, so content published on one
+// node is retrievable from the others.
+type world struct {
+	network   *p2p.MockP2PService
+	discovery *p2p.MockPeerDiscovery
+	nodes     map[string]*worldNode
+}
+
+// worldNode is one participant: its own storage and service over the
+// shared network.
+type worldNode struct {
+	node  *p2p.Node
+	store storage.Storage
+	svc   *service.DDSCoreService
+}
+
+func newWorld() *world {
+	return &world{
+		network:   p2p.NewMockP2PService(),
+		discovery: p2p.NewMockPeerDiscovery(),
+		nodes:     make(map[string]*worldNode),
+	}
+}
+
+// addNode joins a new node with empty storage to the world.
+func (w *world) addNode(t *testing.T, id, address string) *worldNode {
+	t.Helper()
+	chunker, err := chunking.NewBasicChunker(64)
+	if err != nil {
+		t.Fatalf("NewBasicChunker: %v", err)
+	}
+	node := p2p.NewNode(id, address)
+	w.network.AddPeer(node)
+	w.discovery.RegisterNode(node)
+	store := storage.NewInMemoryStorage()
+	wn := &worldNode{
+		node:  node,
+		store: store,
+		svc: service.NewDDSCoreService(
+			node, store, chunker, originator.NewMockOriginator(),
+			w.discovery, w.network,
+		),
+	}
+	w.nodes[id] = wn
+	return wn
+}
+
+// publish publishes content on the node and mirrors the stored manifest
+// and chunks into the mock network, which serves peer fetches from its own
+// holdings rather than from each service's storage.
+func (w *world) publish(t *testing.T, on *worldNode, content []byte, contentType string) string {
+	t.Helper()
+	cid, err := on.svc.Publish(content, contentType)
+	if err != nil {
+		t.Fatalf("Publish on %s: %v", on.node.ID, err)
+	}
+	w.mirror(t, on, cid)
+	return cid
+}
+
+// mirror copies the manifest and chunks behind cid from the node's storage
+// into the mock network's view of that node.
+func (w *world) mirror(t *testing.T, on *worldNode, cid string) {
+	t.Helper()
+	manifest, err := on.store.GetManifest(cid)
+	if err != nil {
+		t.Fatalf("GetManifest %s on %s: %v", cid, on.node.ID, err)
+	}
+	chunks := make([]chunking.Chunk, 0, len(manifest.ChunkIDs))
+	for _, chunkID := range manifest.ChunkIDs {
+		chunk, err := on.store.GetChunk(chunkID)
+		if err != nil {
+			t.Fatalf("GetChunk %s on %s: %v", chunkID, on.node.ID, err)
+		}
+		chunks = append(chunks, *chunk)
+	}
+	if err := w.network.SeedPeerContent(on.node.ID, manifest, chunks); err != nil {
+		t.Fatalf("SeedPeerContent: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package e2e_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/core/user"
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+// TestProfileLifecycleAcrossNodes walks a profile through publish, update,
+// and retrieval on a second node, the scenario the old demo program ran
+// with prints instead of assertions.
+func TestProfileLifecycleAcrossNodes(t *testing.T) {
+	w := newWorld()
+	author := w.addNode(t, "node-e2e-author", "127.0.0.1:9080")
+	reader := w.addNode(t, "node-e2e-reader", "127.0.0.1:9081")
+	authorMgr := user.NewProfileManager(author.svc)
+	readerMgr := user.NewProfileManager(reader.svc)
+
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	profile, err := user.NewProfile(wallet.Address, "Ada", "first bio")
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+
+	var firstCID, secondCID string
+	t.Run("publish", func(t *testing.T) {
+		firstCID, err = authorMgr.PublishProfile(profile, wallet)
+		if err != nil {
+			t.Fatalf("PublishProfile: %v", err)
+		}
+		w.mirror(t, author, firstCID)
+	})
+
+	t.Run("retrieve on second node", func(t *testing.T) {
+		got, err := readerMgr.RetrieveProfile(firstCID)
+		if err != nil {
+			t.Fatalf("RetrieveProfile on reader: %v", err)
+		}
+		if got.OwnerAddress != wallet.Address || got.DisplayName != "Ada" || got.Version != 1 {
+			t.Errorf("reader saw %+v, want the published v1 profile", got)
+		}
+		if !got.VerifySignature() {
+			t.Error("retrieved profile fails signature verification")
+		}
+	})
+
+	t.Run("update and re-retrieve", func(t *testing.T) {
+		secondCID, err = authorMgr.UpdateAndPublishProfile(profile, wallet, "Ada L.", "second bio")
+		if err != nil {
+			t.Fatalf("UpdateAndPublishProfile: %v", err)
+		}
+		w.mirror(t, author, secondCID)
+
+		got, err := readerMgr.RetrieveProfile(secondCID)
+		if err != nil {
+			t.Fatalf("RetrieveProfile of update: %v", err)
+		}
+		if got.DisplayName != "Ada L." || got.Version != 2 {
+			t.Errorf("reader saw %+v, want the v2 profile", got)
+		}
+		if got.PreviousCID != firstCID {
+			t.Errorf("PreviousCID = %s, want %s linking to v1", got.PreviousCID, firstCID)
+		}
+	})
+}
+
+// TestProfileFailureInjection covers the ways retrieval on another node
+// can go wrong: the only provider offline, and a document whose owner
+// signature no longer matches its content.
+func TestProfileFailureInjection(t *testing.T) {
+	w := newWorld()
+	author := w.addNode(t, "node-e2e-author2", "127.0.0.1:9082")
+	reader := w.addNode(t, "node-e2e-reader2", "127.0.0.1:9083")
+	readerMgr := user.NewProfileManager(reader.svc)
+
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	t.Run("peer offline", func(t *testing.T) {
+		// Advertised but never mirrored into the network: discovery finds
+		// the provider, every fetch from it fails.
+		author.node.AddAdvertisedContent("cid-on-dead-peer")
+		if _, err := reader.svc.RetrieveFresh("cid-on-dead-peer"); err == nil {
+			t.Fatal("retrieval from an offline provider succeeded")
+		}
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		profile, err := user.NewProfile(wallet.Address, "Mallory", "bio")
+		if err != nil {
+			t.Fatalf("NewProfile: %v", err)
+		}
+		if err := profile.Sign(wallet); err != nil {
+			t.Fatalf("Sign: %v", err)
+		}
+		data, err := profile.ToJSON()
+		if err != nil {
+			t.Fatalf("ToJSON: %v", err)
+		}
+		// Tamper after signing: the document stays well-formed, the
+		// signature no longer covers it.
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("Unmarshal: %v", err)
+		}
+		doc["displayName"] = json.RawMessage(`"Mallory the Honest"`)
+		tampered, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		cid := w.publish(t, author, tampered, user.ProfileContentType)
+
+		_, err = readerMgr.RetrieveProfile(cid)
+		if err == nil || !strings.Contains(err.Error(), "signature") {
+			t.Fatalf("tampered profile retrieved without a signature error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+// Package compat pins the identifiers the wire and storage formats derive
+// — chunk IDs, manifest IDs, transaction IDs, block hashes — to golden
+// files, so a refactor that silently changes every CID fails loudly in
+// tests instead of splitting the network.
+//
+// Golden vectors live under testdata/v<GenerationVersion>/. To change a
+// format deliberately:
+//
+//  1. Bump GenerationVersion.
+//  2. Regenerate every vector: go test ./... -run Golden -update-golden
+//  3. Commit the new testdata directory alongside the format change, and
+//     note the bump in the change description — peers on the previous
+//     generation cannot address the same content.
+//
+// Determinism comes from the clock package: golden tests freeze time with
+// clock.Set(clock.NewFake(...)) rather than using special constructors.
+package compat
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// GenerationVersion names the current CID/hash format generation. Bump it
+// — and regenerate the golden files — only when a format change is
+// intentional.
+const GenerationVersion = 2
+
+var update = flag.Bool("update-golden", false,
+	"rewrite compat golden vectors instead of comparing against them")
+
+// baseDir is the compat package's source directory, so golden files
+// resolve regardless of which package's tests are running.
+var baseDir = func() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return "."
+	}
+	return filepath.Dir(file)
+}()
+
+// AssertStableCID compares got against the named golden vector for the
+// current generation, failing with regeneration instructions on any
+// difference. With -update-golden it rewrites the vector instead.
+func AssertStableCID(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join(baseDir, "testdata", fmt.Sprintf("v%d", GenerationVersion), name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("compat: failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("compat: failed to write golden %s: %v", name, err)
+		}
+		t.Logf("compat: rewrote golden %s", name)
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("compat: no golden vector %q for generation %d (run with -update-golden to create it): %v",
+			name, GenerationVersion, err)
+	}
+	if got != string(want) {
+		t.Fatalf("compat: %s changed for generation %d:\n got: %s\nwant: %s\n"+
+			"If this format change is intentional, bump compat.GenerationVersion and regenerate with -update-golden.",
+			name, GenerationVersion, got, want)
+	}
+}
@@ -0,0 +1,13 @@
+package compat_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/internal/compat"
+)
+
+// TestGoldenSelfTest exercises the lookup path against a vector committed
+// for that purpose.
+func TestGoldenSelfTest(t *testing.T) {
+	compat.AssertStableCID(t, "self-test", "stable")
+}
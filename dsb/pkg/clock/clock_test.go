@@ -0,0 +1,51 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/clock"
+)
+
+func TestFake_FrozenUntilAdvanced(t *testing.T) {
+	fake := clock.NewFake(1000)
+	if fake.Now() != 1000 {
+		t.Fatalf("Now() = %d, want 1000", fake.Now())
+	}
+	if fake.Now() != 1000 {
+		t.Fatal("fake clock moved without Advance")
+	}
+	fake.Advance(time.Microsecond)
+	if fake.Now() != 1000+time.Microsecond.Nanoseconds() {
+		t.Fatalf("Now() after Advance = %d", fake.Now())
+	}
+}
+
+func TestSet_SwapsAndRestoresDefault(t *testing.T) {
+	fake := clock.NewFake(42)
+	prev := clock.Set(fake)
+	defer clock.Set(prev)
+
+	if clock.Now() != 42 {
+		t.Fatalf("Now() = %d, want the fake's 42", clock.Now())
+	}
+	if got := clock.NowTime(); !got.Equal(time.Unix(0, 42)) {
+		t.Fatalf("NowTime() = %v, want %v", got, time.Unix(0, 42))
+	}
+
+	clock.Set(prev)
+	before := time.Now().UnixNano()
+	if now := clock.Now(); now < before {
+		t.Fatalf("restored clock reads %d, before %d", now, before)
+	}
+}
+
+func TestSet_NilRestoresSystemClock(t *testing.T) {
+	prev := clock.Set(clock.NewFake(1))
+	defer clock.Set(prev)
+
+	clock.Set(nil)
+	if clock.Now() == 1 {
+		t.Fatal("Set(nil) left the fake clock active")
+	}
+}
@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a manually advanced clock for tests. Time stands still until
+// Advance is called, so tests control exactly how much time "passes"
+// instead of sleeping.
+type Fake struct {
+	mu  sync.Mutex
+	now int64
+}
+
+// NewFake returns a fake clock frozen at the given Unix-nanosecond time.
+func NewFake(now int64) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the fake's current time in Unix nanoseconds.
+func (f *Fake) Now() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the fake clock forward by d.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now += d.Nanoseconds()
+}
@@ -0,0 +1,169 @@
+package identity
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ChainParams names the chain-wide parameters that distinguish one network
+// from another. Today that is just the human-readable address prefix, so a
+// testnet address ("dsbtest1...") can never be mistaken for a mainnet one.
+type ChainParams struct {
+	// AddressPrefix is the human-readable part of encoded addresses. An
+	// empty prefix falls back to the legacy bare-hex encoding.
+	AddressPrefix string
+}
+
+// Params holds the active chain parameters. Override it at process start,
+// before any addresses are derived; changing it mid-run would make one
+// process render the same identity two ways.
+var Params = ChainParams{AddressPrefix: "dsb"}
+
+// bech32Charset maps 5-bit groups to characters, per BIP-173. It excludes
+// "1" (the prefix separator) and visually ambiguous characters.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+// EncodeAddress renders a raw address hash as a bech32 string under the
+// given human-readable prefix, e.g. "dsb1...". The 6-character checksum
+// catches any single typo and most multi-character ones.
+func EncodeAddress(rawHash []byte, prefix string) (string, error) {
+	if len(rawHash) == 0 {
+		return "", fmt.Errorf("identity: cannot encode an empty address hash")
+	}
+	if err := checkPrefix(prefix); err != nil {
+		return "", err
+	}
+	data := convertBits(rawHash, 8, 5, true)
+	var b strings.Builder
+	b.WriteString(prefix)
+	b.WriteByte('1')
+	for _, v := range data {
+		b.WriteByte(bech32Charset[v])
+	}
+	for _, v := range bech32Checksum(prefix, data) {
+		b.WriteByte(bech32Charset[v])
+	}
+	return b.String(), nil
+}
+
+// DecodeAddress parses a bech32 address back into its raw hash and prefix,
+// verifying the checksum. Mixed-case strings are rejected, as bech32
+// requires.
+func DecodeAddress(addr string) (raw []byte, prefix string, err error) {
+	if strings.ToLower(addr) != addr && strings.ToUpper(addr) != addr {
+		return nil, "", fmt.Errorf("identity: address %q mixes upper and lower case", addr)
+	}
+	addr = strings.ToLower(addr)
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || len(addr)-sep-1 < 7 {
+		return nil, "", fmt.Errorf("identity: address %q is not prefix-encoded", addr)
+	}
+	prefix = addr[:sep]
+	if err := checkPrefix(prefix); err != nil {
+		return nil, "", err
+	}
+	data := make([]byte, 0, len(addr)-sep-1)
+	for _, c := range addr[sep+1:] {
+		v := strings.IndexRune(bech32Charset, c)
+		if v < 0 {
+			return nil, "", fmt.Errorf("identity: address contains invalid character %q", c)
+		}
+		data = append(data, byte(v))
+	}
+	if bech32Polymod(append(bech32ExpandPrefix(prefix), data...)) != 1 {
+		return nil, "", fmt.Errorf("identity: address %q fails its checksum", addr)
+	}
+	raw = convertBits(data[:len(data)-6], 5, 8, false)
+	return raw, prefix, nil
+}
+
+// NormalizeAddress reduces an address to its canonical comparison form: the
+// lowercase hex of the raw hash. Both legacy bare-hex addresses and
+// prefix-encoded ones normalize to the same string, which is what lets the
+// two coexist during the migration window. Strings in neither form are
+// returned lowercased as-is.
+func NormalizeAddress(addr string) string {
+	if raw, _, err := DecodeAddress(addr); err == nil {
+		return hex.EncodeToString(raw)
+	}
+	return strings.ToLower(addr)
+}
+
+// AddressesEqual reports whether two addresses name the same identity,
+// regardless of which encoding each uses.
+func AddressesEqual(a, b string) bool {
+	return NormalizeAddress(a) == NormalizeAddress(b)
+}
+
+func checkPrefix(prefix string) error {
+	if prefix == "" {
+		return fmt.Errorf("identity: address prefix must not be empty")
+	}
+	for _, c := range prefix {
+		if c < 33 || c > 126 || (c >= 'A' && c <= 'Z') {
+			return fmt.Errorf("identity: address prefix %q contains invalid character %q", prefix, c)
+		}
+	}
+	return nil
+}
+
+// convertBits regroups a bit stream between group sizes, e.g. 8-bit bytes
+// into the 5-bit groups bech32 encodes. Encoding pads the tail; decoding
+// drops the padding bits.
+func convertBits(data []byte, from, to uint, pad bool) []byte {
+	var acc, bits uint
+	out := make([]byte, 0, (len(data)*int(from)+int(to)-1)/int(to))
+	maxv := uint(1)<<to - 1
+	for _, b := range data {
+		acc = acc<<from | uint(b)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad && bits > 0 {
+		out = append(out, byte(acc<<(to-bits)&maxv))
+	}
+	return out
+}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = chk&0x1ffffff<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if top>>uint(i)&1 == 1 {
+				chk ^= bech32Generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32ExpandPrefix(prefix string) []byte {
+	out := make([]byte, 0, len(prefix)*2+1)
+	for i := 0; i < len(prefix); i++ {
+		out = append(out, prefix[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(prefix); i++ {
+		out = append(out, prefix[i]&31)
+	}
+	return out
+}
+
+func bech32Checksum(prefix string, data []byte) []byte {
+	values := append(bech32ExpandPrefix(prefix), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := bech32Polymod(values) ^ 1
+	out := make([]byte, 6)
+	for i := 0; i < 6; i++ {
+		out[i] = byte(mod >> uint(5*(5-i)) & 31)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package identity_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func TestEncodeAddress_RoundTrip(t *testing.T) {
+	sum := sha256.Sum256([]byte("some public key"))
+	addr, err := identity.EncodeAddress(sum[:], "dsb")
+	if err != nil {
+		t.Fatalf("EncodeAddress: %v", err)
+	}
+	if !strings.HasPrefix(addr, "dsb1") {
+		t.Errorf("address %q does not start with the prefix and separator", addr)
+	}
+	raw, prefix, err := identity.DecodeAddress(addr)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %v", err)
+	}
+	if prefix != "dsb" {
+		t.Errorf("decoded prefix %q, want dsb", prefix)
+	}
+	if !bytes.Equal(raw, sum[:]) {
+		t.Errorf("decoded hash %x, want %x", raw, sum)
+	}
+}
+
+func TestEncodeAddress_RejectsBadInput(t *testing.T) {
+	if _, err := identity.EncodeAddress(nil, "dsb"); err == nil {
+		t.Error("encoding an empty hash succeeded")
+	}
+	if _, err := identity.EncodeAddress([]byte{1}, ""); err == nil {
+		t.Error("encoding with an empty prefix succeeded")
+	}
+	if _, err := identity.EncodeAddress([]byte{1}, "DSB"); err == nil {
+		t.Error("encoding with an upper-case prefix succeeded")
+	}
+}
+
+func TestDecodeAddress_ChecksumCatchesTypos(t *testing.T) {
+	sum := sha256.Sum256([]byte("typo fodder"))
+	addr, err := identity.EncodeAddress(sum[:], "dsb")
+	if err != nil {
+		t.Fatalf("EncodeAddress: %v", err)
+	}
+	// Flip each data character to a different charset character; every
+	// single-character typo must fail the checksum.
+	for i := len("dsb1"); i < len(addr); i++ {
+		mutated := []byte(addr)
+		if mutated[i] == 'q' {
+			mutated[i] = 'p'
+		} else {
+			mutated[i] = 'q'
+		}
+		if _, _, err := identity.DecodeAddress(string(mutated)); err == nil {
+			t.Fatalf("typo at position %d went undetected: %s", i, mutated)
+		}
+	}
+	if _, _, err := identity.DecodeAddress("Dsb1" + addr[4:]); err == nil {
+		t.Error("mixed-case address was accepted")
+	}
+}
+
+func TestDecodeAddress_WrongPrefixIsVisible(t *testing.T) {
+	sum := sha256.Sum256([]byte("network split"))
+	testnet, err := identity.EncodeAddress(sum[:], "dsbtest")
+	if err != nil {
+		t.Fatalf("EncodeAddress: %v", err)
+	}
+	_, prefix, err := identity.DecodeAddress(testnet)
+	if err != nil {
+		t.Fatalf("DecodeAddress: %v", err)
+	}
+	if prefix != "dsbtest" {
+		t.Errorf("decoded prefix %q, want dsbtest", prefix)
+	}
+	// Grafting the mainnet prefix onto the testnet data part must fail:
+	// the checksum covers the prefix.
+	grafted := "dsb" + testnet[len("dsbtest"):]
+	if _, _, err := identity.DecodeAddress(grafted); err == nil {
+		t.Error("address re-prefixed to another network was accepted")
+	}
+}
+
+func TestAddressesEqual_MixedLegacyAndEncoded(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	sum := sha256.Sum256(wallet.PublicKey)
+	legacy := hex.EncodeToString(sum[:])
+	if !identity.AddressesEqual(wallet.Address, legacy) {
+		t.Errorf("encoded address %s does not equal its legacy form %s", wallet.Address, legacy)
+	}
+	if identity.NormalizeAddress(wallet.Address) != legacy {
+		t.Errorf("NormalizeAddress(%s) = %s, want %s", wallet.Address, identity.NormalizeAddress(wallet.Address), legacy)
+	}
+	other, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	if identity.AddressesEqual(wallet.Address, other.Address) {
+		t.Error("distinct identities compare equal")
+	}
+}
+
+func TestVerifySignature_AcceptsLegacyHexSender(t *testing.T) {
+	wallet, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	sum := sha256.Sum256(wallet.PublicKey)
+	// A wallet restored from data written before the prefix migration
+	// carries a bare-hex address but the same keys.
+	legacy := &identity.Wallet{
+		PrivateKey: wallet.PrivateKey,
+		PublicKey:  wallet.PublicKey,
+		Address:    hex.EncodeToString(sum[:]),
+	}
+	sig, err := legacy.Sign([]byte("payload"))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if !identity.VerifySignature(legacy.PublicKey, []byte("payload"), sig) {
+		t.Error("legacy wallet signature does not verify")
+	}
+	if !identity.AddressesEqual(legacy.Address, identity.PublicKeyToAddress(legacy.PublicKey)) {
+		t.Error("legacy address does not match the freshly derived one")
+	}
+}
@@ -0,0 +1,37 @@
+package identity_test
+
+import (
+	"testing"
+
+	"github.com/BigBossBooling/AIPet3/dsb/pkg/identity"
+)
+
+func TestWalletEqual(t *testing.T) {
+	a, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+	b, err := identity.NewWallet()
+	if err != nil {
+		t.Fatalf("NewWallet: %v", err)
+	}
+
+	if !a.Equal(a) {
+		t.Error("wallet does not equal itself")
+	}
+	// A watch-only copy without the private key is still the same identity.
+	watchOnly := &identity.Wallet{PublicKey: a.PublicKey, Address: a.Address}
+	if !a.Equal(watchOnly) {
+		t.Error("wallet does not equal its watch-only copy")
+	}
+	if a.Equal(b) {
+		t.Error("distinct wallets compare equal")
+	}
+	if a.Equal(nil) {
+		t.Error("wallet equals nil")
+	}
+	var nilWallet *identity.Wallet
+	if !nilWallet.Equal(nil) {
+		t.Error("nil wallets do not compare equal")
+	}
+}
@@ -0,0 +1,93 @@
+package identity
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+)
+
+// WrapKey derives a key-encryption key for the holder of recipientPub (a
+// compressed SEC 1 P-256 key) via ephemeral ECDH and seals key with it
+// using AES-GCM. The returned ephemeral public key and nonce must be
+// transmitted alongside the wrapped key for the recipient to unwrap it.
+func WrapKey(recipientPub, key []byte) (ephemeralPub, nonce, wrapped []byte, err error) {
+	recipientECDH, err := compressedToECDH(recipientPub)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ephemeral, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("identity: failed to generate ephemeral key: %w", err)
+	}
+	shared, err := ephemeral.ECDH(recipientECDH)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("identity: ECDH failed: %w", err)
+	}
+	kek := sha256.Sum256(shared)
+	gcm, err := keyWrapGCM(kek[:])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, nil, fmt.Errorf("identity: failed to generate nonce: %w", err)
+	}
+	return ephemeral.PublicKey().Bytes(), nonce, gcm.Seal(nil, nonce, key, nil), nil
+}
+
+// UnwrapKey recovers a key wrapped with WrapKey using the wallet's private
+// key.
+func UnwrapKey(wallet *Wallet, ephemeralPub, nonce, wrapped []byte) ([]byte, error) {
+	ecdhPriv, err := wallet.PrivateKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("identity: wallet key does not support ECDH: %w", err)
+	}
+	pub, err := ecdh.P256().NewPublicKey(ephemeralPub)
+	if err != nil {
+		return nil, fmt.Errorf("identity: invalid ephemeral public key: %w", err)
+	}
+	shared, err := ecdhPriv.ECDH(pub)
+	if err != nil {
+		return nil, fmt.Errorf("identity: ECDH failed: %w", err)
+	}
+	kek := sha256.Sum256(shared)
+	gcm, err := keyWrapGCM(kek[:])
+	if err != nil {
+		return nil, err
+	}
+	key, err := gcm.Open(nil, nonce, wrapped, nil)
+	if err != nil {
+		return nil, fmt.Errorf("identity: failed to unwrap key: %w", err)
+	}
+	return key, nil
+}
+
+// keyWrapGCM builds an AES-GCM sealer for a 32-byte key-encryption key.
+func keyWrapGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("identity: bad key-encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// compressedToECDH converts a compressed SEC 1 P-256 key to its ECDH form.
+func compressedToECDH(compressed []byte) (*ecdh.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), compressed)
+	if x == nil {
+		return nil, fmt.Errorf("identity: invalid compressed public key")
+	}
+	raw := make([]byte, 1, 65)
+	raw[0] = 4
+	raw = append(raw, x.FillBytes(make([]byte, 32))...)
+	raw = append(raw, y.FillBytes(make([]byte, 32))...)
+	pub, err := ecdh.P256().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("identity: invalid public key point: %w", err)
+	}
+	return pub, nil
+}
@@ -0,0 +1,93 @@
+// Package identity provides wallets: ECDSA P-256 key pairs with a derived
+// address used to identify users across the ledger and the social layer.
+// Addresses are the SHA-256 digest of the compressed public key, rendered
+// bech32-style under the chain's human-readable prefix (see ChainParams);
+// the legacy bare-hex rendering is still accepted wherever addresses are
+// compared.
+package identity
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Wallet is a user's key pair and derived address.
+type Wallet struct {
+	PrivateKey *ecdsa.PrivateKey
+	// PublicKey is the compressed SEC 1 encoding of the public key.
+	PublicKey []byte
+	Address   string
+}
+
+// NewWallet generates a fresh P-256 key pair.
+func NewWallet() (*Wallet, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("identity: failed to generate key pair: %w", err)
+	}
+	pub := elliptic.MarshalCompressed(elliptic.P256(), priv.PublicKey.X, priv.PublicKey.Y)
+	return &Wallet{
+		PrivateKey: priv,
+		PublicKey:  pub,
+		Address:    PublicKeyToAddress(pub),
+	}, nil
+}
+
+// Equal reports whether two wallets hold the same identity, comparing the
+// derived address and public key. Private key material is deliberately not
+// compared: a watch-only copy of a wallet equals the signing original.
+// Either side being nil makes them equal only if both are.
+func (w *Wallet) Equal(other *Wallet) bool {
+	if w == nil || other == nil {
+		return w == other
+	}
+	return w.Address == other.Address && bytes.Equal(w.PublicKey, other.PublicKey)
+}
+
+// PublicKeyToAddress derives the address for a compressed public key,
+// encoded under Params.AddressPrefix. With an empty prefix it falls back
+// to the legacy bare-hex form.
+func PublicKeyToAddress(publicKey []byte) string {
+	sum := sha256.Sum256(publicKey)
+	if Params.AddressPrefix == "" {
+		return hex.EncodeToString(sum[:])
+	}
+	addr, err := EncodeAddress(sum[:], Params.AddressPrefix)
+	if err != nil {
+		// Unreachable with a non-empty valid prefix; fall back rather
+		// than panic in address derivation.
+		return hex.EncodeToString(sum[:])
+	}
+	return addr
+}
+
+// Sign signs the SHA-256 digest of data with the wallet's private key,
+// returning an ASN.1 DER signature.
+func (w *Wallet) Sign(data []byte) ([]byte, error) {
+	if w.PrivateKey == nil {
+		return nil, fmt.Errorf("identity: wallet %s has no private key", w.Address)
+	}
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, w.PrivateKey, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("identity: signing failed: %w", err)
+	}
+	return sig, nil
+}
+
+// VerifySignature reports whether signature is a valid signature of data by
+// the holder of the given compressed public key.
+func VerifySignature(publicKey, data, signature []byte) bool {
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), publicKey)
+	if x == nil {
+		return false
+	}
+	pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	digest := sha256.Sum256(data)
+	return ecdsa.VerifyASN1(pub, digest[:], signature)
+}